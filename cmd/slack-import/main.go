@@ -0,0 +1,105 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"huddle/internal/config"
+	"huddle/internal/database"
+	"huddle/internal/slackimport"
+	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+
+	"go.uber.org/zap"
+)
+
+func main() {
+	archivePath := flag.String("archive", "", "path to the Slack export ZIP")
+	adminEmail := flag.String("admin-email", "", "email of the Huddle user running the import")
+	slackToken := flag.String("token", os.Getenv("SLACK_TOKEN"), "Slack token used to download attached files (defaults to $SLACK_TOKEN)")
+	channels := flag.String("channels", "", "comma separated channel names to import (default: all)")
+	reportPath := flag.String("report", "slack-import-report.json", "where to write the mapping report")
+	flag.Parse()
+
+	// Initialize logger
+	if err := logger.InitLogger(); err != nil {
+		panic("Failed to initialize logger: " + err.Error())
+	}
+	defer logger.Sync()
+
+	if *archivePath == "" || *adminEmail == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	// Load configuration
+	if err := config.Load(); err != nil {
+		logger.Fatal("Failed to load config", zap.Error(err))
+	}
+
+	// Initialize database
+	if err := database.InitDatabase(); err != nil {
+		logger.Fatal("Failed to initialize database", zap.Error(err))
+	}
+	defer database.CloseDatabase()
+
+	// Initialize MinIO; without it messages are imported but files are reported as failed
+	if client, err := minio.NewClient(); err != nil {
+		logger.Error("Failed to create MinIO client, files will not be copied", zap.Error(err))
+	} else {
+		minio.SetClient(client)
+	}
+
+	opts := &slackimport.Options{
+		ArchivePath: *archivePath,
+		AdminEmail:  *adminEmail,
+		SlackToken:  *slackToken,
+	}
+	for _, name := range strings.Split(*channels, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			opts.Channels = append(opts.Channels, name)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	logger.Info("🚚 Importing Slack export...", zap.String("archive", *archivePath))
+	service := slackimport.NewService(slackimport.NewRepository())
+	report, importErr := service.Import(ctx, opts)
+
+	// A partial report is still written so the run can be inspected and resumed
+	if report != nil {
+		if err := writeReport(*reportPath, report); err != nil {
+			logger.Error("Failed to write import report", zap.Error(err))
+		} else {
+			logger.Info("📄 Import report written", zap.String("path", *reportPath))
+		}
+	}
+
+	if importErr != nil {
+		logger.Error("Slack import failed", zap.Error(importErr))
+		os.Exit(1)
+	}
+
+	logger.Info("✅ Slack import completed",
+		zap.Int("conversations_created", report.Totals.ConversationsCreated),
+		zap.Int("messages_imported", report.Totals.MessagesImported),
+		zap.Int("messages_existing", report.Totals.MessagesExisting),
+		zap.Int("files_copied", report.Totals.FilesCopied),
+		zap.Int("errors", report.Totals.Errors))
+}
+
+// writeReport writes the mapping report as indented JSON
+func writeReport(path string, report *slackimport.Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
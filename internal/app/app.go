@@ -9,11 +9,16 @@ import (
 	"huddle/internal/auth"
 	"huddle/internal/config"
 	"huddle/internal/conversation"
+	"huddle/internal/emoji"
+	"huddle/internal/export"
 	"huddle/internal/file"
 	"huddle/internal/friend"
 	"huddle/internal/health"
 	"huddle/internal/message"
 	"huddle/internal/middleware"
+	"huddle/internal/moderation"
+	"huddle/internal/report"
+	"huddle/internal/retention"
 	"huddle/internal/user"
 	"huddle/internal/websocket"
 	"huddle/pkg/logger"
@@ -67,25 +72,65 @@ func setupRoutes(router *gin.Engine) {
 	friendHandler := friend.NewHandler(friendService)
 	logger.Info("Friend module initialized successfully")
 
+	// Initialize WebSocket module first (needed by conversation and message modules)
+	logger.Info("Initializing WebSocket module...")
+	wsService := websocket.NewService()
+	wsHandler := websocket.NewHandler(wsService)
+	logger.Info("WebSocket module initialized successfully")
+
 	// Initialize conversation module
 	logger.Info("Initializing conversation module...")
 	conversationRepo := conversation.NewRepository()
-	conversationService := conversation.NewService(conversationRepo)
+	conversationService := conversation.NewService(conversationRepo, wsService)
 	conversationHandler := conversation.NewHandler(conversationService)
 	logger.Info("Conversation module initialized successfully")
 
-	// Initialize WebSocket module first (needed by message module)
-	logger.Info("Initializing WebSocket module...")
-	wsService := websocket.NewService()
-	wsHandler := websocket.NewHandler(wsService)
-	logger.Info("WebSocket module initialized successfully")
+	// Initialize moderation module (applied by the message module before persistence)
+	logger.Info("Initializing moderation module...")
+	moderationRepo := moderation.NewRepository()
+	moderationService := moderation.NewService(moderationRepo, wsService, conversationService)
+	moderationHandler := moderation.NewHandler(moderationService)
+	logger.Info("Moderation module initialized successfully")
+
+	// Initialize file module (needed by the emoji and report modules)
+	logger.Info("Initializing file module...")
+	fileRepo := file.NewRepository()
+	fileService := file.NewService(fileRepo)
+	fileHandler := file.NewHandler(fileService)
+	logger.Info("File module initialized successfully")
+
+	// Initialize emoji module (custom emoji and stickers, used by the message module)
+	logger.Info("Initializing emoji module...")
+	emojiRepo := emoji.NewRepository()
+	emojiService := emoji.NewService(emojiRepo, moderationService, fileService)
+	emojiHandler := emoji.NewHandler(emojiService)
+	logger.Info("Emoji module initialized successfully")
 
 	// Initialize message module
 	logger.Info("Initializing message module...")
 	messageRepo := message.NewRepository()
-	messageService := message.NewService(messageRepo, wsService)
+	messageService := message.NewService(messageRepo, wsService, conversationService, moderationService, emojiService)
 	messageHandler := message.NewHandler(messageService)
+	messageDispatcher := message.NewDispatcher(messageRepo, messageService)
+	messageSweeper := message.NewSweeper(messageRepo, wsService)
+	wsService.SetLocationHandler(messageService)
 	logger.Info("Message module initialized successfully")
+
+	// Initialize export module
+	logger.Info("Initializing export module...")
+	exportRepo := export.NewRepository()
+	exportService := export.NewService(exportRepo, wsService, conversationService)
+	exportHandler := export.NewHandler(exportService)
+	exportWorker := export.NewWorker(exportRepo, exportService)
+	logger.Info("Export module initialized successfully")
+
+	// Initialize retention module
+	logger.Info("Initializing retention module...")
+	retentionRepo := retention.NewRepository()
+	retentionService := retention.NewService(retentionRepo, wsService, conversationService, moderationService)
+	retentionHandler := retention.NewHandler(retentionService)
+	retentionWorker := retention.NewWorker(retentionService)
+	logger.Info("Retention module initialized successfully")
 	
 	// API routes
 	api := router.Group("/api")
@@ -116,12 +161,37 @@ func setupRoutes(router *gin.Engine) {
 
 	// File routes
 	logger.Info("Setting up file routes...")
-	fileRepo := file.NewRepository()
-	fileService := file.NewService(fileRepo)
-	fileHandler := file.NewHandler(fileService)
 	file.SetupRoutes(api, fileHandler)
 	logger.Info("File routes setup completed")
 
+	// Emoji and sticker routes
+	logger.Info("Setting up emoji routes...")
+	emoji.SetupRoutes(api, emojiHandler)
+	logger.Info("Emoji routes setup completed")
+
+	// Moderation routes
+	logger.Info("Setting up moderation routes...")
+	moderation.SetupRoutes(api, moderationHandler)
+	logger.Info("Moderation routes setup completed")
+
+	// Report routes (snapshots files through the file module)
+	logger.Info("Setting up report routes...")
+	reportRepo := report.NewRepository()
+	reportService := report.NewService(reportRepo, wsService, moderationService, fileService)
+	reportHandler := report.NewHandler(reportService)
+	report.SetupRoutes(api, reportHandler)
+	logger.Info("Report routes setup completed")
+
+	// Retention routes
+	logger.Info("Setting up retention routes...")
+	retention.SetupRoutes(api, retentionHandler)
+	logger.Info("Retention routes setup completed")
+
+	// Export routes
+	logger.Info("Setting up export routes...")
+	export.SetupRoutes(api, exportHandler)
+	logger.Info("Export routes setup completed")
+
 	// WebSocket routes
 	logger.Info("Setting up WebSocket routes...")
 	websocket.SetupRoutes(api, wsHandler)
@@ -133,6 +203,26 @@ func setupRoutes(router *gin.Engine) {
 	wsService.StartHub()
 	logger.Info("WebSocket hub started successfully")
 
+	// Start scheduled message dispatcher
+	logger.Info("Starting scheduled message dispatcher...")
+	messageDispatcher.Start()
+	logger.Info("Scheduled message dispatcher started successfully")
+
+	// Start disappearing message sweeper
+	logger.Info("Starting expired message sweeper...")
+	messageSweeper.Start()
+	logger.Info("Expired message sweeper started successfully")
+
+	// Start conversation export worker
+	logger.Info("Starting conversation export worker...")
+	exportWorker.Start()
+	logger.Info("Conversation export worker started successfully")
+
+	// Start retention purge worker
+	logger.Info("Starting retention purge worker...")
+	retentionWorker.Start()
+	logger.Info("Retention purge worker started successfully")
+
 	// Root route
 	router.GET("/", func(c *gin.Context) {
 		c.JSON(http.StatusOK, gin.H{
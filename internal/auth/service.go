@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"errors"
+	"fmt"
 	"time"
 
 	"huddle/internal/user"
@@ -53,6 +54,14 @@ func (s *service) Login(ctx context.Context, req *LoginRequest, ipAddress, userA
 		return nil, errors.New("account is temporarily locked")
 	}
 	
+	// Check if account is suspended
+	if u.IsSuspended() {
+		s.LogActivity(ctx, u.ID, "login_failed", ipAddress, userAgent, map[string]interface{}{
+			"reason": "account_suspended",
+		})
+		return nil, fmt.Errorf("account is suspended until %s", u.SuspendedUntil.UTC().Format(time.RFC3339))
+	}
+	
 	// Verify password
 	if !auth.CheckPassword(req.Password, u.Password) {
 		// Increment login attempts
@@ -238,6 +247,11 @@ func (s *service) RefreshToken(ctx context.Context, refreshToken string) (*Refre
 		return nil, errors.New("invalid refresh token")
 	}
 	
+	// Suspended users cannot renew their tokens
+	if u, err := s.repo.GetUserByUsername(ctx, claims.Username); err == nil && u.IsSuspended() {
+		return nil, errors.New("account is suspended")
+	}
+	
 	// Generate new tokens
 	tokens, err := auth.GenerateTokenPair(claims.UserID, claims.Username, claims.Email)
 	if err != nil {
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -15,6 +16,10 @@ type Config struct {
 	JWT      JWTConfig
 	Server   ServerConfig
 	MinIO    MinIOConfig
+	LinkPreview LinkPreviewConfig
+	Bots     BotConfig
+	Moderation ModerationConfig
+	Retention RetentionConfig
 }
 
 type DatabaseConfig struct {
@@ -51,6 +56,32 @@ type MinIOConfig struct {
 	UseSSL          bool
 }
 
+type LinkPreviewConfig struct {
+	Enabled      bool
+	Timeout      time.Duration
+	MaxBytes     int
+	CacheTTL     time.Duration
+	AllowedHosts []string // Hostnames or CIDRs exempt from the private address block
+}
+
+type BotConfig struct {
+	WebhookTimeout time.Duration
+	AllowedHosts   []string // Hostnames or CIDRs bot webhooks may use despite resolving to private addresses
+}
+
+type ModerationConfig struct {
+	ClassifierURL        string        // External classifier endpoint; "stub" uses the in-process stub, empty disables it
+	ClassifierTimeout    time.Duration
+	ClassifierFailClosed bool          // Reject messages when the classifier cannot be reached
+	StubTerms            []string      // Terms the stub classifier flags, as term or term=action
+}
+
+type RetentionConfig struct {
+	PurgeEnabled  bool          // Run the scheduled purge; manual runs work either way
+	PurgeInterval time.Duration
+	BatchSize     int           // Messages deleted per transaction
+}
+
 var AppConfig *Config
 
 func Load() error {
@@ -92,6 +123,28 @@ func Load() error {
 			BucketName:      getEnv("MINIO_BUCKET_NAME", "huddle-files"),
 			UseSSL:          getEnvAsBool("MINIO_USE_SSL", false),
 		},
+		LinkPreview: LinkPreviewConfig{
+			Enabled:      getEnvAsBool("LINK_PREVIEW_ENABLED", true),
+			Timeout:      getEnvAsDuration("LINK_PREVIEW_TIMEOUT", 5*time.Second),
+			MaxBytes:     getEnvAsInt("LINK_PREVIEW_MAX_BYTES", 512*1024),
+			CacheTTL:     getEnvAsDuration("LINK_PREVIEW_CACHE_TTL", 24*time.Hour),
+			AllowedHosts: getEnvAsSlice("LINK_PREVIEW_ALLOWLIST", nil),
+		},
+		Bots: BotConfig{
+			WebhookTimeout: getEnvAsDuration("BOT_WEBHOOK_TIMEOUT", 3*time.Second),
+			AllowedHosts:   getEnvAsSlice("BOT_WEBHOOK_ALLOWLIST", nil),
+		},
+		Moderation: ModerationConfig{
+			ClassifierURL:        getEnv("MODERATION_CLASSIFIER_URL", ""),
+			ClassifierTimeout:    getEnvAsDuration("MODERATION_CLASSIFIER_TIMEOUT", 2*time.Second),
+			ClassifierFailClosed: getEnvAsBool("MODERATION_CLASSIFIER_FAIL_CLOSED", false),
+			StubTerms:            getEnvAsSlice("MODERATION_STUB_TERMS", nil),
+		},
+		Retention: RetentionConfig{
+			PurgeEnabled:  getEnvAsBool("RETENTION_PURGE_ENABLED", true),
+			PurgeInterval: getEnvAsDuration("RETENTION_PURGE_INTERVAL", time.Hour),
+			BatchSize:     getEnvAsInt("RETENTION_BATCH_SIZE", 500),
+		},
 	}
 
 	return nil
@@ -135,3 +188,16 @@ func getEnvAsBool(key string, defaultValue bool) bool {
 	}
 	return defaultValue
 }
+
+func getEnvAsSlice(key string, defaultValue []string) []string {
+	if value := os.Getenv(key); value != "" {
+		var values []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				values = append(values, item)
+			}
+		}
+		return values
+	}
+	return defaultValue
+}
@@ -0,0 +1,59 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAccessDenied is wrapped by errors returned when a user lacks permission in a conversation
+var ErrAccessDenied = errors.New("access denied")
+
+// Block errors returned when a user block prevents an action
+var (
+	ErrUserBlocked   = errors.New("you have blocked this user")
+	ErrBlockedByUser = errors.New("this user is not accepting messages from you")
+)
+
+// Block error codes returned by the API
+const (
+	ErrorCodeUserBlocked   = "USER_BLOCKED"
+	ErrorCodeBlockedByUser = "BLOCKED_BY_USER"
+)
+
+// BlockErrorCode returns the API error code for an error caused by a user block
+func BlockErrorCode(err error) (string, bool) {
+	switch {
+	case errors.Is(err, ErrUserBlocked):
+		return ErrorCodeUserBlocked, true
+	case errors.Is(err, ErrBlockedByUser):
+		return ErrorCodeBlockedByUser, true
+	}
+	return "", false
+}
+
+// ValidateNotBlocked checks that neither participant of a direct conversation has blocked the other
+func (s *service) ValidateNotBlocked(ctx context.Context, userID, conversationID uint) error {
+	peerID, err := s.repo.GetDirectPeerID(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if peerID == 0 {
+		return nil
+	}
+	return s.checkBlocked(ctx, userID, peerID)
+}
+
+// checkBlocked returns a block error if either user has blocked the other
+func (s *service) checkBlocked(ctx context.Context, userID, otherID uint) error {
+	blocked, blockedBy, err := s.repo.GetBlockStatus(ctx, userID, otherID)
+	if err != nil {
+		return err
+	}
+	if blocked {
+		return ErrUserBlocked
+	}
+	if blockedBy {
+		return ErrBlockedByUser
+	}
+	return nil
+}
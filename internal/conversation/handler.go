@@ -1,6 +1,9 @@
 package conversation
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 
 	"huddle/pkg/logger"
@@ -35,6 +38,10 @@ func (h *Handler) CreateConversation(c *gin.Context) {
 	conversation, err := h.service.CreateConversation(c.Request.Context(), userID, &req)
 	if err != nil {
 		logger.Error("Failed to create conversation", zap.Error(err))
+		if code, ok := BlockErrorCode(err); ok {
+			utils.ErrorResponse(c, http.StatusForbidden, code, err.Error(), nil)
+			return
+		}
 		utils.BadRequestResponse(c, err.Error())
 		return
 	}
@@ -119,6 +126,145 @@ func (h *Handler) UpdateConversation(c *gin.Context) {
 	utils.SuccessResponse(c, nil, "Conversation updated successfully")
 }
 
+// UpdateConversationSettings updates conversation settings
+func (h *Handler) UpdateConversationSettings(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	var req UpdateConversationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind update conversation settings request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	err = h.service.UpdateConversationSettings(c.Request.Context(), userID, uint(conversationID), &req)
+	if err != nil {
+		logger.Error("Failed to update conversation settings", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Conversation settings updated successfully")
+}
+
+// GetUnreadSummary gets the current user's unread and mention counts across conversations
+func (h *Handler) GetUnreadSummary(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	summary, err := h.service.GetUnreadSummary(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get unread summary", zap.Error(err))
+		utils.InternalServerErrorResponse(c, "Failed to get unread counts")
+		return
+	}
+
+	utils.SuccessResponse(c, summary, "Unread counts retrieved successfully")
+}
+
+// MarkUnread moves the current user's read pointer back so the conversation shows as unread
+func (h *Handler) MarkUnread(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	// The body is optional: a plain POST marks the latest message unread
+	var req MarkUnreadRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error("Failed to bind mark unread request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	state, err := h.service.MarkUnread(c.Request.Context(), userID, uint(conversationID), &req)
+	if err != nil {
+		logger.Error("Failed to mark conversation unread", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, state, "Conversation marked as unread")
+}
+
+// SaveDraft saves the current user's draft for a conversation
+func (h *Handler) SaveDraft(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	var req SaveDraftRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind save draft request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	draft, err := h.service.SaveDraft(c.Request.Context(), userID, uint(conversationID), &req)
+	if err != nil {
+		logger.Error("Failed to save draft", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	if draft == nil {
+		utils.SuccessResponse(c, nil, "Draft cleared successfully")
+		return
+	}
+	utils.SuccessResponse(c, draft, "Draft saved successfully")
+}
+
+// GetDraft gets the current user's draft for a conversation (null if none)
+func (h *Handler) GetDraft(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	draft, err := h.service.GetDraft(c.Request.Context(), userID, uint(conversationID))
+	if err != nil {
+		logger.Error("Failed to get draft", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, draft, "Draft retrieved successfully")
+}
+
+// DeleteDraft deletes the current user's draft for a conversation
+func (h *Handler) DeleteDraft(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	if err := h.service.DeleteDraft(c.Request.Context(), userID, uint(conversationID)); err != nil {
+		logger.Error("Failed to delete draft", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Draft deleted successfully")
+}
+
 // DeleteConversation deletes a conversation
 func (h *Handler) DeleteConversation(c *gin.Context) {
 	userID := getUserIDFromContext(c)
@@ -159,6 +305,10 @@ func (h *Handler) AddParticipant(c *gin.Context) {
 	err = h.service.AddParticipant(c.Request.Context(), userID, uint(conversationID), &req)
 	if err != nil {
 		logger.Error("Failed to add participant", zap.Error(err))
+		if code, ok := BlockErrorCode(err); ok {
+			utils.ErrorResponse(c, http.StatusForbidden, code, err.Error(), nil)
+			return
+		}
 		utils.BadRequestResponse(c, err.Error())
 		return
 	}
@@ -12,6 +12,7 @@ type Repository interface {
 	GetConversationByParticipants(ctx context.Context, participantIDs []uint, convType string) (*Conversation, error)
 	GetUserConversations(ctx context.Context, userID uint, limit, offset int) ([]Conversation, error)
 	UpdateConversation(ctx context.Context, conversationID uint, name string) error
+	UpdateConversationSettings(ctx context.Context, conversationID uint, settings map[string]interface{}) error
 	DeleteConversation(ctx context.Context, conversationID uint) error
 
 	// Participants
@@ -19,12 +20,36 @@ type Repository interface {
 	RemoveParticipant(ctx context.Context, conversationID, userID uint) error
 	GetConversationParticipants(ctx context.Context, conversationID uint) ([]ConversationParticipant, error)
 	UpdateLastReadAt(ctx context.Context, conversationID, userID uint) error
+	AdvanceReadPointer(ctx context.Context, conversationID, userID, messageID uint) (*ReceiptPointers, bool, error)
+	RewindReadPointer(ctx context.Context, conversationID, userID, pointerID uint) (*ReceiptPointers, bool, error)
+	AdvanceDeliveredPointer(ctx context.Context, conversationID, userID, messageID uint) (*ReceiptPointers, bool, error)
 	CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error)
 	PromoteToAdmin(ctx context.Context, conversationID, userID uint) error
 
 	// Messages (basic operations for conversation context)
-	GetLastMessage(ctx context.Context, conversationID uint) (*Message, error)
-	GetUnreadCount(ctx context.Context, conversationID, userID uint) (int, error)
+	GetLastMessages(ctx context.Context, conversationIDs []uint) (map[uint]*Message, error)
+	GetLatestMessageID(ctx context.Context, conversationID uint) (uint, error)
+	GetPreviousMessageID(ctx context.Context, conversationID, messageID uint) (uint, error)
+	CheckMessageInConversation(ctx context.Context, conversationID, messageID uint) (bool, error)
+
+	// Unread counters
+	GetUnreadStates(ctx context.Context, conversationID uint) ([]UnreadState, error)
+	GetUnreadState(ctx context.Context, conversationID, userID uint) (*UnreadState, error)
+	GetUserUnreadStates(ctx context.Context, userID uint) ([]UnreadState, error)
+
+	// Drafts
+	UpsertDraft(ctx context.Context, draft *ConversationDraft) (*ConversationDraft, error)
+	GetDraft(ctx context.Context, conversationID, userID uint) (*ConversationDraft, error)
+	DeleteDraft(ctx context.Context, conversationID, userID uint) (bool, error)
+	GetDraftFlags(ctx context.Context, userID uint, conversationIDs []uint) (map[uint]bool, error)
+
+	// Files
+	CountUserFiles(ctx context.Context, userID uint, fileIDs []uint) (int, error)
+
+	// Blocks
+	GetBlockStatus(ctx context.Context, userID, otherID uint) (blocked bool, blockedBy bool, err error)
+	GetBlockedUserIDs(ctx context.Context, userID uint) ([]uint, error)
+	GetDirectPeerID(ctx context.Context, conversationID, userID uint) (uint, error) // 0 for group conversations
 }
 
 // Service interface defines business logic methods for conversations
@@ -34,6 +59,9 @@ type Service interface {
 	GetConversation(ctx context.Context, userID, conversationID uint) (*ConversationResponse, error)
 	GetConversations(ctx context.Context, userID uint, limit, offset int) (*ConversationListResponse, error)
 	UpdateConversation(ctx context.Context, userID, conversationID uint, req *UpdateConversationRequest) error
+	UpdateConversationSettings(ctx context.Context, userID, conversationID uint, req *UpdateConversationSettingsRequest) error
+	SetMessageTTL(ctx context.Context, userID, conversationID uint, ttlSeconds int) (bool, error)
+	UpdateTopic(ctx context.Context, userID, conversationID uint, topic string) error
 	DeleteConversation(ctx context.Context, userID, conversationID uint) error
 
 	// Participants
@@ -41,7 +69,27 @@ type Service interface {
 	RemoveParticipant(ctx context.Context, userID, conversationID uint, req *RemoveParticipantRequest) error
 	LeaveConversation(ctx context.Context, userID, conversationID uint, req *LeaveConversationRequest) error
 
+	// Unread counters
+	GetUnreadSummary(ctx context.Context, userID uint) (*UnreadSummaryResponse, error)
+	MarkUnread(ctx context.Context, userID, conversationID uint, req *MarkUnreadRequest) (*UnreadState, error)
+	NotifyUnreadChanged(ctx context.Context, conversationID, senderID uint)
+
+	// Drafts
+	SaveDraft(ctx context.Context, userID, conversationID uint, req *SaveDraftRequest) (*DraftResponse, error)
+	GetDraft(ctx context.Context, userID, conversationID uint) (*DraftResponse, error)
+	DeleteDraft(ctx context.Context, userID, conversationID uint) error
+
 	// Validation methods
 	ValidateConversationAccess(ctx context.Context, userID, conversationID uint) error
 	ValidateConversationAdmin(ctx context.Context, userID, conversationID uint) error
+	ValidateNotBlocked(ctx context.Context, userID, conversationID uint) error
+}
+
+// Broadcaster defines the real-time events published by the conversation module.
+// It is implemented by websocket.Service.
+type Broadcaster interface {
+	HandleNewMessage(ctx context.Context, conversationID uint, messageData map[string]interface{})
+	HandleDraftUpdated(ctx context.Context, userID uint, draftData map[string]interface{})
+	HandleReceiptUpdated(ctx context.Context, conversationID, userID uint, lastReadMessageID, lastDeliveredMessageID *uint)
+	HandleUnreadChanged(ctx context.Context, userID uint, unreadData map[string]interface{})
 }
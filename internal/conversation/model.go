@@ -11,7 +11,11 @@ type Conversation struct {
 	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	Name      string    `json:"name"`
 	Type      string    `json:"type" gorm:"not null;default:'direct';size:20"`
+	Topic     string    `json:"topic" gorm:"not null;default:'';size:250"`
 	CreatedBy uint      `json:"created_by"`
+	OnlyAdminsCanPin bool `json:"only_admins_can_pin" gorm:"not null;default:true"`
+	MessageTTLSeconds int `json:"message_ttl_seconds" gorm:"not null;default:0"`
+	LinkPreviewsEnabled bool `json:"link_previews_enabled" gorm:"not null;default:true"`
 	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
 	UpdatedAt time.Time `json:"updated_at" gorm:"default:now()"`
 
@@ -29,6 +33,10 @@ type ConversationParticipant struct {
 	Role           string    `json:"role" gorm:"not null;default:'member';size:20"`
 	JoinedAt       time.Time `json:"joined_at" gorm:"default:now()"`
 	LastReadAt     time.Time `json:"last_read_at" gorm:"default:now()"`
+	LastReadMessageID      *uint `json:"last_read_message_id"`
+	LastDeliveredMessageID *uint `json:"last_delivered_message_id"`
+	UnreadCount            int   `json:"unread_count" gorm:"not null;default:0"`         // Maintained by database triggers
+	UnreadMentionCount     int   `json:"unread_mention_count" gorm:"not null;default:0"` // Maintained by database triggers
 
 	// Relations
 	Conversation Conversation `json:"conversation" gorm:"foreignKey:ConversationID"`
@@ -48,6 +56,7 @@ type Message struct {
 	ReplyToID      *uint     `json:"reply_to_id"`
 	IsEdited       bool      `json:"is_edited" gorm:"default:false"`
 	EditedAt       *time.Time `json:"edited_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
 	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
 
@@ -63,7 +72,7 @@ type MessageReaction struct {
 	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	MessageID    uint      `json:"message_id" gorm:"not null"`
 	UserID       uint      `json:"user_id" gorm:"not null"`
-	ReactionType string    `json:"reaction_type" gorm:"not null;default:'like';size:20"`
+	Emoji        string    `json:"emoji" gorm:"not null;size:64"`
 	CreatedAt    time.Time `json:"created_at" gorm:"default:now()"`
 
 	// Relations
@@ -71,6 +80,47 @@ type MessageReaction struct {
 	User    user.User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// ConversationDraft represents a user's unsent message in a conversation, synced across devices
+type ConversationDraft struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID uint      `json:"conversation_id" gorm:"not null"`
+	UserID         uint      `json:"user_id" gorm:"not null"`
+	Content        string    `json:"content" gorm:"not null;default:''"`
+	ReplyToID      *uint     `json:"reply_to_id"`
+	AttachmentIDs  []uint    `json:"attachment_ids" gorm:"type:jsonb;serializer:json;not null"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+// ReceiptPointers represents a participant's read and delivery pointers after an update
+type ReceiptPointers struct {
+	LastReadMessageID      *uint `json:"last_read_message_id"`
+	LastDeliveredMessageID *uint `json:"last_delivered_message_id"`
+	UnreadCount            int   `json:"unread_count"`
+	UnreadMentionCount     int   `json:"unread_mention_count"`
+}
+
+// UnreadState represents a participant's unread counters in one conversation
+type UnreadState struct {
+	ConversationID     uint  `json:"conversation_id"`
+	UserID             uint  `json:"-"`
+	LastReadMessageID  *uint `json:"last_read_message_id"` // Messages after this one are unread
+	UnreadCount        int   `json:"unread_count"`
+	UnreadMentionCount int   `json:"unread_mention_count"`
+}
+
+// Draft limits
+const (
+	// MaxDraftLength is the maximum number of characters stored in a draft
+	MaxDraftLength = 10000
+
+	// MaxDraftAttachments is the maximum number of files attached to a draft
+	MaxDraftAttachments = 10
+)
+
+// MaxTopicLength is the maximum number of characters in a conversation topic
+const MaxTopicLength = 250
+
 // Conversation Type Constants
 const (
 	ConversationTypeDirect = "direct"
@@ -83,6 +133,22 @@ const (
 	ParticipantRoleMember = "member"
 )
 
+// Message TTL Constants (disappearing messages)
+const (
+	MessageTTLOff     = 0
+	MessageTTLOneHour = 60 * 60
+	MessageTTLOneDay  = 24 * 60 * 60
+	MessageTTLOneWeek = 7 * 24 * 60 * 60
+)
+
+// MessageTTLLabels lists the allowed message TTLs with a human readable label
+var MessageTTLLabels = map[int]string{
+	MessageTTLOff:     "off",
+	MessageTTLOneHour: "1 hour",
+	MessageTTLOneDay:  "24 hours",
+	MessageTTLOneWeek: "7 days",
+}
+
 // Message Type Constants
 const (
 	MessageTypeText   = "text"
@@ -91,16 +157,6 @@ const (
 	MessageTypeSystem = "system"
 )
 
-// Reaction Type Constants
-const (
-	ReactionTypeLike   = "like"
-	ReactionTypeLove   = "love"
-	ReactionTypeHaha   = "haha"
-	ReactionTypeWow    = "wow"
-	ReactionTypeSad    = "sad"
-	ReactionTypeAngry  = "angry"
-)
-
 // DTOs for API requests/responses
 
 // CreateConversationRequest represents request to create a conversation
@@ -115,16 +171,44 @@ type UpdateConversationRequest struct {
 	Name string `json:"name" binding:"required"`
 }
 
+// UpdateConversationSettingsRequest represents request to update conversation settings
+type UpdateConversationSettingsRequest struct {
+	OnlyAdminsCanPin    *bool `json:"only_admins_can_pin,omitempty"`
+	LinkPreviewsEnabled *bool `json:"link_previews_enabled,omitempty"`
+}
+
+// SaveDraftRequest represents request to save the current user's draft
+type SaveDraftRequest struct {
+	Content       string `json:"content"`
+	ReplyToID     *uint  `json:"reply_to_id,omitempty"`
+	AttachmentIDs []uint `json:"attachment_ids,omitempty"` // IDs of files uploaded by the user
+}
+
+// DraftResponse represents a draft response
+type DraftResponse struct {
+	ConversationID uint      `json:"conversation_id"`
+	Content        string    `json:"content"`
+	ReplyToID      *uint     `json:"reply_to_id,omitempty"`
+	AttachmentIDs  []uint    `json:"attachment_ids"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
 // ConversationResponse represents conversation response
 type ConversationResponse struct {
 	ID           uint                    `json:"id"`
 	Name         string                  `json:"name"`
 	Type         string                  `json:"type"`
+	Topic        string                  `json:"topic,omitempty"`
 	CreatedBy    uint                    `json:"created_by"`
 	Creator      user.UserResponse       `json:"creator"`
 	Participants []ParticipantResponse   `json:"participants"`
+	OnlyAdminsCanPin bool                `json:"only_admins_can_pin"`
+	MessageTTLSeconds int                `json:"message_ttl_seconds"`
+	LinkPreviewsEnabled bool             `json:"link_previews_enabled"`
 	LastMessage  *MessageResponse        `json:"last_message,omitempty"`
 	UnreadCount  int                     `json:"unread_count"`
+	UnreadMentionCount int               `json:"unread_mention_count"`
+	HasDraft     bool                    `json:"has_draft"`
 	CreatedAt    time.Time               `json:"created_at"`
 	UpdatedAt    time.Time               `json:"updated_at"`
 }
@@ -137,6 +221,8 @@ type ParticipantResponse struct {
 	Role       string          `json:"role"`
 	JoinedAt   time.Time       `json:"joined_at"`
 	LastReadAt time.Time       `json:"last_read_at"`
+	LastReadMessageID      *uint `json:"last_read_message_id,omitempty"`
+	LastDeliveredMessageID *uint `json:"last_delivered_message_id,omitempty"`
 }
 
 // ConversationListResponse represents conversation list response
@@ -145,6 +231,18 @@ type ConversationListResponse struct {
 	Total         int                    `json:"total"`
 }
 
+// UnreadSummaryResponse represents the user's unread badge counts across conversations
+type UnreadSummaryResponse struct {
+	TotalUnread   int           `json:"total_unread"`
+	TotalMentions int           `json:"total_mentions"`
+	Conversations []UnreadState `json:"conversations"` // Only conversations with unread messages
+}
+
+// MarkUnreadRequest represents request to mark a conversation unread
+type MarkUnreadRequest struct {
+	MessageID *uint `json:"message_id,omitempty"` // First message to mark unread; defaults to the latest message
+}
+
 // AddParticipantRequest represents request to add participant
 type AddParticipantRequest struct {
 	UserID uint `json:"user_id" binding:"required"`
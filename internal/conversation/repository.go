@@ -3,11 +3,14 @@ package conversation
 import (
 	"context"
 	"errors"
+	"time"
+
 	"huddle/internal/database"
 	"huddle/pkg/logger"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -110,6 +113,15 @@ func (r *repository) UpdateConversation(ctx context.Context, conversationID uint
 	return nil
 }
 
+func (r *repository) UpdateConversationSettings(ctx context.Context, conversationID uint, settings map[string]interface{}) error {
+	if err := r.db.WithContext(ctx).Model(&Conversation{}).Where("id = ?", conversationID).Updates(settings).Error; err != nil {
+		logger.Error("Failed to update conversation settings", zap.Error(err))
+		return err
+	}
+	logger.Info("Conversation settings updated", zap.Uint("conversation_id", conversationID))
+	return nil
+}
+
 func (r *repository) DeleteConversation(ctx context.Context, conversationID uint) error {
 	if err := r.db.WithContext(ctx).Delete(&Conversation{}, conversationID).Error; err != nil {
 		logger.Error("Failed to delete conversation", zap.Error(err))
@@ -171,6 +183,117 @@ func (r *repository) UpdateLastReadAt(ctx context.Context, conversationID, userI
 	return nil
 }
 
+// unreadRecountSQL recomputes a participant's counters for a new read pointer inside an UPDATE.
+// Parameters: conversation id, read pointer, user id, repeated for the mention count.
+const unreadRecountSQL = `
+	unread_count = (
+		SELECT COUNT(*) FROM messages m
+		WHERE m.conversation_id = ? AND m.id > ?
+			AND m.sender_id IS DISTINCT FROM ? AND m.hidden_at IS NULL
+	),
+	unread_mention_count = (
+		SELECT COUNT(*) FROM message_mentions mm
+		JOIN messages m ON m.id = mm.message_id
+		WHERE m.conversation_id = ? AND m.id > ?
+			AND mm.user_id = ? AND m.hidden_at IS NULL
+	)`
+
+// AdvanceReadPointer moves the read pointer forward (never back) and implies delivery up to the same message
+func (r *repository) AdvanceReadPointer(ctx context.Context, conversationID, userID, messageID uint) (*ReceiptPointers, bool, error) {
+	var pointers []ReceiptPointers
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE conversation_participants
+		SET last_read_message_id = ?, last_read_at = ?,
+			last_delivered_message_id = GREATEST(COALESCE(last_delivered_message_id, 0), ?),`+unreadRecountSQL+`
+		WHERE conversation_id = ? AND user_id = ?
+			AND COALESCE(last_read_message_id, 0) < ?
+		RETURNING last_read_message_id, last_delivered_message_id, unread_count, unread_mention_count`,
+		messageID, time.Now().UTC(), messageID,
+		conversationID, messageID, userID, conversationID, messageID, userID,
+		conversationID, userID, messageID,
+	).Scan(&pointers).Error; err != nil {
+		logger.Error("Failed to advance read pointer", zap.Error(err))
+		return nil, false, err
+	}
+	if len(pointers) == 0 {
+		return nil, false, nil
+	}
+	return &pointers[0], true, nil
+}
+
+// RewindReadPointer moves the read pointer back (never forward) so later messages count as unread again
+func (r *repository) RewindReadPointer(ctx context.Context, conversationID, userID, pointerID uint) (*ReceiptPointers, bool, error) {
+	var lastReadMessageID *uint
+	if pointerID > 0 {
+		lastReadMessageID = &pointerID
+	}
+
+	var pointers []ReceiptPointers
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE conversation_participants
+		SET last_read_message_id = ?,
+			last_read_at = COALESCE((SELECT created_at FROM messages WHERE id = ?), joined_at),`+unreadRecountSQL+`
+		WHERE conversation_id = ? AND user_id = ?
+			AND COALESCE(last_read_message_id, 0) > ?
+		RETURNING last_read_message_id, last_delivered_message_id, unread_count, unread_mention_count`,
+		lastReadMessageID, pointerID,
+		conversationID, pointerID, userID, conversationID, pointerID, userID,
+		conversationID, userID, pointerID,
+	).Scan(&pointers).Error; err != nil {
+		logger.Error("Failed to rewind read pointer", zap.Error(err))
+		return nil, false, err
+	}
+	if len(pointers) == 0 {
+		return nil, false, nil
+	}
+	return &pointers[0], true, nil
+}
+
+// AdvanceDeliveredPointer moves the delivery pointer forward (never back)
+func (r *repository) AdvanceDeliveredPointer(ctx context.Context, conversationID, userID, messageID uint) (*ReceiptPointers, bool, error) {
+	var pointers []ReceiptPointers
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE conversation_participants
+		SET last_delivered_message_id = ?
+		WHERE conversation_id = ? AND user_id = ?
+			AND COALESCE(last_delivered_message_id, 0) < ?
+		RETURNING last_read_message_id, last_delivered_message_id, unread_count, unread_mention_count`,
+		messageID, conversationID, userID, messageID,
+	).Scan(&pointers).Error; err != nil {
+		logger.Error("Failed to advance delivered pointer", zap.Error(err))
+		return nil, false, err
+	}
+	if len(pointers) == 0 {
+		return nil, false, nil
+	}
+	return &pointers[0], true, nil
+}
+
+func (r *repository) GetLatestMessageID(ctx context.Context, conversationID uint) (uint, error) {
+	var latestID uint
+	if err := r.db.WithContext(ctx).Model(&Message{}).
+		Where("conversation_id = ?", conversationID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&latestID).Error; err != nil {
+		logger.Error("Failed to get latest message id", zap.Error(err))
+		return 0, err
+	}
+	return latestID, nil
+}
+
+func (r *repository) GetPreviousMessageID(ctx context.Context, conversationID, messageID uint) (uint, error) {
+	var previousID uint
+	if err := r.db.WithContext(ctx).Model(&Message{}).
+		Where("conversation_id = ? AND id < ?", conversationID, messageID).
+		Select("COALESCE(MAX(id), 0)").
+		Scan(&previousID).Error; err != nil {
+		logger.Error("Failed to get previous message id", zap.Error(err))
+		return 0, err
+	}
+	return previousID, nil
+}
+
 func (r *repository) CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error) {
 	var count int64
 	if err := r.db.WithContext(ctx).Model(&ConversationParticipant{}).
@@ -195,42 +318,219 @@ func (r *repository) PromoteToAdmin(ctx context.Context, conversationID, userID
 
 // Messages (basic operations for conversation context)
 
-func (r *repository) GetLastMessage(ctx context.Context, conversationID uint) (*Message, error) {
-	var message Message
+func (r *repository) GetLastMessages(ctx context.Context, conversationIDs []uint) (map[uint]*Message, error) {
+	lastMessages := make(map[uint]*Message, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return lastMessages, nil
+	}
+
+	// One row per conversation instead of a query each
+	latest := r.db.WithContext(ctx).
+		Table("messages").
+		Select("DISTINCT ON (conversation_id) id").
+		Where("conversation_id IN ?", conversationIDs).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("hidden_at IS NULL"). // Shadow-hidden messages never preview in the conversation list
+		Order("conversation_id, created_at DESC, id DESC")
+
+	var messages []Message
 	if err := r.db.WithContext(ctx).
 		Preload("Sender").
+		Where("id IN (?)", latest).
+		Find(&messages).Error; err != nil {
+		logger.Error("Failed to get last messages", zap.Error(err))
+		return nil, err
+	}
+	for i := range messages {
+		lastMessages[messages[i].ConversationID] = &messages[i]
+	}
+	return lastMessages, nil
+}
+
+// Unread counters
+
+func (r *repository) GetUnreadStates(ctx context.Context, conversationID uint) ([]UnreadState, error) {
+	var states []UnreadState
+	if err := r.db.WithContext(ctx).
+		Model(&ConversationParticipant{}).
+		Select("conversation_id, user_id, last_read_message_id, unread_count, unread_mention_count").
 		Where("conversation_id = ?", conversationID).
-		Order("created_at DESC").
-		First(&message).Error; err != nil {
+		Scan(&states).Error; err != nil {
+		logger.Error("Failed to get unread states", zap.Error(err))
+		return nil, err
+	}
+	return states, nil
+}
+
+func (r *repository) GetUnreadState(ctx context.Context, conversationID, userID uint) (*UnreadState, error) {
+	var state UnreadState
+	if err := r.db.WithContext(ctx).
+		Model(&ConversationParticipant{}).
+		Select("conversation_id, user_id, last_read_message_id, unread_count, unread_mention_count").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Take(&state).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, nil // No messages yet
+			return nil, errors.New("access denied: not a participant")
 		}
-		logger.Error("Failed to get last message", zap.Error(err))
+		logger.Error("Failed to get unread state", zap.Error(err))
 		return nil, err
 	}
-	return &message, nil
+	return &state, nil
 }
 
-func (r *repository) GetUnreadCount(ctx context.Context, conversationID, userID uint) (int, error) {
-	var count int64
-	
-	// Get user's last read timestamp
-	var participant ConversationParticipant
+func (r *repository) GetUserUnreadStates(ctx context.Context, userID uint) ([]UnreadState, error) {
+	var states []UnreadState
+	if err := r.db.WithContext(ctx).
+		Model(&ConversationParticipant{}).
+		Select("conversation_id, user_id, last_read_message_id, unread_count, unread_mention_count").
+		Where("user_id = ? AND (unread_count > 0 OR unread_mention_count > 0)", userID).
+		Order("conversation_id ASC").
+		Scan(&states).Error; err != nil {
+		logger.Error("Failed to get user unread states", zap.Error(err))
+		return nil, err
+	}
+	return states, nil
+}
+
+// Drafts
+
+func (r *repository) UpsertDraft(ctx context.Context, draft *ConversationDraft) (*ConversationDraft, error) {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "conversation_id"}, {Name: "user_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"content", "reply_to_id", "attachment_ids"}),
+		}).
+		Create(draft).Error; err != nil {
+		logger.Error("Failed to save draft", zap.Error(err))
+		return nil, err
+	}
+	return r.GetDraft(ctx, draft.ConversationID, draft.UserID)
+}
+
+func (r *repository) GetDraft(ctx context.Context, conversationID, userID uint) (*ConversationDraft, error) {
+	var draft ConversationDraft
 	if err := r.db.WithContext(ctx).
 		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
-		First(&participant).Error; err != nil {
-		logger.Error("Failed to get participant for unread count", zap.Error(err))
-		return 0, err
+		First(&draft).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil // No draft
+		}
+		logger.Error("Failed to get draft", zap.Error(err))
+		return nil, err
 	}
-	
-	// Count messages after last read
-	if err := r.db.WithContext(ctx).Model(&Message{}).
-		Where("conversation_id = ? AND created_at > ? AND sender_id != ?", 
-			conversationID, participant.LastReadAt, userID).
+	return &draft, nil
+}
+
+func (r *repository) DeleteDraft(ctx context.Context, conversationID, userID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Delete(&ConversationDraft{})
+	if result.Error != nil {
+		logger.Error("Failed to delete draft", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// GetDraftFlags reports which of the conversations have a draft saved by the user
+func (r *repository) GetDraftFlags(ctx context.Context, userID uint, conversationIDs []uint) (map[uint]bool, error) {
+	flags := make(map[uint]bool, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return flags, nil
+	}
+
+	var withDrafts []uint
+	if err := r.db.WithContext(ctx).
+		Model(&ConversationDraft{}).
+		Where("user_id = ? AND conversation_id IN ?", userID, conversationIDs).
+		Pluck("conversation_id", &withDrafts).Error; err != nil {
+		logger.Error("Failed to check drafts", zap.Error(err))
+		return nil, err
+	}
+	for _, conversationID := range withDrafts {
+		flags[conversationID] = true
+	}
+	return flags, nil
+}
+
+func (r *repository) CheckMessageInConversation(ctx context.Context, conversationID, messageID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&Message{}).
+		Where("id = ? AND conversation_id = ?", messageID, conversationID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
 		Count(&count).Error; err != nil {
-		logger.Error("Failed to get unread count", zap.Error(err))
+		logger.Error("Failed to check message in conversation", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) CountUserFiles(ctx context.Context, userID uint, fileIDs []uint) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Where("id IN ? AND user_id = ?", fileIDs, userID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count user files", zap.Error(err))
 		return 0, err
 	}
-	
 	return int(count), nil
 }
+
+// Blocks
+
+func (r *repository) GetBlockStatus(ctx context.Context, userID, otherID uint) (bool, bool, error) {
+	var rows []struct {
+		BlockerID uint
+	}
+	if err := r.db.WithContext(ctx).
+		Table("blocked_users").
+		Select("blocker_id").
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)", userID, otherID, otherID, userID).
+		Find(&rows).Error; err != nil {
+		logger.Error("Failed to get block status", zap.Error(err))
+		return false, false, err
+	}
+
+	var blocked, blockedBy bool
+	for _, row := range rows {
+		if row.BlockerID == userID {
+			blocked = true
+		} else {
+			blockedBy = true
+		}
+	}
+	return blocked, blockedBy, nil
+}
+
+func (r *repository) GetBlockedUserIDs(ctx context.Context, userID uint) ([]uint, error) {
+	var userIDs []uint
+	// Blocks hide users from each other in both directions
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT blocked_id FROM blocked_users WHERE blocker_id = ?
+		UNION
+		SELECT blocker_id FROM blocked_users WHERE blocked_id = ?`, userID, userID).
+		Scan(&userIDs).Error; err != nil {
+		logger.Error("Failed to get blocked user IDs", zap.Error(err))
+		return nil, err
+	}
+	return userIDs, nil
+}
+
+func (r *repository) GetDirectPeerID(ctx context.Context, conversationID, userID uint) (uint, error) {
+	var peerIDs []uint
+	if err := r.db.WithContext(ctx).
+		Table("conversation_participants cp").
+		Joins("JOIN conversations c ON c.id = cp.conversation_id").
+		Where("cp.conversation_id = ? AND c.type = ? AND cp.user_id <> ?", conversationID, ConversationTypeDirect, userID).
+		Limit(1).
+		Pluck("cp.user_id", &peerIDs).Error; err != nil {
+		logger.Error("Failed to get direct conversation peer", zap.Error(err))
+		return 0, err
+	}
+	if len(peerIDs) == 0 {
+		return 0, nil
+	}
+	return peerIDs[0], nil
+}
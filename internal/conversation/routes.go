@@ -17,10 +17,26 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 		conversations.GET("/:id", handler.GetConversation)                     // Get conversation by ID
 		conversations.PUT("/:id", handler.UpdateConversation)                  // Update conversation
 		conversations.DELETE("/:id", handler.DeleteConversation)               // Delete conversation
+		conversations.PUT("/:id/settings", handler.UpdateConversationSettings) // Update conversation settings
 
 		// Participant management
 		conversations.POST("/:id/participants", handler.AddParticipant)        // Add participant
 		conversations.DELETE("/:id/participants", handler.RemoveParticipant)   // Remove participant
 		conversations.POST("/:id/leave", handler.LeaveConversation)            // Leave conversation
+
+		// Read state
+		conversations.POST("/:id/unread", handler.MarkUnread)                  // Mark unread from a message (default: latest)
+
+		// Drafts (per user, synced across devices)
+		conversations.PUT("/:id/draft", handler.SaveDraft)                     // Save draft (empty draft clears it)
+		conversations.GET("/:id/draft", handler.GetDraft)                      // Get draft
+		conversations.DELETE("/:id/draft", handler.DeleteDraft)                // Delete draft
+	}
+
+	// Current user's read state across conversations
+	me := router.Group("/me")
+	me.Use(middleware.AuthMiddleware())
+	{
+		me.GET("/unread", handler.GetUnreadSummary) // Total, per-conversation and mention counts
 	}
 }
@@ -3,6 +3,10 @@ package conversation
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"huddle/internal/user"
 	"huddle/pkg/logger"
 
@@ -10,13 +14,15 @@ import (
 )
 
 type service struct {
-	repo Repository
+	repo        Repository
+	broadcaster Broadcaster
 }
 
 // NewService creates a new conversation service
-func NewService(repo Repository) Service {
+func NewService(repo Repository, broadcaster Broadcaster) Service {
 	return &service{
-		repo: repo,
+		repo:        repo,
+		broadcaster: broadcaster,
 	}
 }
 
@@ -28,6 +34,16 @@ func (s *service) CreateConversation(ctx context.Context, userID uint, req *Crea
 		return nil, err
 	}
 
+	// Blocked users cannot start a chat or be added to a group by each other
+	for _, participantID := range req.ParticipantIDs {
+		if participantID == userID {
+			continue
+		}
+		if err := s.checkBlocked(ctx, userID, participantID); err != nil {
+			return nil, err
+		}
+	}
+
 	// Check if conversation already exists (for direct chats)
 	if req.Type == ConversationTypeDirect && len(req.ParticipantIDs) == 2 {
 		existingConv, err := s.repo.GetConversationByParticipants(ctx, req.ParticipantIDs, req.Type)
@@ -82,15 +98,17 @@ func (s *service) GetConversation(ctx context.Context, userID, conversationID ui
 		return nil, err
 	}
 
+	// Opening a conversation reads it up to the latest message
+	if err := s.markRead(ctx, userID, conversationID); err != nil {
+		logger.Error("Failed to mark conversation read", zap.Error(err))
+	}
+
 	// Get conversation
 	conversation, err := s.repo.GetConversationByID(ctx, conversationID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Update last read timestamp
-	s.repo.UpdateLastReadAt(ctx, conversationID, userID)
-
 	return s.buildConversationResponse(ctx, conversation, userID)
 }
 
@@ -101,10 +119,28 @@ func (s *service) GetConversations(ctx context.Context, userID uint, limit, offs
 		return nil, err
 	}
 
+	// Load every last message in one query
+	conversationIDs := make([]uint, len(conversations))
+	for i := range conversations {
+		conversationIDs[i] = conversations[i].ID
+	}
+	lastMessages, err := s.repo.GetLastMessages(ctx, conversationIDs)
+	if err != nil {
+		logger.Error("Failed to get last messages", zap.Error(err))
+		lastMessages = map[uint]*Message{}
+	}
+
+	// And every draft flag in another
+	drafts, err := s.repo.GetDraftFlags(ctx, userID, conversationIDs)
+	if err != nil {
+		logger.Error("Failed to check drafts", zap.Error(err))
+		drafts = map[uint]bool{}
+	}
+
 	// Build responses
 	var responses []ConversationResponse
 	for _, conv := range conversations {
-		response, err := s.buildConversationResponse(ctx, &conv, userID)
+		response, err := s.newConversationResponse(ctx, &conv, userID, lastMessages[conv.ID], drafts[conv.ID])
 		if err != nil {
 			logger.Error("Failed to build conversation response", zap.Error(err))
 			continue
@@ -128,6 +164,78 @@ func (s *service) UpdateConversation(ctx context.Context, userID, conversationID
 	return s.repo.UpdateConversation(ctx, conversationID, req.Name)
 }
 
+func (s *service) UpdateConversationSettings(ctx context.Context, userID, conversationID uint, req *UpdateConversationSettingsRequest) error {
+	// Validate admin access
+	if err := s.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	settings := make(map[string]interface{})
+	if req.OnlyAdminsCanPin != nil {
+		settings["only_admins_can_pin"] = *req.OnlyAdminsCanPin
+	}
+	if req.LinkPreviewsEnabled != nil {
+		settings["link_previews_enabled"] = *req.LinkPreviewsEnabled
+	}
+	if len(settings) == 0 {
+		return errors.New("no settings to update")
+	}
+
+	// Update settings
+	return s.repo.UpdateConversationSettings(ctx, conversationID, settings)
+}
+
+// SetMessageTTL changes the disappearing message timer and reports whether it changed.
+// Either participant of a direct conversation may change it; in groups it is admin only.
+func (s *service) SetMessageTTL(ctx context.Context, userID, conversationID uint, ttlSeconds int) (bool, error) {
+	if _, ok := MessageTTLLabels[ttlSeconds]; !ok {
+		return false, errors.New("invalid message TTL: allowed values are 0, 3600, 86400 and 604800 seconds")
+	}
+
+	conversation, err := s.repo.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return false, err
+	}
+	if conversation.Type == ConversationTypeDirect {
+		err = s.ValidateConversationAccess(ctx, userID, conversationID)
+	} else {
+		err = s.ValidateConversationAdmin(ctx, userID, conversationID)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if ttlSeconds == conversation.MessageTTLSeconds {
+		return false, nil
+	}
+	if err := s.repo.UpdateConversationSettings(ctx, conversationID, map[string]interface{}{"message_ttl_seconds": ttlSeconds}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (s *service) UpdateTopic(ctx context.Context, userID, conversationID uint, topic string) error {
+	// Validate admin access
+	if err := s.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	conversation, err := s.repo.GetConversationByID(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation.Type != ConversationTypeGroup {
+		return errors.New("only group conversations have a topic")
+	}
+
+	topic = strings.TrimSpace(topic)
+	if utf8.RuneCountInString(topic) > MaxTopicLength {
+		return fmt.Errorf("topic cannot be longer than %d characters", MaxTopicLength)
+	}
+
+	return s.repo.UpdateConversationSettings(ctx, conversationID, map[string]interface{}{"topic": topic})
+}
+
 func (s *service) DeleteConversation(ctx context.Context, userID, conversationID uint) error {
 	// Validate admin access
 	if err := s.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
@@ -155,6 +263,11 @@ func (s *service) AddParticipant(ctx context.Context, userID, conversationID uin
 		return errors.New("user is already a participant")
 	}
 
+	// Cannot add a user who has blocked, or been blocked by, the admin
+	if err := s.checkBlocked(ctx, userID, req.UserID); err != nil {
+		return err
+	}
+
 	// Add participant
 	_, err = s.repo.AddParticipant(ctx, conversationID, req.UserID, req.Role)
 	return err
@@ -257,6 +370,195 @@ func (s *service) LeaveConversation(ctx context.Context, userID, conversationID
 	return s.repo.RemoveParticipant(ctx, conversationID, userID)
 }
 
+// Unread counters
+
+func (s *service) GetUnreadSummary(ctx context.Context, userID uint) (*UnreadSummaryResponse, error) {
+	states, err := s.repo.GetUserUnreadStates(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &UnreadSummaryResponse{
+		Conversations: make([]UnreadState, 0, len(states)),
+	}
+	for _, state := range states {
+		response.TotalUnread += state.UnreadCount
+		response.TotalMentions += state.UnreadMentionCount
+		response.Conversations = append(response.Conversations, state)
+	}
+	return response, nil
+}
+
+func (s *service) MarkUnread(ctx context.Context, userID, conversationID uint, req *MarkUnreadRequest) (*UnreadState, error) {
+	// Validate access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	// Resolve the first message to mark unread
+	var messageID uint
+	if req.MessageID != nil {
+		exists, err := s.repo.CheckMessageInConversation(ctx, conversationID, *req.MessageID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("message not found in this conversation")
+		}
+		messageID = *req.MessageID
+	} else {
+		latestID, err := s.repo.GetLatestMessageID(ctx, conversationID)
+		if err != nil {
+			return nil, err
+		}
+		if latestID == 0 {
+			return nil, errors.New("conversation has no messages")
+		}
+		messageID = latestID
+	}
+
+	// The read pointer rests on the message just before it
+	pointerID, err := s.repo.GetPreviousMessageID(ctx, conversationID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	pointers, rewound, err := s.repo.RewindReadPointer(ctx, conversationID, userID, pointerID)
+	if err != nil {
+		return nil, err
+	}
+	if !rewound {
+		// Already unread from that message on
+		return s.repo.GetUnreadState(ctx, conversationID, userID)
+	}
+
+	state := &UnreadState{
+		ConversationID:     conversationID,
+		UserID:             userID,
+		LastReadMessageID:  pointers.LastReadMessageID,
+		UnreadCount:        pointers.UnreadCount,
+		UnreadMentionCount: pointers.UnreadMentionCount,
+	}
+
+	// Other devices redraw the badge and the first-unread divider; other members update "seen by"
+	go s.broadcaster.HandleReceiptUpdated(context.Background(), conversationID, userID, pointers.LastReadMessageID, pointers.LastDeliveredMessageID)
+	s.broadcastUnreadChanged(userID, *state)
+
+	return state, nil
+}
+
+// NotifyUnreadChanged pushes the conversation's new unread counters to every participant except the sender
+func (s *service) NotifyUnreadChanged(ctx context.Context, conversationID, senderID uint) {
+	states, err := s.repo.GetUnreadStates(ctx, conversationID)
+	if err != nil {
+		logger.Error("Failed to load unread counters", zap.Uint("conversation_id", conversationID), zap.Error(err))
+		return
+	}
+	for _, state := range states {
+		if state.UserID == senderID {
+			continue
+		}
+		s.broadcastUnreadChanged(state.UserID, state)
+	}
+}
+
+// Drafts
+
+func (s *service) SaveDraft(ctx context.Context, userID, conversationID uint, req *SaveDraftRequest) (*DraftResponse, error) {
+	// Validate access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	// Saving an empty draft clears it
+	if strings.TrimSpace(req.Content) == "" && req.ReplyToID == nil && len(req.AttachmentIDs) == 0 {
+		return nil, s.DeleteDraft(ctx, userID, conversationID)
+	}
+
+	if utf8.RuneCountInString(req.Content) > MaxDraftLength {
+		return nil, fmt.Errorf("draft cannot be longer than %d characters", MaxDraftLength)
+	}
+
+	if req.ReplyToID != nil {
+		exists, err := s.repo.CheckMessageInConversation(ctx, conversationID, *req.ReplyToID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("reply target not found in this conversation")
+		}
+	}
+
+	// Attachments must be files the user uploaded
+	attachmentIDs := make([]uint, 0, len(req.AttachmentIDs))
+	seen := make(map[uint]bool)
+	for _, fileID := range req.AttachmentIDs {
+		if !seen[fileID] {
+			seen[fileID] = true
+			attachmentIDs = append(attachmentIDs, fileID)
+		}
+	}
+	if len(attachmentIDs) > MaxDraftAttachments {
+		return nil, fmt.Errorf("a draft can have at most %d attachments", MaxDraftAttachments)
+	}
+	if len(attachmentIDs) > 0 {
+		owned, err := s.repo.CountUserFiles(ctx, userID, attachmentIDs)
+		if err != nil {
+			return nil, err
+		}
+		if owned != len(attachmentIDs) {
+			return nil, errors.New("access denied: attachments must be files you uploaded")
+		}
+	}
+
+	draft, err := s.repo.UpsertDraft(ctx, &ConversationDraft{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Content:        req.Content,
+		ReplyToID:      req.ReplyToID,
+		AttachmentIDs:  attachmentIDs,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := buildDraftResponse(draft)
+	s.broadcastDraftUpdated(userID, conversationID, response)
+	return response, nil
+}
+
+func (s *service) GetDraft(ctx context.Context, userID, conversationID uint) (*DraftResponse, error) {
+	// Validate access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	draft, err := s.repo.GetDraft(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if draft == nil {
+		return nil, nil // No draft saved
+	}
+	return buildDraftResponse(draft), nil
+}
+
+func (s *service) DeleteDraft(ctx context.Context, userID, conversationID uint) error {
+	// Validate access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteDraft(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if deleted {
+		s.broadcastDraftUpdated(userID, conversationID, nil)
+	}
+	return nil
+}
+
 // Validation methods
 
 func (s *service) ValidateConversationAccess(ctx context.Context, userID, conversationID uint) error {
@@ -265,7 +567,7 @@ func (s *service) ValidateConversationAccess(ctx context.Context, userID, conver
 		return err
 	}
 	if !isParticipant {
-		return errors.New("access denied: not a participant")
+		return fmt.Errorf("%w: not a participant", ErrAccessDenied)
 	}
 	return nil
 }
@@ -283,15 +585,74 @@ func (s *service) ValidateConversationAdmin(ctx context.Context, userID, convers
 			if participant.Role == ParticipantRoleAdmin {
 				return nil
 			}
-			return errors.New("access denied: admin role required")
+			return fmt.Errorf("%w: admin role required", ErrAccessDenied)
 		}
 	}
 
-	return errors.New("access denied: not a participant")
+	return fmt.Errorf("%w: not a participant", ErrAccessDenied)
 }
 
 // Helper methods
 
+// markRead advances the user's read pointer to the latest message and broadcasts the new receipt
+func (s *service) markRead(ctx context.Context, userID, conversationID uint) error {
+	latestID, err := s.repo.GetLatestMessageID(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if latestID == 0 {
+		return s.repo.UpdateLastReadAt(ctx, conversationID, userID)
+	}
+
+	pointers, advanced, err := s.repo.AdvanceReadPointer(ctx, conversationID, userID, latestID)
+	if err != nil {
+		return err
+	}
+	if advanced {
+		go s.broadcaster.HandleReceiptUpdated(context.Background(), conversationID, userID, pointers.LastReadMessageID, pointers.LastDeliveredMessageID)
+		s.broadcastUnreadChanged(userID, UnreadState{
+			ConversationID:     conversationID,
+			LastReadMessageID:  pointers.LastReadMessageID,
+			UnreadCount:        pointers.UnreadCount,
+			UnreadMentionCount: pointers.UnreadMentionCount,
+		})
+	}
+	return nil
+}
+
+// broadcastUnreadChanged tells the user's devices that a conversation's unread counters changed
+func (s *service) broadcastUnreadChanged(userID uint, state UnreadState) {
+	go s.broadcaster.HandleUnreadChanged(context.Background(), userID, map[string]interface{}{
+		"conversation_id":      state.ConversationID,
+		"last_read_message_id": state.LastReadMessageID,
+		"unread_count":         state.UnreadCount,
+		"unread_mention_count": state.UnreadMentionCount,
+	})
+}
+
+// broadcastDraftUpdated tells the user's devices that a draft changed; a nil draft means it was cleared
+func (s *service) broadcastDraftUpdated(userID, conversationID uint, draft *DraftResponse) {
+	go s.broadcaster.HandleDraftUpdated(context.Background(), userID, map[string]interface{}{
+		"conversation_id": conversationID,
+		"draft":           draft,
+	})
+}
+
+// buildDraftResponse builds a draft response
+func buildDraftResponse(draft *ConversationDraft) *DraftResponse {
+	attachmentIDs := draft.AttachmentIDs
+	if attachmentIDs == nil {
+		attachmentIDs = []uint{}
+	}
+	return &DraftResponse{
+		ConversationID: draft.ConversationID,
+		Content:        draft.Content,
+		ReplyToID:      draft.ReplyToID,
+		AttachmentIDs:  attachmentIDs,
+		UpdatedAt:      draft.UpdatedAt,
+	}
+}
+
 func (s *service) validateCreateConversationRequest(req *CreateConversationRequest) error {
 	if req.Name == "" {
 		return errors.New("conversation name is required")
@@ -310,16 +671,30 @@ func (s *service) validateCreateConversationRequest(req *CreateConversationReque
 
 func (s *service) buildConversationResponse(ctx context.Context, conversation *Conversation, userID uint) (*ConversationResponse, error) {
 	// Get last message
-	lastMessage, err := s.repo.GetLastMessage(ctx, conversation.ID)
+	lastMessages, err := s.repo.GetLastMessages(ctx, []uint{conversation.ID})
 	if err != nil {
 		logger.Error("Failed to get last message", zap.Error(err))
 	}
 
-	// Get unread count
-	unreadCount, err := s.repo.GetUnreadCount(ctx, conversation.ID, userID)
+	// Check for a saved draft
+	drafts, err := s.repo.GetDraftFlags(ctx, userID, []uint{conversation.ID})
 	if err != nil {
-		logger.Error("Failed to get unread count", zap.Error(err))
-		unreadCount = 0
+		logger.Error("Failed to check draft", zap.Error(err))
+	}
+
+	return s.newConversationResponse(ctx, conversation, userID, lastMessages[conversation.ID], drafts[conversation.ID])
+}
+
+// newConversationResponse builds a conversation response from an already loaded last message and draft flag
+func (s *service) newConversationResponse(ctx context.Context, conversation *Conversation, userID uint, lastMessage *Message, hasDraft bool) (*ConversationResponse, error) {
+	// Unread counters are kept on the user's participant row
+	var unreadCount, unreadMentionCount int
+	for _, p := range conversation.Participants {
+		if p.UserID == userID {
+			unreadCount = p.UnreadCount
+			unreadMentionCount = p.UnreadMentionCount
+			break
+		}
 	}
 
 	// Build participants response
@@ -343,6 +718,8 @@ func (s *service) buildConversationResponse(ctx context.Context, conversation *C
 			Role:       p.Role,
 			JoinedAt:   p.JoinedAt,
 			LastReadAt: p.LastReadAt,
+			LastReadMessageID:      p.LastReadMessageID,
+			LastDeliveredMessageID: p.LastDeliveredMessageID,
 		})
 	}
 
@@ -375,6 +752,7 @@ func (s *service) buildConversationResponse(ctx context.Context, conversation *C
 		ID:           conversation.ID,
 		Name:         conversation.Name,
 		Type:         conversation.Type,
+		Topic:        conversation.Topic,
 		CreatedBy:    conversation.CreatedBy,
 		Creator: user.UserResponse{
 			ID:           conversation.Creator.ID,
@@ -389,8 +767,13 @@ func (s *service) buildConversationResponse(ctx context.Context, conversation *C
 			UpdatedAt:    conversation.Creator.UpdatedAt,
 		},
 		Participants: participants,
+		OnlyAdminsCanPin: conversation.OnlyAdminsCanPin,
+		MessageTTLSeconds: conversation.MessageTTLSeconds,
+		LinkPreviewsEnabled: conversation.LinkPreviewsEnabled,
 		LastMessage:  lastMessageResponse,
 		UnreadCount:  unreadCount,
+		UnreadMentionCount: unreadMentionCount,
+		HasDraft:     hasDraft,
 		CreatedAt:    conversation.CreatedAt,
 		UpdatedAt:    conversation.UpdatedAt,
 	}, nil
@@ -0,0 +1,276 @@
+package emoji
+
+import (
+	"strconv"
+	"strings"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new emoji handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Custom emoji
+
+// CreateEmoji adds a custom emoji from an image uploaded through the file module
+func (h *Handler) CreateEmoji(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CreateEmojiRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	emoji, err := h.service.CreateEmoji(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Error("Failed to create custom emoji", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, emoji, "Custom emoji created successfully")
+}
+
+// GetEmoji lists the custom emoji the user can use
+func (h *Handler) GetEmoji(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	emoji, err := h.service.GetEmoji(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get custom emoji", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, emoji, "Custom emoji retrieved successfully")
+}
+
+// LookupEmoji resolves a comma separated list of shortcodes
+func (h *Handler) LookupEmoji(c *gin.Context) {
+	userID := c.GetUint("user_id")
+	shortcodes := strings.Split(c.Query("shortcodes"), ",")
+	if len(shortcodes) > 100 {
+		utils.BadRequestResponse(c, "At most 100 shortcodes can be looked up at once")
+		return
+	}
+
+	emoji, err := h.service.LookupEmoji(c.Request.Context(), userID, shortcodes)
+	if err != nil {
+		logger.Error("Failed to look up custom emoji", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, emoji, "Custom emoji retrieved successfully")
+}
+
+// DeleteEmoji removes a custom emoji (owner or moderator)
+func (h *Handler) DeleteEmoji(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	emojiID, ok := parseID(c, "id", "Invalid emoji ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteEmoji(c.Request.Context(), userID, emojiID); err != nil {
+		logger.Error("Failed to delete custom emoji", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Custom emoji deleted successfully")
+}
+
+// Sticker packs
+
+// CreateStickerPack creates an empty sticker pack
+func (h *Handler) CreateStickerPack(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CreateStickerPackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	pack, err := h.service.CreateStickerPack(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Error("Failed to create sticker pack", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, pack, "Sticker pack created successfully")
+}
+
+// GetStickerPacks lists the packs the user can send stickers from
+func (h *Handler) GetStickerPacks(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packs, err := h.service.GetStickerPacks(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get sticker packs", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, packs, "Sticker packs retrieved successfully")
+}
+
+// GetStickerPack returns a sticker pack with its stickers
+func (h *Handler) GetStickerPack(c *gin.Context) {
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+
+	pack, err := h.service.GetStickerPack(c.Request.Context(), packID)
+	if err != nil {
+		logger.Error("Failed to get sticker pack", zap.Error(err))
+		utils.NotFoundResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, pack, "Sticker pack retrieved successfully")
+}
+
+// UpdateStickerPack renames a sticker pack
+func (h *Handler) UpdateStickerPack(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+
+	var req UpdateStickerPackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	pack, err := h.service.UpdateStickerPack(c.Request.Context(), userID, packID, &req)
+	if err != nil {
+		logger.Error("Failed to update sticker pack", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, pack, "Sticker pack updated successfully")
+}
+
+// DeleteStickerPack deletes a sticker pack and its stickers
+func (h *Handler) DeleteStickerPack(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteStickerPack(c.Request.Context(), userID, packID); err != nil {
+		logger.Error("Failed to delete sticker pack", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Sticker pack deleted successfully")
+}
+
+// Stickers
+
+// AddSticker appends an uploaded image to a sticker pack
+func (h *Handler) AddSticker(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+
+	var req AddStickerRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	sticker, err := h.service.AddSticker(c.Request.Context(), userID, packID, &req)
+	if err != nil {
+		logger.Error("Failed to add sticker", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, sticker, "Sticker added successfully")
+}
+
+// RemoveSticker removes a sticker from its pack
+func (h *Handler) RemoveSticker(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+	stickerID, ok := parseID(c, "sticker_id", "Invalid sticker ID")
+	if !ok {
+		return
+	}
+
+	if err := h.service.RemoveSticker(c.Request.Context(), userID, packID, stickerID); err != nil {
+		logger.Error("Failed to remove sticker", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Sticker removed successfully")
+}
+
+// ReorderStickers sets the order of the stickers in a pack
+func (h *Handler) ReorderStickers(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	packID, ok := parseID(c, "id", "Invalid sticker pack ID")
+	if !ok {
+		return
+	}
+
+	var req ReorderStickersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	pack, err := h.service.ReorderStickers(c.Request.Context(), userID, packID, &req)
+	if err != nil {
+		logger.Error("Failed to reorder stickers", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, pack, "Stickers reordered successfully")
+}
+
+// parseID reads a numeric path parameter, responding with message if it is invalid
+func parseID(c *gin.Context, param, message string) (uint, bool) {
+	id, err := strconv.ParseUint(c.Param(param), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, message)
+		return 0, false
+	}
+	return uint(id), true
+}
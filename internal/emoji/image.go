@@ -0,0 +1,108 @@
+package emoji
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"  // Register GIF decoder
+	_ "image/jpeg" // Register JPEG decoder
+	"image/png"
+	"io"
+)
+
+// Larger sources are rejected before decoding so a small file cannot expand into a huge bitmap
+const maxSourceDimension = 4096
+
+// supportedImageFormats lists the decoders registered above
+var supportedImageFormats = map[string]string{
+	"png":  "image/png",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+}
+
+// processedImage is an image ready to be stored
+type processedImage struct {
+	Data     []byte
+	MimeType string
+	Width    int
+	Height   int
+}
+
+// processImage reads an uploaded image and fits it within size pixels square.
+// Images that already fit keep their original bytes so animated GIFs stay animated;
+// larger ones are downscaled and re-encoded as PNG.
+func processImage(reader io.Reader, maxBytes int64, size int) (*processedImage, error) {
+	data, err := io.ReadAll(io.LimitReader(reader, maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read image: %w", err)
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, fmt.Errorf("image is too large: maximum size is %d KB", maxBytes/1024)
+	}
+
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, errors.New("unsupported image: must be a PNG, JPEG or GIF")
+	}
+	mimeType, ok := supportedImageFormats[format]
+	if !ok {
+		return nil, errors.New("unsupported image: must be a PNG, JPEG or GIF")
+	}
+	if config.Width == 0 || config.Height == 0 || config.Width > maxSourceDimension || config.Height > maxSourceDimension {
+		return nil, fmt.Errorf("image dimensions must be at most %dx%d pixels", maxSourceDimension, maxSourceDimension)
+	}
+
+	if config.Width <= size && config.Height <= size {
+		return &processedImage{Data: data, MimeType: mimeType, Width: config.Width, Height: config.Height}, nil
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	width, height := fitWithin(config.Width, config.Height, size)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, downscale(src, width, height)); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return &processedImage{Data: buf.Bytes(), MimeType: "image/png", Width: width, Height: height}, nil
+}
+
+// fitWithin scales width and height down to fit size pixels square, keeping the aspect ratio
+func fitWithin(width, height, size int) (int, int) {
+	if width >= height {
+		return size, max(1, (height*size+width/2)/width)
+	}
+	return max(1, (width*size+height/2)/height), size
+}
+
+// downscale shrinks src to width x height by averaging the source pixels under each target pixel
+func downscale(src image.Image, width, height int) *image.NRGBA {
+	bounds := src.Bounds()
+	srcWidth, srcHeight := bounds.Dx(), bounds.Dy()
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*srcHeight/height
+		y1 := max(y0+1, bounds.Min.Y+(y+1)*srcHeight/height)
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*srcWidth/width
+			x1 := max(x0+1, bounds.Min.X+(x+1)*srcWidth/width)
+
+			// Average in premultiplied alpha so transparent pixels do not darken the edges
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
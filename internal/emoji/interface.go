@@ -0,0 +1,54 @@
+package emoji
+
+import (
+	"context"
+)
+
+// Repository interface defines data access methods for custom emoji and stickers
+type Repository interface {
+	// Custom emoji
+	CreateEmoji(ctx context.Context, emoji *CustomEmoji) error
+	GetEmojiByID(ctx context.Context, emojiID uint) (*CustomEmoji, error)
+	GetEmojiByShortcodes(ctx context.Context, shortcodes []string) ([]CustomEmoji, error)
+	GetUsableEmoji(ctx context.Context, userID uint) ([]CustomEmoji, error)
+	ShortcodeExists(ctx context.Context, shortcode string, userID *uint) (bool, error)
+	CountUserEmoji(ctx context.Context, userID uint) (int64, error)
+	DeleteEmoji(ctx context.Context, emojiID uint) error
+
+	// Sticker packs
+	CreatePack(ctx context.Context, pack *StickerPack) error
+	GetPackByID(ctx context.Context, packID uint) (*StickerPack, error)
+	GetUsablePacks(ctx context.Context, userID uint) ([]StickerPack, error)
+	CountUserPacks(ctx context.Context, userID uint) (int64, error)
+	UpdatePack(ctx context.Context, pack *StickerPack) error
+	DeletePack(ctx context.Context, packID uint) error
+
+	// Stickers
+	AddSticker(ctx context.Context, sticker *Sticker, maxStickers int) (bool, error)
+	GetStickerByID(ctx context.Context, stickerID uint) (*Sticker, error)
+	DeleteSticker(ctx context.Context, stickerID uint) error
+	ReorderStickers(ctx context.Context, packID uint, stickerIDs []uint) error
+}
+
+// Service interface defines business logic methods for custom emoji and stickers
+type Service interface {
+	// Custom emoji
+	CreateEmoji(ctx context.Context, userID uint, req *CreateEmojiRequest) (*CustomEmojiResponse, error)
+	GetEmoji(ctx context.Context, userID uint) (*EmojiListResponse, error)
+	LookupEmoji(ctx context.Context, userID uint, shortcodes []string) (*EmojiListResponse, error)
+	DeleteEmoji(ctx context.Context, userID, emojiID uint) error
+	ResolveEmoji(ctx context.Context, userID uint, shortcodes []string) (map[string]CustomEmoji, error)
+
+	// Sticker packs
+	CreateStickerPack(ctx context.Context, userID uint, req *CreateStickerPackRequest) (*StickerPackResponse, error)
+	GetStickerPacks(ctx context.Context, userID uint) (*StickerPackListResponse, error)
+	GetStickerPack(ctx context.Context, packID uint) (*StickerPackResponse, error)
+	UpdateStickerPack(ctx context.Context, userID, packID uint, req *UpdateStickerPackRequest) (*StickerPackResponse, error)
+	DeleteStickerPack(ctx context.Context, userID, packID uint) error
+
+	// Stickers
+	AddSticker(ctx context.Context, userID, packID uint, req *AddStickerRequest) (*StickerResponse, error)
+	RemoveSticker(ctx context.Context, userID, packID, stickerID uint) error
+	ReorderStickers(ctx context.Context, userID, packID uint, req *ReorderStickersRequest) (*StickerPackResponse, error)
+	GetUsableSticker(ctx context.Context, userID, stickerID uint) (*Sticker, error)
+}
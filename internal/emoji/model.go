@@ -0,0 +1,162 @@
+package emoji
+
+import (
+	"time"
+)
+
+// CustomEmoji is an image usable as :shortcode: in reactions and message text
+type CustomEmoji struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Shortcode string    `json:"shortcode" gorm:"not null;size:32"` // Without colons, unique per owner
+	UserID    *uint     `json:"user_id"`                           // nil for workspace emoji usable by everyone
+	CreatedBy *uint     `json:"created_by"`
+	ObjectKey string    `json:"-" gorm:"not null"`
+	MimeType  string    `json:"mime_type" gorm:"not null"`
+	FileSize  int64     `json:"file_size" gorm:"not null"`
+	Width     int       `json:"width" gorm:"not null"`
+	Height    int       `json:"height" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for CustomEmoji
+func (CustomEmoji) TableName() string {
+	return "custom_emoji"
+}
+
+// StickerPack is an ordered collection of stickers owned by the workspace or a user
+type StickerPack struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name        string    `json:"name" gorm:"not null;size:100"`
+	Description string    `json:"description" gorm:"not null;default:'';size:500"`
+	UserID      *uint     `json:"user_id"` // nil for workspace packs
+	CreatedBy   *uint     `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Stickers []Sticker `json:"stickers" gorm:"foreignKey:PackID"`
+}
+
+// TableName specifies the table name for StickerPack
+func (StickerPack) TableName() string {
+	return "sticker_packs"
+}
+
+// Sticker is one image of a sticker pack
+type Sticker struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PackID    uint      `json:"pack_id" gorm:"not null"`
+	Position  int       `json:"position" gorm:"not null;default:0"`
+	Emoji     string    `json:"emoji" gorm:"not null;default:'';size:64"` // The emoji the sticker stands for, used as fallback text
+	ObjectKey string    `json:"-" gorm:"not null"`
+	MimeType  string    `json:"mime_type" gorm:"not null"`
+	FileSize  int64     `json:"file_size" gorm:"not null"`
+	Width     int       `json:"width" gorm:"not null"`
+	Height    int       `json:"height" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Pack *StickerPack `json:"pack,omitempty" gorm:"foreignKey:PackID"`
+}
+
+// TableName specifies the table name for Sticker
+func (Sticker) TableName() string {
+	return "stickers"
+}
+
+// Owner Scope Constants
+const (
+	ScopeWorkspace = "workspace"
+	ScopeUser      = "user"
+)
+
+// Image and collection limits
+const (
+	MaxEmojiUploadSize   = 1 << 20 // Source image size for custom emoji
+	MaxStickerUploadSize = 2 << 20 // Source image size for stickers
+	EmojiImageSize       = 128     // Custom emoji are resized to fit this many pixels square
+	StickerImageSize     = 512     // Stickers are resized to fit this many pixels square
+	MaxPersonalEmoji     = 100
+	MaxPersonalPacks     = 20
+	MaxStickersPerPack   = 120
+)
+
+// DTOs for API requests/responses
+
+// CreateEmojiRequest represents request to add a custom emoji from an uploaded image
+type CreateEmojiRequest struct {
+	Shortcode string `json:"shortcode" binding:"required"` // With or without colons
+	FileID    uint   `json:"file_id" binding:"required"`   // Uploaded through POST /api/files/upload
+	Workspace bool   `json:"workspace"`                    // Moderators only; otherwise the emoji is personal
+}
+
+// CreateStickerPackRequest represents request to create a sticker pack
+type CreateStickerPackRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+	Workspace   bool   `json:"workspace"` // Moderators only; otherwise the pack is personal
+}
+
+// UpdateStickerPackRequest represents request to rename a sticker pack
+type UpdateStickerPackRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	Description string `json:"description" binding:"max=500"`
+}
+
+// AddStickerRequest represents request to add an uploaded image to a sticker pack
+type AddStickerRequest struct {
+	FileID uint   `json:"file_id" binding:"required"`
+	Emoji  string `json:"emoji" binding:"max=64"` // Optional emoji the sticker stands for
+}
+
+// ReorderStickersRequest represents the new order of every sticker in a pack
+type ReorderStickersRequest struct {
+	StickerIDs []uint `json:"sticker_ids" binding:"required,min=1"`
+}
+
+// CustomEmojiResponse represents a custom emoji response
+type CustomEmojiResponse struct {
+	ID        uint      `json:"id"`
+	Shortcode string    `json:"shortcode"`
+	Scope     string    `json:"scope"`
+	UserID    *uint     `json:"user_id,omitempty"`
+	ImageURL  string    `json:"image_url"`
+	MimeType  string    `json:"mime_type"`
+	Width     int       `json:"width"`
+	Height    int       `json:"height"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// EmojiListResponse represents a list of custom emoji
+type EmojiListResponse struct {
+	Emoji []CustomEmojiResponse `json:"emoji"`
+}
+
+// StickerResponse represents a sticker response
+type StickerResponse struct {
+	ID       uint   `json:"id"`
+	PackID   uint   `json:"pack_id"`
+	Position int    `json:"position"`
+	Emoji    string `json:"emoji,omitempty"`
+	ImageURL string `json:"image_url"`
+	MimeType string `json:"mime_type"`
+	Width    int    `json:"width"`
+	Height   int    `json:"height"`
+}
+
+// StickerPackResponse represents a sticker pack with its stickers in order
+type StickerPackResponse struct {
+	ID          uint              `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Scope       string            `json:"scope"`
+	UserID      *uint             `json:"user_id,omitempty"`
+	Stickers    []StickerResponse `json:"stickers"`
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
+
+// StickerPackListResponse represents the sticker packs a user can send from
+type StickerPackListResponse struct {
+	Packs []StickerPackResponse `json:"packs"`
+}
@@ -0,0 +1,256 @@
+package emoji
+
+import (
+	"context"
+	"errors"
+
+	"huddle/internal/database"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new emoji repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Custom emoji
+
+func (r *repository) CreateEmoji(ctx context.Context, emoji *CustomEmoji) error {
+	if err := r.db.WithContext(ctx).Create(emoji).Error; err != nil {
+		logger.Error("Failed to create custom emoji", zap.Error(err))
+		return err
+	}
+	logger.Info("Custom emoji created", zap.Uint("emoji_id", emoji.ID), zap.String("shortcode", emoji.Shortcode))
+	return nil
+}
+
+func (r *repository) GetEmojiByID(ctx context.Context, emojiID uint) (*CustomEmoji, error) {
+	var emoji CustomEmoji
+	if err := r.db.WithContext(ctx).First(&emoji, emojiID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("custom emoji not found")
+		}
+		logger.Error("Failed to get custom emoji", zap.Error(err))
+		return nil, err
+	}
+	return &emoji, nil
+}
+
+func (r *repository) GetEmojiByShortcodes(ctx context.Context, shortcodes []string) ([]CustomEmoji, error) {
+	var emoji []CustomEmoji
+	if len(shortcodes) == 0 {
+		return emoji, nil
+	}
+	if err := r.db.WithContext(ctx).Where("shortcode IN ?", shortcodes).Order("shortcode ASC, id ASC").Find(&emoji).Error; err != nil {
+		logger.Error("Failed to get custom emoji by shortcode", zap.Error(err))
+		return nil, err
+	}
+	return emoji, nil
+}
+
+// GetUsableEmoji returns the workspace emoji plus the user's own
+func (r *repository) GetUsableEmoji(ctx context.Context, userID uint) ([]CustomEmoji, error) {
+	var emoji []CustomEmoji
+	if err := r.db.WithContext(ctx).
+		Where("user_id IS NULL OR user_id = ?", userID).
+		Order("shortcode ASC").
+		Find(&emoji).Error; err != nil {
+		logger.Error("Failed to get custom emoji", zap.Error(err))
+		return nil, err
+	}
+	return emoji, nil
+}
+
+// ShortcodeExists reports whether a new emoji for the owner (nil for the workspace) would clash.
+// A workspace emoji takes precedence, so it blocks the name for personal emoji too.
+func (r *repository) ShortcodeExists(ctx context.Context, shortcode string, userID *uint) (bool, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&CustomEmoji{}).Where("shortcode = ?", shortcode)
+	if userID == nil {
+		query = query.Where("user_id IS NULL")
+	} else {
+		query = query.Where("user_id IS NULL OR user_id = ?", *userID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		logger.Error("Failed to check custom emoji shortcode", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) CountUserEmoji(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&CustomEmoji{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		logger.Error("Failed to count custom emoji", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *repository) DeleteEmoji(ctx context.Context, emojiID uint) error {
+	if err := r.db.WithContext(ctx).Delete(&CustomEmoji{}, emojiID).Error; err != nil {
+		logger.Error("Failed to delete custom emoji", zap.Error(err))
+		return err
+	}
+	logger.Info("Custom emoji deleted", zap.Uint("emoji_id", emojiID))
+	return nil
+}
+
+// Sticker packs
+
+// withStickers loads a pack's stickers in order
+func (r *repository) withStickers(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("Stickers", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC, id ASC")
+		})
+}
+
+func (r *repository) CreatePack(ctx context.Context, pack *StickerPack) error {
+	if err := r.db.WithContext(ctx).Create(pack).Error; err != nil {
+		logger.Error("Failed to create sticker pack", zap.Error(err))
+		return err
+	}
+	logger.Info("Sticker pack created", zap.Uint("pack_id", pack.ID))
+	return nil
+}
+
+func (r *repository) GetPackByID(ctx context.Context, packID uint) (*StickerPack, error) {
+	var pack StickerPack
+	if err := r.withStickers(ctx).First(&pack, packID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("sticker pack not found")
+		}
+		logger.Error("Failed to get sticker pack", zap.Error(err))
+		return nil, err
+	}
+	return &pack, nil
+}
+
+// GetUsablePacks returns the workspace packs followed by the user's own
+func (r *repository) GetUsablePacks(ctx context.Context, userID uint) ([]StickerPack, error) {
+	var packs []StickerPack
+	if err := r.withStickers(ctx).
+		Where("user_id IS NULL OR user_id = ?", userID).
+		Order("user_id ASC NULLS FIRST, id ASC").
+		Find(&packs).Error; err != nil {
+		logger.Error("Failed to get sticker packs", zap.Error(err))
+		return nil, err
+	}
+	return packs, nil
+}
+
+func (r *repository) CountUserPacks(ctx context.Context, userID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&StickerPack{}).Where("user_id = ?", userID).Count(&count).Error; err != nil {
+		logger.Error("Failed to count sticker packs", zap.Error(err))
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *repository) UpdatePack(ctx context.Context, pack *StickerPack) error {
+	if err := r.db.WithContext(ctx).Model(&StickerPack{}).Where("id = ?", pack.ID).Updates(map[string]interface{}{
+		"name":        pack.Name,
+		"description": pack.Description,
+	}).Error; err != nil {
+		logger.Error("Failed to update sticker pack", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// DeletePack deletes a pack; its stickers are removed by the foreign key cascade
+func (r *repository) DeletePack(ctx context.Context, packID uint) error {
+	if err := r.db.WithContext(ctx).Delete(&StickerPack{}, packID).Error; err != nil {
+		logger.Error("Failed to delete sticker pack", zap.Error(err))
+		return err
+	}
+	logger.Info("Sticker pack deleted", zap.Uint("pack_id", packID))
+	return nil
+}
+
+// Stickers
+
+// AddSticker appends a sticker to the end of its pack, or returns false if the pack is full
+func (r *repository) AddSticker(ctx context.Context, sticker *Sticker, maxStickers int) (bool, error) {
+	added := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the pack so concurrent uploads get distinct positions
+		if err := tx.Exec("SELECT id FROM sticker_packs WHERE id = ? FOR UPDATE", sticker.PackID).Error; err != nil {
+			return err
+		}
+
+		var saved []Sticker
+		if err := tx.Raw(`
+			INSERT INTO stickers (pack_id, position, emoji, object_key, mime_type, file_size, width, height)
+			SELECT ?, COALESCE(MAX(position) + 1, 0), ?, ?, ?, ?, ?, ?
+			FROM stickers
+			WHERE pack_id = ?
+			HAVING COUNT(*) < ?
+			RETURNING *`,
+			sticker.PackID, sticker.Emoji, sticker.ObjectKey, sticker.MimeType, sticker.FileSize, sticker.Width, sticker.Height,
+			sticker.PackID, maxStickers,
+		).Scan(&saved).Error; err != nil {
+			return err
+		}
+		if len(saved) > 0 {
+			*sticker = saved[0]
+			added = true
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to add sticker", zap.Error(err))
+		return false, err
+	}
+	return added, nil
+}
+
+func (r *repository) GetStickerByID(ctx context.Context, stickerID uint) (*Sticker, error) {
+	var sticker Sticker
+	if err := r.db.WithContext(ctx).Preload("Pack").First(&sticker, stickerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("sticker not found")
+		}
+		logger.Error("Failed to get sticker", zap.Error(err))
+		return nil, err
+	}
+	return &sticker, nil
+}
+
+func (r *repository) DeleteSticker(ctx context.Context, stickerID uint) error {
+	if err := r.db.WithContext(ctx).Delete(&Sticker{}, stickerID).Error; err != nil {
+		logger.Error("Failed to delete sticker", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// ReorderStickers sets each sticker's position to its index in stickerIDs
+func (r *repository) ReorderStickers(ctx context.Context, packID uint, stickerIDs []uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for position, stickerID := range stickerIDs {
+			if err := tx.Model(&Sticker{}).
+				Where("id = ? AND pack_id = ?", stickerID, packID).
+				Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to reorder stickers", zap.Error(err))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,35 @@
+package emoji
+
+import (
+	"huddle/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes sets up custom emoji and sticker routes
+func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
+	// Custom emoji (workspace emoji are managed by moderators)
+	emoji := router.Group("/emoji")
+	emoji.Use(middleware.AuthMiddleware())
+	{
+		emoji.POST("", handler.CreateEmoji)       // Add emoji from an uploaded file
+		emoji.GET("", handler.GetEmoji)           // Workspace emoji and my own
+		emoji.GET("/lookup", handler.LookupEmoji) // Resolve ?shortcodes=a,b
+		emoji.DELETE("/:id", handler.DeleteEmoji) // Remove emoji
+	}
+
+	// Sticker packs (workspace packs are managed by moderators)
+	packs := router.Group("/sticker-packs")
+	packs.Use(middleware.AuthMiddleware())
+	{
+		packs.POST("", handler.CreateStickerPack)       // Create pack
+		packs.GET("", handler.GetStickerPacks)          // Workspace packs and my own
+		packs.GET("/:id", handler.GetStickerPack)       // Get pack with stickers
+		packs.PUT("/:id", handler.UpdateStickerPack)    // Rename pack
+		packs.DELETE("/:id", handler.DeleteStickerPack) // Delete pack
+
+		packs.POST("/:id/stickers", handler.AddSticker)                  // Add sticker from an uploaded file
+		packs.DELETE("/:id/stickers/:sticker_id", handler.RemoveSticker) // Remove sticker
+		packs.PUT("/:id/stickers/order", handler.ReorderStickers)        // Reorder stickers
+	}
+}
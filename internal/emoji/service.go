@@ -0,0 +1,557 @@
+package emoji
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"huddle/internal/file"
+	"huddle/internal/moderation"
+	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+	"huddle/pkg/validation"
+
+	"go.uber.org/zap"
+)
+
+// How long image URLs in responses stay valid
+const imageURLExpiry = 24 * time.Hour
+
+type service struct {
+	repo              Repository
+	moderationService moderation.Service
+	fileService       file.Service
+}
+
+// NewService creates a new emoji service
+func NewService(repo Repository, moderationService moderation.Service, fileService file.Service) Service {
+	return &service{
+		repo:              repo,
+		moderationService: moderationService,
+		fileService:       fileService,
+	}
+}
+
+// Custom emoji
+
+func (s *service) CreateEmoji(ctx context.Context, userID uint, req *CreateEmojiRequest) (*CustomEmojiResponse, error) {
+	shortcode := strings.ToLower(strings.Trim(strings.TrimSpace(req.Shortcode), ":"))
+	if !validation.IsCustomEmoji(":" + shortcode + ":") {
+		return nil, errors.New("invalid shortcode: use 2-32 lowercase letters, digits, _, + or -")
+	}
+
+	// Workspace emoji are managed by moderators; everyone else adds personal emoji up to a limit
+	var ownerID *uint
+	if req.Workspace {
+		if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+			return nil, err
+		}
+	} else {
+		count, err := s.repo.CountUserEmoji(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= MaxPersonalEmoji {
+			return nil, fmt.Errorf("you can have at most %d custom emoji", MaxPersonalEmoji)
+		}
+		ownerID = &userID
+	}
+
+	exists, err := s.repo.ShortcodeExists(ctx, shortcode, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, fmt.Errorf("shortcode :%s: is already taken", shortcode)
+	}
+
+	image, err := s.loadImage(ctx, userID, req.FileID, MaxEmojiUploadSize, EmojiImageSize)
+	if err != nil {
+		return nil, err
+	}
+	objectKey := fmt.Sprintf("emoji/%s_%d%s", shortcode, time.Now().UnixNano(), imageExtension(image.MimeType))
+	if err := s.storeImage(ctx, objectKey, image); err != nil {
+		return nil, err
+	}
+
+	emoji := &CustomEmoji{
+		Shortcode: shortcode,
+		UserID:    ownerID,
+		CreatedBy: &userID,
+		ObjectKey: objectKey,
+		MimeType:  image.MimeType,
+		FileSize:  int64(len(image.Data)),
+		Width:     image.Width,
+		Height:    image.Height,
+	}
+	if err := s.repo.CreateEmoji(ctx, emoji); err != nil {
+		// A concurrent upload may have claimed the shortcode first
+		s.deleteObject(ctx, objectKey)
+		return nil, fmt.Errorf("failed to save custom emoji: %w", err)
+	}
+
+	return s.buildEmojiResponse(ctx, emoji), nil
+}
+
+// GetEmoji lists the custom emoji the user can use: the workspace's and their own
+func (s *service) GetEmoji(ctx context.Context, userID uint) (*EmojiListResponse, error) {
+	emoji, err := s.repo.GetUsableEmoji(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildEmojiListResponse(ctx, emoji), nil
+}
+
+// LookupEmoji resolves shortcodes seen in messages and reactions, whoever owns them.
+// Each shortcode resolves to one emoji: the workspace's, else the viewer's own, else the oldest.
+func (s *service) LookupEmoji(ctx context.Context, userID uint, shortcodes []string) (*EmojiListResponse, error) {
+	emoji, err := s.repo.GetEmojiByShortcodes(ctx, normalizeShortcodes(shortcodes))
+	if err != nil {
+		return nil, err
+	}
+
+	resolved := pickEmoji(emoji, userID, true)
+	picked := make([]CustomEmoji, 0, len(resolved))
+	for _, e := range emoji {
+		if resolved[e.Shortcode].ID == e.ID {
+			picked = append(picked, e)
+		}
+	}
+	return s.buildEmojiListResponse(ctx, picked), nil
+}
+
+func (s *service) DeleteEmoji(ctx context.Context, userID, emojiID uint) error {
+	emoji, err := s.repo.GetEmojiByID(ctx, emojiID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateOwner(ctx, userID, emoji.UserID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeleteEmoji(ctx, emojiID); err != nil {
+		return err
+	}
+	s.deleteObject(ctx, emoji.ObjectKey)
+	return nil
+}
+
+// ResolveEmoji returns the custom emoji among shortcodes that the user may use, keyed by shortcode
+func (s *service) ResolveEmoji(ctx context.Context, userID uint, shortcodes []string) (map[string]CustomEmoji, error) {
+	resolved := make(map[string]CustomEmoji)
+	if len(shortcodes) == 0 {
+		return resolved, nil
+	}
+
+	emoji, err := s.repo.GetEmojiByShortcodes(ctx, normalizeShortcodes(shortcodes))
+	if err != nil {
+		return nil, err
+	}
+	return pickEmoji(emoji, userID, false), nil
+}
+
+// Sticker packs
+
+func (s *service) CreateStickerPack(ctx context.Context, userID uint, req *CreateStickerPackRequest) (*StickerPackResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("sticker pack name is required")
+	}
+
+	var ownerID *uint
+	if req.Workspace {
+		if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+			return nil, err
+		}
+	} else {
+		count, err := s.repo.CountUserPacks(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if count >= MaxPersonalPacks {
+			return nil, fmt.Errorf("you can have at most %d sticker packs", MaxPersonalPacks)
+		}
+		ownerID = &userID
+	}
+
+	pack := &StickerPack{
+		Name:        name,
+		Description: strings.TrimSpace(req.Description),
+		UserID:      ownerID,
+		CreatedBy:   &userID,
+	}
+	if err := s.repo.CreatePack(ctx, pack); err != nil {
+		return nil, fmt.Errorf("failed to create sticker pack: %w", err)
+	}
+	return s.buildPackResponse(ctx, pack), nil
+}
+
+// GetStickerPacks lists the packs the user can send stickers from
+func (s *service) GetStickerPacks(ctx context.Context, userID uint) (*StickerPackListResponse, error) {
+	packs, err := s.repo.GetUsablePacks(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &StickerPackListResponse{Packs: make([]StickerPackResponse, 0, len(packs))}
+	for i := range packs {
+		response.Packs = append(response.Packs, *s.buildPackResponse(ctx, &packs[i]))
+	}
+	return response, nil
+}
+
+// GetStickerPack returns any pack, so stickers received from others can be browsed
+func (s *service) GetStickerPack(ctx context.Context, packID uint) (*StickerPackResponse, error) {
+	pack, err := s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildPackResponse(ctx, pack), nil
+}
+
+func (s *service) UpdateStickerPack(ctx context.Context, userID, packID uint, req *UpdateStickerPackRequest) (*StickerPackResponse, error) {
+	pack, err := s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateOwner(ctx, userID, pack.UserID); err != nil {
+		return nil, err
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, errors.New("sticker pack name is required")
+	}
+	pack.Name = name
+	pack.Description = strings.TrimSpace(req.Description)
+	if err := s.repo.UpdatePack(ctx, pack); err != nil {
+		return nil, fmt.Errorf("failed to update sticker pack: %w", err)
+	}
+	pack.UpdatedAt = time.Now()
+
+	return s.buildPackResponse(ctx, pack), nil
+}
+
+func (s *service) DeleteStickerPack(ctx context.Context, userID, packID uint) error {
+	pack, err := s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return err
+	}
+	if err := s.validateOwner(ctx, userID, pack.UserID); err != nil {
+		return err
+	}
+
+	if err := s.repo.DeletePack(ctx, packID); err != nil {
+		return err
+	}
+	for _, sticker := range pack.Stickers {
+		s.deleteObject(ctx, sticker.ObjectKey)
+	}
+	return nil
+}
+
+// Stickers
+
+func (s *service) AddSticker(ctx context.Context, userID, packID uint, req *AddStickerRequest) (*StickerResponse, error) {
+	pack, err := s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateOwner(ctx, userID, pack.UserID); err != nil {
+		return nil, err
+	}
+	if len(pack.Stickers) >= MaxStickersPerPack {
+		return nil, fmt.Errorf("a sticker pack can hold at most %d stickers", MaxStickersPerPack)
+	}
+
+	emoji := validation.NormalizeEmoji(strings.TrimSpace(req.Emoji))
+	if emoji != "" && !validation.IsEmoji(emoji) {
+		return nil, errors.New("invalid emoji: must be a single emoji")
+	}
+
+	image, err := s.loadImage(ctx, userID, req.FileID, MaxStickerUploadSize, StickerImageSize)
+	if err != nil {
+		return nil, err
+	}
+	objectKey := fmt.Sprintf("stickers/pack_%d/%d%s", packID, time.Now().UnixNano(), imageExtension(image.MimeType))
+	if err := s.storeImage(ctx, objectKey, image); err != nil {
+		return nil, err
+	}
+
+	sticker := &Sticker{
+		PackID:    packID,
+		Emoji:     emoji,
+		ObjectKey: objectKey,
+		MimeType:  image.MimeType,
+		FileSize:  int64(len(image.Data)),
+		Width:     image.Width,
+		Height:    image.Height,
+	}
+	added, err := s.repo.AddSticker(ctx, sticker, MaxStickersPerPack)
+	if err != nil || !added {
+		s.deleteObject(ctx, objectKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add sticker: %w", err)
+		}
+		return nil, fmt.Errorf("a sticker pack can hold at most %d stickers", MaxStickersPerPack)
+	}
+
+	response := BuildStickerResponse(ctx, sticker)
+	return &response, nil
+}
+
+func (s *service) RemoveSticker(ctx context.Context, userID, packID, stickerID uint) error {
+	sticker, err := s.repo.GetStickerByID(ctx, stickerID)
+	if err != nil {
+		return err
+	}
+	if sticker.PackID != packID || sticker.Pack == nil {
+		return errors.New("sticker not found")
+	}
+	if err := s.validateOwner(ctx, userID, sticker.Pack.UserID); err != nil {
+		return err
+	}
+
+	// Messages that used the sticker keep their fallback text
+	if err := s.repo.DeleteSticker(ctx, stickerID); err != nil {
+		return err
+	}
+	s.deleteObject(ctx, sticker.ObjectKey)
+	return nil
+}
+
+// ReorderStickers sets the order of a pack; every sticker must be listed exactly once
+func (s *service) ReorderStickers(ctx context.Context, userID, packID uint, req *ReorderStickersRequest) (*StickerPackResponse, error) {
+	pack, err := s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validateOwner(ctx, userID, pack.UserID); err != nil {
+		return nil, err
+	}
+
+	inPack := make(map[uint]bool, len(pack.Stickers))
+	for _, sticker := range pack.Stickers {
+		inPack[sticker.ID] = true
+	}
+	seen := make(map[uint]bool, len(req.StickerIDs))
+	for _, stickerID := range req.StickerIDs {
+		if !inPack[stickerID] || seen[stickerID] {
+			return nil, errors.New("sticker_ids must list every sticker of the pack exactly once")
+		}
+		seen[stickerID] = true
+	}
+	if len(seen) != len(inPack) {
+		return nil, errors.New("sticker_ids must list every sticker of the pack exactly once")
+	}
+
+	if err := s.repo.ReorderStickers(ctx, packID, req.StickerIDs); err != nil {
+		return nil, err
+	}
+
+	pack, err = s.repo.GetPackByID(ctx, packID)
+	if err != nil {
+		return nil, err
+	}
+	return s.buildPackResponse(ctx, pack), nil
+}
+
+// GetUsableSticker returns a sticker the user may send: from a workspace pack or one of their own
+func (s *service) GetUsableSticker(ctx context.Context, userID, stickerID uint) (*Sticker, error) {
+	sticker, err := s.repo.GetStickerByID(ctx, stickerID)
+	if err != nil {
+		return nil, err
+	}
+	if sticker.Pack == nil || (sticker.Pack.UserID != nil && *sticker.Pack.UserID != userID) {
+		return nil, errors.New("access denied: you can only send stickers from workspace packs or your own")
+	}
+	return sticker, nil
+}
+
+// BuildStickerResponse builds the sticker shown in a message
+func BuildStickerResponse(ctx context.Context, sticker *Sticker) StickerResponse {
+	return StickerResponse{
+		ID:       sticker.ID,
+		PackID:   sticker.PackID,
+		Position: sticker.Position,
+		Emoji:    sticker.Emoji,
+		ImageURL: imageURL(ctx, sticker.ObjectKey),
+		MimeType: sticker.MimeType,
+		Width:    sticker.Width,
+		Height:   sticker.Height,
+	}
+}
+
+// validateOwner allows the owner of a personal emoji or pack, and moderators for everything
+func (s *service) validateOwner(ctx context.Context, userID uint, ownerID *uint) error {
+	if ownerID != nil && *ownerID == userID {
+		return nil
+	}
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		if ownerID != nil {
+			return errors.New("access denied: you don't own this item")
+		}
+		return err
+	}
+	return nil
+}
+
+// loadImage reads an image the user uploaded through the file module and fits it within size pixels
+func (s *service) loadImage(ctx context.Context, userID, fileID uint, maxBytes int64, size int) (*processedImage, error) {
+	reader, source, err := s.fileService.OpenFile(ctx, fileID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	if source.FileSize > maxBytes {
+		return nil, fmt.Errorf("image is too large: maximum size is %d KB", maxBytes/1024)
+	}
+	return processImage(reader, maxBytes, size)
+}
+
+// storeImage uploads a processed image to object storage
+func (s *service) storeImage(ctx context.Context, objectKey string, image *processedImage) error {
+	storage := minio.GetClient()
+	if storage == nil {
+		return errors.New("MinIO client not available")
+	}
+	if err := storage.UploadObject(ctx, objectKey, bytes.NewReader(image.Data), int64(len(image.Data)), image.MimeType); err != nil {
+		return fmt.Errorf("failed to store image: %w", err)
+	}
+	return nil
+}
+
+// deleteObject removes a stored image; a failure only leaves an orphaned object
+func (s *service) deleteObject(ctx context.Context, objectKey string) {
+	storage := minio.GetClient()
+	if storage == nil {
+		return
+	}
+	if err := storage.DeleteFile(ctx, objectKey); err != nil {
+		logger.Error("Failed to delete image from MinIO", zap.String("object_key", objectKey), zap.Error(err))
+	}
+}
+
+// imageURL returns a temporary download URL for a stored image, or "" if it cannot be generated
+func imageURL(ctx context.Context, objectKey string) string {
+	storage := minio.GetClient()
+	if storage == nil {
+		return ""
+	}
+	url, err := storage.GetPresignedURL(ctx, objectKey, imageURLExpiry)
+	if err != nil {
+		logger.Error("Failed to generate image URL", zap.Error(err))
+		return ""
+	}
+	return url
+}
+
+// imageExtension returns the file extension for a stored image type
+func imageExtension(mimeType string) string {
+	switch mimeType {
+	case "image/jpeg":
+		return ".jpg"
+	case "image/gif":
+		return ".gif"
+	default:
+		return ".png"
+	}
+}
+
+// pickEmoji chooses one emoji per shortcode. The workspace emoji wins so a shortcode renders
+// the same for every reader, then the user's own; other users' emoji only count if anyOwner is set,
+// and the oldest of those wins. emoji must be ordered by id within each shortcode.
+func pickEmoji(emoji []CustomEmoji, userID uint, anyOwner bool) map[string]CustomEmoji {
+	rank := func(e CustomEmoji) int {
+		switch {
+		case e.UserID == nil:
+			return 3
+		case *e.UserID == userID:
+			return 2
+		case anyOwner:
+			return 1
+		}
+		return 0
+	}
+
+	picked := make(map[string]CustomEmoji)
+	for _, e := range emoji {
+		r := rank(e)
+		if r == 0 {
+			continue
+		}
+		if current, ok := picked[e.Shortcode]; !ok || r > rank(current) {
+			picked[e.Shortcode] = e
+		}
+	}
+	return picked
+}
+
+// normalizeShortcodes strips colons and drops invalid or repeated shortcodes
+func normalizeShortcodes(shortcodes []string) []string {
+	seen := make(map[string]bool, len(shortcodes))
+	result := make([]string, 0, len(shortcodes))
+	for _, shortcode := range shortcodes {
+		shortcode = strings.ToLower(strings.Trim(strings.TrimSpace(shortcode), ":"))
+		if seen[shortcode] || !validation.IsCustomEmoji(":"+shortcode+":") {
+			continue
+		}
+		seen[shortcode] = true
+		result = append(result, shortcode)
+	}
+	return result
+}
+
+// buildEmojiResponse builds a CustomEmojiResponse from a CustomEmoji
+func (s *service) buildEmojiResponse(ctx context.Context, emoji *CustomEmoji) *CustomEmojiResponse {
+	scope := ScopeWorkspace
+	if emoji.UserID != nil {
+		scope = ScopeUser
+	}
+	return &CustomEmojiResponse{
+		ID:        emoji.ID,
+		Shortcode: emoji.Shortcode,
+		Scope:     scope,
+		UserID:    emoji.UserID,
+		ImageURL:  imageURL(ctx, emoji.ObjectKey),
+		MimeType:  emoji.MimeType,
+		Width:     emoji.Width,
+		Height:    emoji.Height,
+		CreatedAt: emoji.CreatedAt,
+	}
+}
+
+// buildEmojiListResponse builds an EmojiListResponse
+func (s *service) buildEmojiListResponse(ctx context.Context, emoji []CustomEmoji) *EmojiListResponse {
+	response := &EmojiListResponse{Emoji: make([]CustomEmojiResponse, 0, len(emoji))}
+	for i := range emoji {
+		response.Emoji = append(response.Emoji, *s.buildEmojiResponse(ctx, &emoji[i]))
+	}
+	return response
+}
+
+// buildPackResponse builds a StickerPackResponse with the pack's stickers
+func (s *service) buildPackResponse(ctx context.Context, pack *StickerPack) *StickerPackResponse {
+	scope := ScopeWorkspace
+	if pack.UserID != nil {
+		scope = ScopeUser
+	}
+	response := &StickerPackResponse{
+		ID:          pack.ID,
+		Name:        pack.Name,
+		Description: pack.Description,
+		Scope:       scope,
+		UserID:      pack.UserID,
+		Stickers:    make([]StickerResponse, 0, len(pack.Stickers)),
+		CreatedAt:   pack.CreatedAt,
+		UpdatedAt:   pack.UpdatedAt,
+	}
+	for i := range pack.Stickers {
+		response.Stickers = append(response.Stickers, BuildStickerResponse(ctx, &pack.Stickers[i]))
+	}
+	return response
+}
@@ -0,0 +1,70 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPickEmoji(t *testing.T) {
+	owner := func(id uint) *uint { return &id }
+	workspace := CustomEmoji{ID: 10, Shortcode: "party"}
+	mine := CustomEmoji{ID: 11, Shortcode: "party", UserID: owner(1)}
+	theirs := CustomEmoji{ID: 12, Shortcode: "party", UserID: owner(2)}
+	theirsNewer := CustomEmoji{ID: 13, Shortcode: "party", UserID: owner(3)}
+
+	tests := []struct {
+		name     string
+		emoji    []CustomEmoji
+		anyOwner bool
+		want     map[string]uint // shortcode -> emoji id
+	}{
+		{
+			name:  "workspace emoji wins over the user's own",
+			emoji: []CustomEmoji{workspace, mine, theirs},
+			want:  map[string]uint{"party": 10},
+		},
+		{
+			name:  "user's own emoji without a workspace one",
+			emoji: []CustomEmoji{mine, theirs},
+			want:  map[string]uint{"party": 11},
+		},
+		{
+			name:  "other users' emoji are not usable",
+			emoji: []CustomEmoji{theirs},
+			want:  map[string]uint{},
+		},
+		{
+			name:     "lookup prefers the viewer's own over other users'",
+			emoji:    []CustomEmoji{theirs, mine},
+			anyOwner: true,
+			want:     map[string]uint{"party": 11},
+		},
+		{
+			name:     "lookup falls back to the oldest emoji",
+			emoji:    []CustomEmoji{theirs, theirsNewer},
+			anyOwner: true,
+			want:     map[string]uint{"party": 12},
+		},
+		{
+			name: "each shortcode is resolved on its own",
+			emoji: []CustomEmoji{
+				{ID: 20, Shortcode: "blob", UserID: owner(1)},
+				workspace, mine,
+			},
+			want: map[string]uint{"blob": 20, "party": 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			picked := pickEmoji(tt.emoji, 1, tt.anyOwner)
+			got := make(map[string]uint, len(picked))
+			for shortcode, e := range picked {
+				got[shortcode] = e.ID
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("pickEmoji() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,78 @@
+package export
+
+import (
+	"strconv"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new export handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// CreateExport starts an asynchronous export of a conversation's history
+func (h *Handler) CreateExport(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	var req CreateExportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind create export request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	export, err := h.service.RequestExport(c.Request.Context(), userID, uint(conversationID), &req)
+	if err != nil {
+		logger.Error("Failed to request export", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, export, "Export started successfully")
+}
+
+// GetExport gets an export's status and, once completed, a fresh download URL
+func (h *Handler) GetExport(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	exportID, err := strconv.ParseUint(c.Param("export_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid export ID")
+		return
+	}
+
+	export, err := h.service.GetExport(c.Request.Context(), userID, uint(exportID))
+	if err != nil {
+		logger.Error("Failed to get export", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, export, "Export retrieved successfully")
+}
+
+// Helper function to get user ID from context
+func getUserIDFromContext(c *gin.Context) uint {
+	userID, exists := c.Get("user_id")
+	if !exists {
+		return 0
+	}
+	return userID.(uint)
+}
@@ -0,0 +1,33 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"huddle/internal/message"
+)
+
+// Repository interface defines data access methods for exports
+type Repository interface {
+	// Jobs
+	CreateJob(ctx context.Context, job *ExportJob) error
+	GetJobByID(ctx context.Context, jobID uint) (*ExportJob, error)
+	HasActiveJob(ctx context.Context, conversationID, userID uint) (bool, error)
+	ClaimNextJob(ctx context.Context, now, staleBefore time.Time) (*ExportJob, error)
+	MarkJobCompleted(ctx context.Context, jobID uint, objectKey string, fileSize int64, messageCount int) error
+	MarkJobFailed(ctx context.Context, jobID uint, reason string, retry bool) error
+
+	// Conversation history
+	GetConversationInfo(ctx context.Context, conversationID uint) (*ConversationInfo, error)
+	GetParticipants(ctx context.Context, conversationID uint) ([]Participant, error)
+	GetMessageBatch(ctx context.Context, conversationID, afterID uint, limit int) ([]message.Message, error)
+	GetFileReferences(ctx context.Context, messageIDs []uint) ([]FileReference, error)
+}
+
+// Service interface defines business logic methods for exports
+type Service interface {
+	RequestExport(ctx context.Context, userID, conversationID uint, req *CreateExportRequest) (*ExportResponse, error)
+	GetExport(ctx context.Context, userID, exportID uint) (*ExportResponse, error)
+	RunExport(ctx context.Context, job *ExportJob) error
+	NotifyFailed(ctx context.Context, job *ExportJob, reason string)
+}
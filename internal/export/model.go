@@ -0,0 +1,103 @@
+package export
+
+import (
+	"time"
+
+	"huddle/internal/user"
+)
+
+// ExportJob represents an asynchronous conversation history export
+type ExportJob struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID uint       `json:"conversation_id" gorm:"not null"`
+	RequestedBy    uint       `json:"requested_by" gorm:"not null"`
+	Format         string     `json:"format" gorm:"not null;size:10"`
+	Status         string     `json:"status" gorm:"not null;default:'pending';size:20"`
+	ObjectKey      string     `json:"object_key"`
+	FileSize       int64      `json:"file_size" gorm:"not null;default:0"`
+	MessageCount   int        `json:"message_count" gorm:"not null;default:0"`
+	Attempts       int        `json:"attempts" gorm:"not null;default:0"`
+	LastError      string     `json:"last_error"`
+	StartedAt      *time.Time `json:"started_at"`
+	CompletedAt    *time.Time `json:"completed_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for ExportJob
+func (ExportJob) TableName() string {
+	return "conversation_exports"
+}
+
+// ConversationInfo represents the conversation being exported
+type ConversationInfo struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Participant represents a conversation member listed in an export
+type Participant struct {
+	UserID   uint      `json:"user_id"`
+	Role     string    `json:"role"`
+	JoinedAt time.Time `json:"joined_at"`
+
+	// Relations
+	User user.User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for Participant
+func (Participant) TableName() string {
+	return "conversation_participants"
+}
+
+// FileReference represents a stored file attached to an exported message
+type FileReference struct {
+	ID           uint   `json:"id"`
+	MessageID    uint   `json:"message_id"`
+	OriginalName string `json:"original_name"`
+	FileSize     int64  `json:"file_size"`
+	MimeType     string `json:"mime_type"`
+	ObjectKey    string `json:"object_key"`
+}
+
+// Export Format Constants
+const (
+	FormatJSON = "json"
+	FormatHTML = "html"
+	FormatText = "txt"
+)
+
+// Export Status Constants
+const (
+	StatusPending   = "pending"
+	StatusRunning   = "running"
+	StatusCompleted = "completed"
+	StatusFailed    = "failed"
+)
+
+// DownloadURLExpiry is how long a presigned export download link stays valid
+const DownloadURLExpiry = 24 * time.Hour
+
+// DTOs for API requests/responses
+
+// CreateExportRequest represents request to export a conversation
+type CreateExportRequest struct {
+	Format string `json:"format" binding:"required,oneof=json html txt"`
+}
+
+// ExportResponse represents an export job response
+type ExportResponse struct {
+	ID             uint       `json:"id"`
+	ConversationID uint       `json:"conversation_id"`
+	Format         string     `json:"format"`
+	Status         string     `json:"status"`
+	FileSize       int64      `json:"file_size,omitempty"`
+	MessageCount   int        `json:"message_count"`
+	Error          string     `json:"error,omitempty"`
+	DownloadURL    string     `json:"download_url,omitempty"`
+	URLExpiresAt   *time.Time `json:"url_expires_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	CompletedAt    *time.Time `json:"completed_at,omitempty"`
+}
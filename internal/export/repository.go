@@ -0,0 +1,201 @@
+package export
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/database"
+	"huddle/internal/message"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new export repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Jobs
+
+func (r *repository) CreateJob(ctx context.Context, job *ExportJob) error {
+	if err := r.db.WithContext(ctx).Create(job).Error; err != nil {
+		logger.Error("Failed to create export job", zap.Error(err))
+		return err
+	}
+	logger.Info("Export job created", zap.Uint("export_id", job.ID), zap.Uint("conversation_id", job.ConversationID))
+	return nil
+}
+
+func (r *repository) GetJobByID(ctx context.Context, jobID uint) (*ExportJob, error) {
+	var job ExportJob
+	if err := r.db.WithContext(ctx).First(&job, jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("export not found")
+		}
+		logger.Error("Failed to get export job", zap.Error(err))
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *repository) HasActiveJob(ctx context.Context, conversationID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("conversation_id = ? AND requested_by = ? AND status IN ?", conversationID, userID, []string{StatusPending, StatusRunning}).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check active export jobs", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) ClaimNextJob(ctx context.Context, now, staleBefore time.Time) (*ExportJob, error) {
+	var claimed []ExportJob
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE conversation_exports SET status = ?, started_at = ?, attempts = attempts + 1
+		WHERE id = (
+			SELECT id FROM conversation_exports
+			WHERE status = ? OR (status = ? AND started_at < ?)
+			ORDER BY created_at ASC
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		StatusRunning, now,
+		StatusPending, StatusRunning, staleBefore,
+	).Scan(&claimed).Error; err != nil {
+		logger.Error("Failed to claim export job", zap.Error(err))
+		return nil, err
+	}
+	if len(claimed) == 0 {
+		return nil, nil
+	}
+	return &claimed[0], nil
+}
+
+func (r *repository) MarkJobCompleted(ctx context.Context, jobID uint, objectKey string, fileSize int64, messageCount int) error {
+	if err := r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("id = ?", jobID).
+		Updates(map[string]interface{}{
+			"status":        StatusCompleted,
+			"object_key":    objectKey,
+			"file_size":     fileSize,
+			"message_count": messageCount,
+			"last_error":    "",
+			"completed_at":  time.Now().UTC(),
+		}).Error; err != nil {
+		logger.Error("Failed to mark export job completed", zap.Error(err))
+		return err
+	}
+	logger.Info("Export job completed", zap.Uint("export_id", jobID), zap.Int("message_count", messageCount))
+	return nil
+}
+
+func (r *repository) MarkJobFailed(ctx context.Context, jobID uint, reason string, retry bool) error {
+	status := StatusFailed
+	updates := map[string]interface{}{
+		"last_error": reason,
+	}
+	if retry {
+		status = StatusPending
+	} else {
+		updates["completed_at"] = time.Now().UTC()
+	}
+	updates["status"] = status
+
+	if err := r.db.WithContext(ctx).
+		Model(&ExportJob{}).
+		Where("id = ?", jobID).
+		Updates(updates).Error; err != nil {
+		logger.Error("Failed to mark export job failed", zap.Error(err))
+		return err
+	}
+	logger.Warn("Export job failed", zap.Uint("export_id", jobID), zap.String("status", status), zap.String("reason", reason))
+	return nil
+}
+
+// Conversation history
+
+func (r *repository) GetConversationInfo(ctx context.Context, conversationID uint) (*ConversationInfo, error) {
+	var info ConversationInfo
+	if err := r.db.WithContext(ctx).
+		Table("conversations").
+		Select("id, name, type, created_at").
+		Where("id = ?", conversationID).
+		Take(&info).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("conversation not found")
+		}
+		logger.Error("Failed to get conversation info", zap.Error(err))
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (r *repository) GetParticipants(ctx context.Context, conversationID uint) ([]Participant, error) {
+	var participants []Participant
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("conversation_id = ?", conversationID).
+		Order("joined_at ASC").
+		Find(&participants).Error; err != nil {
+		logger.Error("Failed to get export participants", zap.Error(err))
+		return nil, err
+	}
+	return participants, nil
+}
+
+func (r *repository) GetMessageBatch(ctx context.Context, conversationID, afterID uint, limit int) ([]message.Message, error) {
+	var messages []message.Message
+	if err := r.db.WithContext(ctx).
+		Preload("Sender").
+		Preload("ReplyTo.Sender").
+		Preload("Reactions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Reactions.User").
+		Preload("ForwardedFromSender").
+		Preload("Poll.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Poll.Options.Votes").
+		Preload("Location").
+		Where("conversation_id = ? AND id > ?", conversationID, afterID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("hidden_at IS NULL"). // Shadow-hidden messages are pending moderator review
+		Order("id ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		logger.Error("Failed to get export message batch", zap.Error(err))
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *repository) GetFileReferences(ctx context.Context, messageIDs []uint) ([]FileReference, error) {
+	var files []FileReference
+	if len(messageIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, message_id, original_name, file_size, mime_type, object_key").
+		Where("message_id IN ?", messageIDs).
+		Order("id ASC").
+		Scan(&files).Error; err != nil {
+		logger.Error("Failed to get export file references", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
@@ -0,0 +1,23 @@
+package export
+
+import (
+	"huddle/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes sets up export routes
+func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
+	// Conversation export routes (all protected)
+	conversations := router.Group("/conversations/:id")
+	conversations.Use(middleware.AuthMiddleware())
+	{
+		conversations.POST("/export", handler.CreateExport) // Start conversation export
+	}
+
+	exports := router.Group("/exports")
+	exports.Use(middleware.AuthMiddleware())
+	{
+		exports.GET("/:export_id", handler.GetExport) // Get export status and download URL
+	}
+}
@@ -0,0 +1,249 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"huddle/internal/conversation"
+	"huddle/internal/websocket"
+	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+
+	"go.uber.org/zap"
+)
+
+// exportBatchSize is how many messages are read from the database at a time
+const exportBatchSize = 500
+
+type service struct {
+	repo                Repository
+	wsService           websocket.Service
+	conversationService conversation.Service
+}
+
+// NewService creates a new export service
+func NewService(repo Repository, wsService websocket.Service, conversationService conversation.Service) Service {
+	return &service{
+		repo:                repo,
+		wsService:           wsService,
+		conversationService: conversationService,
+	}
+}
+
+func (s *service) RequestExport(ctx context.Context, userID, conversationID uint, req *CreateExportRequest) (*ExportResponse, error) {
+	// Only admins of group conversations can export
+	info, err := s.repo.GetConversationInfo(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if info.Type != conversation.ConversationTypeGroup {
+		return nil, errors.New("only group conversations can be exported")
+	}
+	if err := s.conversationService.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	if minio.GetClient() == nil {
+		return nil, errors.New("file storage is not available")
+	}
+
+	// One export at a time per admin and conversation
+	active, err := s.repo.HasActiveJob(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if active {
+		return nil, errors.New("an export of this conversation is already in progress")
+	}
+
+	job := &ExportJob{
+		ConversationID: conversationID,
+		RequestedBy:    userID,
+		Format:         req.Format,
+		Status:         StatusPending,
+	}
+	if err := s.repo.CreateJob(ctx, job); err != nil {
+		return nil, err
+	}
+
+	return s.buildExportResponse(ctx, job), nil
+}
+
+func (s *service) GetExport(ctx context.Context, userID, exportID uint) (*ExportResponse, error) {
+	job, err := s.repo.GetJobByID(ctx, exportID)
+	if err != nil {
+		return nil, err
+	}
+	if job.RequestedBy != userID {
+		return nil, errors.New("access denied: not the export requester")
+	}
+
+	// Downloads stay limited to current admins
+	if err := s.conversationService.ValidateConversationAdmin(ctx, userID, job.ConversationID); err != nil {
+		return nil, err
+	}
+
+	return s.buildExportResponse(ctx, job), nil
+}
+
+// RunExport writes the conversation history to a temporary file, uploads it and notifies the requester
+func (s *service) RunExport(ctx context.Context, job *ExportJob) error {
+	storage := minio.GetClient()
+	if storage == nil {
+		return errors.New("file storage is not available")
+	}
+
+	info, err := s.repo.GetConversationInfo(ctx, job.ConversationID)
+	if err != nil {
+		return err
+	}
+	participants, err := s.repo.GetParticipants(ctx, job.ConversationID)
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp("", "huddle-export-*")
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	defer tmp.Close()
+
+	buffered := bufio.NewWriter(tmp)
+	writer, err := newTranscriptWriter(job.Format, buffered)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().UTC()
+	if err := writer.WriteHeader(&transcriptHeader{
+		Conversation: info,
+		Participants: participants,
+		ExportID:     job.ID,
+		ExportedBy:   job.RequestedBy,
+		GeneratedAt:  now,
+	}); err != nil {
+		return err
+	}
+
+	// Walk the full history in id order
+	messageCount := 0
+	var afterID uint
+	for {
+		messages, err := s.repo.GetMessageBatch(ctx, job.ConversationID, afterID, exportBatchSize)
+		if err != nil {
+			return err
+		}
+		if len(messages) == 0 {
+			break
+		}
+
+		messageIDs := make([]uint, 0, len(messages))
+		for _, msg := range messages {
+			messageIDs = append(messageIDs, msg.ID)
+		}
+		files, err := s.repo.GetFileReferences(ctx, messageIDs)
+		if err != nil {
+			return err
+		}
+		filesByMessage := make(map[uint][]FileReference)
+		for _, file := range files {
+			filesByMessage[file.MessageID] = append(filesByMessage[file.MessageID], file)
+		}
+
+		for i := range messages {
+			if err := writer.WriteMessage(buildExportedMessage(&messages[i], filesByMessage[messages[i].ID], now)); err != nil {
+				return err
+			}
+			messageCount++
+		}
+		afterID = messages[len(messages)-1].ID
+	}
+
+	if err := writer.WriteFooter(messageCount); err != nil {
+		return err
+	}
+	if err := buffered.Flush(); err != nil {
+		return err
+	}
+
+	size, err := tmp.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	objectKey := fmt.Sprintf("exports/conversation_%d/%d_%s.%s", job.ConversationID, job.ID, now.Format("20060102T150405Z"), job.Format)
+	if err := storage.UploadObject(ctx, objectKey, tmp, size, contentType(job.Format)); err != nil {
+		return err
+	}
+
+	if err := s.repo.MarkJobCompleted(ctx, job.ID, objectKey, size, messageCount); err != nil {
+		return err
+	}
+
+	job.Status = StatusCompleted
+	job.ObjectKey = objectKey
+	job.FileSize = size
+	job.MessageCount = messageCount
+	job.CompletedAt = &now
+	s.notify(ctx, job)
+	return nil
+}
+
+// NotifyFailed tells the requester their export could not be produced
+func (s *service) NotifyFailed(ctx context.Context, job *ExportJob, reason string) {
+	job.Status = StatusFailed
+	job.LastError = reason
+	s.notify(ctx, job)
+}
+
+// Helper methods
+
+// notify sends the export result to the requester's connections
+func (s *service) notify(ctx context.Context, job *ExportJob) {
+	s.wsService.HandleExportFinished(ctx, job.RequestedBy, map[string]interface{}{
+		"conversation_id": job.ConversationID,
+		"export":          s.buildExportResponse(ctx, job),
+	})
+}
+
+// buildExportResponse builds an export response, signing a fresh download URL for completed exports
+func (s *service) buildExportResponse(ctx context.Context, job *ExportJob) *ExportResponse {
+	response := &ExportResponse{
+		ID:             job.ID,
+		ConversationID: job.ConversationID,
+		Format:         job.Format,
+		Status:         job.Status,
+		FileSize:       job.FileSize,
+		MessageCount:   job.MessageCount,
+		CreatedAt:      job.CreatedAt,
+		CompletedAt:    job.CompletedAt,
+	}
+	if job.Status == StatusFailed {
+		response.Error = job.LastError
+	}
+
+	if job.Status == StatusCompleted && job.ObjectKey != "" {
+		if storage := minio.GetClient(); storage != nil {
+			fileName := fmt.Sprintf("conversation-%d-export-%d.%s", job.ConversationID, job.ID, job.Format)
+			url, err := storage.GetPresignedDownloadURL(ctx, job.ObjectKey, fileName, DownloadURLExpiry)
+			if err != nil {
+				logger.Error("Failed to sign export download URL", zap.Uint("export_id", job.ID), zap.Error(err))
+			} else {
+				expiresAt := time.Now().UTC().Add(DownloadURLExpiry)
+				response.DownloadURL = url
+				response.URLExpiresAt = &expiresAt
+			}
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,74 @@
+package export
+
+import (
+	"context"
+	"time"
+
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// How often the worker looks for pending exports
+	exportPollInterval = 5 * time.Second
+
+	// Exports left running longer than this (e.g. after a crash) are reclaimed
+	exportClaimTimeout = 30 * time.Minute
+
+	// Maximum number of attempts before an export is marked failed
+	maxExportAttempts = 3
+)
+
+// Worker runs pending export jobs in the background
+type Worker struct {
+	repo    Repository
+	service Service
+}
+
+// NewWorker creates a new export worker
+func NewWorker(repo Repository, service Service) *Worker {
+	return &Worker{
+		repo:    repo,
+		service: service,
+	}
+}
+
+// Start starts the worker goroutine
+func (w *Worker) Start() {
+	go w.run()
+}
+
+// run drains pending exports on every tick
+func (w *Worker) run() {
+	logger.Info("📦 Conversation export worker started")
+
+	ticker := time.NewTicker(exportPollInterval)
+	defer ticker.Stop()
+
+	w.processPending(context.Background())
+	for range ticker.C {
+		w.processPending(context.Background())
+	}
+}
+
+// processPending claims and runs exports one at a time until none are left
+func (w *Worker) processPending(ctx context.Context) {
+	for {
+		now := time.Now().UTC()
+		job, err := w.repo.ClaimNextJob(ctx, now, now.Add(-exportClaimTimeout))
+		if err != nil || job == nil {
+			return
+		}
+
+		if err := w.service.RunExport(ctx, job); err != nil {
+			retry := job.Attempts < maxExportAttempts
+			if markErr := w.repo.MarkJobFailed(ctx, job.ID, err.Error(), retry); markErr != nil {
+				logger.Error("Failed to record export failure", zap.Error(markErr))
+			}
+			if !retry {
+				w.service.NotifyFailed(ctx, job, err.Error())
+			}
+		}
+	}
+}
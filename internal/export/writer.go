@@ -0,0 +1,377 @@
+package export
+
+import (
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+	"time"
+
+	"huddle/internal/message"
+	"huddle/internal/user"
+	"huddle/pkg/richtext"
+)
+
+// transcriptHeader describes the export at the top of every transcript
+type transcriptHeader struct {
+	Conversation *ConversationInfo
+	Participants []Participant
+	ExportID     uint
+	ExportedBy   uint
+	GeneratedAt  time.Time
+}
+
+// transcriptWriter streams a conversation history in one export format
+type transcriptWriter interface {
+	WriteHeader(header *transcriptHeader) error
+	WriteMessage(msg *exportedMessage) error
+	WriteFooter(messageCount int) error
+}
+
+// exportedMessage is a message flattened for archiving
+type exportedMessage struct {
+	ID            uint               `json:"id"`
+	SenderID      uint               `json:"sender_id"`
+	Sender        string             `json:"sender"`
+	Type          string             `json:"type"`
+	Content       string             `json:"content"`
+	Entities      []richtext.Entity  `json:"entities,omitempty"`
+	CreatedAt     time.Time          `json:"created_at"`
+	IsEdited      bool               `json:"is_edited"`
+	EditedAt      *time.Time         `json:"edited_at,omitempty"`
+	ReplyTo       *exportedReply     `json:"reply_to,omitempty"`
+	ForwardedFrom string             `json:"forwarded_from,omitempty"`
+	FileURL       string             `json:"file_url,omitempty"`
+	Files         []FileReference    `json:"files,omitempty"`
+	Reactions     []exportedReaction `json:"reactions,omitempty"`
+	Poll          *exportedPoll      `json:"poll,omitempty"`
+	Location      *exportedLocation  `json:"location,omitempty"`
+}
+
+// exportedReply identifies the message a reply answers
+type exportedReply struct {
+	ID      uint   `json:"id"`
+	Sender  string `json:"sender"`
+	Excerpt string `json:"excerpt"`
+}
+
+// exportedReaction groups reactions by emoji
+type exportedReaction struct {
+	Emoji string   `json:"emoji"`
+	Count int      `json:"count"`
+	Users []string `json:"users"`
+}
+
+// exportedPoll records a poll with its final tallies
+type exportedPoll struct {
+	Question string               `json:"question"`
+	IsClosed bool                 `json:"is_closed"`
+	Options  []exportedPollOption `json:"options"`
+}
+
+// exportedLocation records a shared position; live locations are archived at their last known position
+type exportedLocation struct {
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Accuracy  *float64   `json:"accuracy,omitempty"`
+	Label     string     `json:"label,omitempty"`
+	LiveUntil *time.Time `json:"live_until,omitempty"`
+}
+
+// exportedPollOption records one poll option's vote count
+type exportedPollOption struct {
+	Text  string `json:"text"`
+	Votes int    `json:"votes"`
+}
+
+// replyExcerptLength is how many characters of a replied-to message are quoted
+const replyExcerptLength = 80
+
+// newTranscriptWriter returns the writer for an export format
+func newTranscriptWriter(format string, w io.Writer) (transcriptWriter, error) {
+	switch format {
+	case FormatJSON:
+		return &jsonWriter{w: w}, nil
+	case FormatHTML:
+		return &htmlWriter{w: w}, nil
+	case FormatText:
+		return &textWriter{w: w}, nil
+	default:
+		return nil, fmt.Errorf("unsupported export format: %s", format)
+	}
+}
+
+// contentType returns the MIME type of an export format
+func contentType(format string) string {
+	switch format {
+	case FormatJSON:
+		return "application/json"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// buildExportedMessage flattens a message with its files for the transcript
+func buildExportedMessage(msg *message.Message, files []FileReference, now time.Time) *exportedMessage {
+	exported := &exportedMessage{
+		ID:        msg.ID,
+		SenderID:  msg.SenderID,
+		Sender:    displayName(msg.Sender),
+		Type:      msg.MessageType,
+		Content:   msg.Content,
+		Entities:  msg.Entities,
+		CreatedAt: msg.CreatedAt,
+		IsEdited:  msg.IsEdited,
+		EditedAt:  msg.EditedAt,
+		FileURL:   msg.FileURL,
+		Files:     files,
+	}
+
+	if msg.ReplyTo != nil {
+		excerpt := []rune(msg.ReplyTo.Content)
+		if len(excerpt) > replyExcerptLength {
+			excerpt = append(excerpt[:replyExcerptLength], '…')
+		}
+		exported.ReplyTo = &exportedReply{
+			ID:      msg.ReplyTo.ID,
+			Sender:  displayName(msg.ReplyTo.Sender),
+			Excerpt: string(excerpt),
+		}
+	} else if msg.ReplyToID != nil {
+		exported.ReplyTo = &exportedReply{ID: *msg.ReplyToID}
+	}
+
+	if msg.ForwardedFromSender != nil {
+		exported.ForwardedFrom = displayName(*msg.ForwardedFromSender)
+	}
+
+	index := make(map[string]int)
+	for _, reaction := range msg.Reactions {
+		i, exists := index[reaction.Emoji]
+		if !exists {
+			i = len(exported.Reactions)
+			index[reaction.Emoji] = i
+			exported.Reactions = append(exported.Reactions, exportedReaction{Emoji: reaction.Emoji})
+		}
+		exported.Reactions[i].Count++
+		exported.Reactions[i].Users = append(exported.Reactions[i].Users, displayName(reaction.User))
+	}
+
+	if msg.Poll != nil {
+		poll := &exportedPoll{
+			Question: msg.Poll.Question,
+			IsClosed: msg.Poll.IsClosed(now),
+			Options:  make([]exportedPollOption, 0, len(msg.Poll.Options)),
+		}
+		for _, option := range msg.Poll.Options {
+			poll.Options = append(poll.Options, exportedPollOption{Text: option.Text, Votes: len(option.Votes)})
+		}
+		exported.Poll = poll
+	}
+
+	if msg.Location != nil {
+		exported.Location = &exportedLocation{
+			Latitude:  msg.Location.Latitude,
+			Longitude: msg.Location.Longitude,
+			Accuracy:  msg.Location.Accuracy,
+			Label:     msg.Location.Label,
+			LiveUntil: msg.Location.LiveUntil,
+		}
+	}
+
+	return exported
+}
+
+// displayName returns the name shown for a user in transcripts
+func displayName(u user.User) string {
+	if u.ID == 0 {
+		return "Deleted user"
+	}
+	if u.DisplayName != "" {
+		return u.DisplayName
+	}
+	return u.Username
+}
+
+// jsonWriter writes a single JSON document: export info, conversation, participants and messages
+type jsonWriter struct {
+	w     io.Writer
+	wrote bool
+}
+
+func (j *jsonWriter) WriteHeader(header *transcriptHeader) error {
+	participants := make([]map[string]interface{}, 0, len(header.Participants))
+	for _, p := range header.Participants {
+		participants = append(participants, map[string]interface{}{
+			"user_id":      p.UserID,
+			"username":     p.User.Username,
+			"display_name": p.User.DisplayName,
+			"role":         p.Role,
+			"joined_at":    p.JoinedAt,
+		})
+	}
+
+	meta, err := json.Marshal(map[string]interface{}{
+		"id":           header.ExportID,
+		"exported_by":  header.ExportedBy,
+		"generated_at": header.GeneratedAt,
+	})
+	if err != nil {
+		return err
+	}
+	conversation, err := json.Marshal(header.Conversation)
+	if err != nil {
+		return err
+	}
+	members, err := json.Marshal(participants)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(j.w, "{\n\"export\": %s,\n\"conversation\": %s,\n\"participants\": %s,\n\"messages\": [\n", meta, conversation, members)
+	return err
+}
+
+func (j *jsonWriter) WriteMessage(msg *exportedMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	if j.wrote {
+		if _, err := io.WriteString(j.w, ",\n"); err != nil {
+			return err
+		}
+	}
+	j.wrote = true
+	_, err = j.w.Write(data)
+	return err
+}
+
+func (j *jsonWriter) WriteFooter(messageCount int) error {
+	_, err := fmt.Fprintf(j.w, "\n],\n\"message_count\": %d\n}\n", messageCount)
+	return err
+}
+
+// textWriter writes a plain-text transcript, one message per block
+type textWriter struct {
+	w io.Writer
+}
+
+func (t *textWriter) WriteHeader(header *transcriptHeader) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Conversation: %s (#%d, %s)\n", header.Conversation.Name, header.Conversation.ID, header.Conversation.Type)
+	fmt.Fprintf(&b, "Exported: %s\n", header.GeneratedAt.Format(time.RFC3339))
+	b.WriteString("Participants:\n")
+	for _, p := range header.Participants {
+		fmt.Fprintf(&b, "  - %s (@%s, %s)\n", displayName(p.User), p.User.Username, p.Role)
+	}
+	b.WriteString(strings.Repeat("=", 60) + "\n\n")
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+func (t *textWriter) WriteMessage(msg *exportedMessage) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "[%s] #%d %s", msg.CreatedAt.UTC().Format("2006-01-02 15:04:05 UTC"), msg.ID, msg.Sender)
+	if msg.IsEdited {
+		b.WriteString(" (edited)")
+	}
+	b.WriteString(":\n")
+	if msg.ForwardedFrom != "" {
+		fmt.Fprintf(&b, "  Forwarded from %s\n", msg.ForwardedFrom)
+	}
+	if msg.ReplyTo != nil {
+		fmt.Fprintf(&b, "  > Reply to #%d %s: %s\n", msg.ReplyTo.ID, msg.ReplyTo.Sender, msg.ReplyTo.Excerpt)
+	}
+	for _, line := range strings.Split(msg.Content, "\n") {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+	if msg.Poll != nil {
+		for _, option := range msg.Poll.Options {
+			fmt.Fprintf(&b, "  [poll] %s: %d vote(s)\n", option.Text, option.Votes)
+		}
+	}
+	if msg.Location != nil {
+		fmt.Fprintf(&b, "  [location] %.6f, %.6f\n", msg.Location.Latitude, msg.Location.Longitude)
+	}
+	if msg.FileURL != "" && len(msg.Files) == 0 {
+		fmt.Fprintf(&b, "  [file] %s\n", msg.FileURL)
+	}
+	for _, file := range msg.Files {
+		fmt.Fprintf(&b, "  [file] %s (%d bytes, %s)\n", file.OriginalName, file.FileSize, file.ObjectKey)
+	}
+	if len(msg.Reactions) > 0 {
+		parts := make([]string, 0, len(msg.Reactions))
+		for _, reaction := range msg.Reactions {
+			parts = append(parts, fmt.Sprintf("%s %d", reaction.Emoji, reaction.Count))
+		}
+		fmt.Fprintf(&b, "  Reactions: %s\n", strings.Join(parts, ", "))
+	}
+	b.WriteString("\n")
+	_, err := io.WriteString(t.w, b.String())
+	return err
+}
+
+func (t *textWriter) WriteFooter(messageCount int) error {
+	_, err := fmt.Fprintf(t.w, "%s\n%d message(s)\n", strings.Repeat("=", 60), messageCount)
+	return err
+}
+
+// htmlWriter writes a self-contained HTML transcript (inline styles, no external assets)
+type htmlWriter struct {
+	w io.Writer
+}
+
+var htmlHeaderTemplate = template.Must(template.New("header").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Conversation.Name}} – Huddle export</title>
+<style>
+body{font-family:-apple-system,Segoe UI,Roboto,sans-serif;max-width:860px;margin:2em auto;padding:0 1em;color:#1f2328}
+header{border-bottom:1px solid #d0d7de;margin-bottom:1.5em}
+.msg{padding:.6em 0;border-bottom:1px solid #f0f0f0}
+.meta{color:#57606a;font-size:.85em}
+.sender{font-weight:600;color:#1f2328}
+.content{white-space:pre-wrap;margin:.3em 0}
+.reply,.forward{border-left:3px solid #d0d7de;padding-left:.6em;color:#57606a;font-size:.9em}
+.files,.reactions,.poll{font-size:.9em;color:#57606a}
+.system{font-style:italic;color:#57606a}
+</style>
+</head>
+<body>
+<header>
+<h1>{{.Conversation.Name}}</h1>
+<p class="meta">Conversation #{{.Conversation.ID}} · exported {{.GeneratedAt.Format "2006-01-02 15:04 MST"}}</p>
+<p class="meta">Participants: {{range $i, $p := .Participants}}{{if $i}}, {{end}}{{$p.User.Username}} ({{$p.Role}}){{end}}</p>
+</header>
+<main>
+`))
+
+var htmlMessageTemplate = template.Must(template.New("message").Parse(`<div class="msg{{if eq .Type "system"}} system{{end}}" id="m{{.ID}}">
+<div class="meta"><span class="sender">{{.Sender}}</span> · {{.CreatedAt.UTC.Format "2006-01-02 15:04:05"}} UTC{{if .IsEdited}} · edited{{end}}</div>
+{{if .ForwardedFrom}}<div class="forward">Forwarded from {{.ForwardedFrom}}</div>{{end}}
+{{if .ReplyTo}}<div class="reply"><a href="#m{{.ReplyTo.ID}}">Reply to {{.ReplyTo.Sender}}</a>: {{.ReplyTo.Excerpt}}</div>{{end}}
+<div class="content">{{.Content}}</div>
+{{if .Poll}}<ul class="poll">{{range .Poll.Options}}<li>{{.Text}} – {{.Votes}} vote(s)</li>{{end}}</ul>{{end}}
+{{if .Location}}<div class="location">📍 {{printf "%.6f, %.6f" .Location.Latitude .Location.Longitude}}</div>{{end}}
+{{if .Files}}<ul class="files">{{range .Files}}<li>📎 {{.OriginalName}} ({{.FileSize}} bytes)</li>{{end}}</ul>{{else if .FileURL}}<div class="files">📎 {{.FileURL}}</div>{{end}}
+{{if .Reactions}}<div class="reactions">{{range .Reactions}}<span title="{{range $i, $u := .Users}}{{if $i}}, {{end}}{{$u}}{{end}}">{{.Emoji}} {{.Count}}</span> {{end}}</div>{{end}}
+</div>
+`))
+
+func (h *htmlWriter) WriteHeader(header *transcriptHeader) error {
+	return htmlHeaderTemplate.Execute(h.w, header)
+}
+
+func (h *htmlWriter) WriteMessage(msg *exportedMessage) error {
+	return htmlMessageTemplate.Execute(h.w, msg)
+}
+
+func (h *htmlWriter) WriteFooter(messageCount int) error {
+	_, err := fmt.Fprintf(h.w, "</main>\n<footer class=\"meta\"><p>%d message(s)</p></footer>\n</body>\n</html>\n", messageCount)
+	return err
+}
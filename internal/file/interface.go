@@ -2,6 +2,7 @@ package file
 
 import (
 	"context"
+	"io"
 	"mime/multipart"
 )
 
@@ -12,11 +13,12 @@ type Repository interface {
 	Update(ctx context.Context, file *File) error
 	Delete(ctx context.Context, id uint) error
 	SoftDelete(ctx context.Context, id uint) error
+	CountObjectReferences(ctx context.Context, objectKey string, excludeID uint) (int64, error)
 	
 	// File queries
 	ListByUser(ctx context.Context, userID uint, page, pageSize int) ([]File, int64, error)
 	ListByConversation(ctx context.Context, conversationID uint, page, pageSize int) ([]File, int64, error)
-	SearchFiles(ctx context.Context, req *FileSearchRequest) ([]File, int64, error)
+	SearchFiles(ctx context.Context, userID uint, req *FileSearchRequest) ([]File, int64, error)
 	
 	// File shares
 	CreateShare(ctx context.Context, share *FileShare) error
@@ -27,6 +29,8 @@ type Repository interface {
 	
 	// Access control
 	CheckFileAccess(ctx context.Context, fileID, userID uint) (bool, error)
+	IsConversationParticipant(ctx context.Context, conversationID, userID uint) (bool, error)
+	IsMessageSender(ctx context.Context, messageID, userID uint) (bool, error)
 	GetUserFiles(ctx context.Context, userID uint, page, pageSize int) ([]File, int64, error)
 }
 
@@ -51,6 +55,7 @@ type Service interface {
 	// File access
 	GetDownloadURL(ctx context.Context, fileID, userID uint) (string, error)
 	CheckFileAccess(ctx context.Context, fileID, userID uint) (bool, error)
+	OpenFile(ctx context.Context, fileID, userID uint) (io.ReadCloser, *File, error)
 	
 	// File processing
 	GenerateThumbnail(ctx context.Context, fileID uint) (string, error)
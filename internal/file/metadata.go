@@ -0,0 +1,47 @@
+package file
+
+import (
+	"image"
+	_ "image/gif"  // Register GIF decoder for image.DecodeConfig
+	_ "image/jpeg" // Register JPEG decoder for image.DecodeConfig
+	_ "image/png"  // Register PNG decoder for image.DecodeConfig
+	"io"
+	"mime/multipart"
+	"time"
+
+	"huddle/pkg/audio"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// imageDimensions reads the width and height from an image header, or nil if the format is not supported
+func imageDimensions(file multipart.File) (*int, *int) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil
+	}
+
+	config, _, err := image.DecodeConfig(file)
+	if err != nil {
+		return nil, nil
+	}
+	return &config.Width, &config.Height
+}
+
+// audioMetadata reads the duration in seconds (rounded up) and waveform of a WAV or Ogg/Opus file, or nil for other formats
+func audioMetadata(file multipart.File) (*int, []int) {
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil
+	}
+
+	info, err := audio.Analyze(file)
+	if err != nil {
+		if err != audio.ErrUnsupportedFormat {
+			logger.Info("Failed to analyze audio file", zap.Error(err))
+		}
+		return nil, nil
+	}
+
+	duration := int((info.Duration + time.Second - 1) / time.Second)
+	return &duration, info.Waveform
+}
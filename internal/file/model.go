@@ -1,6 +1,7 @@
 package file
 
 import (
+	"strings"
 	"time"
 
 	"huddle/internal/user"
@@ -12,6 +13,7 @@ type File struct {
 	UserID         uint       `json:"user_id" gorm:"not null"`
 	ConversationID *uint      `json:"conversation_id"`
 	MessageID      *uint      `json:"message_id"`
+	AttachmentPosition int    `json:"-" gorm:"not null;default:0"` // Order among the attachments of a message
 	
 	// File Information
 	FileName      string `json:"file_name" gorm:"not null"`
@@ -39,6 +41,7 @@ type File struct {
 	Width    *int `json:"width"`    // For images/videos
 	Height   *int `json:"height"`   // For images/videos
 	Duration *int `json:"duration"` // For videos/audio (seconds)
+	Waveform []int `json:"waveform" gorm:"type:jsonb;serializer:json"` // For audio: amplitudes from 0 to 100
 	
 	// Timestamps
 	CreatedAt time.Time  `json:"created_at" gorm:"default:now()"`
@@ -52,6 +55,19 @@ type File struct {
 	Shares       []FileShare       `json:"shares" gorm:"foreignKey:FileID"`
 }
 
+// StoredObjectKeys lists the objects kept in storage for a file: the upload itself plus any
+// thumbnail or preview derived from it. URLs pointing elsewhere are not ours to delete.
+func StoredObjectKeys(objectKey, thumbnailURL, previewURL string) []string {
+	keys := []string{objectKey}
+	for _, derived := range []string{thumbnailURL, previewURL} {
+		if derived == "" || derived == objectKey || strings.HasPrefix(derived, "/") || strings.Contains(derived, "://") {
+			continue
+		}
+		keys = append(keys, derived)
+	}
+	return keys
+}
+
 // FileConversation represents conversation info for file context
 type FileConversation struct {
 	ID   uint   `json:"id" gorm:"primaryKey"`
@@ -152,6 +168,7 @@ type FileResponse struct {
 	Width    *int `json:"width,omitempty"`
 	Height   *int `json:"height,omitempty"`
 	Duration *int `json:"duration,omitempty"`
+	Waveform []int `json:"waveform,omitempty"`
 	
 	// Timestamps
 	CreatedAt time.Time `json:"created_at"`
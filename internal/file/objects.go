@@ -0,0 +1,58 @@
+package file
+
+import (
+	"context"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+
+	"go.uber.org/zap"
+)
+
+// StoredObject is the storage side of an attachment row, loaded before the row is deleted
+type StoredObject struct {
+	ID           uint
+	MessageID    uint
+	ObjectKey    string
+	ThumbnailURL string
+	PreviewURL   string
+}
+
+// ObjectReferences counts the file rows that still point at a stored object
+type ObjectReferences interface {
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+}
+
+// DeleteStoredObjects removes the objects of attachments whose rows are gone, with their thumbnails and previews.
+// Objects another row still references (forwarded copies) are kept. A failure only leaves an orphaned object
+// behind, so it is logged and counted rather than returned.
+func DeleteStoredObjects(ctx context.Context, refs ObjectReferences, attachments []StoredObject) (deleted, failed int) {
+	client := minio.GetClient()
+	for _, attachment := range attachments {
+		count, err := refs.CountFilesByObjectKey(ctx, attachment.ObjectKey)
+		if err != nil {
+			logger.Error("Failed to count attachment references", zap.String("object_key", attachment.ObjectKey), zap.Error(err))
+			continue
+		}
+		if count > 0 {
+			continue
+		}
+
+		for _, objectKey := range StoredObjectKeys(attachment.ObjectKey, attachment.ThumbnailURL, attachment.PreviewURL) {
+			if client == nil {
+				failed++
+				continue
+			}
+			if err := client.DeleteFile(ctx, objectKey); err != nil {
+				failed++
+				logger.Warn("Failed to delete attachment object",
+					zap.Uint("message_id", attachment.MessageID),
+					zap.String("object_key", objectKey),
+					zap.Error(err))
+				continue
+			}
+			deleted++
+		}
+	}
+	return deleted, failed
+}
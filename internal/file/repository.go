@@ -84,6 +84,18 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	return nil
 }
 
+// CountObjectReferences counts other file records pointing at the same stored object (e.g. forwarded copies)
+func (r *repository) CountObjectReferences(ctx context.Context, objectKey string, excludeID uint) (int64, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).Model(&File{}).
+		Where("object_key = ? AND id != ?", objectKey, excludeID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count object references", zap.Error(err), zap.String("object_key", objectKey))
+		return 0, fmt.Errorf("failed to count object references: %w", err)
+	}
+	return count, nil
+}
+
 // SoftDelete soft deletes a file record
 func (r *repository) SoftDelete(ctx context.Context, id uint) error {
 	if err := r.db.WithContext(ctx).Delete(&File{}, id).Error; err != nil {
@@ -150,11 +162,16 @@ func (r *repository) ListByConversation(ctx context.Context, conversationID uint
 }
 
 // SearchFiles searches files with filters
-func (r *repository) SearchFiles(ctx context.Context, req *FileSearchRequest) ([]File, int64, error) {
+func (r *repository) SearchFiles(ctx context.Context, userID uint, req *FileSearchRequest) ([]File, int64, error) {
 	var files []File
 	var total int64
 
-	query := r.db.WithContext(ctx).Model(&File{}).Where("deleted_at IS NULL")
+	// Only files the user owns, was shared, or can see through a conversation
+	query := r.db.WithContext(ctx).Model(&File{}).
+		Where("deleted_at IS NULL").
+		Where(`(user_id = ?
+			OR conversation_id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)
+			OR id IN (SELECT file_id FROM file_shares WHERE shared_with = ?))`, userID, userID, userID)
 
 	// Apply filters
 	if req.Query != "" {
@@ -285,6 +302,45 @@ func (r *repository) CheckFileAccess(ctx context.Context, fileID, userID uint) (
 		return false, fmt.Errorf("failed to check file access: %w", err)
 	}
 	
+	if count > 0 {
+		return true, nil
+	}
+	
+	// Check if user is a member of the conversation the file was posted in
+	if err := r.db.WithContext(ctx).Model(&File{}).
+		Joins("JOIN conversation_participants ON conversation_participants.conversation_id = files.conversation_id").
+		Where("files.id = ? AND files.deleted_at IS NULL AND conversation_participants.user_id = ?", fileID, userID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check file conversation membership", zap.Error(err))
+		return false, fmt.Errorf("failed to check file access: %w", err)
+	}
+	
+	return count > 0, nil
+}
+
+// IsConversationParticipant checks if a user is a member of a conversation
+func (r *repository) IsConversationParticipant(ctx context.Context, conversationID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("conversation_participants").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check conversation membership", zap.Error(err))
+		return false, fmt.Errorf("failed to check conversation membership: %w", err)
+	}
+	return count > 0, nil
+}
+
+// IsMessageSender checks if a user sent a message
+func (r *repository) IsMessageSender(ctx context.Context, messageID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("messages").
+		Where("id = ? AND sender_id = ?", messageID, userID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check message sender", zap.Error(err))
+		return false, fmt.Errorf("failed to check message sender: %w", err)
+	}
 	return count > 0, nil
 }
 
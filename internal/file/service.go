@@ -3,6 +3,7 @@ package file
 import (
 	"context"
 	"fmt"
+	"io"
 	"mime/multipart"
 	"path/filepath"
 	"time"
@@ -56,6 +57,26 @@ func (s *service) UploadFile(ctx context.Context, userID uint, file multipart.Fi
 		return nil, err
 	}
 
+	// Files are visible to the members of the conversation they are uploaded to
+	if req.ConversationID != nil {
+		isParticipant, err := s.repo.IsConversationParticipant(ctx, *req.ConversationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isParticipant {
+			return nil, fmt.Errorf("access denied: you are not a member of this conversation")
+		}
+	}
+	if req.MessageID != nil {
+		isSender, err := s.repo.IsMessageSender(ctx, *req.MessageID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if !isSender {
+			return nil, fmt.Errorf("access denied: you can only attach files to your own messages")
+		}
+	}
+
 	// Generate unique object key
 	objectKey := s.minioClient.GenerateObjectKey(userID, header.Filename)
 	
@@ -86,6 +107,12 @@ func (s *service) UploadFile(ctx context.Context, userID uint, file multipart.Fi
 		IsProcessed:    true,
 		IsPublic:       req.IsPublic,
 	}
+	switch fileType {
+	case FileTypeImage:
+		fileRecord.Width, fileRecord.Height = imageDimensions(file)
+	case FileTypeAudio:
+		fileRecord.Duration, fileRecord.Waveform = audioMetadata(file)
+	}
 
 	// Save to database
 	if err := s.repo.Create(ctx, fileRecord); err != nil {
@@ -122,6 +149,7 @@ func (s *service) UploadFile(ctx context.Context, userID uint, file multipart.Fi
 		Width:          fileRecord.Width,
 		Height:         fileRecord.Height,
 		Duration:       fileRecord.Duration,
+		Waveform:       fileRecord.Waveform,
 		CreatedAt:      fileRecord.CreatedAt,
 		UpdatedAt:      fileRecord.UpdatedAt,
 	}
@@ -167,8 +195,8 @@ func (s *service) GetFileByID(ctx context.Context, fileID, userID uint) (*FileRe
 
 // UpdateFile updates file metadata
 func (s *service) UpdateFile(ctx context.Context, fileID, userID uint, req *UpdateFileRequest) (*FileResponse, error) {
-	// Check access
-	if err := s.ValidateFileAccess(ctx, fileID, userID); err != nil {
+	// Only the owner may change a file; conversation members can only read it
+	if err := s.validateFileOwner(ctx, fileID, userID); err != nil {
 		return nil, err
 	}
 
@@ -194,8 +222,8 @@ func (s *service) UpdateFile(ctx context.Context, fileID, userID uint, req *Upda
 
 // DeleteFile deletes a file
 func (s *service) DeleteFile(ctx context.Context, fileID, userID uint) error {
-	// Check access
-	if err := s.ValidateFileAccess(ctx, fileID, userID); err != nil {
+	// Only the owner may delete a file; conversation members can only read it
+	if err := s.validateFileOwner(ctx, fileID, userID); err != nil {
 		return err
 	}
 
@@ -205,10 +233,18 @@ func (s *service) DeleteFile(ctx context.Context, fileID, userID uint) error {
 		return err
 	}
 
-	// Delete from MinIO
-	if err := s.minioClient.DeleteFile(ctx, file.ObjectKey); err != nil {
-		logger.Error("Failed to delete file from MinIO", zap.Error(err))
-		// Continue with database deletion even if MinIO fails
+	// Delete from MinIO unless forwarded copies still use the object
+	refs, err := s.repo.CountObjectReferences(ctx, file.ObjectKey, file.ID)
+	if err != nil {
+		return err
+	}
+	if refs == 0 {
+		for _, objectKey := range StoredObjectKeys(file.ObjectKey, file.ThumbnailURL, file.PreviewURL) {
+			if err := s.minioClient.DeleteFile(ctx, objectKey); err != nil {
+				logger.Error("Failed to delete file from MinIO", zap.String("object_key", objectKey), zap.Error(err))
+				// Continue with database deletion even if MinIO fails
+			}
+		}
 	}
 
 	// Delete from database
@@ -245,6 +281,15 @@ func (s *service) ListUserFiles(ctx context.Context, userID uint, page, pageSize
 
 // ListConversationFiles lists files in a conversation
 func (s *service) ListConversationFiles(ctx context.Context, conversationID, userID uint, page, pageSize int) (*FileListResponse, error) {
+	// Check membership
+	isParticipant, err := s.repo.IsConversationParticipant(ctx, conversationID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !isParticipant {
+		return nil, fmt.Errorf("access denied: you are not a member of this conversation")
+	}
+
 	files, total, err := s.repo.ListByConversation(ctx, conversationID, page, pageSize)
 	if err != nil {
 		return nil, err
@@ -265,7 +310,7 @@ func (s *service) ListConversationFiles(ctx context.Context, conversationID, use
 
 // SearchFiles searches files with filters
 func (s *service) SearchFiles(ctx context.Context, userID uint, req *FileSearchRequest) (*FileListResponse, error) {
-	files, total, err := s.repo.SearchFiles(ctx, req)
+	files, total, err := s.repo.SearchFiles(ctx, userID, req)
 	if err != nil {
 		return nil, err
 	}
@@ -392,6 +437,28 @@ func (s *service) CheckFileAccess(ctx context.Context, fileID, userID uint) (boo
 	return s.repo.CheckFileAccess(ctx, fileID, userID)
 }
 
+// OpenFile streams the content of a file owned by the user, for modules that build their own assets from an upload
+func (s *service) OpenFile(ctx context.Context, fileID, userID uint) (io.ReadCloser, *File, error) {
+	if s.minioClient == nil {
+		return nil, nil, fmt.Errorf("MinIO client not available")
+	}
+
+	if err := s.validateFileOwner(ctx, fileID, userID); err != nil {
+		return nil, nil, err
+	}
+
+	file, err := s.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	reader, err := s.minioClient.DownloadFile(ctx, file.ObjectKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return reader, file, nil
+}
+
 // GenerateThumbnail generates a thumbnail for an image file
 func (s *service) GenerateThumbnail(ctx context.Context, fileID uint) (string, error) {
 	// This would implement thumbnail generation
@@ -436,6 +503,18 @@ func (s *service) ValidateFileAccess(ctx context.Context, fileID, userID uint) e
 	return nil
 }
 
+// validateFileOwner validates that a user owns a file
+func (s *service) validateFileOwner(ctx context.Context, fileID, userID uint) error {
+	file, err := s.repo.GetByID(ctx, fileID)
+	if err != nil {
+		return err
+	}
+	if file.UserID != userID {
+		return fmt.Errorf("access denied: you don't own this file")
+	}
+	return nil
+}
+
 // buildFileResponse builds a FileResponse from a File
 func (s *service) buildFileResponse(ctx context.Context, file *File) *FileResponse {
 	// Generate download URL
@@ -483,6 +562,7 @@ func (s *service) buildFileResponse(ctx context.Context, file *File) *FileRespon
 		Width:          file.Width,
 		Height:         file.Height,
 		Duration:       file.Duration,
+		Waveform:       file.Waveform,
 		CreatedAt:      file.CreatedAt,
 		UpdatedAt:      file.UpdatedAt,
 		User:           userResponse,
@@ -0,0 +1,141 @@
+package message
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"huddle/internal/config"
+	"huddle/pkg/linkpreview"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// Bot webhook signing headers
+const (
+	BotTimestampHeader = "X-Huddle-Request-Timestamp"
+	BotSignatureHeader = "X-Huddle-Signature" // v1=<hex HMAC-SHA256 of "v1:<timestamp>:<body>">
+)
+
+// botWebhookRequest is the JSON body POSTed to a bot webhook
+type botWebhookRequest struct {
+	Command        string `json:"command"`
+	Text           string `json:"text"`
+	ConversationID uint   `json:"conversation_id"`
+	UserID         uint   `json:"user_id"`
+	Username       string `json:"username"`
+	ReplyToID      *uint  `json:"reply_to_id,omitempty"`
+	Timestamp      int64  `json:"timestamp"`
+}
+
+// botWebhookResponse is the JSON body a bot webhook answers with
+type botWebhookResponse struct {
+	ResponseType string `json:"response_type"` // "ephemeral" (default) or "system"/"in_channel"
+	Text         string `json:"text"`
+}
+
+// botCaller calls bot webhooks with the SSRF protection used for link previews
+type botCaller struct {
+	client *http.Client
+}
+
+// newBotCaller creates a bot caller from the bot config
+func newBotCaller() *botCaller {
+	opts := linkpreview.Options{Timeout: 3 * time.Second}
+	if cfg := config.GetConfig(); cfg != nil {
+		opts.Timeout = cfg.Bots.WebhookTimeout
+		opts.AllowedHosts = cfg.Bots.AllowedHosts
+	}
+	return &botCaller{client: linkpreview.NewSafeClient(opts)}
+}
+
+// callBot forwards a command to its bot webhook and turns the answer into a command result
+func (s *service) callBot(ctx context.Context, bot *BotCommand, call *CommandCall) (*CommandResult, error) {
+	invoker, err := s.repo.GetUserByID(ctx, call.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	reply, err := s.bots.call(ctx, bot, &botWebhookRequest{
+		Command:        "/" + call.Name,
+		Text:           call.Args,
+		ConversationID: call.ConversationID,
+		UserID:         call.UserID,
+		Username:       invoker.Username,
+		ReplyToID:      call.ReplyToID,
+		Timestamp:      time.Now().Unix(),
+	})
+	if err != nil {
+		logger.Error("Bot webhook failed", zap.String("command", bot.Name), zap.Uint("conversation_id", bot.ConversationID), zap.Error(err))
+		return nil, fmt.Errorf("/%s did not respond", bot.Name)
+	}
+
+	switch reply.ResponseType {
+	case CommandResponseSystem, "in_channel":
+		return SystemMessage("%s", reply.Text), nil
+	default:
+		return Ephemeral("%s", reply.Text), nil
+	}
+}
+
+// call POSTs a signed request to a bot webhook and decodes its answer
+func (b *botCaller) call(ctx context.Context, bot *BotCommand, payload *botWebhookRequest) (*botWebhookResponse, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, bot.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	timestamp := strconv.FormatInt(payload.Timestamp, 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(BotTimestampHeader, timestamp)
+	req.Header.Set(BotSignatureHeader, "v1="+signBotRequest(bot.Secret, timestamp, body))
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, MaxBotResponseBytes+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxBotResponseBytes {
+		return nil, fmt.Errorf("webhook response exceeds %d bytes", MaxBotResponseBytes)
+	}
+
+	// An empty body acknowledges the command without a reply
+	reply := &botWebhookResponse{}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return reply, nil
+	}
+	if err := json.Unmarshal(data, reply); err != nil {
+		return nil, fmt.Errorf("invalid webhook response: %w", err)
+	}
+	return reply, nil
+}
+
+// signBotRequest computes the signature bots use to verify a request came from Huddle
+func signBotRequest(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte("v1:" + timestamp + ":"))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,394 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"huddle/internal/conversation"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// reminderDelayRegex matches the day and week delays time.ParseDuration does not understand
+var reminderDelayRegex = regexp.MustCompile(`^(\d+)(d|w)$`)
+
+// registerBuiltinCommands registers the slash commands that ship with Huddle
+func (s *service) registerBuiltinCommands() {
+	builtins := []*Command{
+		{Name: "help", Usage: "/help", Description: "List the commands available here", Handler: s.helpCommand},
+		{Name: "me", Usage: "/me <action>", Description: "Post an action about yourself", Handler: s.meCommand},
+		{Name: "shrug", Usage: "/shrug [message]", Description: "Append ¯\\_(ツ)_/¯ to your message", Handler: s.shrugCommand},
+		{Name: "poll", Usage: `/poll "Question" "Option 1" "Option 2" [--multiple] [--anonymous]`, Description: "Create a poll", Handler: s.pollCommand},
+		{Name: "remind", Usage: "/remind [me|here] in <duration>|at <RFC3339 time> <text>", Description: "Set a reminder for yourself or the conversation", Handler: s.remindCommand},
+		{Name: "topic", Usage: "/topic [text|--clear]", Description: "Show or set the conversation topic", GroupOnly: true, Handler: s.topicCommand},
+		{Name: "invite", Usage: "/invite @user [@user...]", Description: "Add people to the conversation", Permission: CommandPermissionAdmin, GroupOnly: true, Handler: s.inviteCommand},
+		{Name: "leave", Usage: "/leave [@new_admin]", Description: "Leave the conversation", GroupOnly: true, Handler: s.leaveCommand},
+	}
+
+	for _, cmd := range builtins {
+		if err := s.commands.Register(cmd); err != nil {
+			logger.Error("Failed to register built-in command", zap.String("command", cmd.Name), zap.Error(err))
+		}
+	}
+}
+
+func (s *service) helpCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	list, err := s.ListCommands(ctx, call.UserID, call.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, len(list.Commands))
+	for _, cmd := range list.Commands {
+		usage := cmd.Usage
+		if usage == "" {
+			usage = "/" + cmd.Name
+		}
+		line := usage
+		if cmd.Description != "" {
+			line += " - " + cmd.Description
+		}
+		lines = append(lines, line)
+	}
+	return Ephemeral("Available commands:\n%s", strings.Join(lines, "\n")), nil
+}
+
+func (s *service) meCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	if call.Args == "" {
+		return nil, errors.New("usage: /me <action>")
+	}
+	return SystemMessage("%s", call.Args), nil
+}
+
+func (s *service) shrugCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	content := strings.TrimSpace(call.Args + ` ¯\_(ツ)_/¯`)
+	return &CommandResult{
+		ResponseType: CommandResponseMessage,
+		Message: &CreateMessageRequest{
+			Content:     content,
+			MessageType: MessageTypeText,
+			ParseMode:   ParseModePlain, // Keep the backslash and underscores intact
+		},
+	}, nil
+}
+
+func (s *service) pollCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	tokens, err := splitQuotedArgs(call.Args)
+	if err != nil {
+		return nil, err
+	}
+
+	poll := &CreatePollRequest{}
+	var texts []string
+	for _, token := range tokens {
+		switch token {
+		case "--multiple":
+			poll.AllowsMultiple = true
+		case "--anonymous":
+			poll.IsAnonymous = true
+		default:
+			texts = append(texts, token)
+		}
+	}
+	if len(texts) < 3 {
+		return nil, errors.New(`usage: /poll "Question" "Option 1" "Option 2" [--multiple] [--anonymous]`)
+	}
+	if len(texts)-1 > MaxPollOptions {
+		return nil, fmt.Errorf("polls can have at most %d options", MaxPollOptions)
+	}
+	poll.Question = texts[0]
+	poll.Options = texts[1:]
+
+	return &CommandResult{
+		ResponseType: CommandResponseMessage,
+		Message: &CreateMessageRequest{
+			MessageType: MessageTypePoll,
+			Poll:        poll,
+		},
+	}, nil
+}
+
+func (s *service) remindCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	usage := errors.New("usage: /remind [me|here] in <duration>|at <RFC3339 time> <text>, e.g. /remind me in 2h check the build")
+
+	fields := strings.Fields(call.Args)
+	target := "me"
+	if len(fields) > 0 && (fields[0] == "me" || fields[0] == "here") {
+		target = fields[0]
+		fields = fields[1:]
+	}
+	if len(fields) < 3 {
+		return nil, usage
+	}
+
+	var remindAt time.Time
+	switch fields[0] {
+	case "in":
+		delay, err := parseReminderDelay(fields[1])
+		if err != nil {
+			return nil, usage
+		}
+		remindAt = time.Now().Add(delay)
+	case "at":
+		at, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return nil, errors.New("reminder time must be RFC3339, e.g. 2025-01-31T09:00:00+07:00")
+		}
+		remindAt = at
+	default:
+		return nil, usage
+	}
+
+	if !remindAt.After(time.Now()) {
+		return nil, errors.New("reminder time must be in the future")
+	}
+	if remindAt.After(time.Now().Add(MaxReminderDelay)) {
+		return nil, errors.New("reminders can be set at most one year ahead")
+	}
+	remindAt = remindAt.UTC()
+
+	text := strings.Join(fields[2:], " ")
+
+	if target == "here" {
+		scheduled, err := s.ScheduleMessage(ctx, call.UserID, call.ConversationID, &CreateMessageRequest{
+			Content:         "⏰ Reminder: " + text,
+			MessageType:     MessageTypeText,
+			ScheduledAt:     &remindAt,
+			ClientMessageID: call.ClientMessageID,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return Ephemeral("I will post \"%s\" here at %s", text, scheduled.ScheduledAt.Format(time.RFC1123)), nil
+	}
+
+	// A retried command returns the reminder set by the first attempt
+	if call.ClientMessageID != "" {
+		existing, err := s.repo.GetScheduledMessageByClientID(ctx, call.ConversationID, call.UserID, call.ClientMessageID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return Ephemeral("I will remind you about \"%s\" at %s", existing.Content, existing.ScheduledAt.Format(time.RFC1123)), nil
+		}
+	}
+
+	reminder := &ScheduledMessage{
+		ConversationID: call.ConversationID,
+		SenderID:       call.UserID,
+		Content:        text,
+		MessageType:    MessageTypeText,
+		ParseMode:      ParseModePlain,
+		Kind:           ScheduledKindReminder,
+		ScheduledAt:    remindAt,
+		Status:         ScheduledStatusPending,
+	}
+	if call.ClientMessageID != "" {
+		reminder.ClientMessageID = &call.ClientMessageID
+	}
+	if err := s.repo.CreateScheduledMessage(ctx, reminder); err != nil {
+		return nil, err
+	}
+	return Ephemeral("I will remind you about \"%s\" at %s", text, remindAt.Format(time.RFC1123)), nil
+}
+
+func (s *service) topicCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	if call.Args == "" {
+		settings, err := s.repo.GetConversationSettings(ctx, call.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.Topic == "" {
+			return Ephemeral("No topic is set"), nil
+		}
+		return Ephemeral("Topic: %s", settings.Topic), nil
+	}
+
+	// The conversation service only lets admins change the topic
+	topic := call.Args
+	if topic == "--clear" {
+		topic = ""
+	} else {
+		// Everyone sees the topic, so it cannot be shadow-hidden like a message
+		verdict, err := s.moderateContent(ctx, call.UserID, call.ConversationID, topic)
+		if err != nil {
+			return nil, err
+		}
+		if verdict.Hidden() {
+			return nil, errors.New("topic rejected by moderation")
+		}
+		topic = verdict.Content
+	}
+	if err := s.conversationService.UpdateTopic(ctx, call.UserID, call.ConversationID, topic); err != nil {
+		return nil, err
+	}
+
+	if topic == "" {
+		return SystemMessage("cleared the topic"), nil
+	}
+	return SystemMessage("set the topic: %s", topic), nil
+}
+
+func (s *service) inviteCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	usernames := parseMentionArgs(call.Args)
+	if len(usernames) == 0 {
+		return nil, errors.New("usage: /invite @user [@user...]")
+	}
+
+	users, err := s.repo.GetUsersByUsernames(ctx, usernames)
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[string]uint, len(users))
+	for _, u := range users {
+		found[u.Username] = u.ID
+	}
+
+	// Validate everyone before adding anyone
+	var missing, existing []string
+	for _, username := range usernames {
+		userID, ok := found[username]
+		if !ok {
+			missing = append(missing, "@"+username)
+			continue
+		}
+		isParticipant, err := s.repo.CheckUserInConversation(ctx, call.ConversationID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if isParticipant {
+			existing = append(existing, "@"+username)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("user not found: %s", strings.Join(missing, ", "))
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("already in this conversation: %s", strings.Join(existing, ", "))
+	}
+
+	added := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		if err := s.conversationService.AddParticipant(ctx, call.UserID, call.ConversationID, &conversation.AddParticipantRequest{
+			UserID: found[username],
+			Role:   conversation.ParticipantRoleMember,
+		}); err != nil {
+			return nil, err
+		}
+		added = append(added, "@"+username)
+	}
+
+	return SystemMessage("added %s", strings.Join(added, ", ")), nil
+}
+
+func (s *service) leaveCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	req := &conversation.LeaveConversationRequest{}
+
+	if usernames := parseMentionArgs(call.Args); len(usernames) > 0 {
+		users, err := s.repo.GetUsersByUsernames(ctx, usernames[:1])
+		if err != nil {
+			return nil, err
+		}
+		if len(users) == 0 {
+			return nil, fmt.Errorf("user not found: @%s", usernames[0])
+		}
+		req.NewAdminID = &users[0].ID
+	}
+
+	// Announce first: once the invoker has left they can no longer post
+	announcement, err := s.createSystemMessage(ctx, call.UserID, call.ConversationID, "left the conversation")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.conversationService.LeaveConversation(ctx, call.UserID, call.ConversationID, req); err != nil {
+		if deleteErr := s.repo.DeleteMessage(ctx, announcement.ID); deleteErr != nil {
+			logger.Error("Failed to remove leave announcement", zap.Error(deleteErr))
+		}
+		return nil, fmt.Errorf("%s (usage: /leave [@new_admin])", err.Error())
+	}
+
+	return Ephemeral("You left the conversation"), nil
+}
+
+// Helper functions
+
+// parseReminderDelay parses a Go duration such as 90m or 1h30m, or a whole number of days or weeks
+func parseReminderDelay(value string) (time.Duration, error) {
+	if matches := reminderDelayRegex.FindStringSubmatch(value); matches != nil {
+		n, err := strconv.Atoi(matches[1])
+		if err != nil {
+			return 0, err
+		}
+		unit := 24 * time.Hour
+		if matches[2] == "w" {
+			unit *= 7
+		}
+		// Checked before multiplying so a huge count cannot overflow into a valid duration
+		if n > int(MaxReminderDelay/unit) {
+			return 0, errors.New("duration is too long")
+		}
+		return time.Duration(n) * unit, nil
+	}
+
+	delay, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if delay <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	return delay, nil
+}
+
+// splitQuotedArgs splits command arguments on whitespace, keeping "quoted phrases" together
+func splitQuotedArgs(args string) ([]string, error) {
+	var tokens []string
+	var current strings.Builder
+	inQuotes, hasToken := false, false
+
+	for _, r := range args {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			inQuotes = !inQuotes
+			hasToken = true
+		case !inQuotes && (r == ' ' || r == '\t' || r == '\n'):
+			if hasToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				hasToken = false
+			}
+		default:
+			current.WriteRune(r)
+			hasToken = true
+		}
+	}
+	if inQuotes {
+		return nil, errors.New("unterminated quote")
+	}
+	if hasToken {
+		tokens = append(tokens, current.String())
+	}
+	return tokens, nil
+}
+
+// parseMentionArgs extracts unique usernames from arguments such as "@alice @bob"
+func parseMentionArgs(args string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, field := range strings.Fields(args) {
+		username := strings.TrimRight(strings.TrimPrefix(field, "@"), ",")
+		if username == "" || seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+	}
+	return usernames
+}
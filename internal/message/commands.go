@@ -0,0 +1,512 @@
+package message
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"huddle/internal/conversation"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// slashCommandRegex matches a message that invokes a slash command, e.g. "/poll ..." but not "/usr/bin" or "//escaped"
+var slashCommandRegex = regexp.MustCompile(`^/([A-Za-z][A-Za-z0-9_-]{0,31})(?:\s+([\s\S]*))?$`)
+
+// commandNameRegex matches a valid command name (without the slash)
+var commandNameRegex = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,31}$`)
+
+// CommandHandler runs a slash command and says how to answer it
+type CommandHandler func(ctx context.Context, call *CommandCall) (*CommandResult, error)
+
+// Command describes a slash command implemented in Go
+type Command struct {
+	Name        string // Without the leading slash
+	Usage       string
+	Description string
+	Permission  string // CommandPermissionMember or CommandPermissionAdmin
+	GroupOnly   bool
+	Handler     CommandHandler
+}
+
+// CommandCall describes one invocation of a slash command
+type CommandCall struct {
+	Name             string
+	Args             string
+	UserID           uint
+	ConversationID   uint
+	ConversationType string
+	ReplyToID        *uint
+	ClientMessageID  string // Retries with the same id return the first response
+}
+
+// CommandResult tells the framework how to answer a command
+type CommandResult struct {
+	ResponseType string                // CommandResponseEphemeral, CommandResponseSystem or CommandResponseMessage
+	Text         string                // Ephemeral reply or system message content
+	Message      *CreateMessageRequest // Posted as the invoker for CommandResponseMessage
+}
+
+// Ephemeral builds a result only the invoker sees
+func Ephemeral(format string, args ...interface{}) *CommandResult {
+	return &CommandResult{ResponseType: CommandResponseEphemeral, Text: fmt.Sprintf(format, args...)}
+}
+
+// SystemMessage builds a result posted to the conversation as a system message
+func SystemMessage(format string, args ...interface{}) *CommandResult {
+	return &CommandResult{ResponseType: CommandResponseSystem, Text: fmt.Sprintf(format, args...)}
+}
+
+// CommandRegistry holds the slash commands implemented in Go
+type CommandRegistry struct {
+	mu       sync.RWMutex
+	commands map[string]*Command
+}
+
+// NewCommandRegistry creates an empty command registry
+func NewCommandRegistry() *CommandRegistry {
+	return &CommandRegistry{
+		commands: make(map[string]*Command),
+	}
+}
+
+// Register adds a command; names are unique
+func (r *CommandRegistry) Register(cmd *Command) error {
+	if cmd == nil || cmd.Handler == nil {
+		return errors.New("command handler is required")
+	}
+	if !commandNameRegex.MatchString(cmd.Name) {
+		return fmt.Errorf("invalid command name %q", cmd.Name)
+	}
+	if cmd.Permission == "" {
+		cmd.Permission = CommandPermissionMember
+	}
+	if cmd.Permission != CommandPermissionMember && cmd.Permission != CommandPermissionAdmin {
+		return fmt.Errorf("invalid command permission %q", cmd.Permission)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.commands[cmd.Name]; exists {
+		return fmt.Errorf("command /%s is already registered", cmd.Name)
+	}
+	r.commands[cmd.Name] = cmd
+	return nil
+}
+
+// Get looks up a command by name
+func (r *CommandRegistry) Get(name string) (*Command, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	cmd, ok := r.commands[name]
+	return cmd, ok
+}
+
+// List returns all commands sorted by name
+func (r *CommandRegistry) List() []*Command {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	commands := make([]*Command, 0, len(r.commands))
+	for _, cmd := range r.commands {
+		commands = append(commands, cmd)
+	}
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return commands
+}
+
+// IsSlashCommand reports whether a message should be routed to a slash command instead of being posted
+func IsSlashCommand(req *CreateMessageRequest) bool {
+	return req.MessageType == MessageTypeText && slashCommandRegex.MatchString(strings.TrimSpace(req.Content))
+}
+
+// unescapeSlashCommand turns a leading "//" into a literal "/" so text can start with a slash
+func unescapeSlashCommand(messageType, content string) string {
+	if messageType == MessageTypeText && strings.HasPrefix(content, "//") {
+		return content[1:]
+	}
+	return content
+}
+
+// parseSlashCommand splits a slash command message into its lowercased name and raw arguments
+func parseSlashCommand(content string) (string, string) {
+	matches := slashCommandRegex.FindStringSubmatch(strings.TrimSpace(content))
+	if matches == nil {
+		return "", ""
+	}
+	return strings.ToLower(matches[1]), strings.TrimSpace(matches[2])
+}
+
+// Slash commands
+
+func (s *service) RegisterCommand(cmd *Command) error {
+	return s.commands.Register(cmd)
+}
+
+func (s *service) ExecuteCommand(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest) (*CommandResponse, error) {
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetConversationSettings(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Commands can post into the conversation, so a block stops them like any other message
+	if settings.Type == conversation.ConversationTypeDirect {
+		if err := s.conversationService.ValidateNotBlocked(ctx, userID, conversationID); err != nil {
+			return nil, err
+		}
+	}
+
+	name, args := parseSlashCommand(req.Content)
+	call := &CommandCall{
+		Name:             name,
+		Args:             args,
+		UserID:           userID,
+		ConversationID:   conversationID,
+		ConversationType: settings.Type,
+		ReplyToID:        req.ReplyToID,
+		ClientMessageID:  strings.TrimSpace(req.ClientMessageID),
+	}
+	if err := validateClientMessageID(call.ClientMessageID); err != nil {
+		return nil, err
+	}
+
+	// A retried command returns the message posted by the first attempt
+	if call.ClientMessageID != "" {
+		existing, err := s.findClientMessage(ctx, userID, conversationID, call.ClientMessageID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			response := &CommandResponse{Command: "/" + call.Name, ResponseType: CommandResponseMessage, Message: existing}
+			if existing.MessageType == MessageTypeSystem {
+				response.ResponseType = CommandResponseSystem
+			}
+			return response, nil
+		}
+	}
+
+	result, err := s.runCommand(ctx, call)
+	if err != nil {
+		// Command failures are reported to the invoker only
+		logger.Info("Slash command failed", zap.String("command", name), zap.Uint("user_id", userID), zap.Error(err))
+		result = Ephemeral("%s", err.Error())
+	}
+
+	return s.respondToCommand(ctx, call, result)
+}
+
+func (s *service) ListCommands(ctx context.Context, userID, conversationID uint) (*CommandListResponse, error) {
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	settings, err := s.repo.GetConversationSettings(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var commands []CommandInfoResponse
+	for _, cmd := range s.commands.List() {
+		if cmd.GroupOnly && settings.Type != conversation.ConversationTypeGroup {
+			continue
+		}
+		commands = append(commands, CommandInfoResponse{
+			Name:        cmd.Name,
+			Usage:       cmd.Usage,
+			Description: cmd.Description,
+			AdminOnly:   cmd.Permission == CommandPermissionAdmin,
+		})
+	}
+
+	bots, err := s.repo.GetBotCommands(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	for _, bot := range bots {
+		commands = append(commands, CommandInfoResponse{
+			Name:        bot.Name,
+			Usage:       bot.Usage,
+			Description: bot.Description,
+			AdminOnly:   bot.AdminOnly,
+			IsBot:       true,
+		})
+	}
+
+	sort.Slice(commands, func(i, j int) bool { return commands[i].Name < commands[j].Name })
+	return &CommandListResponse{Commands: commands}, nil
+}
+
+func (s *service) CreateBotCommand(ctx context.Context, userID, conversationID uint, req *CreateBotCommandRequest) (*BotCommandResponse, error) {
+	// Only conversation admins manage bots
+	if err := s.conversationService.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(req.Name), "/"))
+	if !commandNameRegex.MatchString(name) {
+		return nil, errors.New("command name must be 1-32 lowercase letters, digits, '_' or '-' and start with a letter")
+	}
+	if _, builtin := s.commands.Get(name); builtin {
+		return nil, fmt.Errorf("/%s is a built-in command", name)
+	}
+
+	webhookURL, err := url.Parse(req.WebhookURL)
+	if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+		return nil, errors.New("webhook URL must be an http or https URL")
+	}
+
+	existing, err := s.repo.GetBotCommands(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxBotCommandsPerConversation {
+		return nil, fmt.Errorf("a conversation can have at most %d bot commands", MaxBotCommandsPerConversation)
+	}
+	for _, bot := range existing {
+		if bot.Name == name {
+			return nil, fmt.Errorf("/%s already exists in this conversation", name)
+		}
+	}
+
+	secret, err := newBotSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	command := &BotCommand{
+		ConversationID: conversationID,
+		Name:           name,
+		Description:    strings.TrimSpace(req.Description),
+		Usage:          strings.TrimSpace(req.Usage),
+		WebhookURL:     webhookURL.String(),
+		Secret:         secret,
+		AdminOnly:      req.AdminOnly,
+		CreatedBy:      &userID,
+	}
+	if err := s.repo.CreateBotCommand(ctx, command); err != nil {
+		return nil, err
+	}
+
+	response := buildBotCommandResponse(command)
+	response.Secret = command.Secret
+	return response, nil
+}
+
+func (s *service) GetBotCommands(ctx context.Context, userID, conversationID uint) ([]BotCommandResponse, error) {
+	// Webhook URLs are only shown to admins
+	if err := s.conversationService.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	bots, err := s.repo.GetBotCommands(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]BotCommandResponse, 0, len(bots))
+	for i := range bots {
+		responses = append(responses, *buildBotCommandResponse(&bots[i]))
+	}
+	return responses, nil
+}
+
+func (s *service) DeleteBotCommand(ctx context.Context, userID, conversationID uint, name string) error {
+	// Only conversation admins manage bots
+	if err := s.conversationService.ValidateConversationAdmin(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeleteBotCommand(ctx, conversationID, strings.ToLower(strings.TrimPrefix(name, "/")))
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("bot command not found")
+	}
+	return nil
+}
+
+// Helper methods
+
+// runCommand finds the command for a call, checks its permission and runs it
+func (s *service) runCommand(ctx context.Context, call *CommandCall) (*CommandResult, error) {
+	if cmd, ok := s.commands.Get(call.Name); ok {
+		if cmd.GroupOnly && call.ConversationType != conversation.ConversationTypeGroup {
+			return nil, fmt.Errorf("/%s only works in group conversations", cmd.Name)
+		}
+		if err := s.checkCommandPermission(ctx, call, cmd.Permission); err != nil {
+			return nil, err
+		}
+		return cmd.Handler(ctx, call)
+	}
+
+	bot, err := s.repo.GetBotCommand(ctx, call.ConversationID, call.Name)
+	if err != nil {
+		return nil, err
+	}
+	if bot == nil {
+		return nil, fmt.Errorf("/%s is not a command. Type /help to see available commands", call.Name)
+	}
+
+	permission := CommandPermissionMember
+	if bot.AdminOnly {
+		permission = CommandPermissionAdmin
+	}
+	if err := s.checkCommandPermission(ctx, call, permission); err != nil {
+		return nil, err
+	}
+	return s.callBot(ctx, bot, call)
+}
+
+// checkCommandPermission checks the invoker's role through the conversation service
+func (s *service) checkCommandPermission(ctx context.Context, call *CommandCall, permission string) error {
+	if permission == CommandPermissionAdmin {
+		if err := s.conversationService.ValidateConversationAdmin(ctx, call.UserID, call.ConversationID); err != nil {
+			return fmt.Errorf("only conversation admins can use /%s", call.Name)
+		}
+		return nil
+	}
+	return s.conversationService.ValidateConversationAccess(ctx, call.UserID, call.ConversationID)
+}
+
+// respondToCommand delivers a command result: ephemerally over the socket, or by posting a message
+func (s *service) respondToCommand(ctx context.Context, call *CommandCall, result *CommandResult) (*CommandResponse, error) {
+	response := &CommandResponse{
+		Command:      "/" + call.Name,
+		ResponseType: CommandResponseEphemeral,
+	}
+	if result == nil {
+		return response, nil
+	}
+
+	switch result.ResponseType {
+	case CommandResponseSystem:
+		if strings.TrimSpace(result.Text) == "" {
+			return response, nil
+		}
+		message, err := s.postCommandMessage(ctx, call, result.Text)
+		if err != nil {
+			return nil, err
+		}
+		response.ResponseType = CommandResponseSystem
+		response.Message = message
+	case CommandResponseMessage:
+		if result.Message == nil {
+			return nil, errors.New("command produced no message")
+		}
+		if result.Message.ReplyToID == nil {
+			result.Message.ReplyToID = call.ReplyToID
+		}
+		if result.Message.ClientMessageID == "" {
+			result.Message.ClientMessageID = call.ClientMessageID
+		}
+		message, err := s.CreateMessage(ctx, call.UserID, call.ConversationID, result.Message)
+		if err != nil {
+			return nil, err
+		}
+		response.ResponseType = CommandResponseMessage
+		response.Message = message
+	default:
+		response.Text = result.Text
+		if response.Text != "" {
+			s.wsService.HandleEphemeralMessage(ctx, call.UserID, map[string]interface{}{
+				"conversation_id": call.ConversationID,
+				"kind":            "command",
+				"command":         response.Command,
+				"text":            response.Text,
+			})
+		}
+	}
+
+	return response, nil
+}
+
+// postCommandMessage posts a command's system message. Unlike createSystemMessage the text comes
+// from the invoker or a bot, so it goes through the same moderation as a regular message.
+func (s *service) postCommandMessage(ctx context.Context, call *CommandCall, text string) (*MessageResponse, error) {
+	verdict, err := s.moderateContent(ctx, call.UserID, call.ConversationID, text)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt, err := s.messageExpiry(ctx, call.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	newMessage := &Message{
+		ConversationID: call.ConversationID,
+		SenderID:       call.UserID,
+		Content:        verdict.Content,
+		MessageType:    MessageTypeSystem,
+		ExpiresAt:      expiresAt,
+	}
+	if call.ClientMessageID != "" {
+		newMessage.ClientMessageID = &call.ClientMessageID
+	}
+	if verdict.Hidden() {
+		now := time.Now().UTC()
+		newMessage.HiddenAt = &now
+	}
+
+	message, err := s.repo.CreateMessage(ctx, newMessage)
+	if err != nil {
+		// A concurrent retry may have inserted the same client id first
+		if call.ClientMessageID != "" {
+			if existing, findErr := s.findClientMessage(ctx, call.UserID, call.ConversationID, call.ClientMessageID); findErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
+		return nil, err
+	}
+
+	// Queue flagged and shadow-hidden messages for moderators
+	if err := s.moderationService.RecordFlag(ctx, message.ID, call.ConversationID, call.UserID, text, verdict); err != nil {
+		logger.Error("Failed to queue message for moderation", zap.Uint("message_id", message.ID), zap.Error(err))
+	}
+
+	response := s.buildMessageResponse(ctx, message, 0)
+	if message.HiddenAt != nil {
+		s.broadcastHiddenMessage(call.UserID, call.ConversationID, response)
+		return response, nil
+	}
+	s.broadcastNewMessage(call.ConversationID, response)
+	return response, nil
+}
+
+// buildBotCommandResponse builds a bot command response without its secret
+func buildBotCommandResponse(command *BotCommand) *BotCommandResponse {
+	return &BotCommandResponse{
+		ID:             command.ID,
+		ConversationID: command.ConversationID,
+		Name:           command.Name,
+		Description:    command.Description,
+		Usage:          command.Usage,
+		WebhookURL:     command.WebhookURL,
+		AdminOnly:      command.AdminOnly,
+		CreatedAt:      command.CreatedAt,
+	}
+}
+
+// newBotSecret generates the secret used to sign webhook calls to a bot
+func newBotSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
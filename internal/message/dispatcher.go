@@ -0,0 +1,133 @@
+package message
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/conversation"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// How often the dispatcher looks for due scheduled messages
+	dispatchInterval = 15 * time.Second
+
+	// Maximum number of scheduled messages claimed per tick
+	dispatchBatchSize = 100
+
+	// Messages left in the sending state longer than this are reclaimed
+	dispatchClaimTimeout = 5 * time.Minute
+
+	// Maximum number of delivery attempts before a scheduled message is marked failed
+	maxDispatchAttempts = 5
+
+	// Maximum number of bookmark reminders sent per tick
+	reminderBatchSize = 100
+
+	// Prefix of the client message ids given to scheduled deliveries; clients may not use it
+	scheduledClientIDPrefix = "scheduled-"
+)
+
+// Dispatcher posts scheduled messages and sends bookmark reminders once they are due
+type Dispatcher struct {
+	repo    Repository
+	service Service
+}
+
+// NewDispatcher creates a new scheduled message dispatcher
+func NewDispatcher(repo Repository, service Service) *Dispatcher {
+	return &Dispatcher{
+		repo:    repo,
+		service: service,
+	}
+}
+
+// Start starts the dispatcher goroutine
+func (d *Dispatcher) Start() {
+	go d.run()
+}
+
+// run dispatches due messages on every tick
+func (d *Dispatcher) run() {
+	logger.Info("⏰ Scheduled message dispatcher started")
+
+	ticker := time.NewTicker(dispatchInterval)
+	defer ticker.Stop()
+
+	// Catch up on anything that became due while the server was down
+	d.dispatchDue(context.Background())
+	d.remindDue(context.Background())
+
+	for range ticker.C {
+		d.dispatchDue(context.Background())
+		d.remindDue(context.Background())
+	}
+}
+
+// dispatchDue claims due scheduled messages and posts them through the normal create path.
+// A message is only marked sent after CreateMessage succeeds, so delivery is at-least-once.
+func (d *Dispatcher) dispatchDue(ctx context.Context) {
+	now := time.Now().UTC()
+	claimed, err := d.repo.ClaimDueScheduledMessages(ctx, now, now.Add(-dispatchClaimTimeout), dispatchBatchSize)
+	if err != nil {
+		logger.Error("Failed to claim scheduled messages", zap.Error(err))
+		return
+	}
+
+	for i := range claimed {
+		scheduled := &claimed[i]
+		messageID, err := d.deliver(ctx, scheduled)
+		if err != nil {
+			retry := scheduled.Attempts < maxDispatchAttempts && !isPermanentDispatchError(err)
+			if markErr := d.repo.MarkScheduledMessageFailed(ctx, scheduled.ID, err.Error(), retry); markErr != nil {
+				logger.Error("Failed to record scheduled message failure", zap.Error(markErr))
+			}
+			continue
+		}
+
+		if err := d.repo.MarkScheduledMessageSent(ctx, scheduled.ID, messageID); err != nil {
+			logger.Error("Failed to mark scheduled message sent", zap.Uint("scheduled_id", scheduled.ID), zap.Error(err))
+		}
+	}
+}
+
+// deliver posts a scheduled message, or sends a /remind me reminder to its author only
+func (d *Dispatcher) deliver(ctx context.Context, scheduled *ScheduledMessage) (*uint, error) {
+	if scheduled.Kind == ScheduledKindReminder {
+		return nil, d.service.SendReminder(ctx, scheduled)
+	}
+
+	message, err := d.service.SendScheduledMessage(ctx, scheduled)
+	if err != nil {
+		return nil, err
+	}
+	return &message.ID, nil
+}
+
+// isPermanentDispatchError reports whether retrying a delivery cannot succeed, e.g. because the
+// sender left the conversation, is blocked, or the content is rejected by moderation
+func isPermanentDispatchError(err error) bool {
+	if _, blocked := conversation.BlockErrorCode(err); blocked {
+		return true
+	}
+	return errors.Is(err, conversation.ErrAccessDenied) || errors.Is(err, ErrModerationRejected)
+}
+
+// remindDue claims due bookmark reminders and notifies their owners.
+// A reminder is marked sent when claimed, so delivery is at-most-once.
+func (d *Dispatcher) remindDue(ctx context.Context) {
+	claimed, err := d.repo.ClaimDueBookmarkReminders(ctx, time.Now().UTC(), reminderBatchSize)
+	if err != nil {
+		logger.Error("Failed to claim bookmark reminders", zap.Error(err))
+		return
+	}
+
+	for i := range claimed {
+		if err := d.service.SendBookmarkReminder(ctx, &claimed[i]); err != nil {
+			logger.Error("Failed to send bookmark reminder", zap.Uint("bookmark_id", claimed[i].ID), zap.Error(err))
+		}
+	}
+}
@@ -1,8 +1,12 @@
 package message
 
 import (
+	"errors"
+	"io"
+	"net/http"
 	"strconv"
 
+	"huddle/internal/conversation"
 	"huddle/pkg/logger"
 	"huddle/pkg/utils"
 
@@ -38,9 +42,43 @@ func (h *Handler) CreateMessage(c *gin.Context) {
 		return
 	}
 
+	// Messages with a scheduled time are queued instead of posted
+	if req.ScheduledAt != nil {
+		scheduled, err := h.service.ScheduleMessage(c.Request.Context(), userID, uint(conversationID), &req)
+		if err != nil {
+			logger.Error("Failed to schedule message", zap.Error(err))
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+
+		utils.SuccessResponse(c, scheduled, "Message scheduled successfully")
+		return
+	}
+
+	// Slash commands run their handler instead of being posted
+	if IsSlashCommand(&req) {
+		result, err := h.service.ExecuteCommand(c.Request.Context(), userID, uint(conversationID), &req)
+		if err != nil {
+			logger.Error("Failed to execute command", zap.Error(err))
+			if code, ok := conversation.BlockErrorCode(err); ok {
+				utils.ErrorResponse(c, http.StatusForbidden, code, err.Error(), nil)
+				return
+			}
+			utils.BadRequestResponse(c, err.Error())
+			return
+		}
+
+		utils.SuccessResponse(c, result, "Command executed successfully")
+		return
+	}
+
 	message, err := h.service.CreateMessage(c.Request.Context(), userID, uint(conversationID), &req)
 	if err != nil {
 		logger.Error("Failed to create message", zap.Error(err))
+		if code, ok := conversation.BlockErrorCode(err); ok {
+			utils.ErrorResponse(c, http.StatusForbidden, code, err.Error(), nil)
+			return
+		}
 		utils.BadRequestResponse(c, err.Error())
 		return
 	}
@@ -48,6 +86,150 @@ func (h *Handler) CreateMessage(c *gin.Context) {
 	utils.SuccessResponse(c, message, "Message created successfully")
 }
 
+// ForwardMessage forwards a message to other conversations
+func (h *Handler) ForwardMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	var req ForwardMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind forward message request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	forwarded, err := h.service.ForwardMessage(c.Request.Context(), userID, uint(messageID), &req)
+	if err != nil {
+		logger.Error("Failed to forward message", zap.Error(err))
+		if code, ok := conversation.BlockErrorCode(err); ok {
+			utils.ErrorResponse(c, http.StatusForbidden, code, err.Error(), nil)
+			return
+		}
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, forwarded, "Message forwarded successfully")
+}
+
+// GetPoll gets a poll with the current user's votes
+func (h *Handler) GetPoll(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	poll, err := h.service.GetPoll(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to get poll", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, poll, "Poll retrieved successfully")
+}
+
+// VotePoll records the current user's vote in a poll
+func (h *Handler) VotePoll(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	var req VotePollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind vote poll request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	poll, err := h.service.VotePoll(c.Request.Context(), userID, uint(messageID), &req)
+	if err != nil {
+		logger.Error("Failed to vote in poll", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, poll, "Vote recorded successfully")
+}
+
+// RetractPollVote removes the current user's vote from a poll
+func (h *Handler) RetractPollVote(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	poll, err := h.service.RetractPollVote(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to retract poll vote", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, poll, "Vote retracted successfully")
+}
+
+// ClosePoll closes a poll early
+func (h *Handler) ClosePoll(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	poll, err := h.service.ClosePoll(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to close poll", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, poll, "Poll closed successfully")
+}
+
+// StopLiveLocation ends live location sharing and returns the finalised message
+func (h *Handler) StopLiveLocation(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	if err := h.service.StopLiveLocation(c.Request.Context(), userID, uint(messageID)); err != nil {
+		logger.Error("Failed to stop live location", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	message, err := h.service.GetMessage(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to get message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, message, "Live location stopped successfully")
+}
+
 // GetMessage gets a specific message by ID
 func (h *Handler) GetMessage(c *gin.Context) {
 	userID := getUserIDFromContext(c)
@@ -235,6 +417,26 @@ func (h *Handler) AddReaction(c *gin.Context) {
 	utils.SuccessResponse(c, nil, "Reaction added successfully")
 }
 
+// GetMessageReceipts gets who has received and read a message
+func (h *Handler) GetMessageReceipts(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	receipts, err := h.service.GetMessageReceipts(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to get message receipts", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, receipts, "Message receipts retrieved successfully")
+}
+
 // RemoveReaction removes a reaction from a message
 func (h *Handler) RemoveReaction(c *gin.Context) {
 	userID := getUserIDFromContext(c)
@@ -245,13 +447,13 @@ func (h *Handler) RemoveReaction(c *gin.Context) {
 		return
 	}
 
-	reactionType := c.Param("reaction_type")
-	if reactionType == "" {
-		utils.BadRequestResponse(c, "Reaction type is required")
+	emoji := c.Param("emoji")
+	if emoji == "" {
+		utils.BadRequestResponse(c, "Emoji is required")
 		return
 	}
 
-	err = h.service.RemoveReaction(c.Request.Context(), userID, uint(messageID), reactionType)
+	err = h.service.RemoveReaction(c.Request.Context(), userID, uint(messageID), emoji)
 	if err != nil {
 		logger.Error("Failed to remove reaction", zap.Error(err))
 		utils.BadRequestResponse(c, err.Error())
@@ -261,6 +463,341 @@ func (h *Handler) RemoveReaction(c *gin.Context) {
 	utils.SuccessResponse(c, nil, "Reaction removed successfully")
 }
 
+// GetScheduledMessages gets the current user's scheduled messages
+func (h *Handler) GetScheduledMessages(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	var conversationID *uint
+	if conversationIDStr := c.Query("conversation_id"); conversationIDStr != "" {
+		id, err := strconv.ParseUint(conversationIDStr, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid conversation ID")
+			return
+		}
+		convID := uint(id)
+		conversationID = &convID
+	}
+
+	scheduled, err := h.service.GetScheduledMessages(c.Request.Context(), userID, conversationID)
+	if err != nil {
+		logger.Error("Failed to get scheduled messages", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, scheduled, "Scheduled messages retrieved successfully")
+}
+
+// UpdateScheduledMessage updates a pending scheduled message
+func (h *Handler) UpdateScheduledMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	scheduledID, err := strconv.ParseUint(c.Param("scheduled_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid scheduled message ID")
+		return
+	}
+
+	var req UpdateScheduledMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind update scheduled message request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	scheduled, err := h.service.UpdateScheduledMessage(c.Request.Context(), userID, uint(scheduledID), &req)
+	if err != nil {
+		logger.Error("Failed to update scheduled message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, scheduled, "Scheduled message updated successfully")
+}
+
+// CancelScheduledMessage cancels a pending scheduled message
+func (h *Handler) CancelScheduledMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	scheduledID, err := strconv.ParseUint(c.Param("scheduled_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid scheduled message ID")
+		return
+	}
+
+	err = h.service.CancelScheduledMessage(c.Request.Context(), userID, uint(scheduledID))
+	if err != nil {
+		logger.Error("Failed to cancel scheduled message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Scheduled message cancelled successfully")
+}
+
+// PinMessage pins a message in its conversation
+func (h *Handler) PinMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	err = h.service.PinMessage(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to pin message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Message pinned successfully")
+}
+
+// UnpinMessage unpins a message in its conversation
+func (h *Handler) UnpinMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("message_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	err = h.service.UnpinMessage(c.Request.Context(), userID, uint(messageID))
+	if err != nil {
+		logger.Error("Failed to unpin message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Message unpinned successfully")
+}
+
+// GetPinnedMessages gets pinned messages of a conversation
+func (h *Handler) GetPinnedMessages(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	pins, err := h.service.GetPinnedMessages(c.Request.Context(), userID, uint(conversationID))
+	if err != nil {
+		logger.Error("Failed to get pinned messages", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, pins, "Pinned messages retrieved successfully")
+}
+
+// UpdateMessageTTL changes the conversation's disappearing message timer
+func (h *Handler) UpdateMessageTTL(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	var req UpdateMessageTTLRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind update message TTL request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	if err := h.service.UpdateMessageTTL(c.Request.Context(), userID, uint(conversationID), &req); err != nil {
+		logger.Error("Failed to update message TTL", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Message TTL updated successfully")
+}
+
+// BookmarkMessage bookmarks a message, or updates the label and reminder of an existing bookmark
+func (h *Handler) BookmarkMessage(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	// The body is optional: a plain POST bookmarks without a label or reminder
+	var req BookmarkMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		logger.Error("Failed to bind bookmark message request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	bookmark, err := h.service.BookmarkMessage(c.Request.Context(), userID, uint(messageID), &req)
+	if err != nil {
+		logger.Error("Failed to bookmark message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, bookmark, "Message bookmarked successfully")
+}
+
+// RemoveBookmark removes a message from the current user's bookmarks
+func (h *Handler) RemoveBookmark(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	messageID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid message ID")
+		return
+	}
+
+	if err := h.service.RemoveBookmark(c.Request.Context(), userID, uint(messageID)); err != nil {
+		logger.Error("Failed to remove bookmark", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Bookmark removed successfully")
+}
+
+// GetBookmarks gets the current user's bookmarks, newest first
+func (h *Handler) GetBookmarks(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	var cursor uint64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		var err error
+		cursor, err = strconv.ParseUint(cursorStr, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid cursor")
+			return
+		}
+	}
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 20
+	}
+
+	bookmarks, err := h.service.GetBookmarks(c.Request.Context(), userID, c.Query("label"), uint(cursor), limit)
+	if err != nil {
+		logger.Error("Failed to get bookmarks", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, bookmarks, "Bookmarks retrieved successfully")
+}
+
+// GetBookmarkLabels gets the current user's bookmark labels with counts
+func (h *Handler) GetBookmarkLabels(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+
+	labels, err := h.service.GetBookmarkLabels(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get bookmark labels", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, labels, "Bookmark labels retrieved successfully")
+}
+
+// ListCommands lists the slash commands available in a conversation
+func (h *Handler) ListCommands(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	commands, err := h.service.ListCommands(c.Request.Context(), userID, uint(conversationID))
+	if err != nil {
+		logger.Error("Failed to list commands", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, commands, "Commands retrieved successfully")
+}
+
+// GetBotCommands gets the bot webhook commands of a conversation (admins only)
+func (h *Handler) GetBotCommands(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	bots, err := h.service.GetBotCommands(c.Request.Context(), userID, uint(conversationID))
+	if err != nil {
+		logger.Error("Failed to get bot commands", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, bots, "Bot commands retrieved successfully")
+}
+
+// CreateBotCommand registers a bot webhook command in a conversation (admins only)
+func (h *Handler) CreateBotCommand(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	var req CreateBotCommandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		logger.Error("Failed to bind create bot command request", zap.Error(err))
+		utils.BadRequestResponse(c, "Invalid request body")
+		return
+	}
+
+	bot, err := h.service.CreateBotCommand(c.Request.Context(), userID, uint(conversationID), &req)
+	if err != nil {
+		logger.Error("Failed to create bot command", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, bot, "Bot command created successfully")
+}
+
+// DeleteBotCommand removes a bot webhook command from a conversation (admins only)
+func (h *Handler) DeleteBotCommand(c *gin.Context) {
+	userID := getUserIDFromContext(c)
+	
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return
+	}
+
+	if err := h.service.DeleteBotCommand(c.Request.Context(), userID, uint(conversationID), c.Param("name")); err != nil {
+		logger.Error("Failed to delete bot command", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Bot command deleted successfully")
+}
+
 // Helper function to get user ID from context
 func getUserIDFromContext(c *gin.Context) uint {
 	userID, exists := c.Get("user_id")
@@ -2,25 +2,98 @@ package message
 
 import (
 	"context"
+	"time"
+
+	"huddle/internal/file"
+	"huddle/internal/user"
+	"huddle/pkg/richtext"
 )
 
 // Repository interface defines data access methods for messages
 type Repository interface {
 	// Messages
-	CreateMessage(ctx context.Context, conversationID, senderID uint, req *CreateMessageRequest) (*Message, error)
+	CreateMessage(ctx context.Context, message *Message) (*Message, error)
 	GetMessageByID(ctx context.Context, messageID uint) (*Message, error)
-	GetMessages(ctx context.Context, conversationID uint, limit, offset int) ([]Message, error)
-	GetMessagesBefore(ctx context.Context, conversationID uint, beforeID uint, limit int) ([]Message, error)
-	UpdateMessage(ctx context.Context, messageID uint, content string) error
+	CreateMessageWithAttachments(ctx context.Context, message *Message, fileIDs []uint) (*Message, error)
+	GetFilesByIDs(ctx context.Context, fileIDs []uint) ([]file.File, error)
+	GetMessageByClientID(ctx context.Context, conversationID, senderID uint, clientMessageID string) (*Message, error)
+	GetMessages(ctx context.Context, conversationID, viewerID uint, limit, offset int) ([]Message, error)
+	GetMessagesBefore(ctx context.Context, conversationID, viewerID uint, beforeID uint, limit int) ([]Message, error)
+	UpdateMessage(ctx context.Context, messageID uint, content string, entities []richtext.Entity) error
+	HideMessage(ctx context.Context, messageID uint) error
 	DeleteMessage(ctx context.Context, messageID uint) error
-	SearchMessages(ctx context.Context, conversationID uint, query string, limit, offset int) ([]Message, error)
-	GetMessageCount(ctx context.Context, conversationID uint) (int, error)
+	SearchMessages(ctx context.Context, conversationID, viewerID uint, query string, limit, offset int) ([]Message, error)
+	GetMessageCount(ctx context.Context, conversationID, viewerID uint) (int, error)
+	CreateForwardedMessage(ctx context.Context, message *Message, sourceMessageID uint) (*Message, error)
+	SaveLinkPreviews(ctx context.Context, messageID uint, previews []MessageLinkPreview) error
+	SaveMentions(ctx context.Context, message *Message, usernames []string) error
+
+	// Polls
+	CreatePollMessage(ctx context.Context, message *Message, poll *Poll) (*Message, error)
+	GetPollByMessageID(ctx context.Context, messageID uint) (*Poll, error)
+	ReplacePollVotes(ctx context.Context, pollID, userID uint, optionIDs []uint) error
+	DeletePollVotes(ctx context.Context, pollID, userID uint) error
+	ClosePoll(ctx context.Context, pollID, closedBy uint, now time.Time) (bool, error)
+	CloseDuePolls(ctx context.Context, now time.Time) ([]Poll, error)
+
+	// Locations
+	CreateLocationMessage(ctx context.Context, message *Message, location *Location) (*Message, error)
+	GetLocationByMessageID(ctx context.Context, messageID uint) (*Location, error)
+	UpdateLiveLocation(ctx context.Context, messageID, userID uint, latitude, longitude float64, accuracy *float64, now, throttleBefore time.Time) (*Location, bool, error)
+	EndLiveLocation(ctx context.Context, messageID, userID uint, now time.Time) (*Location, bool, error)
+	EndExpiredLiveLocations(ctx context.Context, now time.Time) ([]Location, error)
+
+	// Disappearing messages
+	GetExpiredMessages(ctx context.Context, now time.Time, limit int) ([]Message, error)
+	GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error)
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+	HardDeleteMessages(ctx context.Context, messageIDs []uint) error
 
 	// Reactions
-	AddReaction(ctx context.Context, messageID, userID uint, reactionType string) (*MessageReaction, error)
-	RemoveReaction(ctx context.Context, messageID, userID uint, reactionType string) error
+	AddReaction(ctx context.Context, messageID, userID uint, emoji string) (*MessageReaction, error)
+	RemoveReaction(ctx context.Context, messageID, userID uint, emoji string) error
 	GetMessageReactions(ctx context.Context, messageID uint) ([]MessageReaction, error)
-	GetUserReaction(ctx context.Context, messageID, userID uint) (*MessageReaction, error)
+	GetMessageReactionEmojis(ctx context.Context, messageID uint) ([]string, error)
+	GetUserReaction(ctx context.Context, messageID, userID uint, emoji string) (*MessageReaction, error)
+
+	// Pins
+	PinMessage(ctx context.Context, pin *PinnedMessage, maxPins int) (bool, error)
+	UnpinMessage(ctx context.Context, conversationID, messageID uint) error
+	GetPinnedMessages(ctx context.Context, conversationID uint) ([]PinnedMessage, error)
+	CheckMessagePinned(ctx context.Context, conversationID, messageID uint) (bool, error)
+
+	// Bookmarks
+	UpsertBookmark(ctx context.Context, bookmark *MessageBookmark) (*MessageBookmark, error)
+	DeleteBookmark(ctx context.Context, userID, messageID uint) (bool, error)
+	GetUserBookmarks(ctx context.Context, userID uint, label string, cursor uint, limit int) ([]MessageBookmark, error)
+	GetBookmarkLabels(ctx context.Context, userID uint) ([]BookmarkLabelCount, error)
+	ClaimDueBookmarkReminders(ctx context.Context, now time.Time, limit int) ([]MessageBookmark, error)
+
+	// Scheduled messages
+	CreateScheduledMessage(ctx context.Context, scheduled *ScheduledMessage) error
+	GetScheduledMessageByClientID(ctx context.Context, conversationID, senderID uint, clientMessageID string) (*ScheduledMessage, error)
+	GetScheduledMessageByID(ctx context.Context, scheduledID uint) (*ScheduledMessage, error)
+	GetUserScheduledMessages(ctx context.Context, userID uint, conversationID *uint) ([]ScheduledMessage, error)
+	UpdatePendingScheduledMessage(ctx context.Context, scheduledID uint, updates map[string]interface{}) (bool, error)
+	ClaimDueScheduledMessages(ctx context.Context, now, staleBefore time.Time, limit int) ([]ScheduledMessage, error)
+	MarkScheduledMessageSent(ctx context.Context, scheduledID uint, messageID *uint) error
+	MarkScheduledMessageFailed(ctx context.Context, scheduledID uint, reason string, retry bool) error
+
+	// Bot commands
+	CreateBotCommand(ctx context.Context, command *BotCommand) error
+	GetBotCommand(ctx context.Context, conversationID uint, name string) (*BotCommand, error)
+	GetBotCommands(ctx context.Context, conversationID uint) ([]BotCommand, error)
+	DeleteBotCommand(ctx context.Context, conversationID uint, name string) (bool, error)
+
+	// Users
+	GetUserByID(ctx context.Context, userID uint) (*user.User, error)
+	GetUsersByUsernames(ctx context.Context, usernames []string) ([]user.User, error)
+
+	// Conversations
+	GetConversationSettings(ctx context.Context, conversationID uint) (*ConversationSettings, error)
+
+	// Receipts
+	GetReceiptPointers(ctx context.Context, conversationID uint) ([]ReceiptPointer, error)
 
 	// Validation
 	CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error)
@@ -38,13 +111,59 @@ type Service interface {
 	UpdateMessage(ctx context.Context, userID, messageID uint, req *UpdateMessageRequest) error
 	DeleteMessage(ctx context.Context, userID, messageID uint) error
 	SearchMessages(ctx context.Context, userID, conversationID uint, req *SearchMessagesRequest) (*MessageListResponse, error)
+	ForwardMessage(ctx context.Context, userID, messageID uint, req *ForwardMessageRequest) (*ForwardMessageResponse, error)
 
 	// Reactions
 	AddReaction(ctx context.Context, userID, messageID uint, req *AddReactionRequest) error
-	RemoveReaction(ctx context.Context, userID, messageID uint, reactionType string) error
+	RemoveReaction(ctx context.Context, userID, messageID uint, emoji string) error
+
+	// Receipts
+	GetMessageReceipts(ctx context.Context, userID, messageID uint) (*MessageReceiptsResponse, error)
+
+	// Bookmarks
+	BookmarkMessage(ctx context.Context, userID, messageID uint, req *BookmarkMessageRequest) (*BookmarkResponse, error)
+	RemoveBookmark(ctx context.Context, userID, messageID uint) error
+	GetBookmarks(ctx context.Context, userID uint, label string, cursor uint, limit int) (*BookmarkListResponse, error)
+	GetBookmarkLabels(ctx context.Context, userID uint) (*BookmarkLabelListResponse, error)
+	SendBookmarkReminder(ctx context.Context, bookmark *MessageBookmark) error
+
+	// Scheduled messages
+	ScheduleMessage(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest) (*ScheduledMessageResponse, error)
+	GetScheduledMessages(ctx context.Context, userID uint, conversationID *uint) (*ScheduledMessageListResponse, error)
+	UpdateScheduledMessage(ctx context.Context, userID, scheduledID uint, req *UpdateScheduledMessageRequest) (*ScheduledMessageResponse, error)
+	CancelScheduledMessage(ctx context.Context, userID, scheduledID uint) error
+	SendScheduledMessage(ctx context.Context, scheduled *ScheduledMessage) (*MessageResponse, error)
+	SendReminder(ctx context.Context, reminder *ScheduledMessage) error
+
+	// Slash commands
+	ExecuteCommand(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest) (*CommandResponse, error)
+	RegisterCommand(cmd *Command) error
+	ListCommands(ctx context.Context, userID, conversationID uint) (*CommandListResponse, error)
+	CreateBotCommand(ctx context.Context, userID, conversationID uint, req *CreateBotCommandRequest) (*BotCommandResponse, error)
+	GetBotCommands(ctx context.Context, userID, conversationID uint) ([]BotCommandResponse, error)
+	DeleteBotCommand(ctx context.Context, userID, conversationID uint, name string) error
+
+	// Polls
+	GetPoll(ctx context.Context, userID, messageID uint) (*PollResponse, error)
+	VotePoll(ctx context.Context, userID, messageID uint, req *VotePollRequest) (*PollResponse, error)
+	RetractPollVote(ctx context.Context, userID, messageID uint) (*PollResponse, error)
+	ClosePoll(ctx context.Context, userID, messageID uint) (*PollResponse, error)
+
+	// Live locations
+	UpdateLiveLocation(ctx context.Context, userID, messageID uint, latitude, longitude float64, accuracy *float64) error
+	StopLiveLocation(ctx context.Context, userID, messageID uint) error
+
+	// Pins
+	PinMessage(ctx context.Context, userID, messageID uint) error
+	UnpinMessage(ctx context.Context, userID, messageID uint) error
+	GetPinnedMessages(ctx context.Context, userID, conversationID uint) (*PinnedMessageListResponse, error)
+
+	// Disappearing messages
+	UpdateMessageTTL(ctx context.Context, userID, conversationID uint, req *UpdateMessageTTLRequest) error
 
 	// Validation methods
 	ValidateConversationAccess(ctx context.Context, userID, conversationID uint) error
 	ValidateMessageAccess(ctx context.Context, userID, messageID uint) error
 	ValidateMessageSender(ctx context.Context, userID, messageID uint) error
+	ValidatePinPermission(ctx context.Context, userID, conversationID uint) error
 }
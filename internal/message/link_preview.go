@@ -0,0 +1,152 @@
+package message
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"huddle/internal/config"
+	"huddle/internal/websocket"
+	"huddle/pkg/linkpreview"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Failed lookups are cached briefly so a broken link is not refetched for every message
+	linkPreviewNegativeCacheTTL = 10 * time.Minute
+
+	// Upper bound for unfurling all links of one message
+	linkPreviewJobTimeout = 30 * time.Second
+)
+
+// unfurler fetches link previews for new messages in the background
+type unfurler struct {
+	repo      Repository
+	wsService websocket.Service
+	fetcher   *linkpreview.Fetcher
+}
+
+// previewCache stores fetched previews in Redis; it does nothing when Redis is unavailable
+type previewCache struct {
+	ttl time.Duration
+}
+
+// newUnfurler creates an unfurler from the link preview config, or nil when previews are disabled
+func newUnfurler(repo Repository, wsService websocket.Service) *unfurler {
+	cfg := config.GetConfig()
+	if cfg == nil || !cfg.LinkPreview.Enabled {
+		return nil
+	}
+
+	return &unfurler{
+		repo:      repo,
+		wsService: wsService,
+		fetcher: linkpreview.NewFetcher(linkpreview.Options{
+			Timeout:      cfg.LinkPreview.Timeout,
+			MaxBytes:     int64(cfg.LinkPreview.MaxBytes),
+			AllowedHosts: cfg.LinkPreview.AllowedHosts,
+			Cache:        &previewCache{ttl: cfg.LinkPreview.CacheTTL},
+		}),
+	}
+}
+
+// unfurl fetches previews for the URLs in a message, stores them and pushes a message_updated event
+func (u *unfurler) unfurl(conversationID, messageID uint, content string) {
+	urls := linkpreview.ExtractURLs(content, MaxLinkPreviewsPerMessage)
+	if len(urls) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), linkPreviewJobTimeout)
+	defer cancel()
+
+	var previews []MessageLinkPreview
+	for _, url := range urls {
+		preview, err := u.fetcher.Fetch(ctx, url)
+		if err != nil {
+			logger.Debug("Link preview fetch failed", zap.String("url", url), zap.Error(err))
+			continue
+		}
+		previews = append(previews, MessageLinkPreview{
+			URL:         preview.URL,
+			Title:       preview.Title,
+			Description: preview.Description,
+			ImageURL:    preview.ImageURL,
+			SiteName:    preview.SiteName,
+		})
+	}
+	if len(previews) == 0 {
+		return
+	}
+
+	if err := u.repo.SaveLinkPreviews(ctx, messageID, previews); err != nil {
+		return
+	}
+
+	response := make([]LinkPreviewResponse, 0, len(previews))
+	for _, preview := range previews {
+		response = append(response, buildLinkPreviewResponse(preview))
+	}
+
+	u.wsService.HandleMessageUpdated(ctx, conversationID, map[string]interface{}{
+		"conversation_id": conversationID,
+		"id":              messageID,
+		"link_previews":   response,
+	})
+}
+
+// Get returns the cached preview for a URL; a cached failure is reported as found with a nil preview
+func (c *previewCache) Get(ctx context.Context, url string) (*linkpreview.Preview, bool) {
+	redisClient := config.GetRedisClient()
+	if redisClient == nil {
+		return nil, false
+	}
+
+	cached, err := redisClient.Get(ctx, linkPreviewCacheKey(url)).Bytes()
+	if err != nil {
+		return nil, false
+	}
+	var preview linkpreview.Preview
+	if json.Unmarshal(cached, &preview) != nil || preview.IsEmpty() {
+		return nil, true
+	}
+	return &preview, true
+}
+
+// Set caches a preview, or a failed fetch for a shorter time when preview is nil
+func (c *previewCache) Set(ctx context.Context, url string, preview *linkpreview.Preview) {
+	redisClient := config.GetRedisClient()
+	if redisClient == nil {
+		return
+	}
+
+	key := linkPreviewCacheKey(url)
+	if preview == nil {
+		redisClient.Set(ctx, key, "{}", linkPreviewNegativeCacheTTL)
+		return
+	}
+	if data, err := json.Marshal(preview); err == nil {
+		redisClient.Set(ctx, key, data, c.ttl)
+	}
+}
+
+// linkPreviewCacheKey hashes the URL so arbitrary user input never ends up in a Redis key
+func linkPreviewCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return "link_preview:" + hex.EncodeToString(sum[:])
+}
+
+// buildLinkPreviewResponse converts a stored preview to its API shape
+func buildLinkPreviewResponse(preview MessageLinkPreview) LinkPreviewResponse {
+	return LinkPreviewResponse{
+		URL:         preview.URL,
+		Title:       preview.Title,
+		Description: preview.Description,
+		ImageURL:    preview.ImageURL,
+		SiteName:    preview.SiteName,
+	}
+}
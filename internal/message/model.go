@@ -3,7 +3,10 @@ package message
 import (
 	"time"
 
+	"huddle/internal/emoji"
+	"huddle/internal/file"
 	"huddle/internal/user"
+	"huddle/pkg/richtext"
 )
 
 // Message represents a message in a conversation
@@ -12,6 +15,7 @@ type Message struct {
 	ConversationID uint      `json:"conversation_id" gorm:"not null"`
 	SenderID       uint      `json:"sender_id"`
 	Content        string    `json:"content" gorm:"not null"`
+	Entities       []richtext.Entity `json:"entities" gorm:"type:jsonb;serializer:json"`
 	MessageType    string    `json:"message_type" gorm:"not null;default:'text';size:20"`
 	FileURL        string    `json:"file_url"`
 	FileName       string    `json:"file_name"`
@@ -19,6 +23,13 @@ type Message struct {
 	ReplyToID      *uint     `json:"reply_to_id"`
 	IsEdited       bool      `json:"is_edited" gorm:"default:false"`
 	EditedAt       *time.Time `json:"edited_at"`
+	ExpiresAt      *time.Time `json:"expires_at"`
+	ForwardedFromMessageID      *uint `json:"forwarded_from_message_id"`
+	ForwardedFromSenderID       *uint `json:"forwarded_from_sender_id"`
+	ForwardedFromConversationID *uint `json:"forwarded_from_conversation_id"`
+	StickerID      *uint     `json:"sticker_id"` // Set for sticker messages; cleared if the sticker is removed
+	ClientMessageID *string  `json:"client_message_id" gorm:"size:64"` // Unique per sender and conversation
+	HiddenAt       *time.Time `json:"-"` // Shadow-hidden by moderation: only the sender sees it
 	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
 
@@ -27,6 +38,13 @@ type Message struct {
 	Sender       user.User            `json:"sender" gorm:"foreignKey:SenderID"`
 	ReplyTo      *Message             `json:"reply_to" gorm:"foreignKey:ReplyToID"`
 	Reactions    []MessageReaction    `json:"reactions" gorm:"foreignKey:MessageID"`
+	ForwardedFromSender       *user.User           `json:"forwarded_from_sender" gorm:"foreignKey:ForwardedFromSenderID"`
+	ForwardedFromConversation *MessageConversation `json:"forwarded_from_conversation" gorm:"foreignKey:ForwardedFromConversationID"`
+	LinkPreviews []MessageLinkPreview `json:"link_previews" gorm:"foreignKey:MessageID"`
+	Poll         *Poll                `json:"poll" gorm:"foreignKey:MessageID"`
+	Location     *Location            `json:"location" gorm:"foreignKey:MessageID"`
+	Sticker      *emoji.Sticker       `json:"sticker" gorm:"foreignKey:StickerID"`
+	Attachments  []file.File          `json:"attachments" gorm:"foreignKey:MessageID"`
 }
 
 // MessageConversation represents conversation info for message context
@@ -36,12 +54,17 @@ type MessageConversation struct {
 	Type string `json:"type"`
 }
 
+// TableName specifies the table name for MessageConversation
+func (MessageConversation) TableName() string {
+	return "conversations"
+}
+
 // MessageReaction represents a reaction to a message
 type MessageReaction struct {
 	ID           uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	MessageID    uint      `json:"message_id" gorm:"not null"`
 	UserID       uint      `json:"user_id" gorm:"not null"`
-	ReactionType string    `json:"reaction_type" gorm:"not null;default:'like';size:20"`
+	Emoji        string    `json:"emoji" gorm:"not null;size:64"`
 	CreatedAt    time.Time `json:"created_at" gorm:"default:now()"`
 
 	// Relations
@@ -49,45 +72,384 @@ type MessageReaction struct {
 	User    user.User `json:"user" gorm:"foreignKey:UserID"`
 }
 
+// PinnedMessage represents a message pinned in a conversation
+type PinnedMessage struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID uint      `json:"conversation_id" gorm:"not null"`
+	MessageID      uint      `json:"message_id" gorm:"not null"`
+	PinnedBy       *uint     `json:"pinned_by"` // NULL once the pinner's account is deleted
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	Message      Message    `json:"message" gorm:"foreignKey:MessageID"`
+	PinnedByUser *user.User `json:"pinned_by_user,omitempty" gorm:"foreignKey:PinnedBy"`
+}
+
+// MessageBookmark represents a message saved by a user for later
+type MessageBookmark struct {
+	ID         uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID     uint       `json:"user_id" gorm:"not null"`
+	MessageID  uint       `json:"message_id" gorm:"not null"`
+	Label      string     `json:"label" gorm:"not null;default:'';size:50"`
+	RemindAt   *time.Time `json:"remind_at"`
+	RemindedAt *time.Time `json:"reminded_at"`
+	CreatedAt  time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt  time.Time  `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Message Message `json:"message" gorm:"foreignKey:MessageID"`
+}
+
+// BookmarkLabelCount represents how many bookmarks a user has under a label
+type BookmarkLabelCount struct {
+	Label string `json:"label"`
+	Count int    `json:"count"`
+}
+
+// MessageLinkPreview represents unfurled metadata for a URL in a message
+type MessageLinkPreview struct {
+	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID   uint      `json:"message_id" gorm:"not null"`
+	URL         string    `json:"url" gorm:"not null"`
+	Title       string    `json:"title"`
+	Description string    `json:"description"`
+	ImageURL    string    `json:"image_url"`
+	SiteName    string    `json:"site_name"`
+	CreatedAt   time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// Poll represents a poll attached to a poll message
+type Poll struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID      uint       `json:"message_id" gorm:"not null;uniqueIndex"`
+	ConversationID uint       `json:"conversation_id" gorm:"not null"`
+	CreatedBy      uint       `json:"created_by"`
+	Question       string     `json:"question" gorm:"not null;size:300"`
+	AllowsMultiple bool       `json:"allows_multiple" gorm:"not null;default:false"`
+	IsAnonymous    bool       `json:"is_anonymous" gorm:"not null;default:false"`
+	ClosesAt       *time.Time `json:"closes_at"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	ClosedBy       *uint      `json:"closed_by"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:now()"`
+
+	// Relations
+	Options []PollOption `json:"options" gorm:"foreignKey:PollID"`
+}
+
+// IsClosed reports whether the poll no longer accepts votes
+func (p *Poll) IsClosed(now time.Time) bool {
+	return p.ClosedAt != nil || (p.ClosesAt != nil && !p.ClosesAt.After(now))
+}
+
+// PollOption represents one answer of a poll
+type PollOption struct {
+	ID       uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID   uint   `json:"poll_id" gorm:"not null"`
+	Position int    `json:"position" gorm:"not null"`
+	Text     string `json:"text" gorm:"not null;size:200"`
+
+	// Relations
+	Votes []PollVote `json:"votes" gorm:"foreignKey:OptionID"`
+}
+
+// PollVote represents a user's vote for a poll option
+type PollVote struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	PollID    uint      `json:"poll_id" gorm:"not null"`
+	OptionID  uint      `json:"option_id" gorm:"not null"`
+	UserID    uint      `json:"user_id" gorm:"not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+
+	// Relations
+	User user.User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// Location represents the position shared by a location message
+type Location struct {
+	MessageID      uint       `json:"message_id" gorm:"primaryKey"`
+	ConversationID uint       `json:"conversation_id" gorm:"not null"`
+	UserID         uint       `json:"user_id" gorm:"not null"`
+	Latitude       float64    `json:"latitude" gorm:"not null"`
+	Longitude      float64    `json:"longitude" gorm:"not null"`
+	Accuracy       *float64   `json:"accuracy"` // Radius in meters
+	Label          string     `json:"label" gorm:"size:200"`
+	LiveUntil      *time.Time `json:"live_until"` // Set for live locations
+	EndedAt        *time.Time `json:"ended_at"`   // When live sharing stopped or expired
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for Location
+func (Location) TableName() string {
+	return "message_locations"
+}
+
+// IsLive reports whether the location still accepts position updates
+func (l *Location) IsLive(now time.Time) bool {
+	return l.LiveUntil != nil && l.EndedAt == nil && l.LiveUntil.After(now)
+}
+
+// ScheduledMessage represents a message queued to be posted later
+type ScheduledMessage struct {
+	ID              uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID  uint       `json:"conversation_id" gorm:"not null"`
+	SenderID        uint       `json:"sender_id" gorm:"not null"`
+	Content         string     `json:"content" gorm:"not null"`
+	MessageType     string     `json:"message_type" gorm:"not null;default:'text';size:20"`
+	FileURL         string     `json:"file_url"`
+	FileName        string     `json:"file_name"`
+	FileSize        int64      `json:"file_size"`
+	ReplyToID       *uint      `json:"reply_to_id"`
+	ParseMode       string     `json:"parse_mode" gorm:"not null;default:'markdown';size:20"`
+	Kind            string     `json:"kind" gorm:"not null;default:'message';size:20"`
+	ScheduledAt     time.Time  `json:"scheduled_at" gorm:"not null"`
+	Status          string     `json:"status" gorm:"not null;default:'pending';size:20"`
+	Attempts        int        `json:"attempts" gorm:"not null;default:0"`
+	LastError       string     `json:"last_error"`
+	ClaimedAt       *time.Time `json:"claimed_at"`
+	MessageID       *uint      `json:"message_id"`
+	SentAt          *time.Time `json:"sent_at"`
+	ClientMessageID *string    `json:"client_message_id" gorm:"size:64"` // Unique per sender and conversation
+	CreatedAt       time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt       time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+// BotCommand represents a slash command answered by an external bot webhook
+type BotCommand struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID uint      `json:"conversation_id" gorm:"not null"`
+	Name           string    `json:"name" gorm:"not null;size:32"`
+	Description    string    `json:"description" gorm:"not null;default:'';size:200"`
+	Usage          string    `json:"usage" gorm:"not null;default:'';size:200"`
+	WebhookURL     string    `json:"webhook_url" gorm:"not null"`
+	Secret         string    `json:"-" gorm:"not null;size:64"`
+	AdminOnly      bool      `json:"admin_only" gorm:"not null;default:false"`
+	CreatedBy      *uint     `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+// ConversationSettings represents the conversation settings relevant to messages
+type ConversationSettings struct {
+	ID               uint   `json:"id"`
+	Type             string `json:"type"`
+	OnlyAdminsCanPin bool   `json:"only_admins_can_pin"`
+	MessageTTLSeconds int   `json:"message_ttl_seconds"`
+	LinkPreviewsEnabled bool `json:"link_previews_enabled"`
+	Topic            string `json:"topic"`
+}
+
+// ReceiptPointer represents how far a participant has received and read a conversation
+type ReceiptPointer struct {
+	ConversationID         uint      `json:"conversation_id"`
+	UserID                 uint      `json:"user_id"`
+	JoinedAt               time.Time `json:"joined_at"`
+	LastReadMessageID      *uint     `json:"last_read_message_id"`
+	LastDeliveredMessageID *uint     `json:"last_delivered_message_id"`
+
+	// Relations
+	User user.User `json:"user" gorm:"foreignKey:UserID"`
+}
+
+// TableName specifies the table name for ReceiptPointer
+func (ReceiptPointer) TableName() string {
+	return "conversation_participants"
+}
+
+// Poll limits
+const (
+	// MaxPollDuration is the furthest in the future a poll may be set to close
+	MaxPollDuration = 30 * 24 * time.Hour
+	// MinPollOptions and MaxPollOptions bound the number of answers
+	MinPollOptions = 2
+	MaxPollOptions = 10
+	// MaxPollQuestionLength and MaxPollOptionLength are measured in characters
+	MaxPollQuestionLength = 300
+	MaxPollOptionLength   = 200
+)
+
+// Live location limits
+const (
+	// MinLiveLocationDuration and MaxLiveLocationDuration bound how long a location is shared live
+	MinLiveLocationDuration = time.Minute
+	MaxLiveLocationDuration = 8 * time.Hour
+
+	// MinLiveLocationInterval is the shortest gap between two position updates; faster updates are dropped
+	MinLiveLocationInterval = 2 * time.Second
+)
+
+// MaxLinkPreviewsPerMessage is the maximum number of URLs unfurled per message
+const MaxLinkPreviewsPerMessage = 3
+
+// MaxAttachmentsPerMessage is the maximum number of files attached to one message
+const MaxAttachmentsPerMessage = 10
+
+// MaxMentionsPerMessage is the maximum number of @mentions counted in one message
+const MaxMentionsPerMessage = 50
+
+// MaxForwardTargets is the maximum number of conversations a message can be forwarded to at once
+const MaxForwardTargets = 10
+
+// MaxBookmarkReminderDelay is the furthest in the future a bookmark reminder may be set
+const MaxBookmarkReminderDelay = 365 * 24 * time.Hour
+
+// MaxBookmarkLabelLength is the maximum length of a personal bookmark label
+const MaxBookmarkLabelLength = 50
+
+// MaxReceiptGroupSize is the largest conversation that gets per-message read receipts
+const MaxReceiptGroupSize = 50
+
+// MaxPinnedMessages is the maximum number of pinned messages per conversation
+const MaxPinnedMessages = 50
+
+// Parse Mode Constants
+const (
+	ParseModeMarkdown = "markdown"
+	ParseModePlain    = "plain"
+)
+
 // Message Type Constants
 const (
 	MessageTypeText   = "text"
 	MessageTypeImage  = "image"
 	MessageTypeFile   = "file"
 	MessageTypeSystem = "system"
+	MessageTypePoll   = "poll"
+	MessageTypeVoice  = "voice" // One WAV or Ogg/Opus attachment with duration and waveform
+	MessageTypeLocation = "location" // A position, optionally shared live for a while
+	MessageTypeSticker  = "sticker"  // An image from a sticker pack
+)
+
+// Delivery Status Constants (shown to the sender)
+const (
+	DeliveryStatusSent      = "sent"
+	DeliveryStatusDelivered = "delivered"
+	DeliveryStatusRead      = "read"
+)
+
+// Scheduled Message Kind Constants
+const (
+	ScheduledKindMessage  = "message"  // Posted to the conversation when due
+	ScheduledKindReminder = "reminder" // Sent only to its author when due (/remind me)
+)
+
+// Scheduled Message Status Constants
+const (
+	ScheduledStatusPending   = "pending"
+	ScheduledStatusSending   = "sending"
+	ScheduledStatusSent      = "sent"
+	ScheduledStatusCancelled = "cancelled"
+	ScheduledStatusFailed    = "failed"
+)
+
+// Slash command response types
+const (
+	CommandResponseEphemeral = "ephemeral" // Only the invoker sees the reply
+	CommandResponseSystem    = "system"    // Posted to the conversation as a system message
+	CommandResponseMessage   = "message"   // Posted to the conversation as the invoker's message
 )
 
-// Reaction Type Constants
+// Slash command permission levels, checked through the conversation service
 const (
-	ReactionTypeLike   = "like"
-	ReactionTypeLove   = "love"
-	ReactionTypeHaha   = "haha"
-	ReactionTypeWow    = "wow"
-	ReactionTypeSad    = "sad"
-	ReactionTypeAngry  = "angry"
+	CommandPermissionMember = "member"
+	CommandPermissionAdmin  = "admin"
+)
+
+// Slash command limits
+const (
+	// MaxBotCommandsPerConversation is the maximum number of bot webhook commands in one conversation
+	MaxBotCommandsPerConversation = 25
+
+	// MaxBotResponseBytes bounds the body read from a bot webhook
+	MaxBotResponseBytes = 64 * 1024
+
+	// MaxReminderDelay is the furthest in the future /remind accepts
+	MaxReminderDelay = 365 * 24 * time.Hour
+)
+
+// LegacyReactionEmoji maps the old fixed reaction types to their emoji
+var LegacyReactionEmoji = map[string]string{
+	"like":  "👍",
+	"love":  "❤️",
+	"haha":  "😂",
+	"wow":   "😮",
+	"sad":   "😢",
+	"angry": "😠",
+}
+
+// Reaction limits
+const (
+	// MaxReactionSampleUsers is how many reacting users are listed per emoji
+	MaxReactionSampleUsers = 3
+
+	// MaxDistinctReactions is the maximum number of different emoji on one message
+	MaxDistinctReactions = 50
 )
 
 // DTOs for API requests/responses
 
 // CreateMessageRequest represents request to create a message
 type CreateMessageRequest struct {
-	Content     string `json:"content" binding:"required"`
-	MessageType string `json:"message_type" binding:"required,oneof=text image file system"`
+	Content     string `json:"content" binding:"required_without_all=Poll AttachmentIDs Location StickerID"`
+	MessageType string `json:"message_type" binding:"required,oneof=text image file system poll voice location sticker"`
 	FileURL     string `json:"file_url,omitempty"`
 	FileName    string `json:"file_name,omitempty"`
 	FileSize    int64  `json:"file_size,omitempty"`
 	ReplyToID   *uint  `json:"reply_to_id,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // Optional: post the message later
+	ParseMode   string `json:"parse_mode,omitempty" binding:"omitempty,oneof=markdown plain"` // Defaults to markdown
+	Poll        *CreatePollRequest `json:"poll,omitempty"` // Required for poll messages
+	Location    *CreateLocationRequest `json:"location,omitempty"` // Required for location messages
+	StickerID   *uint `json:"sticker_id,omitempty"` // Required for sticker messages
+	ClientMessageID string `json:"client_message_id,omitempty" binding:"omitempty,max=64"` // Optional: retries with the same id return the original message
+	AttachmentIDs []uint `json:"attachment_ids,omitempty" binding:"omitempty,max=10,dive,required"` // Uploaded files (file ids) to attach
+}
+
+// CreatePollRequest represents the poll part of a poll message
+type CreatePollRequest struct {
+	Question       string     `json:"question" binding:"required,max=300"`
+	Options        []string   `json:"options" binding:"required,min=2,max=10,dive,required,max=200"`
+	AllowsMultiple bool       `json:"allows_multiple"`
+	IsAnonymous    bool       `json:"is_anonymous"`
+	ClosesAt       *time.Time `json:"closes_at,omitempty"`
+}
+
+// CreateLocationRequest represents the location part of a location message
+type CreateLocationRequest struct {
+	Latitude            *float64 `json:"latitude" binding:"required,min=-90,max=90"`
+	Longitude           *float64 `json:"longitude" binding:"required,min=-180,max=180"`
+	Accuracy            *float64 `json:"accuracy,omitempty" binding:"omitempty,min=0"` // Radius in meters
+	Label               string   `json:"label,omitempty" binding:"max=200"`            // Optional place name
+	LiveDurationSeconds int      `json:"live_duration_seconds,omitempty" binding:"omitempty,min=60,max=28800"` // Share live for this long
+}
+
+// VotePollRequest represents request to vote in a poll (replaces any previous vote)
+type VotePollRequest struct {
+	OptionIDs []uint `json:"option_ids" binding:"required,min=1,dive,required"`
+}
+
+// ForwardMessageRequest represents request to forward a message to other conversations
+type ForwardMessageRequest struct {
+	ConversationIDs []uint `json:"conversation_ids" binding:"required,min=1,max=10,dive,required"`
 }
 
 // UpdateMessageRequest represents request to update a message
 type UpdateMessageRequest struct {
-	Content string `json:"content" binding:"required"`
+	Content   string `json:"content" binding:"required"`
+	ParseMode string `json:"parse_mode,omitempty" binding:"omitempty,oneof=markdown plain"` // Defaults to markdown
+}
+
+// UpdateMessageTTLRequest represents request to change a conversation's disappearing message timer
+type UpdateMessageTTLRequest struct {
+	MessageTTLSeconds *int `json:"message_ttl_seconds" binding:"required"` // 0 disables disappearing messages
 }
 
 // MessageResponse represents message response
 type MessageResponse struct {
 	ID          uint                    `json:"id"`
 	Content     string                  `json:"content"`
+	Entities    []richtext.Entity       `json:"entities,omitempty"`
 	MessageType string                  `json:"message_type"`
 	SenderID    uint                    `json:"sender_id"`
 	Sender      user.UserResponse       `json:"sender"`
@@ -98,17 +460,43 @@ type MessageResponse struct {
 	ReplyTo     *MessageResponse        `json:"reply_to,omitempty"`
 	IsEdited    bool                    `json:"is_edited"`
 	EditedAt    *time.Time              `json:"edited_at,omitempty"`
-	Reactions   []MessageReactionResponse `json:"reactions"`
+	ExpiresAt   *time.Time              `json:"expires_at,omitempty"`
+	ForwardedFrom *ForwardedFromResponse `json:"forwarded_from,omitempty"`
+	LinkPreviews []LinkPreviewResponse   `json:"link_previews,omitempty"`
+	Poll        *PollResponse           `json:"poll,omitempty"`
+	Location    *LocationResponse       `json:"location,omitempty"`
+	Sticker     *emoji.StickerResponse  `json:"sticker,omitempty"`
+	Attachments []AttachmentResponse    `json:"attachments,omitempty"`
+	Reactions   []ReactionSummaryResponse `json:"reactions"`
+	Status      string                  `json:"status,omitempty"` // sent/delivered/read, only on the sender's own messages
+	ClientMessageID string              `json:"client_message_id,omitempty"`
 	CreatedAt   time.Time               `json:"created_at"`
 	UpdatedAt   time.Time               `json:"updated_at"`
 }
 
-// MessageReactionResponse represents message reaction response
-type MessageReactionResponse struct {
-	ID           uint      `json:"id"`
-	ReactionType string    `json:"reaction_type"`
-	User         user.UserResponse `json:"user"`
-	CreatedAt    time.Time `json:"created_at"`
+// ReactionSummaryResponse represents all reactions with one emoji on a message
+type ReactionSummaryResponse struct {
+	Emoji       string                `json:"emoji"`
+	Count       int                   `json:"count"`
+	ReactedByMe bool                  `json:"reacted_by_me"`
+	SampleUsers []UserSummaryResponse `json:"sample_users"`
+}
+
+// MessageReceiptsResponse represents who has received and seen a message
+type MessageReceiptsResponse struct {
+	MessageID   uint                  `json:"message_id"`
+	Status      string                `json:"status"`
+	ReadBy      []UserSummaryResponse `json:"read_by"`
+	DeliveredTo []UserSummaryResponse `json:"delivered_to"` // Delivered but not read yet
+	Pending     []UserSummaryResponse `json:"pending"`
+}
+
+// UserSummaryResponse represents the public profile of a user listed on a message (reactions, poll votes)
+type UserSummaryResponse struct {
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	DisplayName string `json:"display_name"`
+	Avatar      string `json:"avatar"`
 }
 
 // MessageListResponse represents message list response
@@ -118,9 +506,209 @@ type MessageListResponse struct {
 	HasMore  bool              `json:"has_more"`
 }
 
+// UpdateScheduledMessageRequest represents request to update a scheduled message
+type UpdateScheduledMessageRequest struct {
+	Content     *string    `json:"content,omitempty"`
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"`
+}
+
+// ScheduledMessageResponse represents scheduled message response
+type ScheduledMessageResponse struct {
+	ID             uint       `json:"id"`
+	ConversationID uint       `json:"conversation_id"`
+	SenderID       uint       `json:"sender_id"`
+	Content        string     `json:"content"`
+	MessageType    string     `json:"message_type"`
+	FileURL        string     `json:"file_url,omitempty"`
+	FileName       string     `json:"file_name,omitempty"`
+	FileSize       int64      `json:"file_size,omitempty"`
+	ReplyToID      *uint      `json:"reply_to_id,omitempty"`
+	Kind           string     `json:"kind"`
+	ScheduledAt    time.Time  `json:"scheduled_at"`
+	Status         string     `json:"status"`
+	LastError      string     `json:"last_error,omitempty"`
+	MessageID      *uint      `json:"message_id,omitempty"`
+	SentAt         *time.Time `json:"sent_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+// ScheduledMessageListResponse represents scheduled message list response
+type ScheduledMessageListResponse struct {
+	ScheduledMessages []ScheduledMessageResponse `json:"scheduled_messages"`
+	Total             int                        `json:"total"`
+}
+
+// LocationResponse represents a shared position; live locations keep updating until they end
+type LocationResponse struct {
+	MessageID uint       `json:"message_id"`
+	Latitude  float64    `json:"latitude"`
+	Longitude float64    `json:"longitude"`
+	Accuracy  *float64   `json:"accuracy,omitempty"`
+	Label     string     `json:"label,omitempty"`
+	Live      bool       `json:"live"`
+	LiveUntil *time.Time `json:"live_until,omitempty"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// PollResponse represents a poll with its live tallies
+type PollResponse struct {
+	ID             uint                 `json:"id"`
+	MessageID      uint                 `json:"message_id"`
+	Question       string               `json:"question"`
+	CreatedBy      uint                 `json:"created_by"`
+	AllowsMultiple bool                 `json:"allows_multiple"`
+	IsAnonymous    bool                 `json:"is_anonymous"`
+	IsClosed       bool                 `json:"is_closed"`
+	ClosesAt       *time.Time           `json:"closes_at,omitempty"`
+	ClosedAt       *time.Time           `json:"closed_at,omitempty"`
+	Options        []PollOptionResponse `json:"options"`
+	TotalVoters    int                  `json:"total_voters"`
+	MyOptionIDs    []uint               `json:"my_option_ids,omitempty"` // Only set in responses to the viewer
+}
+
+// PollOptionResponse represents a poll option with its tally
+type PollOptionResponse struct {
+	ID        uint                `json:"id"`
+	Text      string              `json:"text"`
+	VoteCount int                 `json:"vote_count"`
+	Voters    []UserSummaryResponse `json:"voters,omitempty"` // Omitted for anonymous polls
+}
+
+// LinkPreviewResponse represents a link preview attached to a message
+type LinkPreviewResponse struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// AttachmentResponse represents a file attached to a message
+type AttachmentResponse struct {
+	ID           uint   `json:"id"`
+	FileName     string `json:"file_name"`
+	MimeType     string `json:"mime_type"`
+	FileType     string `json:"file_type"` // image/video/audio/document/archive/other
+	FileSize     int64  `json:"file_size"`
+	DownloadURL  string `json:"download_url"` // Access checked against conversation membership
+	ThumbnailURL string `json:"thumbnail_url,omitempty"`
+	PreviewURL   string `json:"preview_url,omitempty"`
+	Width        *int   `json:"width,omitempty"`
+	Height       *int   `json:"height,omitempty"`
+	Duration     *int   `json:"duration,omitempty"` // Seconds, for audio and video
+	Waveform     []int  `json:"waveform,omitempty"` // Audio amplitudes from 0 to 100, for voice messages
+}
+
+// ForwardedFromResponse represents the origin of a forwarded message
+type ForwardedFromResponse struct {
+	MessageID        *uint              `json:"message_id,omitempty"`
+	SenderID         *uint              `json:"sender_id,omitempty"`
+	Sender           *user.UserResponse `json:"sender,omitempty"`
+	ConversationID   *uint              `json:"conversation_id,omitempty"`   // Only set when the origin is a group
+	ConversationName string             `json:"conversation_name,omitempty"`
+}
+
+// ForwardMessageResponse represents the messages created by a forward
+type ForwardMessageResponse struct {
+	Messages []MessageResponse `json:"messages"`
+	Total    int               `json:"total"`
+}
+
+// PinnedMessageResponse represents pinned message response
+type PinnedMessageResponse struct {
+	ID             uint               `json:"id"`
+	ConversationID uint               `json:"conversation_id"`
+	Message        MessageResponse    `json:"message"`
+	PinnedBy       *user.UserResponse `json:"pinned_by"` // Null once the pinner's account is deleted
+	PinnedAt       time.Time          `json:"pinned_at"`
+}
+
+// PinnedMessageListResponse represents pinned message list response
+type PinnedMessageListResponse struct {
+	Pins  []PinnedMessageResponse `json:"pins"`
+	Total int                     `json:"total"`
+	Limit int                     `json:"limit"`
+}
+
+// BookmarkMessageRequest represents request to bookmark a message (or update an existing bookmark)
+type BookmarkMessageRequest struct {
+	Label    string     `json:"label,omitempty"`
+	RemindAt *time.Time `json:"remind_at,omitempty"` // Optional: notify me about this message later
+}
+
+// BookmarkResponse represents a bookmarked message
+type BookmarkResponse struct {
+	ID         uint            `json:"id"`
+	Label      string          `json:"label"`
+	RemindAt   *time.Time      `json:"remind_at,omitempty"`
+	RemindedAt *time.Time      `json:"reminded_at,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	UpdatedAt  time.Time       `json:"updated_at"`
+	Message    MessageResponse `json:"message"`
+}
+
+// BookmarkListResponse represents a page of bookmarks
+type BookmarkListResponse struct {
+	Bookmarks  []BookmarkResponse `json:"bookmarks"`
+	NextCursor *uint              `json:"next_cursor,omitempty"`
+	HasMore    bool               `json:"has_more"`
+}
+
+// BookmarkLabelListResponse represents a user's bookmark labels
+type BookmarkLabelListResponse struct {
+	Labels []BookmarkLabelCount `json:"labels"`
+}
+
+// CommandResponse represents the outcome of a slash command
+type CommandResponse struct {
+	Command      string           `json:"command"`
+	ResponseType string           `json:"response_type"`
+	Text         string           `json:"text,omitempty"`    // Ephemeral reply, also pushed over the socket
+	Message      *MessageResponse `json:"message,omitempty"` // Set when the command posted a message
+}
+
+// CommandInfoResponse describes a slash command available in a conversation
+type CommandInfoResponse struct {
+	Name        string `json:"name"`
+	Usage       string `json:"usage,omitempty"`
+	Description string `json:"description,omitempty"`
+	AdminOnly   bool   `json:"admin_only"`
+	IsBot       bool   `json:"is_bot"`
+}
+
+// CommandListResponse represents the slash commands available in a conversation
+type CommandListResponse struct {
+	Commands []CommandInfoResponse `json:"commands"`
+}
+
+// CreateBotCommandRequest represents request to register a bot webhook command in a conversation
+type CreateBotCommandRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description" binding:"max=200"`
+	Usage       string `json:"usage" binding:"max=200"`
+	WebhookURL  string `json:"webhook_url" binding:"required,url"`
+	AdminOnly   bool   `json:"admin_only"`
+}
+
+// BotCommandResponse represents a registered bot webhook command
+type BotCommandResponse struct {
+	ID             uint      `json:"id"`
+	ConversationID uint      `json:"conversation_id"`
+	Name           string    `json:"name"`
+	Description    string    `json:"description,omitempty"`
+	Usage          string    `json:"usage,omitempty"`
+	WebhookURL     string    `json:"webhook_url"`
+	AdminOnly      bool      `json:"admin_only"`
+	Secret         string    `json:"secret,omitempty"` // Signing secret, only returned when the command is created
+	CreatedAt      time.Time `json:"created_at"`
+}
+
 // AddReactionRequest represents request to add reaction
 type AddReactionRequest struct {
-	ReactionType string `json:"reaction_type" binding:"required,oneof=like love haha wow sad angry"`
+	Emoji        string `json:"emoji" binding:"required_without=ReactionType,max=64"` // Unicode emoji or :custom_shortcode:
+	ReactionType string `json:"reaction_type,omitempty"` // Deprecated: legacy like/love/haha/wow/sad/angry
 }
 
 // SearchMessagesRequest represents request to search messages
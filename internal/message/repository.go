@@ -2,14 +2,21 @@ package message
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
+	"time"
 
 	"huddle/internal/database"
+	"huddle/internal/file"
+	"huddle/internal/user"
 	"huddle/pkg/logger"
+	"huddle/pkg/richtext"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type repository struct {
@@ -23,45 +30,120 @@ func NewRepository() Repository {
 	}
 }
 
-// Messages
+// withMessageRelations preloads everything buildMessageResponse reads
+func (r *repository) withMessageRelations(ctx context.Context) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Preload("Sender").
+		Preload("ReplyTo.Sender").
+		Preload("Reactions", func(db *gorm.DB) *gorm.DB {
+			return db.Order("created_at ASC, id ASC")
+		}).
+		Preload("Reactions.User").
+		Preload("ForwardedFromSender").
+		Preload("ForwardedFromConversation").
+		Preload("LinkPreviews").
+		Preload("Poll.Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Poll.Options.Votes.User").
+		Preload("Location").
+		Preload("Sticker").
+		Preload("Attachments", func(db *gorm.DB) *gorm.DB {
+			return db.Where("deleted_at IS NULL").Order("attachment_position ASC, id ASC")
+		})
+}
 
-func (r *repository) CreateMessage(ctx context.Context, conversationID, senderID uint, req *CreateMessageRequest) (*Message, error) {
-	message := &Message{
-		ConversationID: conversationID,
-		SenderID:       senderID,
-		Content:        req.Content,
-		MessageType:    req.MessageType,
-		FileURL:        req.FileURL,
-		FileName:       req.FileName,
-		FileSize:       req.FileSize,
-		ReplyToID:      req.ReplyToID,
-	}
+// Messages
 
+func (r *repository) CreateMessage(ctx context.Context, message *Message) (*Message, error) {
 	if err := r.db.WithContext(ctx).Create(message).Error; err != nil {
 		logger.Error("Failed to create message", zap.Error(err))
 		return nil, err
 	}
 
 	// Load relations
-	if err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("ReplyTo.Sender").
-		Preload("Reactions.User").
+	if err := r.withMessageRelations(ctx).
 		First(message, message.ID).Error; err != nil {
 		logger.Error("Failed to load message relations", zap.Error(err))
 		return nil, err
 	}
 
-	logger.Info("Message created", zap.Uint("message_id", message.ID), zap.Uint("conversation_id", conversationID))
+	logger.Info("Message created", zap.Uint("message_id", message.ID), zap.Uint("conversation_id", message.ConversationID))
 	return message, nil
 }
 
+func (r *repository) CreateMessageWithAttachments(ctx context.Context, message *Message, fileIDs []uint) (*Message, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		// Claim each file only if it is still unattached, so concurrent sends cannot share it
+		for position, fileID := range fileIDs {
+			result := tx.Model(&file.File{}).
+				Where("id = ? AND user_id = ? AND message_id IS NULL AND deleted_at IS NULL", fileID, message.SenderID).
+				Updates(map[string]interface{}{
+					"message_id":          message.ID,
+					"conversation_id":     message.ConversationID,
+					"attachment_position": position,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				return fmt.Errorf("file %d is already attached to a message", fileID)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to create message with attachments", zap.Error(err))
+		return nil, err
+	}
+
+	// Load relations
+	if err := r.withMessageRelations(ctx).
+		First(message, message.ID).Error; err != nil {
+		logger.Error("Failed to load message relations", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Message created", zap.Uint("message_id", message.ID), zap.Int("attachments", len(fileIDs)))
+	return message, nil
+}
+
+func (r *repository) GetFilesByIDs(ctx context.Context, fileIDs []uint) ([]file.File, error) {
+	var files []file.File
+	if len(fileIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("id IN ? AND deleted_at IS NULL", fileIDs).
+		Find(&files).Error; err != nil {
+		logger.Error("Failed to get files", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *repository) GetMessageByClientID(ctx context.Context, conversationID, senderID uint, clientMessageID string) (*Message, error) {
+	var message Message
+	if err := r.withMessageRelations(ctx).
+		Where("conversation_id = ? AND sender_id = ? AND client_message_id = ?", conversationID, senderID, clientMessageID).
+		First(&message).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to get message by client ID", zap.Error(err))
+		return nil, err
+	}
+	return &message, nil
+}
+
 func (r *repository) GetMessageByID(ctx context.Context, messageID uint) (*Message, error) {
 	var message Message
-	if err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("ReplyTo.Sender").
-		Preload("Reactions.User").
+	if err := r.withMessageRelations(ctx).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
 		First(&message, messageID).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("message not found")
@@ -72,13 +154,12 @@ func (r *repository) GetMessageByID(ctx context.Context, messageID uint) (*Messa
 	return &message, nil
 }
 
-func (r *repository) GetMessages(ctx context.Context, conversationID uint, limit, offset int) ([]Message, error) {
+func (r *repository) GetMessages(ctx context.Context, conversationID, viewerID uint, limit, offset int) ([]Message, error) {
 	var messages []Message
-	if err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("ReplyTo.Sender").
-		Preload("Reactions.User").
+	if err := r.withMessageRelations(ctx).
 		Where("conversation_id = ?", conversationID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("(hidden_at IS NULL OR sender_id = ?)", viewerID).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -89,13 +170,12 @@ func (r *repository) GetMessages(ctx context.Context, conversationID uint, limit
 	return messages, nil
 }
 
-func (r *repository) GetMessagesBefore(ctx context.Context, conversationID uint, beforeID uint, limit int) ([]Message, error) {
+func (r *repository) GetMessagesBefore(ctx context.Context, conversationID, viewerID uint, beforeID uint, limit int) ([]Message, error) {
 	var messages []Message
-	if err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("ReplyTo.Sender").
-		Preload("Reactions.User").
+	if err := r.withMessageRelations(ctx).
 		Where("conversation_id = ? AND id < ?", conversationID, beforeID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("(hidden_at IS NULL OR sender_id = ?)", viewerID).
 		Order("created_at DESC").
 		Limit(limit).
 		Find(&messages).Error; err != nil {
@@ -105,12 +185,23 @@ func (r *repository) GetMessagesBefore(ctx context.Context, conversationID uint,
 	return messages, nil
 }
 
-func (r *repository) UpdateMessage(ctx context.Context, messageID uint, content string) error {
+func (r *repository) UpdateMessage(ctx context.Context, messageID uint, content string, entities []richtext.Entity) error {
+	// Map updates bypass the field serializer, so encode entities here
+	var entitiesJSON interface{}
+	if len(entities) > 0 {
+		data, err := json.Marshal(entities)
+		if err != nil {
+			return err
+		}
+		entitiesJSON = string(data)
+	}
+
 	if err := r.db.WithContext(ctx).
 		Model(&Message{}).
 		Where("id = ?", messageID).
 		Updates(map[string]interface{}{
 			"content":    content,
+			"entities":   entitiesJSON,
 			"is_edited":  true,
 			"edited_at":  "NOW()",
 		}).Error; err != nil {
@@ -121,6 +212,18 @@ func (r *repository) UpdateMessage(ctx context.Context, messageID uint, content
 	return nil
 }
 
+func (r *repository) HideMessage(ctx context.Context, messageID uint) error {
+	if err := r.db.WithContext(ctx).
+		Model(&Message{}).
+		Where("id = ? AND hidden_at IS NULL", messageID).
+		Update("hidden_at", time.Now().UTC()).Error; err != nil {
+		logger.Error("Failed to hide message", zap.Error(err))
+		return err
+	}
+	logger.Info("Message shadow-hidden", zap.Uint("message_id", messageID))
+	return nil
+}
+
 func (r *repository) DeleteMessage(ctx context.Context, messageID uint) error {
 	if err := r.db.WithContext(ctx).Delete(&Message{}, messageID).Error; err != nil {
 		logger.Error("Failed to delete message", zap.Error(err))
@@ -130,15 +233,14 @@ func (r *repository) DeleteMessage(ctx context.Context, messageID uint) error {
 	return nil
 }
 
-func (r *repository) SearchMessages(ctx context.Context, conversationID uint, query string, limit, offset int) ([]Message, error) {
+func (r *repository) SearchMessages(ctx context.Context, conversationID, viewerID uint, query string, limit, offset int) ([]Message, error) {
 	var messages []Message
 	searchQuery := "%" + strings.ToLower(query) + "%"
 	
-	if err := r.db.WithContext(ctx).
-		Preload("Sender").
-		Preload("ReplyTo.Sender").
-		Preload("Reactions.User").
+	if err := r.withMessageRelations(ctx).
 		Where("conversation_id = ? AND LOWER(content) LIKE ?", conversationID, searchQuery).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("(hidden_at IS NULL OR sender_id = ?)", viewerID).
 		Order("created_at DESC").
 		Limit(limit).
 		Offset(offset).
@@ -149,11 +251,13 @@ func (r *repository) SearchMessages(ctx context.Context, conversationID uint, qu
 	return messages, nil
 }
 
-func (r *repository) GetMessageCount(ctx context.Context, conversationID uint) (int, error) {
+func (r *repository) GetMessageCount(ctx context.Context, conversationID, viewerID uint) (int, error) {
 	var count int64
 	if err := r.db.WithContext(ctx).
 		Model(&Message{}).
 		Where("conversation_id = ?", conversationID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Where("(hidden_at IS NULL OR sender_id = ?)", viewerID).
 		Count(&count).Error; err != nil {
 		logger.Error("Failed to get message count", zap.Error(err))
 		return 0, err
@@ -161,13 +265,357 @@ func (r *repository) GetMessageCount(ctx context.Context, conversationID uint) (
 	return int(count), nil
 }
 
+func (r *repository) CreateForwardedMessage(ctx context.Context, message *Message, sourceMessageID uint) (*Message, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+
+		// Point the new message at the same stored objects instead of re-uploading
+		if err := tx.Exec(`
+			INSERT INTO files (user_id, conversation_id, message_id, attachment_position, file_name, original_name, file_size, mime_type,
+				file_extension, bucket_name, object_key, storage_path, is_processed, thumbnail_url, preview_url,
+				is_public, width, height, duration, waveform)
+			SELECT ?, ?, ?, attachment_position, file_name, original_name, file_size, mime_type,
+				file_extension, bucket_name, object_key, storage_path, is_processed, thumbnail_url, preview_url,
+				is_public, width, height, duration, waveform
+			FROM files
+			WHERE message_id = ? AND deleted_at IS NULL`,
+			message.SenderID, message.ConversationID, message.ID, sourceMessageID).Error; err != nil {
+			return err
+		}
+
+		// The legacy file_url must point at the copy, which members of the target conversation can read
+		return tx.Exec(`
+			UPDATE messages SET file_url = '/api/files/' || (
+				SELECT id FROM files WHERE message_id = ? AND deleted_at IS NULL ORDER BY attachment_position, id LIMIT 1
+			) || '/download'
+			WHERE id = ? AND file_url LIKE '/api/files/%' AND EXISTS (SELECT 1 FROM files WHERE message_id = ? AND deleted_at IS NULL)`,
+			message.ID, message.ID, message.ID).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create forwarded message", zap.Error(err))
+		return nil, err
+	}
+
+	// Load relations
+	if err := r.withMessageRelations(ctx).
+		First(message, message.ID).Error; err != nil {
+		logger.Error("Failed to load message relations", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Message forwarded", zap.Uint("message_id", message.ID), zap.Uint("source_message_id", sourceMessageID))
+	return message, nil
+}
+
+func (r *repository) SaveLinkPreviews(ctx context.Context, messageID uint, previews []MessageLinkPreview) error {
+	if len(previews) == 0 {
+		return nil
+	}
+	for i := range previews {
+		previews[i].MessageID = messageID
+	}
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&previews).Error; err != nil {
+		logger.Error("Failed to save link previews", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *repository) SaveMentions(ctx context.Context, message *Message, usernames []string) error {
+	if len(usernames) == 0 {
+		return nil
+	}
+	// Only participants can be mentioned; the unread mention counters are bumped by a trigger
+	if err := r.db.WithContext(ctx).Exec(`
+		INSERT INTO message_mentions (message_id, user_id)
+		SELECT ?, cp.user_id FROM conversation_participants cp
+		JOIN users u ON u.id = cp.user_id
+		WHERE cp.conversation_id = ? AND cp.user_id <> ? AND LOWER(u.username) IN ?
+		ON CONFLICT DO NOTHING`,
+		message.ID, message.ConversationID, message.SenderID, usernames).Error; err != nil {
+		logger.Error("Failed to save mentions", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Polls
+
+func (r *repository) CreatePollMessage(ctx context.Context, message *Message, poll *Poll) (*Message, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		poll.MessageID = message.ID
+		// Options are created through the association
+		return tx.Create(poll).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create poll message", zap.Error(err))
+		return nil, err
+	}
+
+	// Load relations
+	if err := r.withMessageRelations(ctx).
+		First(message, message.ID).Error; err != nil {
+		logger.Error("Failed to load message relations", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Poll created", zap.Uint("message_id", message.ID), zap.Uint("poll_id", poll.ID))
+	return message, nil
+}
+
+func (r *repository) GetPollByMessageID(ctx context.Context, messageID uint) (*Poll, error) {
+	var poll Poll
+	if err := r.db.WithContext(ctx).
+		Preload("Options", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Preload("Options.Votes.User").
+		Where("message_id = ?", messageID).
+		First(&poll).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("poll not found")
+		}
+		logger.Error("Failed to get poll", zap.Error(err))
+		return nil, err
+	}
+	return &poll, nil
+}
+
+func (r *repository) ReplacePollVotes(ctx context.Context, pollID, userID uint, optionIDs []uint) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("poll_id = ? AND user_id = ?", pollID, userID).Delete(&PollVote{}).Error; err != nil {
+			return err
+		}
+		votes := make([]PollVote, 0, len(optionIDs))
+		for _, optionID := range optionIDs {
+			votes = append(votes, PollVote{PollID: pollID, OptionID: optionID, UserID: userID})
+		}
+		return tx.Create(&votes).Error
+	})
+	if err != nil {
+		logger.Error("Failed to record poll vote", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *repository) DeletePollVotes(ctx context.Context, pollID, userID uint) error {
+	if err := r.db.WithContext(ctx).
+		Where("poll_id = ? AND user_id = ?", pollID, userID).
+		Delete(&PollVote{}).Error; err != nil {
+		logger.Error("Failed to retract poll vote", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *repository) ClosePoll(ctx context.Context, pollID, closedBy uint, now time.Time) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&Poll{}).
+		Where("id = ? AND closed_at IS NULL", pollID).
+		Updates(map[string]interface{}{
+			"closed_at": now,
+			"closed_by": closedBy,
+		})
+	if result.Error != nil {
+		logger.Error("Failed to close poll", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repository) CloseDuePolls(ctx context.Context, now time.Time) ([]Poll, error) {
+	var polls []Poll
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE polls
+		SET closed_at = closes_at
+		WHERE closed_at IS NULL AND closes_at IS NOT NULL AND closes_at <= ?
+		RETURNING *`, now).
+		Scan(&polls).Error; err != nil {
+		logger.Error("Failed to close due polls", zap.Error(err))
+		return nil, err
+	}
+	return polls, nil
+}
+
+// Locations
+
+func (r *repository) CreateLocationMessage(ctx context.Context, message *Message, location *Location) (*Message, error) {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		location.MessageID = message.ID
+		return tx.Create(location).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create location message", zap.Error(err))
+		return nil, err
+	}
+
+	// Load relations
+	if err := r.withMessageRelations(ctx).
+		First(message, message.ID).Error; err != nil {
+		logger.Error("Failed to load message relations", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Location shared", zap.Uint("message_id", message.ID), zap.Bool("live", location.LiveUntil != nil))
+	return message, nil
+}
+
+func (r *repository) GetLocationByMessageID(ctx context.Context, messageID uint) (*Location, error) {
+	var location Location
+	if err := r.db.WithContext(ctx).Where("message_id = ?", messageID).First(&location).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("location not found")
+		}
+		logger.Error("Failed to get location", zap.Error(err))
+		return nil, err
+	}
+	return &location, nil
+}
+
+func (r *repository) UpdateLiveLocation(ctx context.Context, messageID, userID uint, latitude, longitude float64, accuracy *float64, now, throttleBefore time.Time) (*Location, bool, error) {
+	var locations []Location
+	// Only the sharer's own live location, and no more often than the throttle allows
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE message_locations
+		SET latitude = ?, longitude = ?, accuracy = ?, updated_at = ?
+		WHERE message_id = ? AND user_id = ?
+			AND ended_at IS NULL AND live_until > ?
+			AND updated_at <= ?
+		RETURNING *`,
+		latitude, longitude, accuracy, now,
+		messageID, userID, now, throttleBefore,
+	).Scan(&locations).Error; err != nil {
+		logger.Error("Failed to update live location", zap.Error(err))
+		return nil, false, err
+	}
+	if len(locations) == 0 {
+		return nil, false, nil
+	}
+	return &locations[0], true, nil
+}
+
+func (r *repository) EndLiveLocation(ctx context.Context, messageID, userID uint, now time.Time) (*Location, bool, error) {
+	var locations []Location
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE message_locations
+		SET ended_at = LEAST(live_until, ?)
+		WHERE message_id = ? AND user_id = ? AND live_until IS NOT NULL AND ended_at IS NULL
+		RETURNING *`,
+		now, messageID, userID,
+	).Scan(&locations).Error; err != nil {
+		logger.Error("Failed to end live location", zap.Error(err))
+		return nil, false, err
+	}
+	if len(locations) == 0 {
+		return nil, false, nil
+	}
+	return &locations[0], true, nil
+}
+
+func (r *repository) EndExpiredLiveLocations(ctx context.Context, now time.Time) ([]Location, error) {
+	var locations []Location
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE message_locations
+		SET ended_at = live_until
+		WHERE ended_at IS NULL AND live_until IS NOT NULL AND live_until <= ?
+		RETURNING *`, now).
+		Scan(&locations).Error; err != nil {
+		logger.Error("Failed to end expired live locations", zap.Error(err))
+		return nil, err
+	}
+	return locations, nil
+}
+
+// Disappearing messages
+
+func (r *repository) GetExpiredMessages(ctx context.Context, now time.Time, limit int) ([]Message, error) {
+	var messages []Message
+	if err := r.db.WithContext(ctx).
+		Where("expires_at IS NOT NULL AND expires_at <= ?", now).
+		// Held conversations keep expired messages; they stay hidden from participants
+		Where("NOT EXISTS (SELECT 1 FROM legal_holds h WHERE h.conversation_id = messages.conversation_id)").
+		Order("expires_at ASC").
+		Limit(limit).
+		Find(&messages).Error; err != nil {
+		logger.Error("Failed to get expired messages", zap.Error(err))
+		return nil, err
+	}
+	return messages, nil
+}
+
+func (r *repository) GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error) {
+	var files []file.StoredObject
+	if len(messageIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, message_id, object_key, thumbnail_url, preview_url").
+		Where("message_id IN ?", messageIDs).
+		Find(&files).Error; err != nil {
+		logger.Error("Failed to get message files", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *repository) CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Where("object_key = ?", objectKey).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count files by object key", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *repository) HardDeleteMessages(ctx context.Context, messageIDs []uint) error {
+	if len(messageIDs) == 0 {
+		return nil
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("message_id IN ?", messageIDs).Delete(&MessageReaction{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id IN ?", messageIDs).Delete(&PinnedMessage{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("message_id IN ?", messageIDs).Delete(&MessageBookmark{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM files WHERE message_id IN ?", messageIDs).Error; err != nil {
+			return err
+		}
+		return tx.Where("id IN ?", messageIDs).Delete(&Message{}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to hard delete messages", zap.Error(err))
+		return err
+	}
+	logger.Info("Expired messages deleted", zap.Int("count", len(messageIDs)))
+	return nil
+}
+
 // Reactions
 
-func (r *repository) AddReaction(ctx context.Context, messageID, userID uint, reactionType string) (*MessageReaction, error) {
+func (r *repository) AddReaction(ctx context.Context, messageID, userID uint, emoji string) (*MessageReaction, error) {
 	reaction := &MessageReaction{
-		MessageID:    messageID,
-		UserID:       userID,
-		ReactionType: reactionType,
+		MessageID: messageID,
+		UserID:    userID,
+		Emoji:     emoji,
 	}
 
 	if err := r.db.WithContext(ctx).Create(reaction).Error; err != nil {
@@ -181,18 +629,18 @@ func (r *repository) AddReaction(ctx context.Context, messageID, userID uint, re
 		return nil, err
 	}
 
-	logger.Info("Reaction added", zap.Uint("message_id", messageID), zap.String("reaction_type", reactionType))
+	logger.Info("Reaction added", zap.Uint("message_id", messageID), zap.String("emoji", emoji))
 	return reaction, nil
 }
 
-func (r *repository) RemoveReaction(ctx context.Context, messageID, userID uint, reactionType string) error {
+func (r *repository) RemoveReaction(ctx context.Context, messageID, userID uint, emoji string) error {
 	if err := r.db.WithContext(ctx).
-		Where("message_id = ? AND user_id = ? AND reaction_type = ?", messageID, userID, reactionType).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
 		Delete(&MessageReaction{}).Error; err != nil {
 		logger.Error("Failed to remove reaction", zap.Error(err))
 		return err
 	}
-	logger.Info("Reaction removed", zap.Uint("message_id", messageID), zap.String("reaction_type", reactionType))
+	logger.Info("Reaction removed", zap.Uint("message_id", messageID), zap.String("emoji", emoji))
 	return nil
 }
 
@@ -208,11 +656,24 @@ func (r *repository) GetMessageReactions(ctx context.Context, messageID uint) ([
 	return reactions, nil
 }
 
-func (r *repository) GetUserReaction(ctx context.Context, messageID, userID uint) (*MessageReaction, error) {
+func (r *repository) GetMessageReactionEmojis(ctx context.Context, messageID uint) ([]string, error) {
+	var emojis []string
+	if err := r.db.WithContext(ctx).
+		Model(&MessageReaction{}).
+		Where("message_id = ?", messageID).
+		Distinct().
+		Pluck("emoji", &emojis).Error; err != nil {
+		logger.Error("Failed to get message reaction emojis", zap.Error(err))
+		return nil, err
+	}
+	return emojis, nil
+}
+
+func (r *repository) GetUserReaction(ctx context.Context, messageID, userID uint, emoji string) (*MessageReaction, error) {
 	var reaction MessageReaction
 	if err := r.db.WithContext(ctx).
 		Preload("User").
-		Where("message_id = ? AND user_id = ?", messageID, userID).
+		Where("message_id = ? AND user_id = ? AND emoji = ?", messageID, userID, emoji).
 		First(&reaction).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, nil // No reaction found
@@ -223,6 +684,472 @@ func (r *repository) GetUserReaction(ctx context.Context, messageID, userID uint
 	return &reaction, nil
 }
 
+// Pins
+
+// PinMessage pins a message, or returns false if the conversation already has maxPins pins
+func (r *repository) PinMessage(ctx context.Context, pin *PinnedMessage, maxPins int) (bool, error) {
+	pinned := false
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Lock the conversation so concurrent pins cannot both pass the limit
+		if err := tx.Exec("SELECT id FROM conversations WHERE id = ? FOR UPDATE", pin.ConversationID).Error; err != nil {
+			return err
+		}
+
+		var saved []PinnedMessage
+		if err := tx.Raw(`
+			INSERT INTO pinned_messages (conversation_id, message_id, pinned_by)
+			SELECT ?, ?, ?
+			FROM pinned_messages
+			WHERE conversation_id = ?
+			HAVING COUNT(*) < ?
+			RETURNING *`,
+			pin.ConversationID, pin.MessageID, pin.PinnedBy,
+			pin.ConversationID, maxPins,
+		).Scan(&saved).Error; err != nil {
+			return err
+		}
+		if len(saved) > 0 {
+			*pin = saved[0]
+			pinned = true
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to pin message", zap.Error(err))
+		return false, err
+	}
+
+	if pinned {
+		logger.Info("Message pinned", zap.Uint("message_id", pin.MessageID), zap.Uint("conversation_id", pin.ConversationID))
+	}
+	return pinned, nil
+}
+
+func (r *repository) UnpinMessage(ctx context.Context, conversationID, messageID uint) error {
+	if err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND message_id = ?", conversationID, messageID).
+		Delete(&PinnedMessage{}).Error; err != nil {
+		logger.Error("Failed to unpin message", zap.Error(err))
+		return err
+	}
+	logger.Info("Message unpinned", zap.Uint("message_id", messageID), zap.Uint("conversation_id", conversationID))
+	return nil
+}
+
+// GetPinnedMessages returns the pins of messages everyone in the conversation can see
+func (r *repository) GetPinnedMessages(ctx context.Context, conversationID uint) ([]PinnedMessage, error) {
+	var pins []PinnedMessage
+	if err := r.db.WithContext(ctx).
+		Preload("PinnedByUser").
+		Joins("JOIN messages ON messages.id = pinned_messages.message_id").
+		Where("pinned_messages.conversation_id = ?", conversationID).
+		Where("messages.hidden_at IS NULL").
+		Where("(messages.expires_at IS NULL OR messages.expires_at > ?)", time.Now().UTC()).
+		Select("pinned_messages.*").
+		Order("pinned_messages.created_at DESC").
+		Find(&pins).Error; err != nil {
+		logger.Error("Failed to get pinned messages", zap.Error(err))
+		return nil, err
+	}
+	if len(pins) == 0 {
+		return pins, nil
+	}
+
+	// Load the pinned messages with everything a message response needs
+	messageIDs := make([]uint, 0, len(pins))
+	for _, pin := range pins {
+		messageIDs = append(messageIDs, pin.MessageID)
+	}
+	var messages []Message
+	if err := r.withMessageRelations(ctx).
+		Where("id IN ?", messageIDs).
+		Find(&messages).Error; err != nil {
+		logger.Error("Failed to get pinned messages", zap.Error(err))
+		return nil, err
+	}
+	messagesByID := make(map[uint]Message, len(messages))
+	for _, message := range messages {
+		messagesByID[message.ID] = message
+	}
+	for i := range pins {
+		pins[i].Message = messagesByID[pins[i].MessageID]
+	}
+	return pins, nil
+}
+
+func (r *repository) CheckMessagePinned(ctx context.Context, conversationID, messageID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&PinnedMessage{}).
+		Where("conversation_id = ? AND message_id = ?", conversationID, messageID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check message pinned", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Bookmarks
+
+func (r *repository) UpsertBookmark(ctx context.Context, bookmark *MessageBookmark) (*MessageBookmark, error) {
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "message_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"label", "remind_at", "reminded_at"}),
+		}).
+		Create(bookmark).Error; err != nil {
+		logger.Error("Failed to save bookmark", zap.Error(err))
+		return nil, err
+	}
+
+	// Reload so an update returns the original created_at
+	var saved MessageBookmark
+	if err := r.db.WithContext(ctx).
+		Where("user_id = ? AND message_id = ?", bookmark.UserID, bookmark.MessageID).
+		First(&saved).Error; err != nil {
+		logger.Error("Failed to get saved bookmark", zap.Error(err))
+		return nil, err
+	}
+
+	logger.Info("Message bookmarked", zap.Uint("message_id", bookmark.MessageID), zap.Uint("user_id", bookmark.UserID))
+	return &saved, nil
+}
+
+func (r *repository) DeleteBookmark(ctx context.Context, userID, messageID uint) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("user_id = ? AND message_id = ?", userID, messageID).
+		Delete(&MessageBookmark{})
+	if result.Error != nil {
+		logger.Error("Failed to delete bookmark", zap.Error(result.Error))
+		return false, result.Error
+	}
+	logger.Info("Bookmark removed", zap.Uint("message_id", messageID), zap.Uint("user_id", userID))
+	return result.RowsAffected > 0, nil
+}
+
+// visibleBookmarks scopes bookmarks to messages the user can still see
+func (r *repository) visibleBookmarks(ctx context.Context, userID uint) *gorm.DB {
+	return r.db.WithContext(ctx).
+		Model(&MessageBookmark{}).
+		Joins("JOIN messages ON messages.id = message_bookmarks.message_id").
+		Joins("JOIN conversation_participants ON conversation_participants.conversation_id = messages.conversation_id AND conversation_participants.user_id = message_bookmarks.user_id").
+		Where("message_bookmarks.user_id = ?", userID).
+		Where("(messages.hidden_at IS NULL OR messages.sender_id = message_bookmarks.user_id)").
+		Where("(messages.expires_at IS NULL OR messages.expires_at > ?)", time.Now().UTC())
+}
+
+func (r *repository) GetUserBookmarks(ctx context.Context, userID uint, label string, cursor uint, limit int) ([]MessageBookmark, error) {
+	query := r.visibleBookmarks(ctx, userID)
+	if label != "" {
+		query = query.Where("message_bookmarks.label = ?", label)
+	}
+	if cursor > 0 {
+		query = query.Where("message_bookmarks.id < ?", cursor)
+	}
+
+	var bookmarks []MessageBookmark
+	if err := query.
+		Select("message_bookmarks.*").
+		Order("message_bookmarks.id DESC").
+		Limit(limit).
+		Find(&bookmarks).Error; err != nil {
+		logger.Error("Failed to get bookmarks", zap.Error(err))
+		return nil, err
+	}
+	if len(bookmarks) == 0 {
+		return bookmarks, nil
+	}
+
+	// Load the bookmarked messages with everything a message response needs
+	messageIDs := make([]uint, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		messageIDs = append(messageIDs, bookmark.MessageID)
+	}
+	var messages []Message
+	if err := r.withMessageRelations(ctx).
+		Where("id IN ?", messageIDs).
+		Find(&messages).Error; err != nil {
+		logger.Error("Failed to get bookmarked messages", zap.Error(err))
+		return nil, err
+	}
+	messagesByID := make(map[uint]Message, len(messages))
+	for _, message := range messages {
+		messagesByID[message.ID] = message
+	}
+	for i := range bookmarks {
+		bookmarks[i].Message = messagesByID[bookmarks[i].MessageID]
+	}
+	return bookmarks, nil
+}
+
+func (r *repository) GetBookmarkLabels(ctx context.Context, userID uint) ([]BookmarkLabelCount, error) {
+	var labels []BookmarkLabelCount
+	if err := r.visibleBookmarks(ctx, userID).
+		Select("message_bookmarks.label AS label, COUNT(*) AS count").
+		Where("message_bookmarks.label <> ''").
+		Group("message_bookmarks.label").
+		Order("message_bookmarks.label ASC").
+		Scan(&labels).Error; err != nil {
+		logger.Error("Failed to get bookmark labels", zap.Error(err))
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (r *repository) ClaimDueBookmarkReminders(ctx context.Context, now time.Time, limit int) ([]MessageBookmark, error) {
+	var claimed []MessageBookmark
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE message_bookmarks SET reminded_at = ?
+		WHERE id IN (
+			SELECT id FROM message_bookmarks
+			WHERE remind_at <= ? AND reminded_at IS NULL
+			ORDER BY remind_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		now, now, limit,
+	).Scan(&claimed).Error; err != nil {
+		logger.Error("Failed to claim due bookmark reminders", zap.Error(err))
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// Scheduled messages
+
+func (r *repository) CreateScheduledMessage(ctx context.Context, scheduled *ScheduledMessage) error {
+	if err := r.db.WithContext(ctx).Create(scheduled).Error; err != nil {
+		logger.Error("Failed to create scheduled message", zap.Error(err))
+		return err
+	}
+	logger.Info("Scheduled message created", zap.Uint("scheduled_id", scheduled.ID), zap.Time("scheduled_at", scheduled.ScheduledAt))
+	return nil
+}
+
+// GetScheduledMessageByClientID returns nil when no scheduled message uses the client id
+func (r *repository) GetScheduledMessageByClientID(ctx context.Context, conversationID, senderID uint, clientMessageID string) (*ScheduledMessage, error) {
+	var scheduled ScheduledMessage
+	if err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND sender_id = ? AND client_message_id = ?", conversationID, senderID, clientMessageID).
+		First(&scheduled).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to get scheduled message by client ID", zap.Error(err))
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (r *repository) GetScheduledMessageByID(ctx context.Context, scheduledID uint) (*ScheduledMessage, error) {
+	var scheduled ScheduledMessage
+	if err := r.db.WithContext(ctx).First(&scheduled, scheduledID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("scheduled message not found")
+		}
+		logger.Error("Failed to get scheduled message", zap.Error(err))
+		return nil, err
+	}
+	return &scheduled, nil
+}
+
+func (r *repository) GetUserScheduledMessages(ctx context.Context, userID uint, conversationID *uint) ([]ScheduledMessage, error) {
+	var scheduled []ScheduledMessage
+	query := r.db.WithContext(ctx).
+		Where("sender_id = ? AND status IN ?", userID, []string{ScheduledStatusPending, ScheduledStatusSending, ScheduledStatusFailed})
+	if conversationID != nil {
+		query = query.Where("conversation_id = ?", *conversationID)
+	}
+	if err := query.Order("scheduled_at ASC").Find(&scheduled).Error; err != nil {
+		logger.Error("Failed to get scheduled messages", zap.Error(err))
+		return nil, err
+	}
+	return scheduled, nil
+}
+
+func (r *repository) UpdatePendingScheduledMessage(ctx context.Context, scheduledID uint, updates map[string]interface{}) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Model(&ScheduledMessage{}).
+		Where("id = ? AND status = ?", scheduledID, ScheduledStatusPending).
+		Updates(updates)
+	if result.Error != nil {
+		logger.Error("Failed to update scheduled message", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ClaimDueScheduledMessages atomically moves due messages to the sending state.
+// Messages stuck in sending since before staleBefore (e.g. after a crash) are reclaimed.
+func (r *repository) ClaimDueScheduledMessages(ctx context.Context, now, staleBefore time.Time, limit int) ([]ScheduledMessage, error) {
+	var claimed []ScheduledMessage
+	if err := r.db.WithContext(ctx).Raw(`
+		UPDATE scheduled_messages SET status = ?, claimed_at = ?, attempts = attempts + 1
+		WHERE id IN (
+			SELECT id FROM scheduled_messages
+			WHERE (status = ? AND scheduled_at <= ?) OR (status = ? AND claimed_at < ?)
+			ORDER BY scheduled_at ASC
+			LIMIT ?
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING *`,
+		ScheduledStatusSending, now,
+		ScheduledStatusPending, now, ScheduledStatusSending, staleBefore,
+		limit,
+	).Scan(&claimed).Error; err != nil {
+		logger.Error("Failed to claim due scheduled messages", zap.Error(err))
+		return nil, err
+	}
+	return claimed, nil
+}
+
+// MarkScheduledMessageSent marks a scheduled message delivered; messageID is nil for reminders, which post nothing
+func (r *repository) MarkScheduledMessageSent(ctx context.Context, scheduledID uint, messageID *uint) error {
+	if err := r.db.WithContext(ctx).
+		Model(&ScheduledMessage{}).
+		Where("id = ?", scheduledID).
+		Updates(map[string]interface{}{
+			"status":     ScheduledStatusSent,
+			"message_id": messageID,
+			"sent_at":    time.Now().UTC(),
+			"last_error": "",
+		}).Error; err != nil {
+		logger.Error("Failed to mark scheduled message sent", zap.Error(err))
+		return err
+	}
+	logger.Info("Scheduled message sent", zap.Uint("scheduled_id", scheduledID))
+	return nil
+}
+
+func (r *repository) MarkScheduledMessageFailed(ctx context.Context, scheduledID uint, reason string, retry bool) error {
+	status := ScheduledStatusFailed
+	if retry {
+		status = ScheduledStatusPending
+	}
+	if err := r.db.WithContext(ctx).
+		Model(&ScheduledMessage{}).
+		Where("id = ?", scheduledID).
+		Updates(map[string]interface{}{
+			"status":     status,
+			"last_error": reason,
+		}).Error; err != nil {
+		logger.Error("Failed to mark scheduled message failed", zap.Error(err))
+		return err
+	}
+	logger.Warn("Scheduled message dispatch failed", zap.Uint("scheduled_id", scheduledID), zap.String("reason", reason), zap.Bool("retry", retry))
+	return nil
+}
+
+// Bot commands
+
+func (r *repository) CreateBotCommand(ctx context.Context, command *BotCommand) error {
+	if err := r.db.WithContext(ctx).Create(command).Error; err != nil {
+		logger.Error("Failed to create bot command", zap.Error(err))
+		return err
+	}
+	logger.Info("Bot command created", zap.Uint("conversation_id", command.ConversationID), zap.String("name", command.Name))
+	return nil
+}
+
+// GetBotCommand returns nil when the conversation has no bot command with that name
+func (r *repository) GetBotCommand(ctx context.Context, conversationID uint, name string) (*BotCommand, error) {
+	var command BotCommand
+	if err := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND name = ?", conversationID, name).
+		First(&command).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		logger.Error("Failed to get bot command", zap.Error(err))
+		return nil, err
+	}
+	return &command, nil
+}
+
+func (r *repository) GetBotCommands(ctx context.Context, conversationID uint) ([]BotCommand, error) {
+	var commands []BotCommand
+	if err := r.db.WithContext(ctx).
+		Where("conversation_id = ?", conversationID).
+		Order("name ASC").
+		Find(&commands).Error; err != nil {
+		logger.Error("Failed to get bot commands", zap.Error(err))
+		return nil, err
+	}
+	return commands, nil
+}
+
+func (r *repository) DeleteBotCommand(ctx context.Context, conversationID uint, name string) (bool, error) {
+	result := r.db.WithContext(ctx).
+		Where("conversation_id = ? AND name = ?", conversationID, name).
+		Delete(&BotCommand{})
+	if result.Error != nil {
+		logger.Error("Failed to delete bot command", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// Users
+
+func (r *repository) GetUserByID(ctx context.Context, userID uint) (*user.User, error) {
+	var u user.User
+	if err := r.db.WithContext(ctx).First(&u, userID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		logger.Error("Failed to get user", zap.Error(err))
+		return nil, err
+	}
+	return &u, nil
+}
+
+func (r *repository) GetUsersByUsernames(ctx context.Context, usernames []string) ([]user.User, error) {
+	var users []user.User
+	if len(usernames) == 0 {
+		return users, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Where("username IN ?", usernames).
+		Find(&users).Error; err != nil {
+		logger.Error("Failed to get users by username", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// Conversations
+
+func (r *repository) GetConversationSettings(ctx context.Context, conversationID uint) (*ConversationSettings, error) {
+	var settings ConversationSettings
+	if err := r.db.WithContext(ctx).
+		Table("conversations").
+		Where("id = ?", conversationID).
+		First(&settings).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("conversation not found")
+		}
+		logger.Error("Failed to get conversation settings", zap.Error(err))
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// Receipts
+
+func (r *repository) GetReceiptPointers(ctx context.Context, conversationID uint) ([]ReceiptPointer, error) {
+	var pointers []ReceiptPointer
+	if err := r.db.WithContext(ctx).
+		Preload("User").
+		Where("conversation_id = ?", conversationID).
+		Order("joined_at ASC").
+		Find(&pointers).Error; err != nil {
+		logger.Error("Failed to get receipt pointers", zap.Error(err))
+		return nil, err
+	}
+	return pointers, nil
+}
+
 // Validation
 
 func (r *repository) CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error) {
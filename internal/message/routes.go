@@ -20,9 +20,75 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 		messages.GET("/:message_id", handler.GetMessage)             // Get specific message
 		messages.PUT("/:message_id", handler.UpdateMessage)          // Update message
 		messages.DELETE("/:message_id", handler.DeleteMessage)       // Delete message
+		messages.GET("/:message_id/receipts", handler.GetMessageReceipts) // Get read receipts (seen by)
 
 		// Message reactions
 		messages.POST("/:message_id/reactions", handler.AddReaction)           // Add reaction
-		messages.DELETE("/:message_id/reactions/:reaction_type", handler.RemoveReaction) // Remove reaction
+		messages.DELETE("/:message_id/reactions/:emoji", handler.RemoveReaction) // Remove reaction
+
+		// Message pins
+		messages.POST("/:message_id/pin", handler.PinMessage)        // Pin message
+		messages.DELETE("/:message_id/pin", handler.UnpinMessage)    // Unpin message
+	}
+
+	// Message actions addressed by message ID (all protected)
+	messageActions := router.Group("/messages")
+	messageActions.Use(middleware.AuthMiddleware())
+	{
+		messageActions.POST("/:id/forward", handler.ForwardMessage)          // Forward message
+
+		// Bookmarks
+		messageActions.POST("/:id/bookmark", handler.BookmarkMessage)        // Bookmark message (or update label/reminder)
+		messageActions.DELETE("/:id/bookmark", handler.RemoveBookmark)       // Remove bookmark
+
+		// Polls
+		messageActions.GET("/:id/poll", handler.GetPoll)                     // Get poll with my votes
+		messageActions.POST("/:id/poll/votes", handler.VotePoll)             // Vote (replaces previous vote)
+		messageActions.DELETE("/:id/poll/votes", handler.RetractPollVote)    // Retract vote
+		messageActions.POST("/:id/poll/close", handler.ClosePoll)            // Close poll early
+
+		// Live locations (position updates stream over the WebSocket)
+		messageActions.POST("/:id/location/stop", handler.StopLiveLocation)  // Stop sharing live location
+	}
+
+	// Current user's saved messages (all protected)
+	me := router.Group("/me")
+	me.Use(middleware.AuthMiddleware())
+	{
+		me.GET("/bookmarks", handler.GetBookmarks)                   // Get bookmarks (cursor pagination, optional label)
+		me.GET("/bookmarks/labels", handler.GetBookmarkLabels)       // Get bookmark labels with counts
+	}
+
+	// Conversation pins (all protected)
+	pins := router.Group("/conversations/:id/pins")
+	pins.Use(middleware.AuthMiddleware())
+	{
+		pins.GET("/", handler.GetPinnedMessages)                     // Get pinned messages
+	}
+
+	// Disappearing messages (all protected)
+	ttl := router.Group("/conversations/:id/message-ttl")
+	ttl.Use(middleware.AuthMiddleware())
+	{
+		ttl.PUT("/", handler.UpdateMessageTTL)                       // Set timer (DM participants or group admins)
+	}
+
+	// Conversation slash commands (all protected)
+	commands := router.Group("/conversations/:id/commands")
+	commands.Use(middleware.AuthMiddleware())
+	{
+		commands.GET("/", handler.ListCommands)                      // List built-in and bot commands
+		commands.GET("/bots", handler.GetBotCommands)                // Get bot webhook commands (admin)
+		commands.POST("/bots", handler.CreateBotCommand)             // Register bot webhook command (admin)
+		commands.DELETE("/bots/:name", handler.DeleteBotCommand)     // Remove bot webhook command (admin)
+	}
+
+	// Scheduled messages (all protected)
+	scheduled := router.Group("/scheduled-messages")
+	scheduled.Use(middleware.AuthMiddleware())
+	{
+		scheduled.GET("/", handler.GetScheduledMessages)                         // Get scheduled messages
+		scheduled.PUT("/:scheduled_id", handler.UpdateScheduledMessage)          // Update scheduled message
+		scheduled.DELETE("/:scheduled_id", handler.CancelScheduledMessage)       // Cancel scheduled message
 	}
 }
@@ -3,34 +3,79 @@ package message
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"huddle/internal/conversation"
+	"huddle/internal/emoji"
+	"huddle/internal/file"
+	"huddle/internal/moderation"
 	"huddle/internal/user"
 	"huddle/internal/websocket"
 	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+	"huddle/pkg/richtext"
+	"huddle/pkg/validation"
 
 	"go.uber.org/zap"
 )
 
+// ErrModerationRejected is wrapped by errors returned when moderation blocks content from being posted
+var ErrModerationRejected = errors.New("message rejected by moderation")
+
 type service struct {
 	repo Repository
 	wsService websocket.Service
+	conversationService conversation.Service
+	moderationService moderation.Service
+	emojiService emoji.Service
+	unfurler *unfurler
+	commands *CommandRegistry
+	bots *botCaller
 }
 
 // NewService creates a new message service
-func NewService(repo Repository, wsService websocket.Service) Service {
-	return &service{
+func NewService(repo Repository, wsService websocket.Service, conversationService conversation.Service, moderationService moderation.Service, emojiService emoji.Service) Service {
+	s := &service{
 		repo: repo,
 		wsService: wsService,
+		conversationService: conversationService,
+		moderationService: moderationService,
+		emojiService: emojiService,
+		unfurler: newUnfurler(repo, wsService),
+		commands: NewCommandRegistry(),
+		bots: newBotCaller(),
 	}
+	s.registerBuiltinCommands()
+	return s
 }
 
 // Messages
 
 func (s *service) CreateMessage(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest) (*MessageResponse, error) {
+	if err := validateClientMessageID(req.ClientMessageID); err != nil {
+		return nil, err
+	}
+	return s.createMessage(ctx, userID, conversationID, req, nil)
+}
+
+// createMessage validates, moderates, stores and broadcasts a new message.
+// scheduled is set when the dispatcher delivers a scheduled message on the sender's behalf.
+func (s *service) createMessage(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest, scheduled *ScheduledMessage) (*MessageResponse, error) {
 	// Validate conversation access
 	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
 		return nil, err
 	}
 
+	// Direct messages are not delivered while either user blocks the other
+	if err := s.conversationService.ValidateNotBlocked(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
 	// Validate reply message if provided
 	if req.ReplyToID != nil {
 		exists, err := s.repo.CheckMessageExists(ctx, *req.ReplyToID)
@@ -42,35 +87,220 @@ func (s *service) CreateMessage(ctx context.Context, userID, conversationID uint
 		}
 	}
 
+	// A retried send returns the message stored by the first attempt
+	clientMessageID := strings.TrimSpace(req.ClientMessageID)
+	if clientMessageID != "" {
+		existing, err := s.findClientMessage(ctx, userID, conversationID, clientMessageID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return existing, nil
+		}
+	}
+
+	// Validate attachments
+	var attachments []file.File
+	if len(req.AttachmentIDs) > 0 {
+		if req.MessageType == MessageTypePoll || req.MessageType == MessageTypeSystem || req.MessageType == MessageTypeLocation || req.MessageType == MessageTypeSticker {
+			return nil, fmt.Errorf("%s messages cannot have attachments", req.MessageType)
+		}
+		var err error
+		attachments, err = s.loadAttachments(ctx, userID, req.AttachmentIDs)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if req.MessageType == MessageTypeVoice {
+		if err := validateVoiceAttachments(attachments); err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate location
+	var location *Location
+	if req.MessageType == MessageTypeLocation {
+		var err error
+		location, err = buildLocation(userID, conversationID, req.Location)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Validate sticker
+	var sticker *emoji.Sticker
+	if req.MessageType == MessageTypeSticker {
+		if req.StickerID == nil {
+			return nil, errors.New("sticker messages need a sticker_id")
+		}
+		if strings.TrimSpace(req.Content) != "" {
+			return nil, errors.New("sticker messages cannot have text")
+		}
+		var err error
+		sticker, err = s.emojiService.GetUsableSticker(ctx, userID, *req.StickerID)
+		if err != nil {
+			return nil, err
+		}
+	} else if req.StickerID != nil {
+		return nil, errors.New("sticker_id is only allowed on sticker messages")
+	}
+
+	// Run the moderation pipeline before anything is stored
+	rawContent := unescapeSlashCommand(req.MessageType, req.Content)
+	if location != nil && strings.TrimSpace(rawContent) == "" {
+		rawContent = locationText(location)
+	}
+	if sticker != nil {
+		rawContent = stickerText(sticker)
+	}
+	verdict, err := s.moderateContent(ctx, userID, conversationID, rawContent)
+	if err != nil {
+		return nil, err
+	}
+	snapshot := rawContent
+	if location != nil && location.Label != "" {
+		// A label used as the text was moderated with it
+		if location.Label == rawContent {
+			location.Label = verdict.Content
+		} else {
+			labelVerdict, err := s.moderateContent(ctx, userID, conversationID, location.Label)
+			if err != nil {
+				return nil, err
+			}
+			verdict.Merge(labelVerdict)
+			snapshot += "\n" + location.Label
+			location.Label = labelVerdict.Content
+		}
+	}
+	pollReq := req.Poll
+	if req.MessageType == MessageTypePoll && req.Poll != nil {
+		pollReq, err = s.moderatePoll(ctx, userID, conversationID, req.Poll, verdict)
+		if err != nil {
+			return nil, err
+		}
+		snapshot = strings.Join(append([]string{req.Poll.Question}, req.Poll.Options...), "\n")
+	}
+
+	// Apply the conversation's disappearing message timer
+	expiresAt, err := s.messageExpiry(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse formatting into plain text plus entities
+	content, entities, err := formatContent(req.MessageType, verdict.Content, req.ParseMode)
+	if err != nil {
+		return nil, err
+	}
+	entities = s.addCustomEmojiEntities(ctx, userID, req.MessageType, content, entities)
+
+	newMessage := &Message{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        content,
+		Entities:       entities,
+		MessageType:    req.MessageType,
+		FileURL:        req.FileURL,
+		FileName:       req.FileName,
+		FileSize:       req.FileSize,
+		ReplyToID:      req.ReplyToID,
+		ExpiresAt:      expiresAt,
+	}
+	if sticker != nil {
+		newMessage.StickerID = &sticker.ID
+	}
+	if clientMessageID != "" {
+		newMessage.ClientMessageID = &clientMessageID
+	}
+	if verdict.Hidden() {
+		now := time.Now().UTC()
+		newMessage.HiddenAt = &now
+
+		// Only the sender sees a shadow-hidden message, so there is no one to stream to
+		if location != nil {
+			location.LiveUntil = nil
+		}
+	}
+
+	// Keep the legacy single-file fields pointing at the first attachment for older clients
+	if len(attachments) > 0 && newMessage.FileURL == "" {
+		newMessage.FileURL = fmt.Sprintf("/api/files/%d/download", attachments[0].ID)
+		newMessage.FileName = attachments[0].OriginalName
+		newMessage.FileSize = attachments[0].FileSize
+	}
+
 	// Create message
-	message, err := s.repo.CreateMessage(ctx, conversationID, userID, req)
+	var message *Message
+	if req.MessageType == MessageTypePoll {
+		poll, err := buildPoll(userID, conversationID, pollReq)
+		if err != nil {
+			return nil, err
+		}
+		newMessage.Content = poll.Question
+		message, err = s.repo.CreatePollMessage(ctx, newMessage, poll)
+	} else if location != nil {
+		message, err = s.repo.CreateLocationMessage(ctx, newMessage, location)
+	} else if len(attachments) > 0 {
+		fileIDs := make([]uint, len(attachments))
+		for i := range attachments {
+			fileIDs[i] = attachments[i].ID
+		}
+		message, err = s.repo.CreateMessageWithAttachments(ctx, newMessage, fileIDs)
+	} else {
+		message, err = s.repo.CreateMessage(ctx, newMessage)
+	}
 	if err != nil {
+		// A concurrent retry may have inserted the same client id first
+		if clientMessageID != "" {
+			if existing, findErr := s.findClientMessage(ctx, userID, conversationID, clientMessageID); findErr == nil && existing != nil {
+				return existing, nil
+			}
+		}
 		return nil, err
 	}
 
+	// Queue flagged and shadow-hidden messages for moderators
+	if err := s.moderationService.RecordFlag(ctx, message.ID, conversationID, userID, snapshot, verdict); err != nil {
+		logger.Error("Failed to queue message for moderation", zap.Uint("message_id", message.ID), zap.Error(err))
+	}
+
+	// Record @mentions; they count once the message is visible
+	if message.MessageType != MessageTypeSystem {
+		if err := s.repo.SaveMentions(ctx, message, parseMentions(message.Content)); err != nil {
+			logger.Error("Failed to record mentions", zap.Uint("message_id", message.ID), zap.Error(err))
+		}
+	}
+
+	// The draft has been sent; a scheduled delivery leaves whatever the user is typing now alone.
+	// DeleteDraft syncs the cleared draft to the sender's other devices.
+	if scheduled == nil {
+		if err := s.conversationService.DeleteDraft(ctx, userID, conversationID); err != nil {
+			logger.Error("Failed to clear draft after send", zap.Uint("conversation_id", conversationID), zap.Error(err))
+		}
+	}
+
 	// Build response
-	response := s.buildMessageResponse(ctx, message)
+	response := s.buildMessageResponse(ctx, message, 0)
+	response.Status = DeliveryStatusSent
+	if scheduled != nil {
+		// Echo the id the client gave when scheduling rather than the internal delivery key
+		response.ClientMessageID = ""
+		if scheduled.ClientMessageID != nil {
+			response.ClientMessageID = *scheduled.ClientMessageID
+		}
+	}
+
+	// A shadow-hidden message only reaches its sender
+	if message.HiddenAt != nil {
+		s.broadcastHiddenMessage(userID, conversationID, response)
+		return response, nil
+	}
 
 	// Broadcast real-time message to conversation participants
-	go func() {
-		// Convert MessageResponse to map for WebSocket
-		messageData := map[string]interface{}{
-			"id":              response.ID,
-			"sender_id":       response.SenderID,
-			"sender_name":     response.Sender.Username,
-			"content":         response.Content,
-			"message_type":    response.MessageType,
-			"created_at":      response.CreatedAt,
-			"updated_at":      response.UpdatedAt,
-		}
-		
-		logger.Info("Broadcasting new message", 
-			zap.Uint("conversation_id", conversationID),
-			zap.Uint("message_id", response.ID),
-			zap.String("content", response.Content))
-		
-		s.wsService.HandleNewMessage(context.Background(), conversationID, messageData)
-	}()
+	s.broadcastNewMessage(conversationID, response)
+
+	// Unfurl links in the background
+	s.scheduleLinkPreviews(ctx, conversationID, message)
 
 	return response, nil
 }
@@ -86,9 +316,14 @@ func (s *service) GetMessage(ctx context.Context, userID, messageID uint) (*Mess
 	if err := s.ValidateConversationAccess(ctx, userID, message.ConversationID); err != nil {
 		return nil, err
 	}
+	if !visibleTo(message, userID) {
+		return nil, errors.New("message not found")
+	}
 
 	// Build response
-	return s.buildMessageResponse(ctx, message), nil
+	response := s.buildMessageResponse(ctx, message, userID)
+	s.applyDeliveryStatus(ctx, userID, message.ConversationID, []*MessageResponse{response})
+	return response, nil
 }
 
 func (s *service) GetMessages(ctx context.Context, userID, conversationID uint, limit, offset int) (*MessageListResponse, error) {
@@ -106,13 +341,13 @@ func (s *service) GetMessages(ctx context.Context, userID, conversationID uint,
 	}
 
 	// Get messages
-	messages, err := s.repo.GetMessages(ctx, conversationID, limit, offset)
+	messages, err := s.repo.GetMessages(ctx, conversationID, userID, limit, offset)
 	if err != nil {
 		return nil, err
 	}
 
 	// Get total count
-	total, err := s.repo.GetMessageCount(ctx, conversationID)
+	total, err := s.repo.GetMessageCount(ctx, conversationID, userID)
 	if err != nil {
 		return nil, err
 	}
@@ -120,8 +355,9 @@ func (s *service) GetMessages(ctx context.Context, userID, conversationID uint,
 	// Build response
 	var messageResponses []MessageResponse
 	for _, msg := range messages {
-		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg))
+		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg, userID))
 	}
+	s.applyDeliveryStatus(ctx, userID, conversationID, responsePointers(messageResponses))
 
 	hasMore := offset+limit < total
 
@@ -147,7 +383,7 @@ func (s *service) GetMessagesBefore(ctx context.Context, userID, conversationID
 	}
 
 	// Get messages
-	messages, err := s.repo.GetMessagesBefore(ctx, conversationID, beforeID, limit)
+	messages, err := s.repo.GetMessagesBefore(ctx, conversationID, userID, beforeID, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -155,8 +391,9 @@ func (s *service) GetMessagesBefore(ctx context.Context, userID, conversationID
 	// Build response
 	var messageResponses []MessageResponse
 	for _, msg := range messages {
-		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg))
+		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg, userID))
 	}
+	s.applyDeliveryStatus(ctx, userID, conversationID, responsePointers(messageResponses))
 
 	hasMore := len(messages) == limit
 
@@ -173,8 +410,46 @@ func (s *service) UpdateMessage(ctx context.Context, userID, messageID uint, req
 		return err
 	}
 
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if message.MessageType == MessageTypePoll {
+		return errors.New("polls cannot be edited")
+	}
+	if message.MessageType == MessageTypeLocation {
+		return errors.New("location messages cannot be edited")
+	}
+	if message.MessageType == MessageTypeSticker {
+		return errors.New("sticker messages cannot be edited")
+	}
+
+	// Edits go through the same moderation pipeline as new messages
+	verdict, err := s.moderateContent(ctx, userID, message.ConversationID, req.Content)
+	if err != nil {
+		return err
+	}
+
+	// Parse formatting into plain text plus entities
+	content, entities, err := formatContent(message.MessageType, verdict.Content, req.ParseMode)
+	if err != nil {
+		return err
+	}
+	entities = s.addCustomEmojiEntities(ctx, userID, message.MessageType, content, entities)
+
 	// Update message
-	return s.repo.UpdateMessage(ctx, messageID, req.Content)
+	if err := s.repo.UpdateMessage(ctx, messageID, content, entities); err != nil {
+		return err
+	}
+	if verdict.Hidden() && message.HiddenAt == nil {
+		if err := s.repo.HideMessage(ctx, messageID); err != nil {
+			return err
+		}
+	}
+	if err := s.moderationService.RecordFlag(ctx, messageID, message.ConversationID, userID, req.Content, verdict); err != nil {
+		logger.Error("Failed to queue message for moderation", zap.Uint("message_id", messageID), zap.Error(err))
+	}
+	return nil
 }
 
 func (s *service) DeleteMessage(ctx context.Context, userID, messageID uint) error {
@@ -183,8 +458,28 @@ func (s *service) DeleteMessage(ctx context.Context, userID, messageID uint) err
 		return err
 	}
 
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	// Attachments cascade with the message, so look up their objects first
+	files, err := s.repo.GetMessageFiles(ctx, []uint{messageID})
+	if err != nil {
+		return err
+	}
+
 	// Delete message
-	return s.repo.DeleteMessage(ctx, messageID)
+	if err := s.repo.DeleteMessage(ctx, messageID); err != nil {
+		return err
+	}
+	file.DeleteStoredObjects(ctx, s.repo, files)
+
+	s.wsService.HandleMessageDeleted(ctx, message.ConversationID, messageID)
+
+	// Deleting an unread message lowers the other participants' counters
+	go s.conversationService.NotifyUnreadChanged(context.Background(), message.ConversationID, userID)
+	return nil
 }
 
 func (s *service) SearchMessages(ctx context.Context, userID, conversationID uint, req *SearchMessagesRequest) (*MessageListResponse, error) {
@@ -202,7 +497,7 @@ func (s *service) SearchMessages(ctx context.Context, userID, conversationID uin
 	}
 
 	// Search messages
-	messages, err := s.repo.SearchMessages(ctx, conversationID, req.Query, req.Limit, req.Offset)
+	messages, err := s.repo.SearchMessages(ctx, conversationID, userID, req.Query, req.Limit, req.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -210,8 +505,9 @@ func (s *service) SearchMessages(ctx context.Context, userID, conversationID uin
 	// Build response
 	var messageResponses []MessageResponse
 	for _, msg := range messages {
-		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg))
+		messageResponses = append(messageResponses, *s.buildMessageResponse(ctx, &msg, userID))
 	}
+	s.applyDeliveryStatus(ctx, userID, conversationID, responsePointers(messageResponses))
 
 	return &MessageListResponse{
 		Messages: messageResponses,
@@ -220,6 +516,131 @@ func (s *service) SearchMessages(ctx context.Context, userID, conversationID uin
 	}, nil
 }
 
+func (s *service) ForwardMessage(ctx context.Context, userID, messageID uint, req *ForwardMessageRequest) (*ForwardMessageResponse, error) {
+	// Get source message
+	source, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate access to the source conversation
+	if err := s.ValidateConversationAccess(ctx, userID, source.ConversationID); err != nil {
+		return nil, err
+	}
+	if !visibleTo(source, userID) {
+		return nil, errors.New("message not found")
+	}
+
+	if source.MessageType == MessageTypeSystem || source.MessageType == MessageTypePoll || source.MessageType == MessageTypeLocation {
+		return nil, fmt.Errorf("%s messages cannot be forwarded", source.MessageType)
+	}
+
+	// Stickers follow the same rule as sending: only from workspace packs or the forwarder's own
+	if source.StickerID != nil {
+		if _, err := s.emojiService.GetUsableSticker(ctx, userID, *source.StickerID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check the target limit before any per-target work
+	targetIDs := make([]uint, 0, len(req.ConversationIDs))
+	seen := make(map[uint]bool)
+	for _, conversationID := range req.ConversationIDs {
+		if !seen[conversationID] {
+			seen[conversationID] = true
+			targetIDs = append(targetIDs, conversationID)
+		}
+	}
+	if len(targetIDs) > MaxForwardTargets {
+		return nil, fmt.Errorf("cannot forward to more than %d conversations at once", MaxForwardTargets)
+	}
+
+	// Validate every target before creating anything; each target's moderation rules apply to the copy
+	verdicts := make(map[uint]*moderation.Verdict)
+	for _, conversationID := range targetIDs {
+		if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", conversationID, err)
+		}
+		if err := s.conversationService.ValidateNotBlocked(ctx, userID, conversationID); err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", conversationID, err)
+		}
+		verdict, err := s.moderateContent(ctx, userID, conversationID, source.Content)
+		if err != nil {
+			return nil, fmt.Errorf("conversation %d: %w", conversationID, err)
+		}
+		verdicts[conversationID] = verdict
+	}
+
+	// Re-forwarding keeps pointing at the original message
+	originMessageID := source.ForwardedFromMessageID
+	originSenderID := source.ForwardedFromSenderID
+	originConversationID := source.ForwardedFromConversationID
+	if originMessageID == nil && originSenderID == nil {
+		originMessageID = &source.ID
+		originSenderID = &source.SenderID
+
+		// Direct conversations are private, so only group origins are recorded
+		settings, err := s.repo.GetConversationSettings(ctx, source.ConversationID)
+		if err != nil {
+			return nil, err
+		}
+		if settings.Type == conversation.ConversationTypeGroup {
+			originConversationID = &source.ConversationID
+		}
+	}
+
+	var responses []MessageResponse
+	for _, conversationID := range targetIDs {
+		expiresAt, err := s.messageExpiry(ctx, conversationID)
+		if err != nil {
+			return nil, err
+		}
+
+		verdict := verdicts[conversationID]
+		message := &Message{
+			ConversationID: conversationID,
+			SenderID:       userID,
+			Content:        verdict.Content,
+			Entities:       source.Entities,
+			MessageType:    source.MessageType,
+			FileURL:        source.FileURL,
+			FileName:       source.FileName,
+			FileSize:       source.FileSize,
+			StickerID:      source.StickerID,
+			ExpiresAt:      expiresAt,
+			ForwardedFromMessageID:      originMessageID,
+			ForwardedFromSenderID:       originSenderID,
+			ForwardedFromConversationID: originConversationID,
+		}
+		if verdict.Hidden() {
+			now := time.Now().UTC()
+			message.HiddenAt = &now
+		}
+
+		created, err := s.repo.CreateForwardedMessage(ctx, message, source.ID)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.moderationService.RecordFlag(ctx, created.ID, conversationID, userID, source.Content, verdict); err != nil {
+			logger.Error("Failed to queue message for moderation", zap.Uint("message_id", created.ID), zap.Error(err))
+		}
+
+		response := s.buildMessageResponse(ctx, created, 0)
+		if created.HiddenAt != nil {
+			s.broadcastHiddenMessage(userID, conversationID, response)
+		} else {
+			s.broadcastNewMessage(conversationID, response)
+			s.scheduleLinkPreviews(ctx, conversationID, created)
+		}
+		responses = append(responses, *response)
+	}
+
+	return &ForwardMessageResponse{
+		Messages: responses,
+		Total:    len(responses),
+	}, nil
+}
+
 // Reactions
 
 func (s *service) AddReaction(ctx context.Context, userID, messageID uint, req *AddReactionRequest) error {
@@ -228,124 +649,1653 @@ func (s *service) AddReaction(ctx context.Context, userID, messageID uint, req *
 		return err
 	}
 
-	// Check if reaction already exists
-	existingReaction, err := s.repo.GetUserReaction(ctx, messageID, userID)
+	emoji := req.Emoji
+	if emoji == "" {
+		emoji = req.ReactionType
+	}
+	emoji, err := normalizeReactionEmoji(emoji)
+	if err != nil {
+		return err
+	}
+
+	// Custom emoji must exist and be usable by the reactor
+	if validation.IsCustomEmoji(emoji) {
+		resolved, err := s.emojiService.ResolveEmoji(ctx, userID, []string{emoji})
+		if err != nil {
+			return err
+		}
+		if len(resolved) == 0 {
+			return fmt.Errorf("custom emoji %s not found", emoji)
+		}
+	}
+
+	// Check if this reaction already exists, including rows stored before emoji were normalised
+	for _, variant := range reactionEmojiVariants(emoji) {
+		existingReaction, err := s.repo.GetUserReaction(ctx, messageID, userID, variant)
+		if err != nil {
+			return err
+		}
+		if existingReaction != nil {
+			return errors.New("you already reacted with this emoji")
+		}
+	}
+
+	// Limit the number of different emoji per message
+	emojis, err := s.repo.GetMessageReactionEmojis(ctx, messageID)
 	if err != nil {
 		return err
 	}
-	if existingReaction != nil {
-		return errors.New("user already reacted to this message")
+	distinct := make(map[string]bool, len(emojis))
+	for _, existing := range emojis {
+		distinct[normalizeStoredEmoji(existing)] = true
+	}
+	if !distinct[emoji] && len(distinct) >= MaxDistinctReactions {
+		return fmt.Errorf("a message can have at most %d different reactions", MaxDistinctReactions)
 	}
 
 	// Add reaction
-	_, err = s.repo.AddReaction(ctx, messageID, userID, req.ReactionType)
+	_, err = s.repo.AddReaction(ctx, messageID, userID, emoji)
 	return err
 }
 
-func (s *service) RemoveReaction(ctx context.Context, userID, messageID uint, reactionType string) error {
+func (s *service) RemoveReaction(ctx context.Context, userID, messageID uint, emoji string) error {
 	// Validate message access
 	if err := s.ValidateMessageAccess(ctx, userID, messageID); err != nil {
 		return err
 	}
 
-	// Remove reaction
-	return s.repo.RemoveReaction(ctx, messageID, userID, reactionType)
-}
-
-// Validation methods
-
-func (s *service) ValidateConversationAccess(ctx context.Context, userID, conversationID uint) error {
-	isParticipant, err := s.repo.CheckUserInConversation(ctx, conversationID, userID)
+	emoji, err := normalizeReactionEmoji(emoji)
 	if err != nil {
 		return err
 	}
-	if !isParticipant {
-		return errors.New("access denied: not a participant")
+
+	// Remove reaction under every stored spelling of the emoji
+	for _, variant := range reactionEmojiVariants(emoji) {
+		if err := s.repo.RemoveReaction(ctx, messageID, userID, variant); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-func (s *service) ValidateMessageAccess(ctx context.Context, userID, messageID uint) error {
-	// Get message to check conversation
+// Receipts
+
+func (s *service) GetMessageReceipts(ctx context.Context, userID, messageID uint) (*MessageReceiptsResponse, error) {
+	// Get message
 	message, err := s.repo.GetMessageByID(ctx, messageID)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Validate conversation access
-	return s.ValidateConversationAccess(ctx, userID, message.ConversationID)
-}
-
-func (s *service) ValidateMessageSender(ctx context.Context, userID, messageID uint) error {
-	// Validate message access first
-	if err := s.ValidateMessageAccess(ctx, userID, messageID); err != nil {
-		return err
+	if err := s.ValidateConversationAccess(ctx, userID, message.ConversationID); err != nil {
+		return nil, err
+	}
+	if !visibleTo(message, userID) {
+		return nil, errors.New("message not found")
 	}
 
-	// Check if user is the sender
-	isSender, err := s.repo.CheckMessageSender(ctx, messageID, userID)
+	pointers, err := s.repo.GetReceiptPointers(ctx, message.ConversationID)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if !isSender {
-		return errors.New("access denied: not the message sender")
+	if len(pointers) > MaxReceiptGroupSize {
+		return nil, fmt.Errorf("read receipts are only available in conversations with up to %d members", MaxReceiptGroupSize)
 	}
-	return nil
-}
 
-// Helper methods
-
-func (s *service) buildMessageResponse(ctx context.Context, message *Message) *MessageResponse {
-	// Build reactions response
-	var reactions []MessageReactionResponse
-	for _, r := range message.Reactions {
-		reactions = append(reactions, MessageReactionResponse{
-			ID:           r.ID,
-			ReactionType: r.ReactionType,
-			User: user.UserResponse{
-				ID:           r.User.ID,
-				Username:     r.User.Username,
-				Email:        r.User.Email,
-				DisplayName:  r.User.DisplayName,
-				Bio:          r.User.Bio,
-				Avatar:       r.User.Avatar,
-				IsPublic:     r.User.IsPublic,
-				LastLogin:    r.User.LastLogin,
-				CreatedAt:    r.User.CreatedAt,
-				UpdatedAt:    r.User.UpdatedAt,
-			},
-			CreatedAt: r.CreatedAt,
-		})
+	response := &MessageReceiptsResponse{
+		MessageID:   message.ID,
+		Status:      deliveryStatus(message, pointers),
+		ReadBy:      make([]UserSummaryResponse, 0),
+		DeliveredTo: make([]UserSummaryResponse, 0),
+		Pending:     make([]UserSummaryResponse, 0),
 	}
-
-	// Build reply response if exists
-	var replyTo *MessageResponse
-	if message.ReplyTo != nil {
-		replyTo = &MessageResponse{
-			ID:          message.ReplyTo.ID,
-			Content:     message.ReplyTo.Content,
-			MessageType: message.ReplyTo.MessageType,
-			SenderID:    message.ReplyTo.SenderID,
-			Sender: user.UserResponse{
-				ID:           message.ReplyTo.Sender.ID,
-				Username:     message.ReplyTo.Sender.Username,
-				Email:        message.ReplyTo.Sender.Email,
-				DisplayName:  message.ReplyTo.Sender.DisplayName,
-				Bio:          message.ReplyTo.Sender.Bio,
-				Avatar:       message.ReplyTo.Sender.Avatar,
-				IsPublic:     message.ReplyTo.Sender.IsPublic,
-				LastLogin:    message.ReplyTo.Sender.LastLogin,
-				CreatedAt:    message.ReplyTo.Sender.CreatedAt,
-				UpdatedAt:    message.ReplyTo.Sender.UpdatedAt,
-			},
-			IsEdited:  message.ReplyTo.IsEdited,
-			CreatedAt: message.ReplyTo.CreatedAt,
+	for _, pointer := range pointers {
+		if !awaitsReceipt(message, pointer) {
+			continue
+		}
+		switch {
+		case pointerReached(pointer.LastReadMessageID, message.ID):
+			response.ReadBy = append(response.ReadBy, buildUserSummary(pointer.User))
+		case pointerReached(pointer.LastDeliveredMessageID, message.ID):
+			response.DeliveredTo = append(response.DeliveredTo, buildUserSummary(pointer.User))
+		default:
+			response.Pending = append(response.Pending, buildUserSummary(pointer.User))
 		}
 	}
 
-	return &MessageResponse{
+	return response, nil
+}
+
+// Bookmarks
+
+func (s *service) BookmarkMessage(ctx context.Context, userID, messageID uint, req *BookmarkMessageRequest) (*BookmarkResponse, error) {
+	// Get message
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, message.ConversationID); err != nil {
+		return nil, err
+	}
+	if !visibleTo(message, userID) {
+		return nil, errors.New("message not found")
+	}
+
+	label := strings.TrimSpace(req.Label)
+	if utf8.RuneCountInString(label) > MaxBookmarkLabelLength {
+		return nil, fmt.Errorf("bookmark label cannot be longer than %d characters", MaxBookmarkLabelLength)
+	}
+
+	var remindAt *time.Time
+	if req.RemindAt != nil {
+		now := time.Now()
+		if !req.RemindAt.After(now) {
+			return nil, errors.New("reminder time must be in the future")
+		}
+		if req.RemindAt.After(now.Add(MaxBookmarkReminderDelay)) {
+			return nil, errors.New("reminder time cannot be more than a year ahead")
+		}
+		utc := req.RemindAt.UTC()
+		remindAt = &utc
+	}
+
+	bookmark, err := s.repo.UpsertBookmark(ctx, &MessageBookmark{
+		UserID:    userID,
+		MessageID: messageID,
+		Label:     label,
+		RemindAt:  remindAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	bookmark.Message = *message
+
+	return s.buildBookmarkResponse(ctx, bookmark), nil
+}
+
+func (s *service) RemoveBookmark(ctx context.Context, userID, messageID uint) error {
+	deleted, err := s.repo.DeleteBookmark(ctx, userID, messageID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("bookmark not found")
+	}
+	return nil
+}
+
+func (s *service) GetBookmarks(ctx context.Context, userID uint, label string, cursor uint, limit int) (*BookmarkListResponse, error) {
+	// Set default limit
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	// Fetch one extra bookmark to know whether there is another page
+	bookmarks, err := s.repo.GetUserBookmarks(ctx, userID, strings.TrimSpace(label), cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	hasMore := len(bookmarks) > limit
+	if hasMore {
+		bookmarks = bookmarks[:limit]
+	}
+
+	// Build response
+	bookmarkResponses := make([]BookmarkResponse, 0, len(bookmarks))
+	for i := range bookmarks {
+		bookmarkResponses = append(bookmarkResponses, *s.buildBookmarkResponse(ctx, &bookmarks[i]))
+	}
+
+	var nextCursor *uint
+	if hasMore {
+		nextCursor = &bookmarks[len(bookmarks)-1].ID
+	}
+
+	return &BookmarkListResponse{
+		Bookmarks:  bookmarkResponses,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	}, nil
+}
+
+func (s *service) GetBookmarkLabels(ctx context.Context, userID uint) (*BookmarkLabelListResponse, error) {
+	labels, err := s.repo.GetBookmarkLabels(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if labels == nil {
+		labels = []BookmarkLabelCount{}
+	}
+	return &BookmarkLabelListResponse{Labels: labels}, nil
+}
+
+// SendBookmarkReminder notifies the user about a bookmark whose reminder is due,
+// unless they can no longer see the message
+func (s *service) SendBookmarkReminder(ctx context.Context, bookmark *MessageBookmark) error {
+	message, err := s.repo.GetMessageByID(ctx, bookmark.MessageID)
+	if err != nil {
+		return err
+	}
+
+	isParticipant, err := s.repo.CheckUserInConversation(ctx, message.ConversationID, bookmark.UserID)
+	if err != nil {
+		return err
+	}
+	if !isParticipant || !visibleTo(message, bookmark.UserID) {
+		logger.Info("Skipping bookmark reminder for inaccessible message",
+			zap.Uint("bookmark_id", bookmark.ID),
+			zap.Uint("user_id", bookmark.UserID))
+		return nil
+	}
+
+	bookmark.Message = *message
+	s.wsService.HandleBookmarkReminder(ctx, bookmark.UserID, map[string]interface{}{
+		"bookmark": s.buildBookmarkResponse(ctx, bookmark),
+	})
+	return nil
+}
+
+// Scheduled messages
+
+func (s *service) ScheduleMessage(ctx context.Context, userID, conversationID uint, req *CreateMessageRequest) (*ScheduledMessageResponse, error) {
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	if req.ScheduledAt == nil || !req.ScheduledAt.After(time.Now()) {
+		return nil, errors.New("scheduled time must be in the future")
+	}
+
+	if req.MessageType == MessageTypePoll {
+		return nil, errors.New("polls cannot be scheduled")
+	}
+
+	if req.MessageType == MessageTypeLocation {
+		return nil, errors.New("location messages cannot be scheduled")
+	}
+
+	if req.MessageType == MessageTypeSticker {
+		return nil, errors.New("sticker messages cannot be scheduled")
+	}
+
+	if IsSlashCommand(req) {
+		return nil, errors.New("slash commands cannot be scheduled")
+	}
+
+	if len(req.AttachmentIDs) > 0 {
+		return nil, errors.New("messages with attachments cannot be scheduled")
+	}
+
+	// Validate reply message if provided
+	if req.ReplyToID != nil {
+		exists, err := s.repo.CheckMessageExists(ctx, *req.ReplyToID)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			return nil, errors.New("reply message not found")
+		}
+	}
+
+	// A retried request returns the message scheduled by the first attempt
+	clientMessageID := strings.TrimSpace(req.ClientMessageID)
+	if err := validateClientMessageID(clientMessageID); err != nil {
+		return nil, err
+	}
+	if clientMessageID != "" {
+		existing, err := s.repo.GetScheduledMessageByClientID(ctx, conversationID, userID, clientMessageID)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil {
+			return buildScheduledMessageResponse(existing), nil
+		}
+	}
+
+	// Create scheduled message
+	scheduled := &ScheduledMessage{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        req.Content,
+		MessageType:    req.MessageType,
+		FileURL:        req.FileURL,
+		FileName:       req.FileName,
+		FileSize:       req.FileSize,
+		ReplyToID:      req.ReplyToID,
+		ParseMode:      ParseModeMarkdown,
+		Kind:           ScheduledKindMessage,
+		ScheduledAt:    req.ScheduledAt.UTC(),
+		Status:         ScheduledStatusPending,
+	}
+	if req.ParseMode != "" {
+		scheduled.ParseMode = req.ParseMode
+	}
+	if clientMessageID != "" {
+		scheduled.ClientMessageID = &clientMessageID
+	}
+	if err := s.repo.CreateScheduledMessage(ctx, scheduled); err != nil {
+		return nil, err
+	}
+
+	return buildScheduledMessageResponse(scheduled), nil
+}
+
+func (s *service) GetScheduledMessages(ctx context.Context, userID uint, conversationID *uint) (*ScheduledMessageListResponse, error) {
+	// Validate conversation access if filtering by conversation
+	if conversationID != nil {
+		if err := s.ValidateConversationAccess(ctx, userID, *conversationID); err != nil {
+			return nil, err
+		}
+	}
+
+	scheduled, err := s.repo.GetUserScheduledMessages(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]ScheduledMessageResponse, 0, len(scheduled))
+	for i := range scheduled {
+		responses = append(responses, *buildScheduledMessageResponse(&scheduled[i]))
+	}
+
+	return &ScheduledMessageListResponse{
+		ScheduledMessages: responses,
+		Total:             len(responses),
+	}, nil
+}
+
+func (s *service) UpdateScheduledMessage(ctx context.Context, userID, scheduledID uint, req *UpdateScheduledMessageRequest) (*ScheduledMessageResponse, error) {
+	// Validate author
+	existing, err := s.getOwnScheduledMessage(ctx, userID, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	updates := make(map[string]interface{})
+	if req.Content != nil {
+		if strings.TrimSpace(*req.Content) == "" {
+			return nil, errors.New("content cannot be empty")
+		}
+		if existing.Kind == ScheduledKindMessage && existing.MessageType == MessageTypeText && slashCommandRegex.MatchString(strings.TrimSpace(*req.Content)) {
+			return nil, errors.New("slash commands cannot be scheduled")
+		}
+		updates["content"] = *req.Content
+	}
+	if req.ScheduledAt != nil {
+		if !req.ScheduledAt.After(time.Now()) {
+			return nil, errors.New("scheduled time must be in the future")
+		}
+		updates["scheduled_at"] = req.ScheduledAt.UTC()
+	}
+	if len(updates) == 0 {
+		return nil, errors.New("nothing to update")
+	}
+
+	// Only pending messages can be edited
+	updated, err := s.repo.UpdatePendingScheduledMessage(ctx, scheduledID, updates)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, errors.New("scheduled message is no longer pending")
+	}
+
+	scheduled, err := s.repo.GetScheduledMessageByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildScheduledMessageResponse(scheduled), nil
+}
+
+func (s *service) CancelScheduledMessage(ctx context.Context, userID, scheduledID uint) error {
+	// Validate author
+	if _, err := s.getOwnScheduledMessage(ctx, userID, scheduledID); err != nil {
+		return err
+	}
+
+	// Only pending messages can be cancelled
+	cancelled, err := s.repo.UpdatePendingScheduledMessage(ctx, scheduledID, map[string]interface{}{
+		"status": ScheduledStatusCancelled,
+	})
+	if err != nil {
+		return err
+	}
+	if !cancelled {
+		return errors.New("scheduled message is no longer pending")
+	}
+
+	return nil
+}
+
+// SendScheduledMessage posts a due scheduled message through the normal create path.
+// The internal client id makes a redelivery after a crash between create and mark-sent return the first message.
+func (s *service) SendScheduledMessage(ctx context.Context, scheduled *ScheduledMessage) (*MessageResponse, error) {
+	req := &CreateMessageRequest{
+		Content:         scheduled.Content,
+		MessageType:     scheduled.MessageType,
+		FileURL:         scheduled.FileURL,
+		FileName:        scheduled.FileName,
+		FileSize:        scheduled.FileSize,
+		ReplyToID:       scheduled.ReplyToID,
+		ParseMode:       scheduled.ParseMode,
+		ClientMessageID: fmt.Sprintf("%s%d", scheduledClientIDPrefix, scheduled.ID),
+	}
+	return s.createMessage(ctx, scheduled.SenderID, scheduled.ConversationID, req, scheduled)
+}
+
+func (s *service) SendReminder(ctx context.Context, reminder *ScheduledMessage) error {
+	// Reminders are dropped once the user has left the conversation
+	if err := s.ValidateConversationAccess(ctx, reminder.SenderID, reminder.ConversationID); err != nil {
+		return err
+	}
+
+	s.wsService.HandleEphemeralMessage(ctx, reminder.SenderID, map[string]interface{}{
+		"conversation_id": reminder.ConversationID,
+		"kind":            ScheduledKindReminder,
+		"scheduled_id":    reminder.ID,
+		"text":            "⏰ Reminder: " + reminder.Content,
+	})
+	return nil
+}
+
+// Polls
+
+func (s *service) GetPoll(ctx context.Context, userID, messageID uint) (*PollResponse, error) {
+	_, poll, err := s.getPollForUser(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildPollResponse(poll, userID), nil
+}
+
+func (s *service) VotePoll(ctx context.Context, userID, messageID uint, req *VotePollRequest) (*PollResponse, error) {
+	message, poll, err := s.getPollForUser(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.IsClosed(time.Now().UTC()) {
+		return nil, errors.New("poll is closed")
+	}
+
+	// Validate options
+	validOptions := make(map[uint]bool)
+	for _, option := range poll.Options {
+		validOptions[option.ID] = true
+	}
+	var optionIDs []uint
+	seen := make(map[uint]bool)
+	for _, optionID := range req.OptionIDs {
+		if !validOptions[optionID] {
+			return nil, fmt.Errorf("option %d does not belong to this poll", optionID)
+		}
+		if !seen[optionID] {
+			seen[optionID] = true
+			optionIDs = append(optionIDs, optionID)
+		}
+	}
+	if !poll.AllowsMultiple && len(optionIDs) > 1 {
+		return nil, errors.New("this poll only allows a single choice")
+	}
+
+	// Record vote (replaces any previous vote)
+	if err := s.repo.ReplacePollVotes(ctx, poll.ID, userID, optionIDs); err != nil {
+		return nil, err
+	}
+
+	return s.reloadAndBroadcastPoll(ctx, message, userID)
+}
+
+func (s *service) RetractPollVote(ctx context.Context, userID, messageID uint) (*PollResponse, error) {
+	message, poll, err := s.getPollForUser(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	if poll.IsClosed(time.Now().UTC()) {
+		return nil, errors.New("poll is closed")
+	}
+
+	if err := s.repo.DeletePollVotes(ctx, poll.ID, userID); err != nil {
+		return nil, err
+	}
+
+	return s.reloadAndBroadcastPoll(ctx, message, userID)
+}
+
+func (s *service) ClosePoll(ctx context.Context, userID, messageID uint) (*PollResponse, error) {
+	message, poll, err := s.getPollForUser(ctx, userID, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Only the creator or a conversation admin may close a poll early
+	if poll.CreatedBy != userID {
+		if err := s.conversationService.ValidateConversationAdmin(ctx, userID, message.ConversationID); err != nil {
+			return nil, errors.New("access denied: only the poll creator or an admin can close this poll")
+		}
+	}
+
+	now := time.Now().UTC()
+	if poll.IsClosed(now) {
+		return nil, errors.New("poll is already closed")
+	}
+
+	closed, err := s.repo.ClosePoll(ctx, poll.ID, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, errors.New("poll is already closed")
+	}
+
+	return s.reloadAndBroadcastPoll(ctx, message, userID)
+}
+
+// Live locations
+
+// UpdateLiveLocation moves a live location and pushes the new position to the conversation
+func (s *service) UpdateLiveLocation(ctx context.Context, userID, messageID uint, latitude, longitude float64, accuracy *float64) error {
+	if err := validateCoordinates(latitude, longitude, accuracy); err != nil {
+		return err
+	}
+
+	location, err := s.repo.GetLocationByMessageID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if location.UserID != userID {
+		return errors.New("only the sharer can update a live location")
+	}
+
+	now := time.Now().UTC()
+	if !location.IsLive(now) {
+		return errors.New("live location has ended")
+	}
+
+	// Participants who left stop sharing with the conversation
+	if err := s.ValidateConversationAccess(ctx, userID, location.ConversationID); err != nil {
+		return err
+	}
+
+	updated, ok, err := s.repo.UpdateLiveLocation(ctx, messageID, userID, latitude, longitude, accuracy, now, now.Add(-MinLiveLocationInterval))
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("location updates are limited to one every %s", MinLiveLocationInterval)
+	}
+
+	s.broadcastLocation(updated)
+	return nil
+}
+
+// StopLiveLocation ends live sharing; the message keeps the last known position
+func (s *service) StopLiveLocation(ctx context.Context, userID, messageID uint) error {
+	location, err := s.repo.GetLocationByMessageID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+	if location.UserID != userID {
+		return errors.New("only the sharer can stop a live location")
+	}
+
+	ended, ok, err := s.repo.EndLiveLocation(ctx, messageID, userID, time.Now().UTC())
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("live location has already ended")
+	}
+
+	s.broadcastLocation(ended)
+	return nil
+}
+
+// broadcastLocation pushes a location's position and live state to the conversation
+func (s *service) broadcastLocation(location *Location) {
+	go s.wsService.HandleLocationUpdated(context.Background(), location.ConversationID, map[string]interface{}{
+		"message_id": location.MessageID,
+		"location":   buildLocationResponse(location, time.Now().UTC()),
+	})
+}
+
+// Pins
+
+func (s *service) PinMessage(ctx context.Context, userID, messageID uint) error {
+	// Get message to find its conversation
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	// Validate pin permission
+	if err := s.ValidatePinPermission(ctx, userID, message.ConversationID); err != nil {
+		return err
+	}
+
+	// A shadow-hidden message does not exist for anyone but its sender
+	if !visibleTo(message, userID) {
+		return errors.New("message not found")
+	}
+	if message.MessageType == MessageTypeSystem {
+		return errors.New("system messages cannot be pinned")
+	}
+	if message.HiddenAt != nil {
+		return errors.New("this message cannot be pinned")
+	}
+
+	// Check if message is already pinned
+	isPinned, err := s.repo.CheckMessagePinned(ctx, message.ConversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if isPinned {
+		return errors.New("message is already pinned")
+	}
+
+	// Pin message; the repository enforces the pin limit atomically
+	pinned, err := s.repo.PinMessage(ctx, &PinnedMessage{
+		ConversationID: message.ConversationID,
+		MessageID:      messageID,
+		PinnedBy:       &userID,
+	}, MaxPinnedMessages)
+	if err != nil {
+		return err
+	}
+	if !pinned {
+		return fmt.Errorf("pin limit reached: a conversation can have at most %d pinned messages", MaxPinnedMessages)
+	}
+
+	// Record the pin in conversation history
+	if _, err := s.createSystemMessage(ctx, userID, message.ConversationID, "pinned a message"); err != nil {
+		logger.Error("Failed to create pin system message", zap.Error(err))
+	}
+
+	go s.wsService.HandleMessagePinned(context.Background(), message.ConversationID, messageID, userID, true)
+
+	return nil
+}
+
+func (s *service) UnpinMessage(ctx context.Context, userID, messageID uint) error {
+	// Get message to find its conversation
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	// Validate pin permission
+	if err := s.ValidatePinPermission(ctx, userID, message.ConversationID); err != nil {
+		return err
+	}
+
+	// Check if message is pinned
+	isPinned, err := s.repo.CheckMessagePinned(ctx, message.ConversationID, messageID)
+	if err != nil {
+		return err
+	}
+	if !isPinned {
+		return errors.New("message is not pinned")
+	}
+
+	// Unpin message
+	if err := s.repo.UnpinMessage(ctx, message.ConversationID, messageID); err != nil {
+		return err
+	}
+
+	// Record the unpin in conversation history
+	if _, err := s.createSystemMessage(ctx, userID, message.ConversationID, "unpinned a message"); err != nil {
+		logger.Error("Failed to create unpin system message", zap.Error(err))
+	}
+
+	go s.wsService.HandleMessagePinned(context.Background(), message.ConversationID, messageID, userID, false)
+
+	return nil
+}
+
+func (s *service) GetPinnedMessages(ctx context.Context, userID, conversationID uint) (*PinnedMessageListResponse, error) {
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	// Get pinned messages
+	pins, err := s.repo.GetPinnedMessages(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Build response
+	pinResponses := make([]PinnedMessageResponse, 0, len(pins))
+	for _, pin := range pins {
+		pinResponse := PinnedMessageResponse{
+			ID:             pin.ID,
+			ConversationID: pin.ConversationID,
+			Message:        *s.buildMessageResponse(ctx, &pin.Message, userID),
+			PinnedAt:       pin.CreatedAt,
+		}
+		if pin.PinnedByUser != nil {
+			pinnedBy := pin.PinnedByUser.ToResponse()
+			pinResponse.PinnedBy = &pinnedBy
+		}
+		pinResponses = append(pinResponses, pinResponse)
+	}
+
+	return &PinnedMessageListResponse{
+		Pins:  pinResponses,
+		Total: len(pinResponses),
+		Limit: MaxPinnedMessages,
+	}, nil
+}
+
+// Disappearing messages
+
+// UpdateMessageTTL changes a conversation's disappearing message timer and announces it.
+// The announcement inherits the new timer, so it disappears along with the messages it announces.
+func (s *service) UpdateMessageTTL(ctx context.Context, userID, conversationID uint, req *UpdateMessageTTLRequest) error {
+	changed, err := s.conversationService.SetMessageTTL(ctx, userID, conversationID, *req.MessageTTLSeconds)
+	if err != nil || !changed {
+		return err
+	}
+
+	content := "turned off disappearing messages"
+	if *req.MessageTTLSeconds > 0 {
+		content = fmt.Sprintf("set disappearing messages to %s", conversation.MessageTTLLabels[*req.MessageTTLSeconds])
+	}
+	if _, err := s.createSystemMessage(ctx, userID, conversationID, content); err != nil {
+		logger.Error("Failed to create message TTL system message", zap.Error(err))
+	}
+	return nil
+}
+
+// Validation methods
+
+func (s *service) ValidateConversationAccess(ctx context.Context, userID, conversationID uint) error {
+	isParticipant, err := s.repo.CheckUserInConversation(ctx, conversationID, userID)
+	if err != nil {
+		return err
+	}
+	if !isParticipant {
+		return fmt.Errorf("%w: not a participant", conversation.ErrAccessDenied)
+	}
+	return nil
+}
+
+func (s *service) ValidateMessageAccess(ctx context.Context, userID, messageID uint) error {
+	// Get message to check conversation
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return err
+	}
+
+	// Validate conversation access
+	if err := s.ValidateConversationAccess(ctx, userID, message.ConversationID); err != nil {
+		return err
+	}
+	if !visibleTo(message, userID) {
+		return errors.New("message not found")
+	}
+	return nil
+}
+
+func (s *service) ValidateMessageSender(ctx context.Context, userID, messageID uint) error {
+	// Validate message access first
+	if err := s.ValidateMessageAccess(ctx, userID, messageID); err != nil {
+		return err
+	}
+
+	// Check if user is the sender
+	isSender, err := s.repo.CheckMessageSender(ctx, messageID, userID)
+	if err != nil {
+		return err
+	}
+	if !isSender {
+		return fmt.Errorf("%w: not the message sender", conversation.ErrAccessDenied)
+	}
+	return nil
+}
+
+func (s *service) ValidatePinPermission(ctx context.Context, userID, conversationID uint) error {
+	settings, err := s.repo.GetConversationSettings(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	// Group conversations may restrict pinning to admins
+	if settings.Type == conversation.ConversationTypeGroup && settings.OnlyAdminsCanPin {
+		return s.conversationService.ValidateConversationAdmin(ctx, userID, conversationID)
+	}
+
+	return s.ValidateConversationAccess(ctx, userID, conversationID)
+}
+
+// Helper methods
+
+// getOwnScheduledMessage gets a scheduled message and checks the user is its author
+func (s *service) getOwnScheduledMessage(ctx context.Context, userID, scheduledID uint) (*ScheduledMessage, error) {
+	scheduled, err := s.repo.GetScheduledMessageByID(ctx, scheduledID)
+	if err != nil {
+		return nil, err
+	}
+	if scheduled.SenderID != userID {
+		return nil, fmt.Errorf("%w: not the message author", conversation.ErrAccessDenied)
+	}
+	return scheduled, nil
+}
+
+func buildScheduledMessageResponse(scheduled *ScheduledMessage) *ScheduledMessageResponse {
+	return &ScheduledMessageResponse{
+		ID:             scheduled.ID,
+		ConversationID: scheduled.ConversationID,
+		SenderID:       scheduled.SenderID,
+		Content:        scheduled.Content,
+		MessageType:    scheduled.MessageType,
+		FileURL:        scheduled.FileURL,
+		FileName:       scheduled.FileName,
+		FileSize:       scheduled.FileSize,
+		ReplyToID:      scheduled.ReplyToID,
+		Kind:           scheduled.Kind,
+		ScheduledAt:    scheduled.ScheduledAt,
+		Status:         scheduled.Status,
+		LastError:      scheduled.LastError,
+		MessageID:      scheduled.MessageID,
+		SentAt:         scheduled.SentAt,
+		CreatedAt:      scheduled.CreatedAt,
+		UpdatedAt:      scheduled.UpdatedAt,
+	}
+}
+
+// scheduleLinkPreviews starts unfurling the links of a text message unless the conversation opted out
+func (s *service) scheduleLinkPreviews(ctx context.Context, conversationID uint, message *Message) {
+	if s.unfurler == nil || message.MessageType != MessageTypeText {
+		return
+	}
+
+	settings, err := s.repo.GetConversationSettings(ctx, conversationID)
+	if err != nil || !settings.LinkPreviewsEnabled {
+		return
+	}
+
+	// Links hidden behind text are unfurled too
+	content := message.Content
+	for _, entity := range message.Entities {
+		if entity.Type == richtext.EntityTextLink {
+			content += " " + entity.URL
+		}
+	}
+
+	go s.unfurler.unfurl(conversationID, message.ID, content)
+}
+
+// loadAttachments loads the files to attach in request order; they must be the sender's own uploads and not attached yet
+func (s *service) loadAttachments(ctx context.Context, userID uint, fileIDs []uint) ([]file.File, error) {
+	seen := make(map[uint]bool, len(fileIDs))
+	ids := make([]uint, 0, len(fileIDs))
+	for _, id := range fileIDs {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) > MaxAttachmentsPerMessage {
+		return nil, fmt.Errorf("a message can have at most %d attachments", MaxAttachmentsPerMessage)
+	}
+
+	files, err := s.repo.GetFilesByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[uint]file.File, len(files))
+	for _, f := range files {
+		byID[f.ID] = f
+	}
+
+	attachments := make([]file.File, 0, len(ids))
+	for _, id := range ids {
+		f, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("file %d not found", id)
+		}
+		if f.UserID != userID {
+			return nil, fmt.Errorf("%w: file %d was uploaded by another user", conversation.ErrAccessDenied, id)
+		}
+		if f.MessageID != nil {
+			return nil, fmt.Errorf("file %d is already attached to a message", id)
+		}
+		attachments = append(attachments, f)
+	}
+	return attachments, nil
+}
+
+// buildLocation validates a location request and turns it into a location ready to be stored
+func buildLocation(userID, conversationID uint, req *CreateLocationRequest) (*Location, error) {
+	if req == nil || req.Latitude == nil || req.Longitude == nil {
+		return nil, errors.New("location messages need a latitude and longitude")
+	}
+	if err := validateCoordinates(*req.Latitude, *req.Longitude, req.Accuracy); err != nil {
+		return nil, err
+	}
+
+	location := &Location{
+		ConversationID: conversationID,
+		UserID:         userID,
+		Latitude:       *req.Latitude,
+		Longitude:      *req.Longitude,
+		Accuracy:       req.Accuracy,
+		Label:          strings.TrimSpace(req.Label),
+	}
+
+	if req.LiveDurationSeconds != 0 {
+		duration := time.Duration(req.LiveDurationSeconds) * time.Second
+		if duration < MinLiveLocationDuration || duration > MaxLiveLocationDuration {
+			return nil, fmt.Errorf("live location duration must be between %s and %s", MinLiveLocationDuration, MaxLiveLocationDuration)
+		}
+		liveUntil := time.Now().UTC().Add(duration)
+		location.LiveUntil = &liveUntil
+	}
+	return location, nil
+}
+
+// validateCoordinates checks a position is a point on the globe with a sane accuracy radius
+func validateCoordinates(latitude, longitude float64, accuracy *float64) error {
+	if math.IsNaN(latitude) || latitude < -90 || latitude > 90 {
+		return errors.New("latitude must be between -90 and 90")
+	}
+	if math.IsNaN(longitude) || longitude < -180 || longitude > 180 {
+		return errors.New("longitude must be between -180 and 180")
+	}
+	if accuracy != nil && (math.IsNaN(*accuracy) || math.IsInf(*accuracy, 0) || *accuracy < 0) {
+		return errors.New("accuracy must be a non-negative number of meters")
+	}
+	return nil
+}
+
+// locationText is the message text of a location shared without a caption
+func locationText(location *Location) string {
+	if location.Label != "" {
+		return location.Label
+	}
+	if location.LiveUntil != nil {
+		return "📍 Live location"
+	}
+	return "📍 Location"
+}
+
+// buildLocationResponse builds a location response; now decides whether it is still live
+func buildLocationResponse(location *Location, now time.Time) *LocationResponse {
+	return &LocationResponse{
+		MessageID: location.MessageID,
+		Latitude:  location.Latitude,
+		Longitude: location.Longitude,
+		Accuracy:  location.Accuracy,
+		Label:     location.Label,
+		Live:      location.IsLive(now),
+		LiveUntil: location.LiveUntil,
+		EndedAt:   location.EndedAt,
+		UpdatedAt: location.UpdatedAt,
+	}
+}
+
+// stickerText is the message text of a sticker, shown where the image cannot be
+func stickerText(sticker *emoji.Sticker) string {
+	if sticker.Emoji != "" {
+		return sticker.Emoji + " Sticker"
+	}
+	return "Sticker"
+}
+
+// addCustomEmojiEntities marks :shortcode: tokens of text messages that name a custom emoji the sender may use
+func (s *service) addCustomEmojiEntities(ctx context.Context, userID uint, messageType, content string, entities []richtext.Entity) []richtext.Entity {
+	if messageType != MessageTypeText {
+		return entities
+	}
+
+	shortcodes := richtext.FindShortcodes(content, entities)
+	if len(shortcodes) == 0 {
+		return entities
+	}
+	names := make([]string, 0, len(shortcodes))
+	for _, shortcode := range shortcodes {
+		names = append(names, shortcode.Name)
+	}
+
+	// Unknown shortcodes stay plain text; a lookup failure must not block sending
+	resolved, err := s.emojiService.ResolveEmoji(ctx, userID, names)
+	if err != nil {
+		logger.Error("Failed to resolve custom emoji", zap.Error(err))
+		return entities
+	}
+
+	var extra []richtext.Entity
+	for _, shortcode := range shortcodes {
+		if custom, ok := resolved[shortcode.Name]; ok {
+			extra = append(extra, richtext.Entity{
+				Type:          richtext.EntityCustomEmoji,
+				Offset:        shortcode.Offset,
+				Length:        shortcode.Length,
+				CustomEmojiID: custom.ID,
+			})
+		}
+	}
+	if len(extra) == 0 {
+		return entities
+	}
+	return richtext.Merge(entities, extra...)
+}
+
+// validateVoiceAttachments checks that a voice message carries exactly one recording the server could analyze
+func validateVoiceAttachments(attachments []file.File) error {
+	if len(attachments) != 1 {
+		return errors.New("voice messages need exactly one audio attachment")
+	}
+	recording := attachments[0]
+	if minio.GetFileType(recording.MimeType) != file.FileTypeAudio || recording.Duration == nil {
+		return errors.New("voice messages must be a WAV or Ogg/Opus recording")
+	}
+	return nil
+}
+
+// buildAttachmentResponses builds the attachment metadata of a message
+func buildAttachmentResponses(files []file.File) []AttachmentResponse {
+	if len(files) == 0 {
+		return nil
+	}
+
+	attachments := make([]AttachmentResponse, 0, len(files))
+	for _, f := range files {
+		attachments = append(attachments, AttachmentResponse{
+			ID:           f.ID,
+			FileName:     f.OriginalName,
+			MimeType:     f.MimeType,
+			FileType:     minio.GetFileType(f.MimeType),
+			FileSize:     f.FileSize,
+			DownloadURL:  fmt.Sprintf("/api/files/%d/download", f.ID),
+			ThumbnailURL: f.ThumbnailURL,
+			PreviewURL:   f.PreviewURL,
+			Width:        f.Width,
+			Height:       f.Height,
+			Duration:     f.Duration,
+			Waveform:     f.Waveform,
+		})
+	}
+	return attachments
+}
+
+// findClientMessage returns the sender's message with a client id, or nil if there is none
+func (s *service) findClientMessage(ctx context.Context, userID, conversationID uint, clientMessageID string) (*MessageResponse, error) {
+	message, err := s.repo.GetMessageByClientID(ctx, conversationID, userID, clientMessageID)
+	if err != nil || message == nil {
+		return nil, err
+	}
+
+	response := s.buildMessageResponse(ctx, message, userID)
+	s.applyDeliveryStatus(ctx, userID, conversationID, []*MessageResponse{response})
+	return response, nil
+}
+
+// applyDeliveryStatus fills in sent/delivered/read on the viewer's own messages.
+// Large groups are skipped, matching the receipts endpoint.
+func (s *service) applyDeliveryStatus(ctx context.Context, viewerID, conversationID uint, responses []*MessageResponse) {
+	hasOwn := false
+	for _, response := range responses {
+		if response.SenderID == viewerID && response.MessageType != MessageTypeSystem {
+			hasOwn = true
+			break
+		}
+	}
+	if !hasOwn {
+		return
+	}
+
+	pointers, err := s.repo.GetReceiptPointers(ctx, conversationID)
+	if err != nil {
+		logger.Error("Failed to load receipt pointers", zap.Error(err))
+		return
+	}
+	if len(pointers) > MaxReceiptGroupSize {
+		return
+	}
+
+	for _, response := range responses {
+		if response.SenderID == viewerID && response.MessageType != MessageTypeSystem {
+			response.Status = deliveryStatus(&Message{ID: response.ID, SenderID: response.SenderID, CreatedAt: response.CreatedAt}, pointers)
+		}
+	}
+}
+
+// deliveryStatus is read once every recipient has read the message, delivered once all have received it
+func deliveryStatus(message *Message, pointers []ReceiptPointer) string {
+	status := DeliveryStatusRead
+	for _, pointer := range pointers {
+		if !awaitsReceipt(message, pointer) {
+			continue
+		}
+		if !pointerReached(pointer.LastDeliveredMessageID, message.ID) {
+			return DeliveryStatusSent
+		}
+		if !pointerReached(pointer.LastReadMessageID, message.ID) {
+			status = DeliveryStatusDelivered
+		}
+	}
+	return status
+}
+
+// awaitsReceipt reports whether a participant is expected to receive a message:
+// everyone except the sender who was already in the conversation when it was sent
+func awaitsReceipt(message *Message, pointer ReceiptPointer) bool {
+	return pointer.UserID != message.SenderID && !pointer.JoinedAt.After(message.CreatedAt)
+}
+
+// pointerReached reports whether a read/delivery pointer covers the message
+func pointerReached(pointer *uint, messageID uint) bool {
+	return pointer != nil && *pointer >= messageID
+}
+
+// responsePointers returns pointers into a response slice so they can be updated in place
+func responsePointers(responses []MessageResponse) []*MessageResponse {
+	pointers := make([]*MessageResponse, len(responses))
+	for i := range responses {
+		pointers[i] = &responses[i]
+	}
+	return pointers
+}
+
+// buildBookmarkResponse builds a bookmark response for its owner
+func (s *service) buildBookmarkResponse(ctx context.Context, bookmark *MessageBookmark) *BookmarkResponse {
+	return &BookmarkResponse{
+		ID:         bookmark.ID,
+		Label:      bookmark.Label,
+		RemindAt:   bookmark.RemindAt,
+		RemindedAt: bookmark.RemindedAt,
+		CreatedAt:  bookmark.CreatedAt,
+		UpdatedAt:  bookmark.UpdatedAt,
+		Message:    *s.buildMessageResponse(ctx, &bookmark.Message, bookmark.UserID),
+	}
+}
+
+// normalizeReactionEmoji maps legacy reaction names to emoji and validates the result
+func normalizeReactionEmoji(emoji string) (string, error) {
+	emoji = strings.TrimSpace(emoji)
+	if legacy, ok := LegacyReactionEmoji[emoji]; ok {
+		return legacy, nil
+	}
+	if validation.IsCustomEmoji(emoji) {
+		return emoji, nil
+	}
+	if emoji = validation.NormalizeEmoji(emoji); validation.IsEmoji(emoji) {
+		return emoji, nil
+	}
+	return "", errors.New("invalid reaction: must be a single emoji or a :custom_emoji: shortcode")
+}
+
+// normalizeStoredEmoji brings a stored reaction to its normalised form; custom shortcodes are kept as is
+func normalizeStoredEmoji(emoji string) string {
+	if validation.IsCustomEmoji(emoji) {
+		return emoji
+	}
+	return validation.NormalizeEmoji(emoji)
+}
+
+// reactionEmojiVariants lists the spellings a normalised reaction may be stored under:
+// the normalised form and, for older rows, the same emoji without variation selectors
+func reactionEmojiVariants(emoji string) []string {
+	variants := []string{emoji}
+	if bare := strings.ReplaceAll(emoji, "\uFE0F", ""); bare != emoji {
+		variants = append(variants, bare)
+	}
+	return variants
+}
+
+// buildReactionSummaries groups reactions by emoji in order of first use
+func buildReactionSummaries(reactions []MessageReaction, viewerID uint) []ReactionSummaryResponse {
+	summaries := make([]ReactionSummaryResponse, 0)
+	index := make(map[string]int)
+	for _, reaction := range reactions {
+		emoji := normalizeStoredEmoji(reaction.Emoji)
+		i, exists := index[emoji]
+		if !exists {
+			i = len(summaries)
+			index[emoji] = i
+			summaries = append(summaries, ReactionSummaryResponse{
+				Emoji:       emoji,
+				SampleUsers: make([]UserSummaryResponse, 0, MaxReactionSampleUsers),
+			})
+		}
+
+		summary := &summaries[i]
+		summary.Count++
+		if viewerID != 0 && reaction.UserID == viewerID {
+			summary.ReactedByMe = true
+		}
+		if len(summary.SampleUsers) < MaxReactionSampleUsers {
+			summary.SampleUsers = append(summary.SampleUsers, buildUserSummary(reaction.User))
+		}
+	}
+	return summaries
+}
+
+// buildUserSummary returns the public profile fields shown next to message activity
+func buildUserSummary(u user.User) UserSummaryResponse {
+	return UserSummaryResponse{
+		ID:          u.ID,
+		Username:    u.Username,
+		DisplayName: u.DisplayName,
+		Avatar:      u.Avatar,
+	}
+}
+
+// getPollForUser loads a poll message after checking the user can see it
+func (s *service) getPollForUser(ctx context.Context, userID, messageID uint) (*Message, *Poll, error) {
+	message, err := s.repo.GetMessageByID(ctx, messageID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := s.ValidateConversationAccess(ctx, userID, message.ConversationID); err != nil {
+		return nil, nil, err
+	}
+	if !visibleTo(message, userID) {
+		return nil, nil, errors.New("message not found")
+	}
+
+	if message.MessageType != MessageTypePoll || message.Poll == nil {
+		return nil, nil, errors.New("message is not a poll")
+	}
+
+	return message, message.Poll, nil
+}
+
+// reloadAndBroadcastPoll pushes fresh tallies to the conversation and returns them for the acting user.
+// Shadow-hidden polls only exist for their sender, so nothing is pushed for them.
+func (s *service) reloadAndBroadcastPoll(ctx context.Context, message *Message, userID uint) (*PollResponse, error) {
+	poll, err := s.repo.GetPollByMessageID(ctx, message.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if message.HiddenAt == nil {
+		go s.wsService.HandlePollUpdated(context.Background(), message.ConversationID, map[string]interface{}{
+			"message_id": message.ID,
+			"poll":       buildPollResponse(poll, 0),
+		})
+	}
+
+	return buildPollResponse(poll, userID), nil
+}
+
+// buildPoll validates a poll request and turns it into a poll ready to be stored
+func buildPoll(userID, conversationID uint, req *CreatePollRequest) (*Poll, error) {
+	if req == nil {
+		return nil, errors.New("poll details are required for poll messages")
+	}
+
+	question := strings.TrimSpace(req.Question)
+	if question == "" {
+		return nil, errors.New("poll question is required")
+	}
+	if utf8.RuneCountInString(question) > MaxPollQuestionLength {
+		return nil, fmt.Errorf("poll question must be at most %d characters", MaxPollQuestionLength)
+	}
+	if len(req.Options) < MinPollOptions || len(req.Options) > MaxPollOptions {
+		return nil, fmt.Errorf("polls need between %d and %d options", MinPollOptions, MaxPollOptions)
+	}
+
+	if req.ClosesAt != nil {
+		now := time.Now()
+		if !req.ClosesAt.After(now) {
+			return nil, errors.New("poll close time must be in the future")
+		}
+		if req.ClosesAt.After(now.Add(MaxPollDuration)) {
+			return nil, errors.New("poll close time is too far in the future")
+		}
+	}
+
+	poll := &Poll{
+		ConversationID: conversationID,
+		CreatedBy:      userID,
+		Question:       question,
+		AllowsMultiple: req.AllowsMultiple,
+		IsAnonymous:    req.IsAnonymous,
+	}
+	if req.ClosesAt != nil {
+		closesAt := req.ClosesAt.UTC()
+		poll.ClosesAt = &closesAt
+	}
+
+	seen := make(map[string]bool)
+	for _, text := range req.Options {
+		text = strings.TrimSpace(text)
+		if text == "" {
+			return nil, errors.New("poll options cannot be empty")
+		}
+		if utf8.RuneCountInString(text) > MaxPollOptionLength {
+			return nil, fmt.Errorf("poll options must be at most %d characters", MaxPollOptionLength)
+		}
+		key := strings.ToLower(text)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate poll option: %s", text)
+		}
+		seen[key] = true
+		poll.Options = append(poll.Options, PollOption{Position: len(poll.Options), Text: text})
+	}
+
+	return poll, nil
+}
+
+// buildPollResponse builds poll tallies; viewerID fills in the viewer's own choices (0 for broadcasts)
+func buildPollResponse(poll *Poll, viewerID uint) *PollResponse {
+	response := &PollResponse{
+		ID:             poll.ID,
+		MessageID:      poll.MessageID,
+		Question:       poll.Question,
+		CreatedBy:      poll.CreatedBy,
+		AllowsMultiple: poll.AllowsMultiple,
+		IsAnonymous:    poll.IsAnonymous,
+		IsClosed:       poll.IsClosed(time.Now().UTC()),
+		ClosesAt:       poll.ClosesAt,
+		ClosedAt:       poll.ClosedAt,
+		Options:        make([]PollOptionResponse, 0, len(poll.Options)),
+	}
+
+	voters := make(map[uint]bool)
+	for _, option := range poll.Options {
+		optionResponse := PollOptionResponse{
+			ID:        option.ID,
+			Text:      option.Text,
+			VoteCount: len(option.Votes),
+		}
+		for _, vote := range option.Votes {
+			voters[vote.UserID] = true
+			if viewerID != 0 && vote.UserID == viewerID {
+				response.MyOptionIDs = append(response.MyOptionIDs, option.ID)
+			}
+			if !poll.IsAnonymous {
+				optionResponse.Voters = append(optionResponse.Voters, buildUserSummary(vote.User))
+			}
+		}
+		response.Options = append(response.Options, optionResponse)
+	}
+	response.TotalVoters = len(voters)
+
+	return response
+}
+
+// formatContent parses Markdown in text messages into plain text and entity spans
+func formatContent(messageType, content, parseMode string) (string, []richtext.Entity, error) {
+	if messageType != MessageTypeText || parseMode == ParseModePlain {
+		return content, nil, nil
+	}
+
+	text, entities := richtext.Parse(content)
+	if strings.TrimSpace(text) == "" {
+		return "", nil, errors.New("message content is empty")
+	}
+	return text, entities, nil
+}
+
+// messageExpiry returns the expiry for a new message based on the conversation TTL, or nil if messages do not disappear
+func (s *service) messageExpiry(ctx context.Context, conversationID uint) (*time.Time, error) {
+	settings, err := s.repo.GetConversationSettings(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if settings.MessageTTLSeconds <= 0 {
+		return nil, nil
+	}
+
+	expiresAt := time.Now().UTC().Add(time.Duration(settings.MessageTTLSeconds) * time.Second)
+	return &expiresAt, nil
+}
+
+// moderateContent runs text a user is about to post through the moderation pipeline
+func (s *service) moderateContent(ctx context.Context, userID, conversationID uint, text string) (*moderation.Verdict, error) {
+	verdict, err := s.moderationService.Moderate(ctx, &moderation.Input{
+		UserID:         userID,
+		ConversationID: conversationID,
+		Text:           text,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if verdict.Rejected() {
+		if reasons := verdict.Reasons(); len(reasons) > 0 {
+			return nil, fmt.Errorf("%w: %s", ErrModerationRejected, strings.Join(reasons, ", "))
+		}
+		return nil, ErrModerationRejected
+	}
+	return verdict, nil
+}
+
+// moderatePoll moderates a poll's question and options, folding the results into verdict
+func (s *service) moderatePoll(ctx context.Context, userID, conversationID uint, req *CreatePollRequest, verdict *moderation.Verdict) (*CreatePollRequest, error) {
+	moderated := *req
+	moderated.Options = make([]string, len(req.Options))
+
+	texts := append([]string{req.Question}, req.Options...)
+	for i, text := range texts {
+		result, err := s.moderateContent(ctx, userID, conversationID, text)
+		if err != nil {
+			return nil, err
+		}
+		verdict.Merge(result)
+		if i == 0 {
+			moderated.Question = result.Content
+		} else {
+			moderated.Options[i-1] = result.Content
+		}
+	}
+	return &moderated, nil
+}
+
+// mentionRegex matches @username at the start of the text or after a non-word character
+var mentionRegex = regexp.MustCompile(`(?:^|[^\w@])@([A-Za-z0-9_]{3,20})\b`)
+
+// parseMentions extracts unique, lowercased usernames mentioned in a message
+func parseMentions(content string) []string {
+	seen := make(map[string]bool)
+	var usernames []string
+	for _, match := range mentionRegex.FindAllStringSubmatch(content, -1) {
+		username := strings.ToLower(match[1])
+		if seen[username] {
+			continue
+		}
+		seen[username] = true
+		usernames = append(usernames, username)
+		if len(usernames) == MaxMentionsPerMessage {
+			break
+		}
+	}
+	return usernames
+}
+
+// visibleTo reports whether a viewer may see a message; shadow-hidden messages are shown to their sender only
+func visibleTo(message *Message, viewerID uint) bool {
+	return message.HiddenAt == nil || message.SenderID == viewerID
+}
+
+// validateClientMessageID rejects client ids in the namespace reserved for scheduled deliveries
+func validateClientMessageID(clientMessageID string) error {
+	if strings.HasPrefix(strings.TrimSpace(clientMessageID), scheduledClientIDPrefix) {
+		return fmt.Errorf("client_message_id must not start with %q", scheduledClientIDPrefix)
+	}
+	return nil
+}
+
+// createSystemMessage writes a server-generated system message to a conversation and broadcasts it
+func (s *service) createSystemMessage(ctx context.Context, userID, conversationID uint, content string) (*MessageResponse, error) {
+	expiresAt, err := s.messageExpiry(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	message, err := s.repo.CreateMessage(ctx, &Message{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Content:        content,
+		MessageType:    MessageTypeSystem,
+		ExpiresAt:      expiresAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := s.buildMessageResponse(ctx, message, 0)
+	s.broadcastNewMessage(conversationID, response)
+
+	return response, nil
+}
+
+// broadcastNewMessage broadcasts a new message to conversation participants
+func (s *service) broadcastNewMessage(conversationID uint, response *MessageResponse) {
+	go func() {
+		messageData := newMessageData(response)
+
+		logger.Info("Broadcasting new message", 
+			zap.Uint("conversation_id", conversationID),
+			zap.Uint("message_id", response.ID),
+			zap.String("content", response.Content))
+		
+		s.wsService.HandleNewMessage(context.Background(), conversationID, messageData)
+		s.conversationService.NotifyUnreadChanged(context.Background(), conversationID, response.SenderID)
+	}()
+}
+
+// broadcastHiddenMessage echoes a shadow-hidden message to its sender's devices only
+func (s *service) broadcastHiddenMessage(userID, conversationID uint, response *MessageResponse) {
+	go s.wsService.HandleNewMessageForUser(context.Background(), userID, conversationID, newMessageData(response))
+}
+
+// newMessageData converts a MessageResponse to the new_message event payload
+func newMessageData(response *MessageResponse) map[string]interface{} {
+	messageData := map[string]interface{}{
+		"id":              response.ID,
+		"sender_id":       response.SenderID,
+		"sender_name":     response.Sender.Username,
+		"content":         response.Content,
+		"message_type":    response.MessageType,
+		"created_at":      response.CreatedAt,
+		"updated_at":      response.UpdatedAt,
+	}
+	if response.ExpiresAt != nil {
+		messageData["expires_at"] = response.ExpiresAt
+	}
+	if len(response.Entities) > 0 {
+		messageData["entities"] = response.Entities
+	}
+	if response.ForwardedFrom != nil {
+		messageData["forwarded_from"] = response.ForwardedFrom
+	}
+	if response.Poll != nil {
+		messageData["poll"] = response.Poll
+	}
+	if response.Location != nil {
+		messageData["location"] = response.Location
+	}
+	if response.Sticker != nil {
+		messageData["sticker"] = response.Sticker
+	}
+	if len(response.Attachments) > 0 {
+		messageData["attachments"] = response.Attachments
+	}
+	if response.ClientMessageID != "" {
+		messageData["client_message_id"] = response.ClientMessageID
+	}
+	return messageData
+}
+
+// buildMessageResponse builds a message response; viewerID personalises reacted_by_me (0 for broadcasts)
+func (s *service) buildMessageResponse(ctx context.Context, message *Message, viewerID uint) *MessageResponse {
+	// Aggregate reactions per emoji
+	reactions := buildReactionSummaries(message.Reactions, viewerID)
+
+	// Build reply response if exists
+	var replyTo *MessageResponse
+	if message.ReplyTo != nil {
+		replyTo = &MessageResponse{
+			ID:          message.ReplyTo.ID,
+			Content:     message.ReplyTo.Content,
+			MessageType: message.ReplyTo.MessageType,
+			SenderID:    message.ReplyTo.SenderID,
+			Sender: user.UserResponse{
+				ID:           message.ReplyTo.Sender.ID,
+				Username:     message.ReplyTo.Sender.Username,
+				Email:        message.ReplyTo.Sender.Email,
+				DisplayName:  message.ReplyTo.Sender.DisplayName,
+				Bio:          message.ReplyTo.Sender.Bio,
+				Avatar:       message.ReplyTo.Sender.Avatar,
+				IsPublic:     message.ReplyTo.Sender.IsPublic,
+				LastLogin:    message.ReplyTo.Sender.LastLogin,
+				CreatedAt:    message.ReplyTo.Sender.CreatedAt,
+				UpdatedAt:    message.ReplyTo.Sender.UpdatedAt,
+			},
+			IsEdited:  message.ReplyTo.IsEdited,
+			CreatedAt: message.ReplyTo.CreatedAt,
+		}
+	}
+
+	// Build forwarded origin if exists
+	var forwardedFrom *ForwardedFromResponse
+	if message.ForwardedFromMessageID != nil || message.ForwardedFromSenderID != nil {
+		forwardedFrom = &ForwardedFromResponse{
+			MessageID:      message.ForwardedFromMessageID,
+			SenderID:       message.ForwardedFromSenderID,
+			ConversationID: message.ForwardedFromConversationID,
+		}
+		if message.ForwardedFromSender != nil {
+			forwardedFrom.Sender = &user.UserResponse{
+				ID:           message.ForwardedFromSender.ID,
+				Username:     message.ForwardedFromSender.Username,
+				DisplayName:  message.ForwardedFromSender.DisplayName,
+				Avatar:       message.ForwardedFromSender.Avatar,
+				IsPublic:     message.ForwardedFromSender.IsPublic,
+				CreatedAt:    message.ForwardedFromSender.CreatedAt,
+				UpdatedAt:    message.ForwardedFromSender.UpdatedAt,
+			}
+		}
+		if message.ForwardedFromConversation != nil {
+			forwardedFrom.ConversationName = message.ForwardedFromConversation.Name
+		}
+	}
+
+	// Build link previews
+	var linkPreviews []LinkPreviewResponse
+	for _, preview := range message.LinkPreviews {
+		linkPreviews = append(linkPreviews, buildLinkPreviewResponse(preview))
+	}
+
+	// Build poll if exists
+	var poll *PollResponse
+	if message.Poll != nil {
+		poll = buildPollResponse(message.Poll, 0)
+	}
+
+	// Build location if exists
+	var location *LocationResponse
+	if message.Location != nil {
+		location = buildLocationResponse(message.Location, time.Now().UTC())
+	}
+
+	// Build sticker if it still exists
+	var sticker *emoji.StickerResponse
+	if message.Sticker != nil {
+		response := emoji.BuildStickerResponse(ctx, message.Sticker)
+		sticker = &response
+	}
+
+	response := &MessageResponse{
 		ID:          message.ID,
 		Content:     message.Content,
+		Entities:    message.Entities,
 		MessageType: message.MessageType,
 		SenderID:    message.SenderID,
 		Sender: user.UserResponse{
@@ -367,8 +2317,19 @@ func (s *service) buildMessageResponse(ctx context.Context, message *Message) *M
 		ReplyTo:     replyTo,
 		IsEdited:    message.IsEdited,
 		EditedAt:    message.EditedAt,
+		ExpiresAt:   message.ExpiresAt,
+		ForwardedFrom: forwardedFrom,
+		LinkPreviews: linkPreviews,
+		Poll:        poll,
+		Location:    location,
+		Sticker:     sticker,
+		Attachments: buildAttachmentResponses(message.Attachments),
 		Reactions:   reactions,
 		CreatedAt:   message.CreatedAt,
 		UpdatedAt:   message.UpdatedAt,
 	}
+	if message.ClientMessageID != nil {
+		response.ClientMessageID = *message.ClientMessageID
+	}
+	return response
 }
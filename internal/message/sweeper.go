@@ -0,0 +1,148 @@
+package message
+
+import (
+	"context"
+	"time"
+
+	"huddle/internal/file"
+	"huddle/internal/websocket"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// How often the sweeper purges expired messages
+	sweepInterval = time.Minute
+
+	// Maximum number of expired messages purged per tick
+	sweepBatchSize = 200
+)
+
+// Sweeper permanently removes messages whose disappearing timer has run out, closes due polls and ends expired live locations
+type Sweeper struct {
+	repo      Repository
+	wsService websocket.Service
+}
+
+// NewSweeper creates a new expired message sweeper
+func NewSweeper(repo Repository, wsService websocket.Service) *Sweeper {
+	return &Sweeper{
+		repo:      repo,
+		wsService: wsService,
+	}
+}
+
+// Start starts the sweeper goroutine
+func (sw *Sweeper) Start() {
+	go sw.run()
+}
+
+// run purges expired messages on every tick
+func (sw *Sweeper) run() {
+	logger.Info("🧹 Expired message sweeper started")
+
+	ticker := time.NewTicker(sweepInterval)
+	defer ticker.Stop()
+
+	sw.sweepExpired(context.Background())
+	sw.closeDuePolls(context.Background())
+	sw.endExpiredLiveLocations(context.Background())
+
+	for range ticker.C {
+		sw.sweepExpired(context.Background())
+		sw.closeDuePolls(context.Background())
+		sw.endExpiredLiveLocations(context.Background())
+	}
+}
+
+// endExpiredLiveLocations finalises live locations whose sharing time has run out at their last known position
+func (sw *Sweeper) endExpiredLiveLocations(ctx context.Context) {
+	ended, err := sw.repo.EndExpiredLiveLocations(ctx, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to end expired live locations", zap.Error(err))
+		return
+	}
+
+	now := time.Now().UTC()
+	for i := range ended {
+		sw.wsService.HandleLocationUpdated(ctx, ended[i].ConversationID, map[string]interface{}{
+			"message_id": ended[i].MessageID,
+			"location":   buildLocationResponse(&ended[i], now),
+		})
+	}
+}
+
+// closeDuePolls closes polls whose close time has passed and pushes the final tallies
+func (sw *Sweeper) closeDuePolls(ctx context.Context) {
+	closed, err := sw.repo.CloseDuePolls(ctx, time.Now().UTC())
+	if err != nil {
+		logger.Error("Failed to close due polls", zap.Error(err))
+		return
+	}
+
+	for _, due := range closed {
+		// Shadow-hidden polls only exist for their sender
+		message, err := sw.repo.GetMessageByID(ctx, due.MessageID)
+		if err != nil {
+			logger.Error("Failed to load closed poll message", zap.Uint("message_id", due.MessageID), zap.Error(err))
+			continue
+		}
+		if message.HiddenAt != nil {
+			continue
+		}
+
+		poll, err := sw.repo.GetPollByMessageID(ctx, due.MessageID)
+		if err != nil {
+			logger.Error("Failed to load closed poll", zap.Uint("message_id", due.MessageID), zap.Error(err))
+			continue
+		}
+		sw.wsService.HandlePollUpdated(ctx, poll.ConversationID, map[string]interface{}{
+			"message_id": poll.MessageID,
+			"poll":       buildPollResponse(poll, 0),
+		})
+	}
+}
+
+// sweepExpired hard deletes expired messages with their reactions and attachments.
+// Attachment objects are looked up before the rows go, since files cascade with their message.
+func (sw *Sweeper) sweepExpired(ctx context.Context) {
+	for {
+		expired, err := sw.repo.GetExpiredMessages(ctx, time.Now().UTC(), sweepBatchSize)
+		if err != nil {
+			logger.Error("Failed to get expired messages", zap.Error(err))
+			return
+		}
+		if len(expired) == 0 {
+			return
+		}
+
+		messageIDs := make([]uint, len(expired))
+		for i, message := range expired {
+			messageIDs[i] = message.ID
+		}
+
+		files, err := sw.repo.GetMessageFiles(ctx, messageIDs)
+		if err != nil {
+			logger.Error("Failed to get expired message attachments", zap.Error(err))
+			return
+		}
+
+		if err := sw.repo.HardDeleteMessages(ctx, messageIDs); err != nil {
+			logger.Error("Failed to delete expired messages", zap.Error(err))
+			return
+		}
+
+		// Remove stored objects with their thumbnails and previews
+		file.DeleteStoredObjects(ctx, sw.repo, files)
+
+		// Let connected clients drop the messages
+		for _, message := range expired {
+			sw.wsService.HandleMessageDeleted(ctx, message.ConversationID, message.ID)
+		}
+
+		if len(expired) < sweepBatchSize {
+			return
+		}
+	}
+}
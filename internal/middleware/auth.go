@@ -54,6 +54,13 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
+		// Suspended users keep valid tokens but cannot use them
+		if auth.IsUserSuspended(c.Request.Context(), claims.UserID) {
+			utils.ForbiddenResponse(c, "Account is suspended")
+			c.Abort()
+			return
+		}
+
 		// Inject user info into context
 		c.Set("user_id", claims.UserID)
 		c.Set("username", claims.Username)
@@ -0,0 +1,182 @@
+package moderation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ClassifierStub is the classifier URL that selects the in-process StubClassifier
+const ClassifierStub = "stub"
+
+// maxClassifierResponseBytes bounds how much of a classifier answer is read
+const maxClassifierResponseBytes = 64 * 1024
+
+// Classification is a classifier's opinion of a text
+type Classification struct {
+	Action string   `json:"action"`          // One of the Action constants; empty allows the text
+	Reason string   `json:"reason"`
+	Terms  []string `json:"terms,omitempty"` // Offending substrings, masked when the action is mask
+}
+
+// classifierRequest is the body POSTed to an external classifier
+type classifierRequest struct {
+	Text           string `json:"text"`
+	UserID         uint   `json:"user_id"`
+	ConversationID uint   `json:"conversation_id"`
+}
+
+// HTTPClassifier asks an external service to classify text
+type HTTPClassifier struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPClassifier creates a classifier that POSTs to url
+func NewHTTPClassifier(url string, timeout time.Duration) *HTTPClassifier {
+	return &HTTPClassifier{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Classify sends the text to the classifier endpoint
+func (c *HTTPClassifier) Classify(ctx context.Context, input *Input) (*Classification, error) {
+	body, err := json.Marshal(&classifierRequest{
+		Text:           input.Text,
+		UserID:         input.UserID,
+		ConversationID: input.ConversationID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("classifier returned status %d", resp.StatusCode)
+	}
+
+	var result Classification
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxClassifierResponseBytes)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("invalid classifier response: %w", err)
+	}
+	if _, ok := actionSeverity[result.Action]; !ok {
+		return nil, fmt.Errorf("classifier returned unknown action %q", result.Action)
+	}
+	return &result, nil
+}
+
+// StubClassifier is a local stand-in for an external classifier, for development and tests.
+// It reports any configured term found in the text.
+type StubClassifier struct {
+	terms map[string]string // lowercase term -> action
+}
+
+// NewStubClassifier creates a stub from "term" or "term=action" entries; terms default to flag
+func NewStubClassifier(entries []string) *StubClassifier {
+	stub := &StubClassifier{terms: make(map[string]string)}
+	for _, entry := range entries {
+		term, action, found := strings.Cut(entry, "=")
+		term = strings.ToLower(strings.TrimSpace(term))
+		action = strings.TrimSpace(action)
+		if !found {
+			action = ActionFlag
+		}
+		if _, ok := actionSeverity[action]; !ok || term == "" || action == ActionAllow {
+			continue
+		}
+		stub.terms[term] = action
+	}
+	return stub
+}
+
+// Classify returns the strictest action of the terms present in the text
+func (c *StubClassifier) Classify(ctx context.Context, input *Input) (*Classification, error) {
+	text := strings.ToLower(input.Text)
+	result := &Classification{}
+	for term, action := range c.terms {
+		if !strings.Contains(text, term) {
+			continue
+		}
+		result.Terms = append(result.Terms, term)
+		if actionSeverity[action] > actionSeverity[result.Action] {
+			result.Action = action
+			result.Reason = "stub classifier matched " + term
+		}
+	}
+	return result, nil
+}
+
+// classifierChecker adapts a Classifier to the checker chain
+type classifierChecker struct {
+	classifier Classifier
+	failClosed bool
+}
+
+func (cc *classifierChecker) Name() string {
+	return SourceClassifier
+}
+
+// Check asks the classifier and turns its answer into matches
+func (cc *classifierChecker) Check(ctx context.Context, input *Input) ([]Match, error) {
+	result, err := cc.classifier.Classify(ctx, input)
+	if err != nil {
+		if cc.failClosed {
+			return nil, err
+		}
+		// Failing open lets the message through unchecked, so make the outage visible
+		logger.Warn("Moderation classifier unavailable, allowing message",
+			zap.String("checker", cc.Name()),
+			zap.Uint("conversation_id", input.ConversationID),
+			zap.Error(err))
+		return nil, nil
+	}
+	if result.Action == ActionAllow {
+		return nil, nil
+	}
+
+	reason := result.Reason
+	if reason == "" {
+		reason = "flagged by content classifier"
+	}
+
+	// Terms locate what to mask; without them the match covers the whole text
+	var matches []Match
+	for _, term := range result.Terms {
+		if term == "" {
+			continue
+		}
+		re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(term))
+		if err != nil {
+			continue
+		}
+		for _, span := range re.FindAllStringIndex(input.Text, -1) {
+			matches = append(matches, Match{Source: SourceClassifier, Action: result.Action, Reason: reason, Start: span[0], End: span[1]})
+		}
+	}
+	if len(matches) == 0 {
+		matches = append(matches, Match{Source: SourceClassifier, Action: result.Action, Reason: reason, Start: 0, End: len(input.Text)})
+	}
+	return matches, nil
+}
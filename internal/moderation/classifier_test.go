@@ -0,0 +1,184 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sort"
+	"testing"
+
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// fakeClassifier returns a fixed answer
+type fakeClassifier struct {
+	result *Classification
+	err    error
+}
+
+func (f *fakeClassifier) Classify(ctx context.Context, input *Input) (*Classification, error) {
+	return f.result, f.err
+}
+
+func TestNewStubClassifier(t *testing.T) {
+	tests := []struct {
+		name    string
+		entries []string
+		want    map[string]string
+	}{
+		{
+			name:    "bare term defaults to flag",
+			entries: []string{"spam"},
+			want:    map[string]string{"spam": ActionFlag},
+		},
+		{
+			name:    "explicit action",
+			entries: []string{"scam=reject", "darn=mask"},
+			want:    map[string]string{"scam": ActionReject, "darn": ActionMask},
+		},
+		{
+			name:    "terms are trimmed and lowercased",
+			entries: []string{"  BadWord = shadow_hide "},
+			want:    map[string]string{"badword": ActionShadowHide},
+		},
+		{
+			name:    "unknown action is skipped",
+			entries: []string{"spam=ban"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "allow is skipped",
+			entries: []string{"hello=allow"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "empty term is skipped",
+			entries: []string{"", "  ", "=reject"},
+			want:    map[string]string{},
+		},
+		{
+			name:    "empty action after separator is skipped",
+			entries: []string{"spam="},
+			want:    map[string]string{},
+		},
+		{
+			name:    "later entry wins",
+			entries: []string{"spam=flag", "SPAM=reject"},
+			want:    map[string]string{"spam": ActionReject},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := NewStubClassifier(tt.entries)
+			if !reflect.DeepEqual(stub.terms, tt.want) {
+				t.Errorf("NewStubClassifier(%q).terms = %v, want %v", tt.entries, stub.terms, tt.want)
+			}
+		})
+	}
+}
+
+func TestStubClassifierClassify(t *testing.T) {
+	stub := NewStubClassifier([]string{"spam", "darn=mask", "scam=reject"})
+
+	tests := []struct {
+		name       string
+		text       string
+		wantAction string
+		wantTerms  []string
+	}{
+		{name: "clean text", text: "hello there"},
+		{name: "single term", text: "buy SPAM now", wantAction: ActionFlag, wantTerms: []string{"spam"}},
+		{name: "mask term", text: "oh darn", wantAction: ActionMask, wantTerms: []string{"darn"}},
+		{name: "strictest term decides", text: "darn this spam scam", wantAction: ActionReject, wantTerms: []string{"darn", "scam", "spam"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := stub.Classify(context.Background(), &Input{Text: tt.text})
+			if err != nil {
+				t.Fatalf("Classify() error = %v", err)
+			}
+			if result.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", result.Action, tt.wantAction)
+			}
+			sort.Strings(result.Terms)
+			if !reflect.DeepEqual(result.Terms, tt.wantTerms) {
+				t.Errorf("Terms = %v, want %v", result.Terms, tt.wantTerms)
+			}
+		})
+	}
+}
+
+func TestClassifierCheckerCheck(t *testing.T) {
+	logger.Logger = zap.NewNop()
+	unavailable := errors.New("classifier unavailable")
+
+	tests := []struct {
+		name        string
+		result      *Classification
+		err         error
+		failClosed  bool
+		text        string
+		wantMatches []Match
+		wantErr     bool
+	}{
+		{
+			name:   "allow produces no matches",
+			result: &Classification{Action: ActionAllow},
+			text:   "hello",
+		},
+		{
+			name:   "terms become spans",
+			result: &Classification{Action: ActionMask, Reason: "rude", Terms: []string{"darn"}},
+			text:   "Darn it, darn",
+			wantMatches: []Match{
+				{Source: SourceClassifier, Action: ActionMask, Reason: "rude", Start: 0, End: 4},
+				{Source: SourceClassifier, Action: ActionMask, Reason: "rude", Start: 9, End: 13},
+			},
+		},
+		{
+			name:   "no terms covers the whole text",
+			result: &Classification{Action: ActionFlag},
+			text:   "suspicious",
+			wantMatches: []Match{
+				{Source: SourceClassifier, Action: ActionFlag, Reason: "flagged by content classifier", Start: 0, End: 10},
+			},
+		},
+		{
+			name:   "terms missing from the text cover the whole text",
+			result: &Classification{Action: ActionFlag, Reason: "spam", Terms: []string{"", "elsewhere"}},
+			text:   "text",
+			wantMatches: []Match{
+				{Source: SourceClassifier, Action: ActionFlag, Reason: "spam", Start: 0, End: 4},
+			},
+		},
+		{
+			name: "failure fails open",
+			err:  unavailable,
+			text: "hello",
+		},
+		{
+			name:       "failure fails closed",
+			err:        unavailable,
+			failClosed: true,
+			text:       "hello",
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := &classifierChecker{classifier: &fakeClassifier{result: tt.result, err: tt.err}, failClosed: tt.failClosed}
+			matches, err := checker.Check(context.Background(), &Input{Text: tt.text})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(matches, tt.wantMatches) {
+				t.Errorf("Check() = %+v, want %+v", matches, tt.wantMatches)
+			}
+		})
+	}
+}
@@ -0,0 +1,187 @@
+package moderation
+
+import (
+	"strconv"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new moderation handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Workspace rules
+
+// GetWorkspaceRules lists the rules applied to every conversation
+func (h *Handler) GetWorkspaceRules(c *gin.Context) {
+	h.getRules(c, nil)
+}
+
+// CreateWorkspaceRule adds a rule applied to every conversation
+func (h *Handler) CreateWorkspaceRule(c *gin.Context) {
+	h.createRule(c, nil)
+}
+
+// DeleteWorkspaceRule deletes a workspace rule
+func (h *Handler) DeleteWorkspaceRule(c *gin.Context) {
+	h.deleteRule(c, nil)
+}
+
+// Conversation rules
+
+// GetConversationRules lists the rules of one conversation
+func (h *Handler) GetConversationRules(c *gin.Context) {
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+	h.getRules(c, &conversationID)
+}
+
+// CreateConversationRule adds a rule to one conversation
+func (h *Handler) CreateConversationRule(c *gin.Context) {
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+	h.createRule(c, &conversationID)
+}
+
+// DeleteConversationRule deletes a conversation rule
+func (h *Handler) DeleteConversationRule(c *gin.Context) {
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+	h.deleteRule(c, &conversationID)
+}
+
+// Review queue
+
+// GetFlags lists flagged messages, oldest first
+func (h *Handler) GetFlags(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var cursor uint64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		var err error
+		cursor, err = strconv.ParseUint(cursorStr, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid cursor")
+			return
+		}
+	}
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 20
+	}
+
+	flags, err := h.service.GetFlags(c.Request.Context(), userID, c.DefaultQuery("status", FlagStatusPending), uint(cursor), limit)
+	if err != nil {
+		logger.Error("Failed to get moderation queue", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, flags, "Moderation queue retrieved successfully")
+}
+
+// ResolveFlag approves or removes a flagged message
+func (h *Handler) ResolveFlag(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	flagID, err := strconv.ParseUint(c.Param("flag_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid flag ID")
+		return
+	}
+
+	var req ResolveFlagRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	flag, err := h.service.ResolveFlag(c.Request.Context(), userID, uint(flagID), &req)
+	if err != nil {
+		logger.Error("Failed to resolve flagged message", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, flag, "Flagged message resolved successfully")
+}
+
+// Helper functions
+
+func (h *Handler) getRules(c *gin.Context, conversationID *uint) {
+	userID := c.GetUint("user_id")
+
+	rules, err := h.service.GetRules(c.Request.Context(), userID, conversationID)
+	if err != nil {
+		logger.Error("Failed to get moderation rules", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rules, "Moderation rules retrieved successfully")
+}
+
+func (h *Handler) createRule(c *gin.Context, conversationID *uint) {
+	userID := c.GetUint("user_id")
+
+	var req CreateRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	rule, err := h.service.CreateRule(c.Request.Context(), userID, conversationID, &req)
+	if err != nil {
+		logger.Error("Failed to create moderation rule", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, rule, "Moderation rule created successfully")
+}
+
+func (h *Handler) deleteRule(c *gin.Context, conversationID *uint) {
+	userID := c.GetUint("user_id")
+
+	ruleID, err := strconv.ParseUint(c.Param("rule_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid rule ID")
+		return
+	}
+
+	if err := h.service.DeleteRule(c.Request.Context(), userID, conversationID, uint(ruleID)); err != nil {
+		logger.Error("Failed to delete moderation rule", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Moderation rule deleted successfully")
+}
+
+func parseConversationID(c *gin.Context) (uint, bool) {
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return 0, false
+	}
+	return uint(conversationID), true
+}
@@ -0,0 +1,69 @@
+package moderation
+
+import (
+	"context"
+	"time"
+
+	"huddle/internal/file"
+)
+
+// Repository interface defines data access methods for moderation
+type Repository interface {
+	// Rules
+	CreateRule(ctx context.Context, rule *Rule) error
+	GetRuleByID(ctx context.Context, ruleID uint) (*Rule, error)
+	GetRules(ctx context.Context, conversationID *uint) ([]Rule, error)
+	GetApplicableRules(ctx context.Context, conversationID uint) ([]Rule, error)
+	CountRules(ctx context.Context, conversationID *uint) (int, error)
+	DeleteRule(ctx context.Context, ruleID uint) error
+
+	// Review queue
+	UpsertFlag(ctx context.Context, flag *Flag) error
+	GetFlagByID(ctx context.Context, flagID uint) (*Flag, error)
+	GetFlags(ctx context.Context, status string, cursor uint, limit int) ([]Flag, error)
+	ResolveFlag(ctx context.Context, flag *Flag, reviewerID uint, status, note string, now time.Time) (bool, error)
+
+	// Messages
+	GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error)
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+
+	// Users
+	IsModerator(ctx context.Context, userID uint) (bool, error)
+}
+
+// Service interface defines business logic methods for moderation
+type Service interface {
+	// Pipeline
+	Moderate(ctx context.Context, input *Input) (*Verdict, error)
+	RecordFlag(ctx context.Context, messageID, conversationID, senderID uint, content string, verdict *Verdict) error
+	AddChecker(checker Checker)
+
+	// Rules
+	CreateRule(ctx context.Context, userID uint, conversationID *uint, req *CreateRuleRequest) (*RuleResponse, error)
+	GetRules(ctx context.Context, userID uint, conversationID *uint) (*RuleListResponse, error)
+	DeleteRule(ctx context.Context, userID uint, conversationID *uint, ruleID uint) error
+
+	// Review queue
+	GetFlags(ctx context.Context, userID uint, status string, cursor uint, limit int) (*FlagListResponse, error)
+	ResolveFlag(ctx context.Context, userID, flagID uint, req *ResolveFlagRequest) (*FlagResponse, error)
+
+	// Validation methods
+	ValidateModerator(ctx context.Context, userID uint) error
+}
+
+// Checker is one stage of the moderation chain
+type Checker interface {
+	Name() string
+	Check(ctx context.Context, input *Input) ([]Match, error)
+}
+
+// Classifier scores text with an external model
+type Classifier interface {
+	Classify(ctx context.Context, input *Input) (*Classification, error)
+}
+
+// Broadcaster defines the real-time events published by the moderation module.
+// It is implemented by websocket.Service.
+type Broadcaster interface {
+	HandleMessageDeleted(ctx context.Context, conversationID uint, messageID uint)
+}
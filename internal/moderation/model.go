@@ -0,0 +1,216 @@
+package moderation
+
+import (
+	"time"
+)
+
+// Rule represents a blocklist entry; workspace rules have no conversation
+type Rule struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID *uint     `json:"conversation_id"`
+	Kind           string    `json:"kind" gorm:"not null;size:10"`
+	Pattern        string    `json:"pattern" gorm:"not null;size:500"`
+	Action         string    `json:"action" gorm:"not null;size:20"`
+	Reason         string    `json:"reason" gorm:"size:255"`
+	CreatedBy      *uint     `json:"created_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for Rule
+func (Rule) TableName() string {
+	return "moderation_rules"
+}
+
+// Flag represents a message queued for moderator review
+type Flag struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	MessageID      uint       `json:"message_id" gorm:"not null;uniqueIndex"`
+	ConversationID uint       `json:"conversation_id" gorm:"not null"`
+	SenderID       uint       `json:"sender_id"`
+	Action         string     `json:"action" gorm:"not null;size:20"`
+	Reasons        []string   `json:"reasons" gorm:"type:jsonb;serializer:json"`
+	Content        string     `json:"content"` // Snapshot of the message as sent
+	Status         string     `json:"status" gorm:"not null;default:'pending';size:20"`
+	ReviewedBy     *uint      `json:"reviewed_by"`
+	ReviewedAt     *time.Time `json:"reviewed_at"`
+	ResolutionNote string     `json:"resolution_note" gorm:"size:500"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for Flag
+func (Flag) TableName() string {
+	return "moderation_flags"
+}
+
+// Rule Kind Constants
+const (
+	RuleKindWord   = "word"   // Whole word or phrase, case-insensitive
+	RuleKindRegex  = "regex"  // RE2 regular expression
+	RuleKindDomain = "domain" // Link host or any of its subdomains
+)
+
+// Action Constants, from least to most severe
+const (
+	ActionAllow      = ""
+	ActionMask       = "mask"        // Replace the matched text with asterisks
+	ActionFlag       = "flag"        // Deliver and queue for review
+	ActionShadowHide = "shadow_hide" // Show to the sender only and queue for review
+	ActionReject     = "reject"      // Refuse the message
+)
+
+// Flag Status Constants
+const (
+	FlagStatusPending  = "pending"
+	FlagStatusApproved = "approved"
+	FlagStatusRemoved  = "removed"
+)
+
+// Resolution Constants
+const (
+	ResolutionApprove = "approve" // Keep the message and unhide it
+	ResolutionRemove  = "remove"  // Delete the message
+)
+
+// Match sources
+const (
+	SourceRule       = "rule"
+	SourceClassifier = "classifier"
+)
+
+// Rule limits
+const (
+	MaxRulesPerScope  = 500
+	MaxPatternLength  = 500
+	maxModeratedLinks = 50
+)
+
+// actionSeverity orders actions so the strictest match decides the verdict
+var actionSeverity = map[string]int{
+	ActionAllow:      0,
+	ActionMask:       1,
+	ActionFlag:       2,
+	ActionShadowHide: 3,
+	ActionReject:     4,
+}
+
+// Input is the text to moderate and who is sending it where
+type Input struct {
+	UserID         uint
+	ConversationID uint
+	Text           string
+}
+
+// Match is one reason a checker objected to a message
+type Match struct {
+	Source string `json:"source"`
+	RuleID *uint  `json:"rule_id,omitempty"`
+	Action string `json:"action"`
+	Reason string `json:"reason"`
+	Start  int    `json:"-"` // Byte span of the matched text, used for masking
+	End    int    `json:"-"`
+}
+
+// Verdict is the outcome of running the checker chain over one text
+type Verdict struct {
+	Action  string  // Strictest action across all matches
+	Content string  // Text with mask matches replaced
+	Matches []Match
+}
+
+// Rejected reports whether the message must not be stored
+func (v *Verdict) Rejected() bool {
+	return v.Action == ActionReject
+}
+
+// Hidden reports whether the message should only be shown to its sender
+func (v *Verdict) Hidden() bool {
+	return v.Action == ActionShadowHide
+}
+
+// NeedsReview reports whether the message should be queued for moderators
+func (v *Verdict) NeedsReview() bool {
+	return v.Action == ActionFlag || v.Action == ActionShadowHide
+}
+
+// Reasons returns the distinct reasons of the matches
+func (v *Verdict) Reasons() []string {
+	var reasons []string
+	seen := make(map[string]bool)
+	for _, match := range v.Matches {
+		if match.Reason == "" || seen[match.Reason] {
+			continue
+		}
+		seen[match.Reason] = true
+		reasons = append(reasons, match.Reason)
+	}
+	return reasons
+}
+
+// Merge folds another verdict into this one; used when a message has several moderated fields
+func (v *Verdict) Merge(other *Verdict) {
+	if other == nil {
+		return
+	}
+	if actionSeverity[other.Action] > actionSeverity[v.Action] {
+		v.Action = other.Action
+	}
+	v.Matches = append(v.Matches, other.Matches...)
+}
+
+// DTOs for API requests/responses
+
+// CreateRuleRequest represents request to add a blocklist rule
+type CreateRuleRequest struct {
+	Kind    string `json:"kind" binding:"required,oneof=word regex domain"`
+	Pattern string `json:"pattern" binding:"required,max=500"`
+	Action  string `json:"action" binding:"required,oneof=reject mask flag shadow_hide"`
+	Reason  string `json:"reason" binding:"max=255"`
+}
+
+// ResolveFlagRequest represents a moderator's decision on a flagged message
+type ResolveFlagRequest struct {
+	Resolution string `json:"resolution" binding:"required,oneof=approve remove"`
+	Note       string `json:"note" binding:"max=500"`
+}
+
+// RuleResponse represents a blocklist rule response
+type RuleResponse struct {
+	ID             uint      `json:"id"`
+	ConversationID *uint     `json:"conversation_id,omitempty"`
+	Kind           string    `json:"kind"`
+	Pattern        string    `json:"pattern"`
+	Action         string    `json:"action"`
+	Reason         string    `json:"reason,omitempty"`
+	CreatedBy      *uint     `json:"created_by,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// RuleListResponse represents the rules of one scope
+type RuleListResponse struct {
+	Rules []RuleResponse `json:"rules"`
+}
+
+// FlagResponse represents a queued message
+type FlagResponse struct {
+	ID             uint       `json:"id"`
+	MessageID      uint       `json:"message_id"`
+	ConversationID uint       `json:"conversation_id"`
+	SenderID       uint       `json:"sender_id"`
+	Action         string     `json:"action"`
+	Reasons        []string   `json:"reasons"`
+	Content        string     `json:"content"`
+	Status         string     `json:"status"`
+	ReviewedBy     *uint      `json:"reviewed_by,omitempty"`
+	ReviewedAt     *time.Time `json:"reviewed_at,omitempty"`
+	ResolutionNote string     `json:"resolution_note,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// FlagListResponse represents a page of the review queue
+type FlagListResponse struct {
+	Flags      []FlagResponse `json:"flags"`
+	NextCursor *uint          `json:"next_cursor,omitempty"`
+	HasMore    bool           `json:"has_more"`
+}
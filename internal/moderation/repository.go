@@ -0,0 +1,236 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/database"
+	"huddle/internal/file"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new moderation repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Rules
+
+func (r *repository) CreateRule(ctx context.Context, rule *Rule) error {
+	if err := r.db.WithContext(ctx).Create(rule).Error; err != nil {
+		logger.Error("Failed to create moderation rule", zap.Error(err))
+		return err
+	}
+	logger.Info("Moderation rule created", zap.Uint("rule_id", rule.ID), zap.String("kind", rule.Kind), zap.String("action", rule.Action))
+	return nil
+}
+
+func (r *repository) GetRuleByID(ctx context.Context, ruleID uint) (*Rule, error) {
+	var rule Rule
+	if err := r.db.WithContext(ctx).First(&rule, ruleID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("moderation rule not found")
+		}
+		logger.Error("Failed to get moderation rule", zap.Error(err))
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *repository) GetRules(ctx context.Context, conversationID *uint) ([]Rule, error) {
+	var rules []Rule
+	query := r.db.WithContext(ctx)
+	if conversationID == nil {
+		query = query.Where("conversation_id IS NULL")
+	} else {
+		query = query.Where("conversation_id = ?", *conversationID)
+	}
+	if err := query.Order("id ASC").Find(&rules).Error; err != nil {
+		logger.Error("Failed to get moderation rules", zap.Error(err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *repository) GetApplicableRules(ctx context.Context, conversationID uint) ([]Rule, error) {
+	var rules []Rule
+	if err := r.db.WithContext(ctx).
+		Where("(conversation_id IS NULL OR conversation_id = ?)", conversationID).
+		Order("id ASC").
+		Find(&rules).Error; err != nil {
+		logger.Error("Failed to get applicable moderation rules", zap.Error(err))
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *repository) CountRules(ctx context.Context, conversationID *uint) (int, error) {
+	var count int64
+	query := r.db.WithContext(ctx).Model(&Rule{})
+	if conversationID == nil {
+		query = query.Where("conversation_id IS NULL")
+	} else {
+		query = query.Where("conversation_id = ?", *conversationID)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		logger.Error("Failed to count moderation rules", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *repository) DeleteRule(ctx context.Context, ruleID uint) error {
+	if err := r.db.WithContext(ctx).Delete(&Rule{}, ruleID).Error; err != nil {
+		logger.Error("Failed to delete moderation rule", zap.Error(err))
+		return err
+	}
+	logger.Info("Moderation rule deleted", zap.Uint("rule_id", ruleID))
+	return nil
+}
+
+// Review queue
+
+func (r *repository) UpsertFlag(ctx context.Context, flag *Flag) error {
+	// An edit that trips the filters again reopens the existing entry
+	if err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns: []clause.Column{{Name: "message_id"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"action":          flag.Action,
+				"reasons":         gorm.Expr("EXCLUDED.reasons"),
+				"content":         flag.Content,
+				"status":          FlagStatusPending,
+				"reviewed_by":     nil,
+				"reviewed_at":     nil,
+				"resolution_note": "",
+			}),
+		}).
+		Create(flag).Error; err != nil {
+		logger.Error("Failed to queue flagged message", zap.Error(err))
+		return err
+	}
+	logger.Info("Message queued for moderation", zap.Uint("message_id", flag.MessageID), zap.String("action", flag.Action))
+	return nil
+}
+
+func (r *repository) GetFlagByID(ctx context.Context, flagID uint) (*Flag, error) {
+	var flag Flag
+	if err := r.db.WithContext(ctx).First(&flag, flagID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("flagged message not found")
+		}
+		logger.Error("Failed to get flagged message", zap.Error(err))
+		return nil, err
+	}
+	return &flag, nil
+}
+
+func (r *repository) GetFlags(ctx context.Context, status string, cursor uint, limit int) ([]Flag, error) {
+	var flags []Flag
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if cursor > 0 {
+		query = query.Where("id > ?", cursor)
+	}
+	// Oldest first so the queue is worked in arrival order
+	if err := query.Order("id ASC").Limit(limit).Find(&flags).Error; err != nil {
+		logger.Error("Failed to get moderation queue", zap.Error(err))
+		return nil, err
+	}
+	return flags, nil
+}
+
+// ResolveFlag records the decision and applies it to the message in one transaction,
+// so a failed delete or unhide leaves the flag pending for another attempt
+func (r *repository) ResolveFlag(ctx context.Context, flag *Flag, reviewerID uint, status, note string, now time.Time) (bool, error) {
+	var resolved bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&Flag{}).
+			Where("id = ? AND status = ?", flag.ID, FlagStatusPending).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"reviewed_by":     reviewerID,
+				"reviewed_at":     now,
+				"resolution_note": note,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if resolved = result.RowsAffected > 0; !resolved {
+			return nil
+		}
+
+		switch {
+		case status == FlagStatusRemoved:
+			return tx.Exec("DELETE FROM messages WHERE id = ?", flag.MessageID).Error
+		case flag.Action == ActionShadowHide:
+			return tx.Table("messages").Where("id = ?", flag.MessageID).Update("hidden_at", nil).Error
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to resolve flagged message", zap.Error(err))
+		return false, err
+	}
+	if resolved && status == FlagStatusRemoved {
+		logger.Info("Moderated message deleted", zap.Uint("message_id", flag.MessageID))
+	}
+	return resolved, nil
+}
+
+// Messages
+
+func (r *repository) GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error) {
+	var files []file.StoredObject
+	if len(messageIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, message_id, object_key, thumbnail_url, preview_url").
+		Where("message_id IN ?", messageIDs).
+		Find(&files).Error; err != nil {
+		logger.Error("Failed to get moderated message files", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *repository) CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Where("object_key = ?", objectKey).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count files by object key", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Users
+
+func (r *repository) IsModerator(ctx context.Context, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("users").
+		Where("id = ? AND is_moderator = ? AND deleted_at IS NULL", userID, true).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check moderator", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
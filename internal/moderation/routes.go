@@ -0,0 +1,31 @@
+package moderation
+
+import (
+	"huddle/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes sets up moderation routes
+func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
+	// Workspace rules and review queue (moderators only)
+	moderation := router.Group("/moderation")
+	moderation.Use(middleware.AuthMiddleware())
+	{
+		moderation.GET("/rules", handler.GetWorkspaceRules)               // List workspace rules
+		moderation.POST("/rules", handler.CreateWorkspaceRule)            // Add workspace rule
+		moderation.DELETE("/rules/:rule_id", handler.DeleteWorkspaceRule) // Delete workspace rule
+
+		moderation.GET("/flags", handler.GetFlags)                          // List flagged messages
+		moderation.POST("/flags/:flag_id/resolve", handler.ResolveFlag)     // Approve or remove a flagged message
+	}
+
+	// Conversation rules (conversation admins and moderators)
+	conversations := router.Group("/conversations/:id/moderation")
+	conversations.Use(middleware.AuthMiddleware())
+	{
+		conversations.GET("/rules", handler.GetConversationRules)               // List conversation rules
+		conversations.POST("/rules", handler.CreateConversationRule)            // Add conversation rule
+		conversations.DELETE("/rules/:rule_id", handler.DeleteConversationRule) // Delete conversation rule
+	}
+}
@@ -0,0 +1,228 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	"huddle/pkg/linkpreview"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// ruleChecker matches text against the workspace and conversation blocklists
+type ruleChecker struct {
+	repo     Repository
+	compiled sync.Map // kind + pattern -> *regexp.Regexp
+}
+
+func newRuleChecker(repo Repository) *ruleChecker {
+	return &ruleChecker{repo: repo}
+}
+
+func (rc *ruleChecker) Name() string {
+	return SourceRule
+}
+
+// Check returns a match for every rule the text trips
+func (rc *ruleChecker) Check(ctx context.Context, input *Input) ([]Match, error) {
+	rules, err := rc.repo.GetApplicableRules(ctx, input.ConversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	var links []string
+	var matches []Match
+	for i := range rules {
+		rule := &rules[i]
+
+		var spans [][]int
+		switch rule.Kind {
+		case RuleKindWord, RuleKindRegex:
+			re, err := rc.regexp(rule.Kind, rule.Pattern)
+			if err != nil {
+				// Rules are validated on creation; skip one that no longer compiles rather than block every message
+				logger.Warn("Skipping invalid moderation rule", zap.Uint("rule_id", rule.ID), zap.Error(err))
+				continue
+			}
+			spans = re.FindAllStringIndex(input.Text, -1)
+			if rule.Kind == RuleKindWord {
+				spans = wholeWordSpans(input.Text, spans)
+			}
+		case RuleKindDomain:
+			if links == nil {
+				links = linkpreview.ExtractURLs(input.Text, maxModeratedLinks)
+			}
+			spans = domainSpans(input.Text, links, rule.Pattern)
+		}
+
+		for _, span := range spans {
+			matches = append(matches, Match{
+				Source: SourceRule,
+				RuleID: &rule.ID,
+				Action: rule.Action,
+				Reason: ruleReason(rule),
+				Start:  span[0],
+				End:    span[1],
+			})
+		}
+	}
+	return matches, nil
+}
+
+// regexp returns the compiled form of a word or regex pattern
+func (rc *ruleChecker) regexp(kind, pattern string) (*regexp.Regexp, error) {
+	key := kind + ":" + pattern
+	if re, ok := rc.compiled.Load(key); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := compileRule(kind, pattern)
+	if err != nil {
+		return nil, err
+	}
+	rc.compiled.Store(key, re)
+	return re, nil
+}
+
+// compileRule builds the matcher for a word or regex rule
+func compileRule(kind, pattern string) (*regexp.Regexp, error) {
+	if kind == RuleKindWord {
+		return regexp.Compile("(?i)" + regexp.QuoteMeta(pattern))
+	}
+	return regexp.Compile(pattern)
+}
+
+// normalizeRule validates a rule pattern and returns it in its stored form
+func normalizeRule(kind, pattern string) (string, error) {
+	pattern = strings.TrimSpace(pattern)
+	if pattern == "" {
+		return "", errors.New("pattern is required")
+	}
+	if len(pattern) > MaxPatternLength {
+		return "", fmt.Errorf("pattern cannot exceed %d characters", MaxPatternLength)
+	}
+
+	switch kind {
+	case RuleKindWord:
+		return strings.ToLower(pattern), nil
+	case RuleKindRegex:
+		if _, err := regexp.Compile(pattern); err != nil {
+			return "", fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return pattern, nil
+	case RuleKindDomain:
+		// Accept a pasted link as well as a bare host
+		host := strings.ToLower(pattern)
+		if strings.Contains(host, "://") {
+			u, err := url.Parse(host)
+			if err != nil || u.Hostname() == "" {
+				return "", errors.New("invalid domain")
+			}
+			host = u.Hostname()
+		}
+		host = strings.TrimPrefix(strings.TrimSuffix(host, "."), "*.")
+		if host == "" || strings.ContainsAny(host, "/ @:") || !strings.Contains(host, ".") {
+			return "", errors.New("invalid domain")
+		}
+		return host, nil
+	default:
+		return "", fmt.Errorf("unknown rule kind: %s", kind)
+	}
+}
+
+// wholeWordSpans keeps matches that are not part of a longer word
+func wholeWordSpans(text string, spans [][]int) [][]int {
+	var kept [][]int
+	for _, span := range spans {
+		if span[0] > 0 {
+			if r, _ := utf8.DecodeLastRuneInString(text[:span[0]]); isWordRune(r) {
+				continue
+			}
+		}
+		if span[1] < len(text) {
+			if r, _ := utf8.DecodeRuneInString(text[span[1]:]); isWordRune(r) {
+				continue
+			}
+		}
+		kept = append(kept, span)
+	}
+	return kept
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// domainSpans returns the position of every link whose host is domain or one of its subdomains
+func domainSpans(text string, links []string, domain string) [][]int {
+	var spans [][]int
+	for _, link := range links {
+		u, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+		if host != domain && !strings.HasSuffix(host, "."+domain) {
+			continue
+		}
+		// A link can appear several times; ExtractURLs only returns it once
+		for offset := 0; ; {
+			index := strings.Index(text[offset:], link)
+			if index < 0 {
+				break
+			}
+			spans = append(spans, []int{offset + index, offset + index + len(link)})
+			offset += index + len(link)
+		}
+	}
+	return spans
+}
+
+// ruleReason describes a rule for the sender and the review queue
+func ruleReason(rule *Rule) string {
+	if rule.Reason != "" {
+		return rule.Reason
+	}
+	switch rule.Kind {
+	case RuleKindDomain:
+		return "blocked link domain"
+	case RuleKindWord:
+		return "blocked word"
+	default:
+		return "blocked pattern"
+	}
+}
+
+// maskSpans replaces every rune inside the spans with an asterisk
+func maskSpans(text string, spans [][]int) string {
+	if len(spans) == 0 {
+		return text
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i][0] < spans[j][0] })
+
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, span := range spans {
+		start, end := span[0], span[1]
+		if start < last {
+			start = last // Overlapping spans
+		}
+		if start >= end {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(strings.Repeat("*", utf8.RuneCountInString(text[start:end])))
+		last = end
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
@@ -0,0 +1,29 @@
+package moderation
+
+import "testing"
+
+func TestMaskSpans(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		spans [][]int
+		want  string
+	}{
+		{name: "no spans", text: "hello", want: "hello"},
+		{name: "single span", text: "hello world", spans: [][]int{{6, 11}}, want: "hello *****"},
+		{name: "whole text", text: "darn", spans: [][]int{{0, 4}}, want: "****"},
+		{name: "unsorted spans", text: "one two three", spans: [][]int{{8, 13}, {0, 3}}, want: "*** two *****"},
+		{name: "overlapping spans", text: "abcdefgh", spans: [][]int{{1, 5}, {3, 7}}, want: "a******h"},
+		{name: "nested span", text: "abcdefgh", spans: [][]int{{1, 7}, {2, 4}}, want: "a******h"},
+		{name: "empty span", text: "abc", spans: [][]int{{1, 1}}, want: "abc"},
+		{name: "one asterisk per rune", text: "naïve café", spans: [][]int{{0, 6}, {7, 12}}, want: "***** ****"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := maskSpans(tt.text, tt.spans); got != tt.want {
+				t.Errorf("maskSpans(%q, %v) = %q, want %q", tt.text, tt.spans, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,346 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"huddle/internal/config"
+	"huddle/internal/conversation"
+	"huddle/internal/file"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type service struct {
+	repo                Repository
+	broadcaster         Broadcaster
+	conversationService conversation.Service
+
+	mu       sync.RWMutex
+	checkers []Checker
+}
+
+// NewService creates a new moderation service with the blocklist checker and, if configured, the classifier
+func NewService(repo Repository, broadcaster Broadcaster, conversationService conversation.Service) Service {
+	s := &service{
+		repo:                repo,
+		broadcaster:         broadcaster,
+		conversationService: conversationService,
+	}
+	s.AddChecker(newRuleChecker(repo))
+
+	if cfg := config.GetConfig(); cfg != nil {
+		switch url := cfg.Moderation.ClassifierURL; url {
+		case "":
+		case ClassifierStub:
+			s.AddChecker(&classifierChecker{classifier: NewStubClassifier(cfg.Moderation.StubTerms), failClosed: cfg.Moderation.ClassifierFailClosed})
+		default:
+			s.AddChecker(&classifierChecker{classifier: NewHTTPClassifier(url, cfg.Moderation.ClassifierTimeout), failClosed: cfg.Moderation.ClassifierFailClosed})
+		}
+	}
+	return s
+}
+
+// Pipeline
+
+// AddChecker appends a stage to the moderation chain
+func (s *service) AddChecker(checker Checker) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.checkers = append(s.checkers, checker)
+}
+
+// Moderate runs every checker over the text; the strictest match decides the action
+func (s *service) Moderate(ctx context.Context, input *Input) (*Verdict, error) {
+	verdict := &Verdict{Action: ActionAllow, Content: input.Text}
+	if strings.TrimSpace(input.Text) == "" {
+		return verdict, nil
+	}
+
+	s.mu.RLock()
+	checkers := s.checkers
+	s.mu.RUnlock()
+
+	for _, checker := range checkers {
+		matches, err := checker.Check(ctx, input)
+		if err != nil {
+			logger.Error("Moderation check failed", zap.String("checker", checker.Name()), zap.Error(err))
+			return nil, errors.New("message could not be checked by moderation, please try again")
+		}
+		for _, match := range matches {
+			if actionSeverity[match.Action] > actionSeverity[verdict.Action] {
+				verdict.Action = match.Action
+			}
+		}
+		verdict.Matches = append(verdict.Matches, matches...)
+		// Nothing later in the chain can change a rejection
+		if verdict.Rejected() {
+			return verdict, nil
+		}
+	}
+
+	var spans [][]int
+	for _, match := range verdict.Matches {
+		if match.Action == ActionMask {
+			spans = append(spans, []int{match.Start, match.End})
+		}
+	}
+	verdict.Content = maskSpans(input.Text, spans)
+	return verdict, nil
+}
+
+// RecordFlag queues a stored message for review when its verdict asks for it
+func (s *service) RecordFlag(ctx context.Context, messageID, conversationID, senderID uint, content string, verdict *Verdict) error {
+	if verdict == nil || !verdict.NeedsReview() {
+		return nil
+	}
+	return s.repo.UpsertFlag(ctx, &Flag{
+		MessageID:      messageID,
+		ConversationID: conversationID,
+		SenderID:       senderID,
+		Action:         verdict.Action,
+		Reasons:        verdict.Reasons(),
+		Content:        content,
+		Status:         FlagStatusPending,
+	})
+}
+
+// Rules
+
+func (s *service) CreateRule(ctx context.Context, userID uint, conversationID *uint, req *CreateRuleRequest) (*RuleResponse, error) {
+	if err := s.validateRuleManager(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	pattern, err := normalizeRule(req.Kind, req.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	count, err := s.repo.CountRules(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxRulesPerScope {
+		return nil, fmt.Errorf("cannot have more than %d moderation rules", MaxRulesPerScope)
+	}
+
+	rule := &Rule{
+		ConversationID: conversationID,
+		Kind:           req.Kind,
+		Pattern:        pattern,
+		Action:         req.Action,
+		Reason:         strings.TrimSpace(req.Reason),
+		CreatedBy:      &userID,
+	}
+	if err := s.repo.CreateRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return buildRuleResponse(rule), nil
+}
+
+func (s *service) GetRules(ctx context.Context, userID uint, conversationID *uint) (*RuleListResponse, error) {
+	if err := s.validateRuleManager(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+
+	rules, err := s.repo.GetRules(ctx, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]RuleResponse, 0, len(rules))
+	for i := range rules {
+		responses = append(responses, *buildRuleResponse(&rules[i]))
+	}
+	return &RuleListResponse{Rules: responses}, nil
+}
+
+func (s *service) DeleteRule(ctx context.Context, userID uint, conversationID *uint, ruleID uint) error {
+	if err := s.validateRuleManager(ctx, userID, conversationID); err != nil {
+		return err
+	}
+
+	// The rule must belong to the scope the caller was authorised for
+	rule, err := s.repo.GetRuleByID(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if !sameScope(rule.ConversationID, conversationID) {
+		return errors.New("moderation rule not found")
+	}
+	return s.repo.DeleteRule(ctx, ruleID)
+}
+
+// Review queue
+
+func (s *service) GetFlags(ctx context.Context, userID uint, status string, cursor uint, limit int) (*FlagListResponse, error) {
+	if err := s.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "", FlagStatusPending, FlagStatusApproved, FlagStatusRemoved:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+
+	// Fetch one extra row to know whether another page exists
+	flags, err := s.repo.GetFlags(ctx, status, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(flags) > limit
+	if hasMore {
+		flags = flags[:limit]
+	}
+
+	response := &FlagListResponse{
+		Flags:   make([]FlagResponse, 0, len(flags)),
+		HasMore: hasMore,
+	}
+	for i := range flags {
+		response.Flags = append(response.Flags, *buildFlagResponse(&flags[i]))
+	}
+	if hasMore {
+		next := flags[len(flags)-1].ID
+		response.NextCursor = &next
+	}
+	return response, nil
+}
+
+func (s *service) ResolveFlag(ctx context.Context, userID, flagID uint, req *ResolveFlagRequest) (*FlagResponse, error) {
+	if err := s.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	flag, err := s.repo.GetFlagByID(ctx, flagID)
+	if err != nil {
+		return nil, err
+	}
+
+	status := FlagStatusApproved
+	if req.Resolution == ResolutionRemove {
+		status = FlagStatusRemoved
+	}
+	note := strings.TrimSpace(req.Note)
+
+	// Attachments cascade with a removed message, so look up their objects first
+	var files []file.StoredObject
+	if status == FlagStatusRemoved {
+		if files, err = s.repo.GetMessageFiles(ctx, []uint{flag.MessageID}); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only one moderator can decide on a flag
+	now := time.Now().UTC()
+	resolved, err := s.repo.ResolveFlag(ctx, flag, userID, status, note, now)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, errors.New("flagged message has already been resolved")
+	}
+
+	if status == FlagStatusRemoved {
+		file.DeleteStoredObjects(ctx, s.repo, files)
+		// Members never saw a shadow-hidden message, but its sender did
+		s.broadcaster.HandleMessageDeleted(ctx, flag.ConversationID, flag.MessageID)
+	}
+
+	logger.Info("Flagged message resolved",
+		zap.Uint("flag_id", flagID),
+		zap.Uint("message_id", flag.MessageID),
+		zap.Uint("moderator_id", userID),
+		zap.String("status", status))
+
+	flag.Status = status
+	flag.ReviewedBy = &userID
+	flag.ReviewedAt = &now
+	flag.ResolutionNote = note
+	return buildFlagResponse(flag), nil
+}
+
+// Validation methods
+
+func (s *service) ValidateModerator(ctx context.Context, userID uint) error {
+	isModerator, err := s.repo.IsModerator(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !isModerator {
+		return errors.New("access denied: moderators only")
+	}
+	return nil
+}
+
+// validateRuleManager allows moderators everywhere and conversation admins on their own conversation's rules
+func (s *service) validateRuleManager(ctx context.Context, userID uint, conversationID *uint) error {
+	isModerator, err := s.repo.IsModerator(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if isModerator {
+		return nil
+	}
+	if conversationID == nil {
+		return errors.New("access denied: moderators only")
+	}
+	return s.conversationService.ValidateConversationAdmin(ctx, userID, *conversationID)
+}
+
+// Helper functions
+
+func sameScope(a, b *uint) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
+
+func buildRuleResponse(rule *Rule) *RuleResponse {
+	return &RuleResponse{
+		ID:             rule.ID,
+		ConversationID: rule.ConversationID,
+		Kind:           rule.Kind,
+		Pattern:        rule.Pattern,
+		Action:         rule.Action,
+		Reason:         rule.Reason,
+		CreatedBy:      rule.CreatedBy,
+		CreatedAt:      rule.CreatedAt,
+	}
+}
+
+func buildFlagResponse(flag *Flag) *FlagResponse {
+	reasons := flag.Reasons
+	if reasons == nil {
+		reasons = []string{}
+	}
+	return &FlagResponse{
+		ID:             flag.ID,
+		MessageID:      flag.MessageID,
+		ConversationID: flag.ConversationID,
+		SenderID:       flag.SenderID,
+		Action:         flag.Action,
+		Reasons:        reasons,
+		Content:        flag.Content,
+		Status:         flag.Status,
+		ReviewedBy:     flag.ReviewedBy,
+		ReviewedAt:     flag.ReviewedAt,
+		ResolutionNote: flag.ResolutionNote,
+		CreatedAt:      flag.CreatedAt,
+	}
+}
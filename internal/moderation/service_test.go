@@ -0,0 +1,161 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+// fakeChecker returns fixed matches and counts its calls
+type fakeChecker struct {
+	name    string
+	matches []Match
+	err     error
+	calls   int
+}
+
+func (f *fakeChecker) Name() string {
+	return f.name
+}
+
+func (f *fakeChecker) Check(ctx context.Context, input *Input) ([]Match, error) {
+	f.calls++
+	return f.matches, f.err
+}
+
+func TestModerate(t *testing.T) {
+	logger.Logger = zap.NewNop()
+
+	tests := []struct {
+		name        string
+		text        string
+		checkers    []*fakeChecker
+		wantAction  string
+		wantContent string
+		wantReasons []string
+		wantCalls   []int
+		wantErr     bool
+	}{
+		{
+			name:        "no matches allows",
+			text:        "hello",
+			checkers:    []*fakeChecker{{name: "rule"}},
+			wantAction:  ActionAllow,
+			wantContent: "hello",
+			wantCalls:   []int{1},
+		},
+		{
+			name:        "blank text skips the checkers",
+			text:        "   ",
+			checkers:    []*fakeChecker{{name: "rule", matches: []Match{{Action: ActionReject}}}},
+			wantAction:  ActionAllow,
+			wantContent: "   ",
+			wantCalls:   []int{0},
+		},
+		{
+			name: "strictest action across checkers wins",
+			text: "hello world",
+			checkers: []*fakeChecker{
+				{name: "rule", matches: []Match{{Action: ActionShadowHide, Reason: "a"}, {Action: ActionMask, Reason: "b", Start: 0, End: 5}}},
+				{name: "classifier", matches: []Match{{Action: ActionFlag, Reason: "c"}}},
+			},
+			wantAction:  ActionShadowHide,
+			wantContent: "***** world",
+			wantReasons: []string{"a", "b", "c"},
+			wantCalls:   []int{1, 1},
+		},
+		{
+			name: "mask spans from several checkers are merged",
+			text: "héllo big world",
+			checkers: []*fakeChecker{
+				{name: "rule", matches: []Match{{Action: ActionMask, Reason: "a", Start: 0, End: 6}}},
+				{name: "classifier", matches: []Match{{Action: ActionMask, Reason: "a", Start: 11, End: 16}, {Action: ActionMask, Reason: "b", Start: 3, End: 6}}},
+			},
+			wantAction:  ActionMask,
+			wantContent: "***** big *****",
+			wantReasons: []string{"a", "b"},
+			wantCalls:   []int{1, 1},
+		},
+		{
+			name: "reject short-circuits the chain",
+			text: "buy now",
+			checkers: []*fakeChecker{
+				{name: "rule", matches: []Match{{Action: ActionReject, Reason: "spam"}}},
+				{name: "classifier", matches: []Match{{Action: ActionFlag, Reason: "other"}}},
+			},
+			wantAction:  ActionReject,
+			wantContent: "buy now",
+			wantReasons: []string{"spam"},
+			wantCalls:   []int{1, 0},
+		},
+		{
+			name: "checker failure stops moderation",
+			text: "hello",
+			checkers: []*fakeChecker{
+				{name: "classifier", err: errors.New("unavailable")},
+				{name: "rule"},
+			},
+			wantCalls: []int{1, 0},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &service{}
+			for _, checker := range tt.checkers {
+				s.AddChecker(checker)
+			}
+
+			verdict, err := s.Moderate(context.Background(), &Input{Text: tt.text})
+			for i, checker := range tt.checkers {
+				if checker.calls != tt.wantCalls[i] {
+					t.Errorf("checker %q called %d times, want %d", checker.name, checker.calls, tt.wantCalls[i])
+				}
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Moderate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if verdict.Action != tt.wantAction {
+				t.Errorf("Action = %q, want %q", verdict.Action, tt.wantAction)
+			}
+			if verdict.Content != tt.wantContent {
+				t.Errorf("Content = %q, want %q", verdict.Content, tt.wantContent)
+			}
+			if reasons := verdict.Reasons(); !reflect.DeepEqual(reasons, tt.wantReasons) {
+				t.Errorf("Reasons() = %v, want %v", reasons, tt.wantReasons)
+			}
+		})
+	}
+}
+
+func TestVerdictMerge(t *testing.T) {
+	tests := []struct {
+		name   string
+		base   string
+		other  *Verdict
+		wanted string
+	}{
+		{name: "nil leaves the verdict", base: ActionFlag, other: nil, wanted: ActionFlag},
+		{name: "stricter action wins", base: ActionMask, other: &Verdict{Action: ActionShadowHide}, wanted: ActionShadowHide},
+		{name: "laxer action is ignored", base: ActionReject, other: &Verdict{Action: ActionFlag}, wanted: ActionReject},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &Verdict{Action: tt.base}
+			v.Merge(tt.other)
+			if v.Action != tt.wanted {
+				t.Errorf("Merge() action = %q, want %q", v.Action, tt.wanted)
+			}
+		})
+	}
+}
@@ -0,0 +1,177 @@
+package report
+
+import (
+	"strconv"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new report handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Reporters
+
+// CreateReport reports a message, file or profile
+func (h *Handler) CreateReport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req CreateReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	report, err := h.service.CreateReport(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Error("Failed to create report", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Report submitted successfully")
+}
+
+// GetMyReports lists the current user's reports, newest first
+func (h *Handler) GetMyReports(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	reports, err := h.service.GetMyReports(c.Request.Context(), userID, cursor, limit)
+	if err != nil {
+		logger.Error("Failed to get reports", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, reports, "Reports retrieved successfully")
+}
+
+// Review queue
+
+// GetReviewQueue lists reports for moderators, oldest first
+func (h *Handler) GetReviewQueue(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	cursor, limit, ok := parsePage(c)
+	if !ok {
+		return
+	}
+
+	reports, err := h.service.GetReviewQueue(c.Request.Context(), userID, c.DefaultQuery("status", StatusOpen), c.Query("target_type"), cursor, limit)
+	if err != nil {
+		logger.Error("Failed to get report queue", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, reports, "Report queue retrieved successfully")
+}
+
+// GetReview gets a report with its audit trail
+func (h *Handler) GetReview(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.GetReview(c.Request.Context(), userID, reportID)
+	if err != nil {
+		logger.Error("Failed to get report", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Report retrieved successfully")
+}
+
+// ClaimReport marks a report as being reviewed by the current moderator
+func (h *Handler) ClaimReport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.service.ClaimReport(c.Request.Context(), userID, reportID)
+	if err != nil {
+		logger.Error("Failed to claim report", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Report claimed successfully")
+}
+
+// ResolveReport records a moderator's decision and carries it out
+func (h *Handler) ResolveReport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	reportID, ok := parseReportID(c)
+	if !ok {
+		return
+	}
+
+	var req ResolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	report, err := h.service.ResolveReport(c.Request.Context(), userID, reportID, &req)
+	if err != nil {
+		logger.Error("Failed to resolve report", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Report resolved successfully")
+}
+
+// Helper functions
+
+func parseReportID(c *gin.Context) (uint, bool) {
+	reportID, err := strconv.ParseUint(c.Param("report_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid report ID")
+		return 0, false
+	}
+	return uint(reportID), true
+}
+
+func parsePage(c *gin.Context) (uint, int, bool) {
+	var cursor uint64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		var err error
+		cursor, err = strconv.ParseUint(cursorStr, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid cursor")
+			return 0, 0, false
+		}
+	}
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 20
+	}
+	return uint(cursor), limit, true
+}
@@ -0,0 +1,56 @@
+package report
+
+import (
+	"context"
+	"time"
+
+	"huddle/internal/file"
+)
+
+// Repository interface defines data access methods for reports
+type Repository interface {
+	// Reports
+	CreateReport(ctx context.Context, report *Report) error
+	GetReportByID(ctx context.Context, reportID uint) (*Report, error)
+	HasOpenReport(ctx context.Context, reporterID uint, targetType string, targetID uint) (bool, error)
+	GetReporterReports(ctx context.Context, reporterID, cursor uint, limit int) ([]Report, error)
+	GetReports(ctx context.Context, status, targetType string, cursor uint, limit int) ([]Report, error)
+	ClaimReport(ctx context.Context, reportID, moderatorID uint, now, staleBefore time.Time) (bool, error)
+	ResolveReport(ctx context.Context, reportID, moderatorID uint, status, resolution, note string, now, staleBefore time.Time) (bool, error)
+
+	// Audit trail
+	GetActions(ctx context.Context, reportID uint) ([]ReportAction, error)
+
+	// Targets
+	GetMessageTarget(ctx context.Context, messageID uint) (*MessageTarget, error)
+	GetFileTarget(ctx context.Context, fileID uint) (*FileTarget, error)
+	GetUserTarget(ctx context.Context, userID uint) (*UserTarget, error)
+	CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error)
+
+	// Enforcement
+	DeleteMessage(ctx context.Context, messageID uint) (bool, error)
+	GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error)
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+	SuspendUser(ctx context.Context, userID uint, until time.Time) error
+}
+
+// Service interface defines business logic methods for reports
+type Service interface {
+	// Reporters
+	CreateReport(ctx context.Context, userID uint, req *CreateReportRequest) (*ReportResponse, error)
+	GetMyReports(ctx context.Context, userID, cursor uint, limit int) (*ReportListResponse, error)
+
+	// Moderators
+	GetReviewQueue(ctx context.Context, userID uint, status, targetType string, cursor uint, limit int) (*ReviewListResponse, error)
+	GetReview(ctx context.Context, userID, reportID uint) (*ReviewResponse, error)
+	ClaimReport(ctx context.Context, userID, reportID uint) (*ReviewResponse, error)
+	ResolveReport(ctx context.Context, userID, reportID uint, req *ResolveReportRequest) (*ReviewResponse, error)
+}
+
+// Broadcaster defines the real-time events published by the report module.
+// It is implemented by websocket.Service.
+type Broadcaster interface {
+	HandleMessageDeleted(ctx context.Context, conversationID uint, messageID uint)
+	HandleReportUpdated(ctx context.Context, userID uint, reportData map[string]interface{})
+	HandleModerationNotice(ctx context.Context, userID uint, noticeData map[string]interface{})
+}
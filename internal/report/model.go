@@ -0,0 +1,186 @@
+package report
+
+import (
+	"time"
+)
+
+// Report represents a user's report of a message, file or profile
+type Report struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReporterID     uint       `json:"reporter_id"`
+	TargetType     string     `json:"target_type" gorm:"not null;size:20"`
+	TargetID       uint       `json:"target_id" gorm:"not null"`
+	TargetUserID   *uint      `json:"target_user_id"` // Author, owner or the reported user
+	ConversationID *uint      `json:"conversation_id"`
+	Reason         string     `json:"reason" gorm:"not null;size:30"`
+	Comment        string     `json:"comment"`
+	Snapshot       Snapshot   `json:"snapshot" gorm:"type:jsonb;serializer:json"`
+	Status         string     `json:"status" gorm:"not null;default:'open';size:20"`
+	ClaimedBy      *uint      `json:"claimed_by"`
+	ClaimedAt      *time.Time `json:"claimed_at"`
+	Resolution     *string    `json:"resolution" gorm:"size:20"`
+	ResolutionNote string     `json:"resolution_note" gorm:"size:1000"`
+	ResolvedBy     *uint      `json:"resolved_by"`
+	ResolvedAt     *time.Time `json:"resolved_at"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time  `json:"updated_at" gorm:"default:now()"`
+}
+
+// Snapshot is the reported content as it was when the report was filed
+type Snapshot struct {
+	// Messages
+	Content     string     `json:"content,omitempty"`
+	MessageType string     `json:"message_type,omitempty"`
+	SentAt      *time.Time `json:"sent_at,omitempty"`
+
+	// Files
+	FileName string `json:"file_name,omitempty"`
+	MimeType string `json:"mime_type,omitempty"`
+	FileSize int64  `json:"file_size,omitempty"`
+
+	// Profiles, and the author of messages and files
+	Username    string `json:"username,omitempty"`
+	DisplayName string `json:"display_name,omitempty"`
+	Bio         string `json:"bio,omitempty"`
+	Avatar      string `json:"avatar,omitempty"`
+}
+
+// ReportAction represents one entry in a report's audit trail
+type ReportAction struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ReportID  uint      `json:"report_id" gorm:"not null"`
+	ActorID   uint      `json:"actor_id"`
+	Action    string    `json:"action" gorm:"not null;size:20"`
+	Note      string    `json:"note" gorm:"size:1000"`
+	CreatedAt time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// MessageTarget represents a reported message
+type MessageTarget struct {
+	ID             uint
+	ConversationID uint
+	SenderID       uint
+	Content        string
+	MessageType    string
+	HiddenAt       *time.Time
+	CreatedAt      time.Time
+}
+
+// FileTarget represents a reported file
+type FileTarget struct {
+	ID           uint
+	UserID       uint
+	OriginalName string
+	MimeType     string
+	FileSize     int64
+}
+
+// UserTarget represents a reported profile, or the author of reported content
+type UserTarget struct {
+	ID          uint
+	Username    string
+	DisplayName string
+	Bio         string
+	Avatar      string
+}
+
+// Target Type Constants
+const (
+	TargetTypeMessage = "message"
+	TargetTypeFile    = "file"
+	TargetTypeProfile = "profile"
+)
+
+// Report Status Constants
+const (
+	StatusOpen      = "open"
+	StatusInReview  = "in_review"
+	StatusActioned  = "actioned"
+	StatusDismissed = "dismissed"
+)
+
+// Resolution Constants
+const (
+	ResolutionDeleteMessage = "delete_message"
+	ResolutionWarn          = "warn"
+	ResolutionSuspendUser   = "suspend_user"
+	ResolutionDismiss       = "dismiss"
+)
+
+// Audit trail actions besides the resolutions
+const (
+	ActionSubmitted = "submitted"
+	ActionClaimed   = "claimed"
+)
+
+// Review limits
+const (
+	ClaimTimeout        = 30 * time.Minute // A claim older than this can be taken over by another moderator
+	DefaultSuspendHours = 7 * 24
+)
+
+// DTOs for API requests/responses
+
+// CreateReportRequest represents request to report content
+type CreateReportRequest struct {
+	TargetType string `json:"target_type" binding:"required,oneof=message file profile"`
+	TargetID   uint   `json:"target_id" binding:"required"`
+	Reason     string `json:"reason" binding:"required,oneof=spam harassment hate_speech violence sexual_content self_harm impersonation other"`
+	Comment    string `json:"comment" binding:"max=1000"`
+}
+
+// ResolveReportRequest represents a moderator's decision on a report
+type ResolveReportRequest struct {
+	Resolution   string `json:"resolution" binding:"required,oneof=delete_message warn suspend_user dismiss"`
+	Note         string `json:"note" binding:"max=1000"`
+	SuspendHours int    `json:"suspend_hours,omitempty" binding:"omitempty,min=1,max=8760"` // Defaults to a week
+}
+
+// ReportResponse represents a report as seen by its reporter
+type ReportResponse struct {
+	ID         uint       `json:"id"`
+	TargetType string     `json:"target_type"`
+	TargetID   uint       `json:"target_id"`
+	Reason     string     `json:"reason"`
+	Comment    string     `json:"comment,omitempty"`
+	Status     string     `json:"status"`
+	Resolution *string    `json:"resolution,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ResolvedAt *time.Time `json:"resolved_at,omitempty"`
+}
+
+// ReportListResponse represents a page of a reporter's reports
+type ReportListResponse struct {
+	Reports    []ReportResponse `json:"reports"`
+	NextCursor *uint            `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+}
+
+// ReviewResponse represents a report as seen by moderators
+type ReviewResponse struct {
+	ID             uint           `json:"id"`
+	ReporterID     uint           `json:"reporter_id"`
+	TargetType     string         `json:"target_type"`
+	TargetID       uint           `json:"target_id"`
+	TargetUserID   *uint          `json:"target_user_id,omitempty"`
+	ConversationID *uint          `json:"conversation_id,omitempty"`
+	Reason         string         `json:"reason"`
+	Comment        string         `json:"comment,omitempty"`
+	Snapshot       Snapshot       `json:"snapshot"`
+	Status         string         `json:"status"`
+	ClaimedBy      *uint          `json:"claimed_by,omitempty"`
+	ClaimedAt      *time.Time     `json:"claimed_at,omitempty"`
+	Resolution     *string        `json:"resolution,omitempty"`
+	ResolutionNote string         `json:"resolution_note,omitempty"`
+	ResolvedBy     *uint          `json:"resolved_by,omitempty"`
+	ResolvedAt     *time.Time     `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time      `json:"created_at"`
+	Actions        []ReportAction `json:"actions,omitempty"` // Audit trail, on the detail endpoint only
+}
+
+// ReviewListResponse represents a page of the moderator queue
+type ReviewListResponse struct {
+	Reports    []ReviewResponse `json:"reports"`
+	NextCursor *uint            `json:"next_cursor,omitempty"`
+	HasMore    bool             `json:"has_more"`
+}
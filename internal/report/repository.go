@@ -0,0 +1,298 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/database"
+	"huddle/internal/file"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new report repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Reports
+
+func (r *repository) CreateReport(ctx context.Context, report *Report) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(report).Error; err != nil {
+			return err
+		}
+		return tx.Create(&ReportAction{
+			ReportID: report.ID,
+			ActorID:  report.ReporterID,
+			Action:   ActionSubmitted,
+			Note:     report.Reason,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create report", zap.Error(err))
+		return err
+	}
+	logger.Info("Report created", zap.Uint("report_id", report.ID), zap.String("target_type", report.TargetType), zap.Uint("target_id", report.TargetID))
+	return nil
+}
+
+func (r *repository) GetReportByID(ctx context.Context, reportID uint) (*Report, error) {
+	var report Report
+	if err := r.db.WithContext(ctx).First(&report, reportID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("report not found")
+		}
+		logger.Error("Failed to get report", zap.Error(err))
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *repository) HasOpenReport(ctx context.Context, reporterID uint, targetType string, targetID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&Report{}).
+		Where("reporter_id = ? AND target_type = ? AND target_id = ? AND status IN ?",
+			reporterID, targetType, targetID, []string{StatusOpen, StatusInReview}).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check open reports", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+func (r *repository) GetReporterReports(ctx context.Context, reporterID, cursor uint, limit int) ([]Report, error) {
+	var reports []Report
+	query := r.db.WithContext(ctx).Where("reporter_id = ?", reporterID)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if err := query.Order("id DESC").Limit(limit).Find(&reports).Error; err != nil {
+		logger.Error("Failed to get reporter reports", zap.Error(err))
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *repository) GetReports(ctx context.Context, status, targetType string, cursor uint, limit int) ([]Report, error) {
+	var reports []Report
+	query := r.db.WithContext(ctx)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if cursor > 0 {
+		query = query.Where("id > ?", cursor)
+	}
+	// Oldest first so the queue is worked in arrival order
+	if err := query.Order("id ASC").Limit(limit).Find(&reports).Error; err != nil {
+		logger.Error("Failed to get report queue", zap.Error(err))
+		return nil, err
+	}
+	return reports, nil
+}
+
+func (r *repository) ClaimReport(ctx context.Context, reportID, moderatorID uint, now, staleBefore time.Time) (bool, error) {
+	var claimed bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Unclaimed, already ours, or abandoned by another moderator
+		result := tx.Model(&Report{}).
+			Where("id = ? AND status IN ?", reportID, []string{StatusOpen, StatusInReview}).
+			Where("(claimed_by IS NULL OR claimed_by = ? OR claimed_at < ?)", moderatorID, staleBefore).
+			Updates(map[string]interface{}{
+				"status":     StatusInReview,
+				"claimed_by": moderatorID,
+				"claimed_at": now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if claimed = result.RowsAffected > 0; !claimed {
+			return nil
+		}
+		return tx.Create(&ReportAction{ReportID: reportID, ActorID: moderatorID, Action: ActionClaimed}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to claim report", zap.Error(err))
+		return false, err
+	}
+	return claimed, nil
+}
+
+func (r *repository) ResolveReport(ctx context.Context, reportID, moderatorID uint, status, resolution, note string, now, staleBefore time.Time) (bool, error) {
+	var resolved bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// Resolving an unclaimed report claims it implicitly
+		result := tx.Model(&Report{}).
+			Where("id = ? AND status IN ?", reportID, []string{StatusOpen, StatusInReview}).
+			Where("(claimed_by IS NULL OR claimed_by = ? OR claimed_at < ?)", moderatorID, staleBefore).
+			Updates(map[string]interface{}{
+				"status":          status,
+				"claimed_by":      moderatorID,
+				"claimed_at":      gorm.Expr("COALESCE(claimed_at, ?)", now),
+				"resolution":      resolution,
+				"resolution_note": note,
+				"resolved_by":     moderatorID,
+				"resolved_at":     now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if resolved = result.RowsAffected > 0; !resolved {
+			return nil
+		}
+		return tx.Create(&ReportAction{ReportID: reportID, ActorID: moderatorID, Action: resolution, Note: note}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to resolve report", zap.Error(err))
+		return false, err
+	}
+	if resolved {
+		logger.Info("Report resolved", zap.Uint("report_id", reportID), zap.Uint("moderator_id", moderatorID), zap.String("resolution", resolution))
+	}
+	return resolved, nil
+}
+
+// Audit trail
+
+func (r *repository) GetActions(ctx context.Context, reportID uint) ([]ReportAction, error) {
+	var actions []ReportAction
+	if err := r.db.WithContext(ctx).
+		Where("report_id = ?", reportID).
+		Order("id ASC").
+		Find(&actions).Error; err != nil {
+		logger.Error("Failed to get report actions", zap.Error(err))
+		return nil, err
+	}
+	return actions, nil
+}
+
+// Targets
+
+func (r *repository) GetMessageTarget(ctx context.Context, messageID uint) (*MessageTarget, error) {
+	var target MessageTarget
+	if err := r.db.WithContext(ctx).
+		Table("messages").
+		Select("id, conversation_id, sender_id, content, message_type, hidden_at, created_at").
+		Where("id = ?", messageID).
+		Where("(expires_at IS NULL OR expires_at > ?)", time.Now().UTC()).
+		Take(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("message not found")
+		}
+		logger.Error("Failed to get reported message", zap.Error(err))
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *repository) GetFileTarget(ctx context.Context, fileID uint) (*FileTarget, error) {
+	var target FileTarget
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, user_id, original_name, mime_type, file_size").
+		Where("id = ? AND deleted_at IS NULL", fileID).
+		Take(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("file not found")
+		}
+		logger.Error("Failed to get reported file", zap.Error(err))
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *repository) GetUserTarget(ctx context.Context, userID uint) (*UserTarget, error) {
+	var target UserTarget
+	if err := r.db.WithContext(ctx).
+		Table("users").
+		Select("id, username, display_name, bio, avatar").
+		Where("id = ? AND deleted_at IS NULL", userID).
+		Take(&target).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		logger.Error("Failed to get reported user", zap.Error(err))
+		return nil, err
+	}
+	return &target, nil
+}
+
+func (r *repository) CheckUserInConversation(ctx context.Context, conversationID, userID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("conversation_participants").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check user in conversation", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Enforcement
+
+func (r *repository) GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error) {
+	var files []file.StoredObject
+	if len(messageIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, message_id, object_key, thumbnail_url, preview_url").
+		Where("message_id IN ?", messageIDs).
+		Find(&files).Error; err != nil {
+		logger.Error("Failed to get reported message files", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *repository) CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Where("object_key = ?", objectKey).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count files by object key", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+func (r *repository) DeleteMessage(ctx context.Context, messageID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Exec("DELETE FROM messages WHERE id = ?", messageID)
+	if result.Error != nil {
+		logger.Error("Failed to delete reported message", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repository) SuspendUser(ctx context.Context, userID uint, until time.Time) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Table("users").Where("id = ?", userID).Update("suspended_until", until).Error; err != nil {
+			return err
+		}
+		// Refresh tokens must not outlive the suspension
+		return tx.Exec("DELETE FROM sessions WHERE user_id = ?", userID).Error
+	})
+	if err != nil {
+		logger.Error("Failed to suspend user", zap.Error(err))
+		return err
+	}
+	logger.Info("User suspended", zap.Uint("user_id", userID), zap.Time("until", until))
+	return nil
+}
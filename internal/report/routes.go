@@ -0,0 +1,28 @@
+package report
+
+import (
+	"huddle/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes sets up report routes
+func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
+	// Reporting content (all users)
+	reports := router.Group("/reports")
+	reports.Use(middleware.AuthMiddleware())
+	{
+		reports.POST("", handler.CreateReport) // Report a message, file or profile
+		reports.GET("", handler.GetMyReports)  // List my reports
+	}
+
+	// Review queue (moderators only)
+	moderation := router.Group("/moderation/reports")
+	moderation.Use(middleware.AuthMiddleware())
+	{
+		moderation.GET("", handler.GetReviewQueue)                    // List reports
+		moderation.GET("/:report_id", handler.GetReview)              // Get report with audit trail
+		moderation.POST("/:report_id/claim", handler.ClaimReport)     // Claim report for review
+		moderation.POST("/:report_id/resolve", handler.ResolveReport) // Delete message, warn, suspend or dismiss
+	}
+}
@@ -0,0 +1,405 @@
+package report
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"huddle/internal/file"
+	"huddle/internal/moderation"
+	"huddle/pkg/auth"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+type service struct {
+	repo              Repository
+	broadcaster       Broadcaster
+	moderationService moderation.Service
+	fileService       file.Service
+}
+
+// NewService creates a new report service
+func NewService(repo Repository, broadcaster Broadcaster, moderationService moderation.Service, fileService file.Service) Service {
+	return &service{
+		repo:              repo,
+		broadcaster:       broadcaster,
+		moderationService: moderationService,
+		fileService:       fileService,
+	}
+}
+
+// Reporters
+
+func (s *service) CreateReport(ctx context.Context, userID uint, req *CreateReportRequest) (*ReportResponse, error) {
+	report := &Report{
+		ReporterID: userID,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Reason:     req.Reason,
+		Comment:    strings.TrimSpace(req.Comment),
+		Status:     StatusOpen,
+	}
+
+	// Capture the content now; it may be edited or deleted before a moderator looks at it
+	if err := s.snapshotTarget(ctx, userID, report); err != nil {
+		return nil, err
+	}
+	if report.TargetUserID != nil && *report.TargetUserID == userID {
+		return nil, errors.New("you cannot report your own content")
+	}
+
+	exists, err := s.repo.HasOpenReport(ctx, userID, req.TargetType, req.TargetID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, errors.New("you have already reported this and it is awaiting review")
+	}
+
+	if err := s.repo.CreateReport(ctx, report); err != nil {
+		return nil, err
+	}
+	return buildReportResponse(report), nil
+}
+
+func (s *service) GetMyReports(ctx context.Context, userID, cursor uint, limit int) (*ReportListResponse, error) {
+	limit = clampLimit(limit)
+
+	// Fetch one extra row to know whether another page exists
+	reports, err := s.repo.GetReporterReports(ctx, userID, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(reports) > limit
+	if hasMore {
+		reports = reports[:limit]
+	}
+
+	response := &ReportListResponse{
+		Reports: make([]ReportResponse, 0, len(reports)),
+		HasMore: hasMore,
+	}
+	for i := range reports {
+		response.Reports = append(response.Reports, *buildReportResponse(&reports[i]))
+	}
+	if hasMore {
+		next := reports[len(reports)-1].ID
+		response.NextCursor = &next
+	}
+	return response, nil
+}
+
+// Moderators
+
+func (s *service) GetReviewQueue(ctx context.Context, userID uint, status, targetType string, cursor uint, limit int) (*ReviewListResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case "", StatusOpen, StatusInReview, StatusActioned, StatusDismissed:
+	default:
+		return nil, fmt.Errorf("invalid status: %s", status)
+	}
+	switch targetType {
+	case "", TargetTypeMessage, TargetTypeFile, TargetTypeProfile:
+	default:
+		return nil, fmt.Errorf("invalid target type: %s", targetType)
+	}
+	limit = clampLimit(limit)
+
+	reports, err := s.repo.GetReports(ctx, status, targetType, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(reports) > limit
+	if hasMore {
+		reports = reports[:limit]
+	}
+
+	response := &ReviewListResponse{
+		Reports: make([]ReviewResponse, 0, len(reports)),
+		HasMore: hasMore,
+	}
+	for i := range reports {
+		response.Reports = append(response.Reports, *buildReviewResponse(&reports[i], nil))
+	}
+	if hasMore {
+		next := reports[len(reports)-1].ID
+		response.NextCursor = &next
+	}
+	return response, nil
+}
+
+func (s *service) GetReview(ctx context.Context, userID, reportID uint) (*ReviewResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	return s.loadReview(ctx, reportID)
+}
+
+func (s *service) ClaimReport(ctx context.Context, userID, reportID uint) (*ReviewResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != StatusOpen && report.Status != StatusInReview {
+		return nil, errors.New("report has already been resolved")
+	}
+
+	now := time.Now().UTC()
+	claimed, err := s.repo.ClaimReport(ctx, reportID, userID, now, now.Add(-ClaimTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if !claimed {
+		return nil, errors.New("report is being reviewed by another moderator")
+	}
+	return s.loadReview(ctx, reportID)
+}
+
+func (s *service) ResolveReport(ctx context.Context, userID, reportID uint, req *ResolveReportRequest) (*ReviewResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	report, err := s.repo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	if report.Status != StatusOpen && report.Status != StatusInReview {
+		return nil, errors.New("report has already been resolved")
+	}
+
+	// Check the action fits the target before recording the decision
+	switch req.Resolution {
+	case ResolutionDeleteMessage:
+		if report.TargetType != TargetTypeMessage {
+			return nil, errors.New("only message reports can be resolved by deleting the message")
+		}
+	case ResolutionWarn, ResolutionSuspendUser:
+		if report.TargetUserID == nil {
+			return nil, errors.New("the reported user no longer exists")
+		}
+		if *report.TargetUserID == userID {
+			return nil, errors.New("you cannot take action against yourself")
+		}
+	}
+
+	status := StatusActioned
+	if req.Resolution == ResolutionDismiss {
+		status = StatusDismissed
+	}
+	note := strings.TrimSpace(req.Note)
+
+	now := time.Now().UTC()
+	staleBefore := now.Add(-ClaimTimeout)
+	if report.ClaimedBy != nil && *report.ClaimedBy != userID && (report.ClaimedAt == nil || !report.ClaimedAt.Before(staleBefore)) {
+		return nil, errors.New("report is being reviewed by another moderator")
+	}
+
+	// Enforce before recording the decision, so a failed action leaves the report open to retry.
+	// Every action is safe to repeat if recording then fails.
+	if err := s.enforce(ctx, report, req, note, now); err != nil {
+		return nil, err
+	}
+
+	resolved, err := s.repo.ResolveReport(ctx, reportID, userID, status, req.Resolution, note, now, staleBefore)
+	if err != nil {
+		return nil, err
+	}
+	if !resolved {
+		return nil, errors.New("report is being reviewed by another moderator")
+	}
+
+	// Tell the reporter their report was handled, without the moderator's note
+	s.broadcaster.HandleReportUpdated(ctx, report.ReporterID, map[string]interface{}{
+		"report_id":   report.ID,
+		"target_type": report.TargetType,
+		"target_id":   report.TargetID,
+		"status":      status,
+		"resolution":  req.Resolution,
+	})
+
+	return s.loadReview(ctx, reportID)
+}
+
+// Helper functions
+
+// snapshotTarget checks the reporter can see the target and copies it into the report
+func (s *service) snapshotTarget(ctx context.Context, userID uint, report *Report) error {
+	var authorID uint
+	switch report.TargetType {
+	case TargetTypeMessage:
+		message, err := s.repo.GetMessageTarget(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+		isParticipant, err := s.repo.CheckUserInConversation(ctx, message.ConversationID, userID)
+		if err != nil {
+			return err
+		}
+		// Shadow-hidden messages only exist for their sender
+		if !isParticipant || (message.HiddenAt != nil && message.SenderID != userID) {
+			return errors.New("message not found")
+		}
+		report.ConversationID = &message.ConversationID
+		report.Snapshot.Content = message.Content
+		report.Snapshot.MessageType = message.MessageType
+		report.Snapshot.SentAt = &message.CreatedAt
+		authorID = message.SenderID
+
+	case TargetTypeFile:
+		hasAccess, err := s.fileService.CheckFileAccess(ctx, report.TargetID, userID)
+		if err != nil {
+			return err
+		}
+		if !hasAccess {
+			return errors.New("file not found")
+		}
+		target, err := s.repo.GetFileTarget(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+		report.Snapshot.FileName = target.OriginalName
+		report.Snapshot.MimeType = target.MimeType
+		report.Snapshot.FileSize = target.FileSize
+		authorID = target.UserID
+
+	case TargetTypeProfile:
+		authorID = report.TargetID
+	}
+
+	author, err := s.repo.GetUserTarget(ctx, authorID)
+	if err != nil {
+		return err
+	}
+	report.TargetUserID = &author.ID
+	report.Snapshot.Username = author.Username
+	report.Snapshot.DisplayName = author.DisplayName
+	if report.TargetType == TargetTypeProfile {
+		report.Snapshot.Bio = author.Bio
+		report.Snapshot.Avatar = author.Avatar
+	}
+	return nil
+}
+
+// enforce carries out a resolution against the reported content or user
+func (s *service) enforce(ctx context.Context, report *Report, req *ResolveReportRequest, note string, now time.Time) error {
+	switch req.Resolution {
+	case ResolutionDeleteMessage:
+		// Attachments cascade with the message, so look up their objects first
+		files, err := s.repo.GetMessageFiles(ctx, []uint{report.TargetID})
+		if err != nil {
+			return err
+		}
+		deleted, err := s.repo.DeleteMessage(ctx, report.TargetID)
+		if err != nil {
+			return err
+		}
+		file.DeleteStoredObjects(ctx, s.repo, files)
+		if deleted && report.ConversationID != nil {
+			s.broadcaster.HandleMessageDeleted(ctx, *report.ConversationID, report.TargetID)
+		}
+
+	case ResolutionWarn:
+		s.broadcaster.HandleModerationNotice(ctx, *report.TargetUserID, map[string]interface{}{
+			"kind":        "warning",
+			"report_id":   report.ID,
+			"reason":      report.Reason,
+			"note":        note,
+			"target_type": report.TargetType,
+			"target_id":   report.TargetID,
+		})
+
+	case ResolutionSuspendUser:
+		hours := req.SuspendHours
+		if hours <= 0 {
+			hours = DefaultSuspendHours
+		}
+		until := now.Add(time.Duration(hours) * time.Hour)
+		if err := s.repo.SuspendUser(ctx, *report.TargetUserID, until); err != nil {
+			return err
+		}
+		// Access tokens are rejected by the auth middleware until the suspension ends
+		if err := auth.MarkUserSuspended(ctx, *report.TargetUserID, until); err != nil {
+			logger.Error("Failed to cache user suspension", zap.Uint("user_id", *report.TargetUserID), zap.Error(err))
+		}
+		s.broadcaster.HandleModerationNotice(ctx, *report.TargetUserID, map[string]interface{}{
+			"kind":            "suspension",
+			"report_id":       report.ID,
+			"reason":          report.Reason,
+			"note":            note,
+			"suspended_until": until,
+		})
+	}
+	return nil
+}
+
+// loadReview loads a report with its audit trail
+func (s *service) loadReview(ctx context.Context, reportID uint) (*ReviewResponse, error) {
+	report, err := s.repo.GetReportByID(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	actions, err := s.repo.GetActions(ctx, reportID)
+	if err != nil {
+		return nil, err
+	}
+	return buildReviewResponse(report, actions), nil
+}
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+func buildReportResponse(report *Report) *ReportResponse {
+	return &ReportResponse{
+		ID:         report.ID,
+		TargetType: report.TargetType,
+		TargetID:   report.TargetID,
+		Reason:     report.Reason,
+		Comment:    report.Comment,
+		Status:     report.Status,
+		Resolution: report.Resolution,
+		CreatedAt:  report.CreatedAt,
+		ResolvedAt: report.ResolvedAt,
+	}
+}
+
+func buildReviewResponse(report *Report, actions []ReportAction) *ReviewResponse {
+	return &ReviewResponse{
+		ID:             report.ID,
+		ReporterID:     report.ReporterID,
+		TargetType:     report.TargetType,
+		TargetID:       report.TargetID,
+		TargetUserID:   report.TargetUserID,
+		ConversationID: report.ConversationID,
+		Reason:         report.Reason,
+		Comment:        report.Comment,
+		Snapshot:       report.Snapshot,
+		Status:         report.Status,
+		ClaimedBy:      report.ClaimedBy,
+		ClaimedAt:      report.ClaimedAt,
+		Resolution:     report.Resolution,
+		ResolutionNote: report.ResolutionNote,
+		ResolvedBy:     report.ResolvedBy,
+		ResolvedAt:     report.ResolvedAt,
+		CreatedAt:      report.CreatedAt,
+		Actions:        actions,
+	}
+}
@@ -0,0 +1,248 @@
+package retention
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"huddle/pkg/logger"
+	"huddle/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type Handler struct {
+	service Service
+}
+
+// NewHandler creates a new retention handler
+func NewHandler(service Service) *Handler {
+	return &Handler{
+		service: service,
+	}
+}
+
+// Policies
+
+// GetPolicies lists the workspace policy, conversation overrides and legal holds
+func (h *Handler) GetPolicies(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	policies, err := h.service.GetPolicies(c.Request.Context(), userID)
+	if err != nil {
+		logger.Error("Failed to get retention policies", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, policies, "Retention policies retrieved successfully")
+}
+
+// SetWorkspacePolicy sets the retention applied to conversations without their own policy
+func (h *Handler) SetWorkspacePolicy(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var req SetPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	policy, err := h.service.SetWorkspacePolicy(c.Request.Context(), userID, &req)
+	if err != nil {
+		logger.Error("Failed to set workspace retention policy", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, policy, "Retention policy saved successfully")
+}
+
+// SetConversationPolicy sets a conversation's retention, overriding the workspace policy
+func (h *Handler) SetConversationPolicy(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+
+	var req SetPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	policy, err := h.service.SetConversationPolicy(c.Request.Context(), userID, conversationID, &req)
+	if err != nil {
+		logger.Error("Failed to set conversation retention policy", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, policy, "Retention policy saved successfully")
+}
+
+// DeleteConversationPolicy removes a conversation's retention override
+func (h *Handler) DeleteConversationPolicy(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.DeleteConversationPolicy(c.Request.Context(), userID, conversationID); err != nil {
+		logger.Error("Failed to delete conversation retention policy", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Retention policy deleted successfully")
+}
+
+// GetEffectivePolicy gets how long messages in a conversation are kept
+func (h *Handler) GetEffectivePolicy(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.service.GetEffectivePolicy(c.Request.Context(), userID, conversationID)
+	if err != nil {
+		logger.Error("Failed to get effective retention policy", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, policy, "Retention policy retrieved successfully")
+}
+
+// Legal holds
+
+// PlaceLegalHold exempts a conversation from purges
+func (h *Handler) PlaceLegalHold(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+
+	var req PlaceLegalHoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.BadRequestResponse(c, "Invalid request data")
+		return
+	}
+
+	hold, err := h.service.PlaceLegalHold(c.Request.Context(), userID, conversationID, &req)
+	if err != nil {
+		logger.Error("Failed to place legal hold", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, hold, "Legal hold placed successfully")
+}
+
+// ReleaseLegalHold lets purges apply to a conversation again
+func (h *Handler) ReleaseLegalHold(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	conversationID, ok := parseConversationID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.service.ReleaseLegalHold(c.Request.Context(), userID, conversationID); err != nil {
+		logger.Error("Failed to release legal hold", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, nil, "Legal hold released successfully")
+}
+
+// Purge runs
+
+// StartPurge starts a purge in the background
+func (h *Handler) StartPurge(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	run, err := h.service.StartPurge(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, ErrPurgeRunning) {
+			utils.ErrorResponse(c, http.StatusConflict, "PURGE_RUNNING", err.Error(), nil)
+			return
+		}
+		logger.Error("Failed to start purge", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, run, "Purge started successfully")
+}
+
+// GetRuns lists purge runs, newest first
+func (h *Handler) GetRuns(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	var cursor uint64
+	if cursorStr := c.Query("cursor"); cursorStr != "" {
+		var err error
+		cursor, err = strconv.ParseUint(cursorStr, 10, 32)
+		if err != nil {
+			utils.BadRequestResponse(c, "Invalid cursor")
+			return
+		}
+	}
+
+	limitStr := c.DefaultQuery("limit", "20")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		limit = 20
+	}
+
+	runs, err := h.service.GetRuns(c.Request.Context(), userID, uint(cursor), limit)
+	if err != nil {
+		logger.Error("Failed to get purge runs", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, runs, "Purge runs retrieved successfully")
+}
+
+// GetReport gets a purge run with its per-conversation entries
+func (h *Handler) GetReport(c *gin.Context) {
+	userID := c.GetUint("user_id")
+
+	runID, err := strconv.ParseUint(c.Param("run_id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid run ID")
+		return
+	}
+
+	report, err := h.service.GetReport(c.Request.Context(), userID, uint(runID))
+	if err != nil {
+		logger.Error("Failed to get purge report", zap.Error(err))
+		utils.BadRequestResponse(c, err.Error())
+		return
+	}
+
+	utils.SuccessResponse(c, report, "Purge report retrieved successfully")
+}
+
+// Helper functions
+
+func parseConversationID(c *gin.Context) (uint, bool) {
+	conversationID, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		utils.BadRequestResponse(c, "Invalid conversation ID")
+		return 0, false
+	}
+	return uint(conversationID), true
+}
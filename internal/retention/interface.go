@@ -0,0 +1,65 @@
+package retention
+
+import (
+	"context"
+	"time"
+
+	"huddle/internal/file"
+)
+
+// Repository interface defines data access methods for retention
+type Repository interface {
+	// Policies
+	GetPolicy(ctx context.Context, conversationID *uint) (*Policy, error)
+	GetPolicies(ctx context.Context) ([]Policy, error)
+	UpsertPolicy(ctx context.Context, policy *Policy) error
+	DeletePolicy(ctx context.Context, conversationID uint) (bool, error)
+	ConversationExists(ctx context.Context, conversationID uint) (bool, error)
+
+	// Legal holds
+	PlaceLegalHold(ctx context.Context, hold *LegalHold) (bool, error)
+	ReleaseLegalHold(ctx context.Context, conversationID uint) (bool, error)
+	GetLegalHolds(ctx context.Context) ([]LegalHold, error)
+	IsOnLegalHold(ctx context.Context, conversationID uint) (bool, error)
+
+	// Purge
+	GetPurgeTargets(ctx context.Context) ([]PurgeTarget, error)
+	GetPurgeableMessageIDs(ctx context.Context, conversationID uint, cutoff time.Time, limit int) ([]uint, error)
+	GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error)
+	DeleteMessages(ctx context.Context, messageIDs []uint) (*PurgeBatch, error)
+	CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error)
+
+	// Purge reports
+	StartRun(ctx context.Context, run *PurgeRun, staleBefore time.Time) (bool, error)
+	FinishRun(ctx context.Context, run *PurgeRun) error
+	CreateEntry(ctx context.Context, entry *PurgeEntry) error
+	GetRuns(ctx context.Context, cursor uint, limit int) ([]PurgeRun, error)
+	GetRunByID(ctx context.Context, runID uint) (*PurgeRun, error)
+	GetEntries(ctx context.Context, runID uint) ([]PurgeEntry, error)
+}
+
+// Service interface defines business logic methods for retention
+type Service interface {
+	// Policies
+	GetPolicies(ctx context.Context, userID uint) (*PolicyListResponse, error)
+	SetWorkspacePolicy(ctx context.Context, userID uint, req *SetPolicyRequest) (*PolicyResponse, error)
+	SetConversationPolicy(ctx context.Context, userID, conversationID uint, req *SetPolicyRequest) (*PolicyResponse, error)
+	DeleteConversationPolicy(ctx context.Context, userID, conversationID uint) error
+	GetEffectivePolicy(ctx context.Context, userID, conversationID uint) (*EffectivePolicyResponse, error)
+
+	// Legal holds
+	PlaceLegalHold(ctx context.Context, userID, conversationID uint, req *PlaceLegalHoldRequest) (*LegalHold, error)
+	ReleaseLegalHold(ctx context.Context, userID, conversationID uint) error
+
+	// Purge
+	StartPurge(ctx context.Context, userID uint) (*PurgeRun, error)
+	RunPurge(ctx context.Context, kind string, triggeredBy *uint) (*PurgeRun, error)
+	GetRuns(ctx context.Context, userID, cursor uint, limit int) (*PurgeRunListResponse, error)
+	GetReport(ctx context.Context, userID, runID uint) (*PurgeReportResponse, error)
+}
+
+// Broadcaster defines the real-time events published by the retention module.
+// It is implemented by websocket.Service.
+type Broadcaster interface {
+	HandleMessageDeleted(ctx context.Context, conversationID uint, messageID uint)
+}
@@ -0,0 +1,156 @@
+package retention
+
+import (
+	"time"
+)
+
+// Policy represents how long messages are kept; the workspace policy has no conversation
+type Policy struct {
+	ID             uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	ConversationID *uint     `json:"conversation_id"`
+	RetentionDays  *int      `json:"retention_days"` // nil keeps messages forever
+	UpdatedBy      *uint     `json:"updated_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for Policy
+func (Policy) TableName() string {
+	return "retention_policies"
+}
+
+// LegalHold exempts a conversation from every purge
+type LegalHold struct {
+	ConversationID uint      `json:"conversation_id" gorm:"primaryKey"`
+	Reason         string    `json:"reason" gorm:"not null;size:500"`
+	PlacedBy       *uint     `json:"placed_by"`
+	CreatedAt      time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for LegalHold
+func (LegalHold) TableName() string {
+	return "legal_holds"
+}
+
+// PurgeRun is the audit record of one purge pass
+type PurgeRun struct {
+	ID                  uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	Kind                string     `json:"kind" gorm:"not null;size:20"`
+	TriggeredBy         *uint      `json:"triggered_by"`
+	Status              string     `json:"status" gorm:"not null;default:'running';size:20"`
+	ConversationsPurged int        `json:"conversations_purged"`
+	ConversationsHeld   int        `json:"conversations_held"`
+	MessagesDeleted     int        `json:"messages_deleted"`
+	ReactionsDeleted    int        `json:"reactions_deleted"`
+	FilesDeleted        int        `json:"files_deleted"`
+	ObjectsDeleted      int        `json:"objects_deleted"`
+	ObjectsFailed       int        `json:"objects_failed"` // Stored objects left orphaned after their rows were deleted
+	Error               string     `json:"error"`
+	StartedAt           time.Time  `json:"started_at"`
+	FinishedAt          *time.Time `json:"finished_at"`
+}
+
+// TableName specifies the table name for PurgeRun
+func (PurgeRun) TableName() string {
+	return "retention_purge_runs"
+}
+
+// PurgeEntry records what a run did in one conversation
+type PurgeEntry struct {
+	ID               uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	RunID            uint      `json:"run_id" gorm:"not null"`
+	ConversationID   uint      `json:"conversation_id" gorm:"not null"`
+	RetentionDays    int       `json:"retention_days"`
+	Cutoff           time.Time `json:"cutoff"`
+	LegalHold        bool      `json:"legal_hold"` // Skipped because the conversation is held
+	MessagesDeleted  int       `json:"messages_deleted"`
+	ReactionsDeleted int       `json:"reactions_deleted"`
+	FilesDeleted     int       `json:"files_deleted"`
+	CreatedAt        time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for PurgeEntry
+func (PurgeEntry) TableName() string {
+	return "retention_purge_entries"
+}
+
+// PurgeTarget is a conversation with an effective retention period
+type PurgeTarget struct {
+	ConversationID uint
+	RetentionDays  int
+	LegalHold      bool
+}
+
+// PurgeBatch counts the rows deleted for one batch of messages
+type PurgeBatch struct {
+	Messages  int
+	Reactions int
+	Files     int
+}
+
+// Run Kind Constants
+const (
+	KindScheduled = "scheduled"
+	KindManual    = "manual"
+)
+
+// Run Status Constants
+const (
+	RunStatusRunning   = "running"
+	RunStatusCompleted = "completed"
+	RunStatusFailed    = "failed"
+)
+
+// Policy Source Constants
+const (
+	SourceConversation = "conversation"
+	SourceWorkspace    = "workspace"
+	SourceNone         = "none"
+)
+
+// DTOs for API requests/responses
+
+// SetPolicyRequest represents request to set a retention policy
+type SetPolicyRequest struct {
+	RetentionDays *int `json:"retention_days" binding:"omitempty,min=1,max=36500"` // null keeps messages forever
+}
+
+// PlaceLegalHoldRequest represents request to place a conversation on legal hold
+type PlaceLegalHoldRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"`
+}
+
+// PolicyResponse represents a retention policy response
+type PolicyResponse struct {
+	ConversationID *uint     `json:"conversation_id,omitempty"`
+	RetentionDays  *int      `json:"retention_days"`
+	UpdatedBy      *uint     `json:"updated_by,omitempty"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// PolicyListResponse represents the workspace policy, conversation overrides and legal holds
+type PolicyListResponse struct {
+	Workspace     *PolicyResponse  `json:"workspace"`
+	Conversations []PolicyResponse `json:"conversations"`
+	LegalHolds    []LegalHold      `json:"legal_holds"`
+}
+
+// EffectivePolicyResponse represents the retention that applies to a conversation
+type EffectivePolicyResponse struct {
+	ConversationID uint   `json:"conversation_id"`
+	RetentionDays  *int   `json:"retention_days"` // null keeps messages forever
+	Source         string `json:"source"`
+}
+
+// PurgeRunListResponse represents a page of purge runs
+type PurgeRunListResponse struct {
+	Runs       []PurgeRun `json:"runs"`
+	NextCursor *uint      `json:"next_cursor,omitempty"`
+	HasMore    bool       `json:"has_more"`
+}
+
+// PurgeReportResponse represents a purge run with its per-conversation entries
+type PurgeReportResponse struct {
+	PurgeRun
+	Entries []PurgeEntry `json:"entries"`
+}
@@ -0,0 +1,360 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/database"
+	"huddle/internal/file"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new retention repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Policies
+
+func (r *repository) GetPolicy(ctx context.Context, conversationID *uint) (*Policy, error) {
+	var policies []Policy
+	query := r.db.WithContext(ctx)
+	if conversationID == nil {
+		query = query.Where("conversation_id IS NULL")
+	} else {
+		query = query.Where("conversation_id = ?", *conversationID)
+	}
+	if err := query.Limit(1).Find(&policies).Error; err != nil {
+		logger.Error("Failed to get retention policy", zap.Error(err))
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return &policies[0], nil
+}
+
+func (r *repository) GetPolicies(ctx context.Context) ([]Policy, error) {
+	var policies []Policy
+	if err := r.db.WithContext(ctx).Order("conversation_id ASC NULLS FIRST").Find(&policies).Error; err != nil {
+		logger.Error("Failed to get retention policies", zap.Error(err))
+		return nil, err
+	}
+	return policies, nil
+}
+
+func (r *repository) UpsertPolicy(ctx context.Context, policy *Policy) error {
+	var saved []Policy
+	if err := r.db.WithContext(ctx).Raw(`
+		INSERT INTO retention_policies (conversation_id, retention_days, updated_by)
+		VALUES (?, ?, ?)
+		ON CONFLICT ((COALESCE(conversation_id, 0))) DO UPDATE
+		SET retention_days = EXCLUDED.retention_days, updated_by = EXCLUDED.updated_by
+		RETURNING *`,
+		policy.ConversationID, policy.RetentionDays, policy.UpdatedBy,
+	).Scan(&saved).Error; err != nil {
+		logger.Error("Failed to save retention policy", zap.Error(err))
+		return err
+	}
+	if len(saved) > 0 {
+		*policy = saved[0]
+	}
+	logger.Info("Retention policy saved", zap.Uintp("conversation_id", policy.ConversationID), zap.Intp("retention_days", policy.RetentionDays))
+	return nil
+}
+
+func (r *repository) DeletePolicy(ctx context.Context, conversationID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).Delete(&Policy{})
+	if result.Error != nil {
+		logger.Error("Failed to delete retention policy", zap.Error(result.Error))
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repository) ConversationExists(ctx context.Context, conversationID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("conversations").
+		Where("id = ?", conversationID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check conversation", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Legal holds
+
+func (r *repository) PlaceLegalHold(ctx context.Context, hold *LegalHold) (bool, error) {
+	result := r.db.WithContext(ctx).Exec(`
+		INSERT INTO legal_holds (conversation_id, reason, placed_by)
+		VALUES (?, ?, ?)
+		ON CONFLICT (conversation_id) DO NOTHING`,
+		hold.ConversationID, hold.Reason, hold.PlacedBy,
+	)
+	if result.Error != nil {
+		logger.Error("Failed to place legal hold", zap.Error(result.Error))
+		return false, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return false, nil
+	}
+	logger.Info("Legal hold placed", zap.Uint("conversation_id", hold.ConversationID), zap.Uintp("placed_by", hold.PlacedBy))
+	return true, nil
+}
+
+func (r *repository) ReleaseLegalHold(ctx context.Context, conversationID uint) (bool, error) {
+	result := r.db.WithContext(ctx).Where("conversation_id = ?", conversationID).Delete(&LegalHold{})
+	if result.Error != nil {
+		logger.Error("Failed to release legal hold", zap.Error(result.Error))
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		logger.Info("Legal hold released", zap.Uint("conversation_id", conversationID))
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *repository) GetLegalHolds(ctx context.Context) ([]LegalHold, error) {
+	var holds []LegalHold
+	if err := r.db.WithContext(ctx).Order("conversation_id ASC").Find(&holds).Error; err != nil {
+		logger.Error("Failed to get legal holds", zap.Error(err))
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *repository) IsOnLegalHold(ctx context.Context, conversationID uint) (bool, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Model(&LegalHold{}).
+		Where("conversation_id = ?", conversationID).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to check legal hold", zap.Error(err))
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Purge
+
+func (r *repository) GetPurgeTargets(ctx context.Context) ([]PurgeTarget, error) {
+	var targets []PurgeTarget
+	// A conversation policy, even one keeping messages forever, overrides the workspace policy
+	if err := r.db.WithContext(ctx).Raw(`
+		SELECT c.id AS conversation_id,
+			CASE WHEN p.id IS NOT NULL THEN p.retention_days ELSE w.retention_days END AS retention_days,
+			EXISTS (SELECT 1 FROM legal_holds h WHERE h.conversation_id = c.id) AS legal_hold
+		FROM conversations c
+		LEFT JOIN retention_policies p ON p.conversation_id = c.id
+		LEFT JOIN retention_policies w ON w.conversation_id IS NULL
+		WHERE (CASE WHEN p.id IS NOT NULL THEN p.retention_days ELSE w.retention_days END) IS NOT NULL
+		ORDER BY c.id ASC`,
+	).Scan(&targets).Error; err != nil {
+		logger.Error("Failed to get purge targets", zap.Error(err))
+		return nil, err
+	}
+	return targets, nil
+}
+
+func (r *repository) GetPurgeableMessageIDs(ctx context.Context, conversationID uint, cutoff time.Time, limit int) ([]uint, error) {
+	var messageIDs []uint
+	if err := r.db.WithContext(ctx).
+		Table("messages").
+		Where("conversation_id = ? AND created_at < ?", conversationID, cutoff).
+		Order("id ASC").
+		Limit(limit).
+		Pluck("id", &messageIDs).Error; err != nil {
+		logger.Error("Failed to get purgeable messages", zap.Error(err))
+		return nil, err
+	}
+	return messageIDs, nil
+}
+
+func (r *repository) GetMessageFiles(ctx context.Context, messageIDs []uint) ([]file.StoredObject, error) {
+	var files []file.StoredObject
+	if len(messageIDs) == 0 {
+		return files, nil
+	}
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Select("id, message_id, object_key, thumbnail_url, preview_url").
+		Where("message_id IN ?", messageIDs).
+		Find(&files).Error; err != nil {
+		logger.Error("Failed to get purged message files", zap.Error(err))
+		return nil, err
+	}
+	return files, nil
+}
+
+func (r *repository) DeleteMessages(ctx context.Context, messageIDs []uint) (*PurgeBatch, error) {
+	batch := &PurgeBatch{}
+	if len(messageIDs) == 0 {
+		return batch, nil
+	}
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec("DELETE FROM message_reactions WHERE message_id IN ?", messageIDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		batch.Reactions = int(result.RowsAffected)
+
+		if err := tx.Exec("DELETE FROM pinned_messages WHERE message_id IN ?", messageIDs).Error; err != nil {
+			return err
+		}
+		if err := tx.Exec("DELETE FROM message_bookmarks WHERE message_id IN ?", messageIDs).Error; err != nil {
+			return err
+		}
+
+		result = tx.Exec("DELETE FROM files WHERE message_id IN ?", messageIDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		batch.Files = int(result.RowsAffected)
+
+		result = tx.Exec("DELETE FROM messages WHERE id IN ?", messageIDs)
+		if result.Error != nil {
+			return result.Error
+		}
+		batch.Messages = int(result.RowsAffected)
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to purge messages", zap.Error(err))
+		return nil, err
+	}
+	return batch, nil
+}
+
+func (r *repository) CountFilesByObjectKey(ctx context.Context, objectKey string) (int, error) {
+	var count int64
+	if err := r.db.WithContext(ctx).
+		Table("files").
+		Where("object_key = ?", objectKey).
+		Count(&count).Error; err != nil {
+		logger.Error("Failed to count files by object key", zap.Error(err))
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// Purge reports
+
+func (r *repository) StartRun(ctx context.Context, run *PurgeRun, staleBefore time.Time) (bool, error) {
+	var started bool
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// A run left behind by a crashed process must not block the next one
+		if err := tx.Model(&PurgeRun{}).
+			Where("status = ? AND started_at < ?", RunStatusRunning, staleBefore).
+			Updates(map[string]interface{}{
+				"status":      RunStatusFailed,
+				"error":       "interrupted",
+				"finished_at": run.StartedAt,
+			}).Error; err != nil {
+			return err
+		}
+
+		var created []PurgeRun
+		if err := tx.Raw(`
+			INSERT INTO retention_purge_runs (kind, triggered_by, status, started_at)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT DO NOTHING
+			RETURNING *`,
+			run.Kind, run.TriggeredBy, RunStatusRunning, run.StartedAt,
+		).Scan(&created).Error; err != nil {
+			return err
+		}
+		if started = len(created) > 0; started {
+			*run = created[0]
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("Failed to start purge run", zap.Error(err))
+		return false, err
+	}
+	return started, nil
+}
+
+func (r *repository) FinishRun(ctx context.Context, run *PurgeRun) error {
+	if err := r.db.WithContext(ctx).
+		Model(&PurgeRun{}).
+		Where("id = ?", run.ID).
+		Updates(map[string]interface{}{
+			"status":               run.Status,
+			"conversations_purged": run.ConversationsPurged,
+			"conversations_held":   run.ConversationsHeld,
+			"messages_deleted":     run.MessagesDeleted,
+			"reactions_deleted":    run.ReactionsDeleted,
+			"files_deleted":        run.FilesDeleted,
+			"objects_deleted":      run.ObjectsDeleted,
+			"objects_failed":       run.ObjectsFailed,
+			"error":                run.Error,
+			"finished_at":          run.FinishedAt,
+		}).Error; err != nil {
+		logger.Error("Failed to finish purge run", zap.Error(err))
+		return err
+	}
+	logger.Info("Purge run finished",
+		zap.Uint("run_id", run.ID),
+		zap.String("status", run.Status),
+		zap.Int("messages_deleted", run.MessagesDeleted),
+		zap.Int("files_deleted", run.FilesDeleted))
+	return nil
+}
+
+func (r *repository) CreateEntry(ctx context.Context, entry *PurgeEntry) error {
+	if err := r.db.WithContext(ctx).Create(entry).Error; err != nil {
+		logger.Error("Failed to create purge entry", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *repository) GetRuns(ctx context.Context, cursor uint, limit int) ([]PurgeRun, error) {
+	var runs []PurgeRun
+	query := r.db.WithContext(ctx)
+	if cursor > 0 {
+		query = query.Where("id < ?", cursor)
+	}
+	if err := query.Order("id DESC").Limit(limit).Find(&runs).Error; err != nil {
+		logger.Error("Failed to get purge runs", zap.Error(err))
+		return nil, err
+	}
+	return runs, nil
+}
+
+func (r *repository) GetRunByID(ctx context.Context, runID uint) (*PurgeRun, error) {
+	var run PurgeRun
+	if err := r.db.WithContext(ctx).First(&run, runID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("purge run not found")
+		}
+		logger.Error("Failed to get purge run", zap.Error(err))
+		return nil, err
+	}
+	return &run, nil
+}
+
+func (r *repository) GetEntries(ctx context.Context, runID uint) ([]PurgeEntry, error) {
+	var entries []PurgeEntry
+	if err := r.db.WithContext(ctx).
+		Where("run_id = ?", runID).
+		Order("id ASC").
+		Find(&entries).Error; err != nil {
+		logger.Error("Failed to get purge entries", zap.Error(err))
+		return nil, err
+	}
+	return entries, nil
+}
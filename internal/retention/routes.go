@@ -0,0 +1,34 @@
+package retention
+
+import (
+	"huddle/internal/middleware"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SetupRoutes sets up retention routes
+func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
+	// Policies, legal holds and purge reports (moderators only)
+	retention := router.Group("/moderation/retention")
+	retention.Use(middleware.AuthMiddleware())
+	{
+		retention.GET("", handler.GetPolicies)              // Workspace policy, conversation overrides and legal holds
+		retention.PUT("", handler.SetWorkspacePolicy)       // Set workspace policy
+		retention.PUT("/conversations/:id", handler.SetConversationPolicy)       // Set conversation policy
+		retention.DELETE("/conversations/:id", handler.DeleteConversationPolicy) // Fall back to workspace policy
+
+		retention.PUT("/holds/:id", handler.PlaceLegalHold)       // Place conversation on legal hold
+		retention.DELETE("/holds/:id", handler.ReleaseLegalHold)  // Release legal hold
+
+		retention.POST("/runs", handler.StartPurge)        // Start a purge now
+		retention.GET("/runs", handler.GetRuns)            // List purge runs
+		retention.GET("/runs/:run_id", handler.GetReport)  // Get purge report
+	}
+
+	// Effective policy (participants)
+	conversations := router.Group("/conversations/:id/retention")
+	conversations.Use(middleware.AuthMiddleware())
+	{
+		conversations.GET("", handler.GetEffectivePolicy) // How long messages are kept
+	}
+}
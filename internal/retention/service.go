@@ -0,0 +1,454 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"huddle/internal/config"
+	"huddle/internal/conversation"
+	"huddle/internal/file"
+	"huddle/internal/moderation"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// Runs still marked running after this long (e.g. after a crash) are marked failed
+	purgeRunTimeout = 6 * time.Hour
+
+	// Messages deleted per transaction when not configured
+	defaultPurgeBatchSize = 500
+)
+
+// ErrPurgeRunning is returned when a purge is started while another is in progress
+var ErrPurgeRunning = errors.New("a purge is already running")
+
+type service struct {
+	repo                Repository
+	broadcaster         Broadcaster
+	conversationService conversation.Service
+	moderationService   moderation.Service
+	batchSize           int
+}
+
+// NewService creates a new retention service
+func NewService(repo Repository, broadcaster Broadcaster, conversationService conversation.Service, moderationService moderation.Service) Service {
+	batchSize := defaultPurgeBatchSize
+	if cfg := config.GetConfig(); cfg != nil && cfg.Retention.BatchSize > 0 {
+		batchSize = cfg.Retention.BatchSize
+	}
+	return &service{
+		repo:                repo,
+		broadcaster:         broadcaster,
+		conversationService: conversationService,
+		moderationService:   moderationService,
+		batchSize:           batchSize,
+	}
+}
+
+// Policies
+
+func (s *service) GetPolicies(ctx context.Context, userID uint) (*PolicyListResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	policies, err := s.repo.GetPolicies(ctx)
+	if err != nil {
+		return nil, err
+	}
+	holds, err := s.repo.GetLegalHolds(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &PolicyListResponse{
+		Conversations: make([]PolicyResponse, 0, len(policies)),
+		LegalHolds:    holds,
+	}
+	for i := range policies {
+		if policies[i].ConversationID == nil {
+			response.Workspace = buildPolicyResponse(&policies[i])
+			continue
+		}
+		response.Conversations = append(response.Conversations, *buildPolicyResponse(&policies[i]))
+	}
+	return response, nil
+}
+
+func (s *service) SetWorkspacePolicy(ctx context.Context, userID uint, req *SetPolicyRequest) (*PolicyResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		RetentionDays: req.RetentionDays,
+		UpdatedBy:     &userID,
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return buildPolicyResponse(policy), nil
+}
+
+func (s *service) SetConversationPolicy(ctx context.Context, userID, conversationID uint, req *SetPolicyRequest) (*PolicyResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.validateConversation(ctx, conversationID); err != nil {
+		return nil, err
+	}
+
+	policy := &Policy{
+		ConversationID: &conversationID,
+		RetentionDays:  req.RetentionDays,
+		UpdatedBy:      &userID,
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return buildPolicyResponse(policy), nil
+}
+
+// DeleteConversationPolicy removes a conversation override so the workspace policy applies again
+func (s *service) DeleteConversationPolicy(ctx context.Context, userID, conversationID uint) error {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return err
+	}
+
+	deleted, err := s.repo.DeletePolicy(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return errors.New("conversation has no retention policy")
+	}
+	return nil
+}
+
+// GetEffectivePolicy tells participants how long messages in a conversation are kept
+func (s *service) GetEffectivePolicy(ctx context.Context, userID, conversationID uint) (*EffectivePolicyResponse, error) {
+	if err := s.conversationService.ValidateConversationAccess(ctx, userID, conversationID); err != nil {
+		if modErr := s.moderationService.ValidateModerator(ctx, userID); modErr != nil {
+			return nil, err
+		}
+	}
+
+	response := &EffectivePolicyResponse{ConversationID: conversationID, Source: SourceNone}
+
+	policy, err := s.repo.GetPolicy(ctx, &conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		response.RetentionDays = policy.RetentionDays
+		response.Source = SourceConversation
+		return response, nil
+	}
+
+	policy, err = s.repo.GetPolicy(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	if policy != nil {
+		response.RetentionDays = policy.RetentionDays
+		response.Source = SourceWorkspace
+	}
+	return response, nil
+}
+
+// Legal holds
+
+func (s *service) PlaceLegalHold(ctx context.Context, userID, conversationID uint, req *PlaceLegalHoldRequest) (*LegalHold, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	if err := s.validateConversation(ctx, conversationID); err != nil {
+		return nil, err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return nil, errors.New("reason is required")
+	}
+
+	hold := &LegalHold{
+		ConversationID: conversationID,
+		Reason:         reason,
+		PlacedBy:       &userID,
+		CreatedAt:      time.Now().UTC(),
+	}
+	placed, err := s.repo.PlaceLegalHold(ctx, hold)
+	if err != nil {
+		return nil, err
+	}
+	if !placed {
+		return nil, errors.New("conversation is already on legal hold")
+	}
+	return hold, nil
+}
+
+func (s *service) ReleaseLegalHold(ctx context.Context, userID, conversationID uint) error {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return err
+	}
+
+	released, err := s.repo.ReleaseLegalHold(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return errors.New("conversation is not on legal hold")
+	}
+	return nil
+}
+
+// Purge
+
+// StartPurge starts a manual purge in the background and returns its run record
+func (s *service) StartPurge(ctx context.Context, userID uint) (*PurgeRun, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	run, err := s.startRun(ctx, KindManual, &userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// The run outlives the request
+	started := *run
+	go s.executeRun(context.Background(), run)
+	return &started, nil
+}
+
+// RunPurge runs a purge to completion; used by the worker
+func (s *service) RunPurge(ctx context.Context, kind string, triggeredBy *uint) (*PurgeRun, error) {
+	run, err := s.startRun(ctx, kind, triggeredBy)
+	if err != nil {
+		return nil, err
+	}
+	s.executeRun(ctx, run)
+	return run, nil
+}
+
+func (s *service) GetRuns(ctx context.Context, userID, cursor uint, limit int) (*PurgeRunListResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+	limit = clampLimit(limit)
+
+	// Fetch one extra row to know whether another page exists
+	runs, err := s.repo.GetRuns(ctx, cursor, limit+1)
+	if err != nil {
+		return nil, err
+	}
+	hasMore := len(runs) > limit
+	if hasMore {
+		runs = runs[:limit]
+	}
+
+	response := &PurgeRunListResponse{
+		Runs:    runs,
+		HasMore: hasMore,
+	}
+	if hasMore {
+		next := runs[len(runs)-1].ID
+		response.NextCursor = &next
+	}
+	return response, nil
+}
+
+// GetReport gets a purge run with what it did in each conversation
+func (s *service) GetReport(ctx context.Context, userID, runID uint) (*PurgeReportResponse, error) {
+	if err := s.moderationService.ValidateModerator(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	run, err := s.repo.GetRunByID(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := s.repo.GetEntries(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return &PurgeReportResponse{PurgeRun: *run, Entries: entries}, nil
+}
+
+// startRun records a new run, refusing if another is in progress
+func (s *service) startRun(ctx context.Context, kind string, triggeredBy *uint) (*PurgeRun, error) {
+	run := &PurgeRun{
+		Kind:        kind,
+		TriggeredBy: triggeredBy,
+		StartedAt:   time.Now().UTC(),
+	}
+	started, err := s.repo.StartRun(ctx, run, run.StartedAt.Add(-purgeRunTimeout))
+	if err != nil {
+		return nil, err
+	}
+	if !started {
+		return nil, ErrPurgeRunning
+	}
+	return run, nil
+}
+
+// executeRun purges every conversation past its retention and records the outcome.
+// Batches already deleted stay deleted if a later one fails; the run is then marked failed.
+func (s *service) executeRun(ctx context.Context, run *PurgeRun) {
+	err := s.purgeAll(ctx, run)
+
+	now := time.Now().UTC()
+	run.FinishedAt = &now
+	run.Status = RunStatusCompleted
+	if err != nil {
+		run.Status = RunStatusFailed
+		run.Error = err.Error()
+	}
+	if err := s.repo.FinishRun(ctx, run); err != nil {
+		logger.Error("Failed to record purge run", zap.Uint("run_id", run.ID), zap.Error(err))
+	}
+}
+
+func (s *service) purgeAll(ctx context.Context, run *PurgeRun) error {
+	targets, err := s.repo.GetPurgeTargets(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, target := range targets {
+		if err := s.purgeConversation(ctx, run, target); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// purgeConversation deletes one conversation's messages older than its cutoff, batch by batch
+func (s *service) purgeConversation(ctx context.Context, run *PurgeRun, target PurgeTarget) error {
+	entry := &PurgeEntry{
+		RunID:          run.ID,
+		ConversationID: target.ConversationID,
+		RetentionDays:  target.RetentionDays,
+		Cutoff:         run.StartedAt.AddDate(0, 0, -target.RetentionDays),
+		LegalHold:      target.LegalHold,
+	}
+
+	for !entry.LegalHold {
+		messageIDs, err := s.repo.GetPurgeableMessageIDs(ctx, entry.ConversationID, entry.Cutoff, s.batchSize)
+		if err != nil {
+			return err
+		}
+		if len(messageIDs) == 0 {
+			break
+		}
+
+		// A hold placed while the run is in progress stops it at the next batch
+		held, err := s.repo.IsOnLegalHold(ctx, entry.ConversationID)
+		if err != nil {
+			return err
+		}
+		if held {
+			entry.LegalHold = true
+			break
+		}
+
+		// Attachment objects are looked up before the rows go
+		files, err := s.repo.GetMessageFiles(ctx, messageIDs)
+		if err != nil {
+			return err
+		}
+
+		batch, err := s.repo.DeleteMessages(ctx, messageIDs)
+		if err != nil {
+			return err
+		}
+		entry.MessagesDeleted += batch.Messages
+		entry.ReactionsDeleted += batch.Reactions
+		entry.FilesDeleted += batch.Files
+
+		s.deleteObjects(ctx, run, files)
+
+		// Let connected clients drop the messages
+		for _, messageID := range messageIDs {
+			s.broadcaster.HandleMessageDeleted(ctx, entry.ConversationID, messageID)
+		}
+
+		if len(messageIDs) < s.batchSize {
+			break
+		}
+	}
+
+	if entry.MessagesDeleted == 0 {
+		if !entry.LegalHold {
+			return nil
+		}
+		// Only report holds that actually kept something back
+		pending, err := s.repo.GetPurgeableMessageIDs(ctx, entry.ConversationID, entry.Cutoff, 1)
+		if err != nil {
+			return err
+		}
+		if len(pending) == 0 {
+			return nil
+		}
+	}
+	if entry.LegalHold {
+		run.ConversationsHeld++
+	}
+
+	if entry.MessagesDeleted > 0 {
+		run.ConversationsPurged++
+		run.MessagesDeleted += entry.MessagesDeleted
+		run.ReactionsDeleted += entry.ReactionsDeleted
+		run.FilesDeleted += entry.FilesDeleted
+
+		// Unread counters were adjusted by the delete; refresh everyone's badges
+		s.conversationService.NotifyUnreadChanged(ctx, entry.ConversationID, 0)
+	}
+
+	return s.repo.CreateEntry(ctx, entry)
+}
+
+// deleteObjects removes the stored objects of purged attachments, including derived thumbnails
+// and previews; a failure leaves an orphaned object behind
+func (s *service) deleteObjects(ctx context.Context, run *PurgeRun, files []file.StoredObject) {
+	deleted, failed := file.DeleteStoredObjects(ctx, s.repo, files)
+	run.ObjectsDeleted += deleted
+	run.ObjectsFailed += failed
+}
+
+// validateConversation checks that a conversation exists
+func (s *service) validateConversation(ctx context.Context, conversationID uint) error {
+	exists, err := s.repo.ConversationExists(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("conversation not found")
+	}
+	return nil
+}
+
+// Helper functions
+
+func clampLimit(limit int) int {
+	if limit <= 0 {
+		return 20
+	}
+	if limit > 100 {
+		return 100
+	}
+	return limit
+}
+
+func buildPolicyResponse(policy *Policy) *PolicyResponse {
+	return &PolicyResponse{
+		ConversationID: policy.ConversationID,
+		RetentionDays:  policy.RetentionDays,
+		UpdatedBy:      policy.UpdatedBy,
+		UpdatedAt:      policy.UpdatedAt,
+	}
+}
@@ -0,0 +1,69 @@
+package retention
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"huddle/internal/config"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+)
+
+const (
+	// How often the worker purges messages past retention when not configured
+	defaultPurgeInterval = time.Hour
+)
+
+// Worker runs the scheduled retention purge in the background
+type Worker struct {
+	service  Service
+	enabled  bool
+	interval time.Duration
+}
+
+// NewWorker creates a new retention purge worker
+func NewWorker(service Service) *Worker {
+	w := &Worker{
+		service:  service,
+		enabled:  true,
+		interval: defaultPurgeInterval,
+	}
+	if cfg := config.GetConfig(); cfg != nil {
+		w.enabled = cfg.Retention.PurgeEnabled
+		if cfg.Retention.PurgeInterval > 0 {
+			w.interval = cfg.Retention.PurgeInterval
+		}
+	}
+	return w
+}
+
+// Start starts the worker goroutine
+func (w *Worker) Start() {
+	if !w.enabled {
+		logger.Info("Retention purge worker disabled")
+		return
+	}
+	go w.run()
+}
+
+// run purges on every tick
+func (w *Worker) run() {
+	logger.Info("🗑️ Retention purge worker started", zap.Duration("interval", w.interval))
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	w.purge(context.Background())
+	for range ticker.C {
+		w.purge(context.Background())
+	}
+}
+
+// purge runs one scheduled purge; a manual run already in progress covers this tick
+func (w *Worker) purge(ctx context.Context) {
+	if _, err := w.service.RunPurge(ctx, KindScheduled, nil); err != nil && !errors.Is(err, ErrPurgeRunning) {
+		logger.Error("Scheduled retention purge failed to start", zap.Error(err))
+	}
+}
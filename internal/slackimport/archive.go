@@ -0,0 +1,129 @@
+package slackimport
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// dayFileRegex matches a per-day channel file such as general/2024-03-01.json
+var dayFileRegex = regexp.MustCompile(`^([^/]+)/(\d{4}-\d{2}-\d{2})\.json$`)
+
+// archive gives access to the files of a Slack export ZIP
+type archive struct {
+	reader *zip.ReadCloser
+	root   string
+	files  map[string]*zip.File
+	days   map[string][]*zip.File
+}
+
+// openArchive opens a Slack export ZIP and indexes its files
+func openArchive(archivePath string) (*archive, error) {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open Slack export: %w", err)
+	}
+
+	a := &archive{
+		reader: reader,
+		files:  make(map[string]*zip.File),
+		days:   make(map[string][]*zip.File),
+	}
+
+	// Some exports are zipped with an enclosing folder
+	for _, f := range reader.File {
+		if path.Base(f.Name) == ChannelsFile {
+			a.root = path.Dir(f.Name)
+			break
+		}
+	}
+
+	for _, f := range reader.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		name := strings.TrimPrefix(f.Name, a.root+"/")
+		a.files[name] = f
+		if matches := dayFileRegex.FindStringSubmatch(name); matches != nil {
+			a.days[matches[1]] = append(a.days[matches[1]], f)
+		}
+	}
+
+	// Day files sort chronologically by name
+	for channel := range a.days {
+		sort.Slice(a.days[channel], func(i, j int) bool {
+			return a.days[channel][i].Name < a.days[channel][j].Name
+		})
+	}
+
+	if _, ok := a.files[ChannelsFile]; !ok {
+		reader.Close()
+		return nil, fmt.Errorf("not a Slack export: %s is missing", ChannelsFile)
+	}
+	if _, ok := a.files[UsersFile]; !ok {
+		reader.Close()
+		return nil, fmt.Errorf("not a Slack export: %s is missing", UsersFile)
+	}
+
+	return a, nil
+}
+
+// Close closes the underlying ZIP
+func (a *archive) Close() error {
+	return a.reader.Close()
+}
+
+// Users reads users.json
+func (a *archive) Users() ([]slackUser, error) {
+	var users []slackUser
+	if err := a.decode(a.files[UsersFile], &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// Channels reads channels.json
+func (a *archive) Channels() ([]slackChannel, error) {
+	var channels []slackChannel
+	if err := a.decode(a.files[ChannelsFile], &channels); err != nil {
+		return nil, err
+	}
+	return channels, nil
+}
+
+// DayFiles returns the per-day message files of a channel in date order
+func (a *archive) DayFiles(channelName string) []*zip.File {
+	return a.days[channelName]
+}
+
+// Messages reads one per-day message file
+func (a *archive) Messages(f *zip.File) ([]slackMessage, error) {
+	var messages []slackMessage
+	if err := a.decode(f, &messages); err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// decode reads a JSON file from the archive
+func (a *archive) decode(f *zip.File, v interface{}) error {
+	if f.UncompressedSize64 > MaxArchiveEntrySize {
+		return fmt.Errorf("%s is too large", f.Name)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(io.LimitReader(rc, MaxArchiveEntrySize)).Decode(v); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", f.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package slackimport
+
+import (
+	"archive/zip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeArchive writes a ZIP with the given files to a temporary directory and returns its path
+func writeArchive(t *testing.T, files map[string]string) string {
+	t.Helper()
+	archivePath := filepath.Join(t.TempDir(), "export.zip")
+	out, err := os.Create(archivePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer out.Close()
+
+	zw := zip.NewWriter(out)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return archivePath
+}
+
+func TestOpenArchive(t *testing.T) {
+	tests := []struct {
+		name    string
+		root    string
+		files   map[string]string
+		wantErr string
+	}{
+		{
+			name: "export at the top level",
+			files: map[string]string{
+				"users.json":    `[{"id":"U1","name":"alice"}]`,
+				"channels.json": `[{"id":"C1","name":"general"}]`,
+			},
+		},
+		{
+			name: "export inside a folder",
+			root: "My Workspace Slack export/",
+			files: map[string]string{
+				"users.json":    `[{"id":"U1","name":"alice"}]`,
+				"channels.json": `[{"id":"C1","name":"general"}]`,
+			},
+		},
+		{
+			name:    "missing channels.json",
+			files:   map[string]string{"users.json": `[]`},
+			wantErr: "channels.json is missing",
+		},
+		{
+			name:    "missing users.json",
+			files:   map[string]string{"channels.json": `[]`},
+			wantErr: "users.json is missing",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files := make(map[string]string)
+			for name, content := range tt.files {
+				files[tt.root+name] = content
+			}
+			files[tt.root+"general/2024-03-02.json"] = `[{"type":"message","ts":"1709337600.000100","text":"second"}]`
+			files[tt.root+"general/2024-03-01.json"] = `[{"type":"message","ts":"1709251200.000100","text":"first"}]`
+			files[tt.root+"general/notes.txt"] = "not a day file"
+
+			a, err := openArchive(writeArchive(t, files))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("openArchive() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("openArchive() error = %v", err)
+			}
+			defer a.Close()
+
+			users, err := a.Users()
+			if err != nil || len(users) != 1 || users[0].Name != "alice" {
+				t.Errorf("Users() = %+v, %v; want alice", users, err)
+			}
+			channels, err := a.Channels()
+			if err != nil || len(channels) != 1 || channels[0].Name != "general" {
+				t.Errorf("Channels() = %+v, %v; want general", channels, err)
+			}
+
+			var texts []string
+			for _, f := range a.DayFiles("general") {
+				messages, err := a.Messages(f)
+				if err != nil {
+					t.Fatalf("Messages(%s) error = %v", f.Name, err)
+				}
+				for _, msg := range messages {
+					texts = append(texts, msg.Text)
+				}
+			}
+			if want := []string{"first", "second"}; !reflect.DeepEqual(texts, want) {
+				t.Errorf("messages = %v, want %v in date order", texts, want)
+			}
+			if days := a.DayFiles("random"); len(days) != 0 {
+				t.Errorf("DayFiles(random) = %d files, want none", len(days))
+			}
+		})
+	}
+}
+
+func TestArchiveRejectsInvalidJSON(t *testing.T) {
+	a, err := openArchive(writeArchive(t, map[string]string{
+		"users.json":    `{"not":"a list"}`,
+		"channels.json": `[`,
+	}))
+	if err != nil {
+		t.Fatalf("openArchive() error = %v", err)
+	}
+	defer a.Close()
+
+	if _, err := a.Users(); err == nil || !strings.Contains(err.Error(), "failed to parse users.json") {
+		t.Errorf("Users() error = %v, want a parse error", err)
+	}
+	if _, err := a.Channels(); err == nil || !strings.Contains(err.Error(), "failed to parse channels.json") {
+		t.Errorf("Channels() error = %v, want a parse error", err)
+	}
+}
@@ -0,0 +1,166 @@
+package slackimport
+
+import (
+	"errors"
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"huddle/pkg/validation"
+)
+
+// slackLinkRegex matches Slack's <...> markup for mentions, channels and links
+var slackLinkRegex = regexp.MustCompile(`<([^<>]+)>`)
+
+// skinToneRegex matches the skin tone suffix of a Slack emoji name such as thumbsup::skin-tone-2
+var skinToneRegex = regexp.MustCompile(`::skin-tone-([2-6])$`)
+
+// skinToneModifiers maps Slack skin tones to the Unicode modifiers
+var skinToneModifiers = map[string]string{
+	"2": "\U0001F3FB",
+	"3": "\U0001F3FC",
+	"4": "\U0001F3FD",
+	"5": "\U0001F3FE",
+	"6": "\U0001F3FF",
+}
+
+// slackEmoji maps common Slack emoji names to Unicode; anything else is kept as a :shortcode:
+var slackEmoji = map[string]string{
+	"+1":                    "👍",
+	"thumbsup":              "👍",
+	"-1":                    "👎",
+	"thumbsdown":            "👎",
+	"heart":                 "❤️",
+	"joy":                   "😂",
+	"laughing":              "😆",
+	"smile":                 "😄",
+	"grinning":              "😀",
+	"slightly_smiling_face": "🙂",
+	"sweat_smile":           "😅",
+	"heart_eyes":            "😍",
+	"sunglasses":            "😎",
+	"open_mouth":            "😮",
+	"astonished":            "😲",
+	"cry":                   "😢",
+	"sob":                   "😭",
+	"rage":                  "😡",
+	"angry":                 "😠",
+	"thinking_face":         "🤔",
+	"tada":                  "🎉",
+	"eyes":                  "👀",
+	"fire":                  "🔥",
+	"pray":                  "🙏",
+	"clap":                  "👏",
+	"raised_hands":          "🙌",
+	"ok_hand":               "👌",
+	"wave":                  "👋",
+	"muscle":                "💪",
+	"rocket":                "🚀",
+	"100":                   "💯",
+	"star":                  "⭐",
+	"sparkles":              "✨",
+	"white_check_mark":      "✅",
+	"heavy_check_mark":      "✔️",
+	"x":                     "❌",
+	"warning":               "⚠️",
+	"bulb":                  "💡",
+	"memo":                  "📝",
+	"coffee":                "☕",
+	"beers":                 "🍻",
+}
+
+// parseTimestamp converts a Slack ts such as 1503435956.000247 to a time
+func parseTimestamp(ts string) (time.Time, error) {
+	secPart, fracPart, _ := strings.Cut(ts, ".")
+	sec, err := strconv.ParseInt(secPart, 10, 64)
+	if err != nil {
+		return time.Time{}, errors.New("invalid Slack timestamp: " + ts)
+	}
+
+	var nsec int64
+	if fracPart != "" {
+		fracPart = (fracPart + "000000000")[:9]
+		nsec, err = strconv.ParseInt(fracPart, 10, 64)
+		if err != nil {
+			return time.Time{}, errors.New("invalid Slack timestamp: " + ts)
+		}
+	}
+
+	return time.Unix(sec, nsec).UTC(), nil
+}
+
+// convertEmoji converts a Slack reaction name to a Huddle reaction emoji
+func convertEmoji(name string) (string, bool) {
+	modifier := ""
+	if matches := skinToneRegex.FindStringSubmatch(name); matches != nil {
+		modifier = skinToneModifiers[matches[1]]
+		name = name[:len(name)-len(matches[0])]
+	}
+
+	if emoji, ok := slackEmoji[name]; ok {
+		// Skin tones only apply to the bare hand emoji
+		if toned := validation.NormalizeEmoji(emoji + modifier); modifier != "" && validation.IsEmoji(toned) {
+			return toned, true
+		}
+		return emoji, true
+	}
+
+	shortcode := ":" + strings.ToLower(name) + ":"
+	if validation.IsCustomEmoji(shortcode) {
+		return shortcode, true
+	}
+	return "", false
+}
+
+// textConverter rewrites Slack message markup into plain Huddle text
+type textConverter struct {
+	userNames    map[string]string
+	channelNames map[string]string
+}
+
+// Convert resolves mentions, channel references and links and unescapes entities
+func (c *textConverter) Convert(text string) string {
+	text = slackLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		inner := match[1 : len(match)-1]
+		target, label, hasLabel := strings.Cut(inner, "|")
+
+		switch {
+		case strings.HasPrefix(target, "@"):
+			if name, ok := c.userNames[target[1:]]; ok {
+				return "@" + name
+			}
+			if hasLabel {
+				return "@" + strings.TrimPrefix(label, "@")
+			}
+			return "@" + target[1:]
+		case strings.HasPrefix(target, "#"):
+			if hasLabel {
+				return "#" + label
+			}
+			if name, ok := c.channelNames[target[1:]]; ok {
+				return "#" + name
+			}
+			return "#" + target[1:]
+		case strings.HasPrefix(target, "!"):
+			if hasLabel {
+				return label
+			}
+			command, _, _ := strings.Cut(target[1:], "^")
+			return "@" + command
+		case strings.HasPrefix(target, "mailto:"):
+			if hasLabel {
+				return label
+			}
+			return strings.TrimPrefix(target, "mailto:")
+		default:
+			if hasLabel && label != target {
+				return label + " (" + target + ")"
+			}
+			return target
+		}
+	})
+
+	return html.UnescapeString(text)
+}
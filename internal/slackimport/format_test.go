@@ -0,0 +1,109 @@
+package slackimport
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		ts      string
+		want    time.Time
+		wantErr bool
+	}{
+		{ts: "1503435956.000247", want: time.Unix(1503435956, 247000).UTC()},
+		{ts: "1503435956", want: time.Unix(1503435956, 0).UTC()},
+		{ts: "1503435956.5", want: time.Unix(1503435956, 500000000).UTC()},
+		{ts: "1503435956.1234567891", want: time.Unix(1503435956, 123456789).UTC()},
+		{ts: "", wantErr: true},
+		{ts: "abc.000001", wantErr: true},
+		{ts: "1503435956.x1", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ts, func(t *testing.T) {
+			got, err := parseTimestamp(tt.ts)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseTimestamp(%q) = %v, want an error", tt.ts, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseTimestamp(%q) error = %v", tt.ts, err)
+			}
+			if !got.Equal(tt.want) || got.Location() != time.UTC {
+				t.Errorf("parseTimestamp(%q) = %v, want %v", tt.ts, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertEmoji(t *testing.T) {
+	tests := []struct {
+		name   string
+		want   string
+		wantOK bool
+	}{
+		{name: "+1", want: "👍", wantOK: true},
+		{name: "heart", want: "❤️", wantOK: true},
+		{name: "thumbsup::skin-tone-4", want: "👍🏽", wantOK: true},
+		{name: "heavy_check_mark::skin-tone-2", want: "✔️", wantOK: true},
+		{name: "party_parrot", want: ":party_parrot:", wantOK: true},
+		{name: "Party_Parrot", want: ":party_parrot:", wantOK: true},
+		{name: "custom::skin-tone-3", want: ":custom:", wantOK: true},
+		{name: "x", want: "❌", wantOK: true},
+		{name: "a", wantOK: false},
+		{name: "has space", wantOK: false},
+		{name: "", wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := convertEmoji(tt.name)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("convertEmoji(%q) = %q, %v; want %q, %v", tt.name, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTextConverterConvert(t *testing.T) {
+	converter := &textConverter{
+		userNames:    map[string]string{"U1": "alice"},
+		channelNames: map[string]string{"C1": "general"},
+	}
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"plain text", "hello", "hello"},
+		{"mapped user", "hi <@U1>", "hi @alice"},
+		{"mapped user ignores label", "hi <@U1|bob>", "hi @alice"},
+		{"unmapped user with label", "hi <@U2|bob>", "hi @bob"},
+		{"unmapped user", "hi <@U2>", "hi @U2"},
+		{"channel with label", "see <#C9|random>", "see #random"},
+		{"mapped channel", "see <#C1>", "see #general"},
+		{"unmapped channel", "see <#C9>", "see #C9"},
+		{"special mention", "<!here> and <!channel>", "@here and @channel"},
+		{"special mention with label", "<!subteam^S1|@devs>", "@devs"},
+		{"subteam without label", "<!subteam^S1>", "@subteam"},
+		{"link", "<https://example.com>", "https://example.com"},
+		{"link with label", "<https://example.com|Example>", "Example (https://example.com)"},
+		{"link labelled with itself", "<https://example.com|https://example.com>", "https://example.com"},
+		{"mailto", "<mailto:a@example.com>", "a@example.com"},
+		{"mailto with label", "<mailto:a@example.com|Alice>", "Alice"},
+		{"entities", "a &lt; b &amp;&amp; c &gt; d", "a < b && c > d"},
+		{"escaped markup is not a link", "&lt;@U1&gt;", "<@U1>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := converter.Convert(tt.input); got != tt.want {
+				t.Errorf("Convert(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
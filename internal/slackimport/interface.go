@@ -0,0 +1,34 @@
+package slackimport
+
+import (
+	"context"
+
+	"huddle/internal/conversation"
+	"huddle/internal/file"
+	"huddle/internal/message"
+	"huddle/internal/user"
+)
+
+// Repository interface defines data access methods for Slack imports
+type Repository interface {
+	// Users
+	GetUsersByEmails(ctx context.Context, emails []string) ([]user.User, error)
+
+	// Mappings
+	GetMapping(ctx context.Context, sourceType, slackID string) (uint, bool, error)
+	GetMappings(ctx context.Context, sourceType string, slackIDs []string) (map[string]uint, error)
+
+	// Conversations
+	CreateConversation(ctx context.Context, conv *conversation.Conversation, slackID string) error
+	AddParticipants(ctx context.Context, participants []conversation.ConversationParticipant) (int, error)
+	MarkConversationRead(ctx context.Context, conversationID uint) error
+
+	// Messages
+	CreateMessage(ctx context.Context, msg *message.Message, files []file.File, slackID string) error
+	AddReactions(ctx context.Context, reactions []message.MessageReaction) (int, error)
+}
+
+// Service interface defines business logic methods for Slack imports
+type Service interface {
+	Import(ctx context.Context, opts *Options) (*Report, error)
+}
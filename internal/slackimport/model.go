@@ -0,0 +1,176 @@
+package slackimport
+
+import (
+	"time"
+)
+
+// Mapping records which Huddle row a Slack object was imported as, so re-runs skip it
+type Mapping struct {
+	ID         uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	SourceType string    `json:"source_type" gorm:"not null;size:20"`
+	SlackID    string    `json:"slack_id" gorm:"not null;size:100"`
+	TargetID   uint      `json:"target_id" gorm:"not null"`
+	CreatedAt  time.Time `json:"created_at" gorm:"default:now()"`
+}
+
+// TableName specifies the table name for Mapping
+func (Mapping) TableName() string {
+	return "slack_import_mappings"
+}
+
+// Mapping source types
+const (
+	SourceTypeChannel = "channel"
+	SourceTypeMessage = "message"
+)
+
+// Slack export archive layout
+const (
+	UsersFile    = "users.json"
+	ChannelsFile = "channels.json"
+)
+
+// Import limits
+const (
+	// FileDownloadTimeout bounds fetching a single attachment from Slack
+	FileDownloadTimeout = 2 * time.Minute
+
+	// MaxArchiveEntrySize bounds a single JSON file read from the archive
+	MaxArchiveEntrySize = 256 * 1024 * 1024
+)
+
+// slackUser represents an entry of users.json
+type slackUser struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Deleted  bool   `json:"deleted"`
+	IsBot    bool   `json:"is_bot"`
+	Profile  struct {
+		Email       string `json:"email"`
+		RealName    string `json:"real_name"`
+		DisplayName string `json:"display_name"`
+	} `json:"profile"`
+}
+
+// slackChannel represents an entry of channels.json
+type slackChannel struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Created    int64    `json:"created"`
+	Creator    string   `json:"creator"`
+	IsArchived bool     `json:"is_archived"`
+	Members    []string `json:"members"`
+}
+
+// slackMessage represents one message of a per-day channel file
+type slackMessage struct {
+	Type       string          `json:"type"`
+	Subtype    string          `json:"subtype"`
+	User       string          `json:"user"`
+	Username   string          `json:"username"`
+	BotID      string          `json:"bot_id"`
+	Text       string          `json:"text"`
+	Ts         string          `json:"ts"`
+	ThreadTs   string          `json:"thread_ts"`
+	Edited     *slackEdited    `json:"edited"`
+	Reactions  []slackReaction `json:"reactions"`
+	Files      []slackFile     `json:"files"`
+}
+
+// slackEdited represents the edit marker of a message
+type slackEdited struct {
+	User string `json:"user"`
+	Ts   string `json:"ts"`
+}
+
+// slackReaction represents the users who reacted to a message with one emoji
+type slackReaction struct {
+	Name  string   `json:"name"`
+	Users []string `json:"users"`
+	Count int      `json:"count"`
+}
+
+// slackFile represents a file shared in a message
+type slackFile struct {
+	ID                 string `json:"id"`
+	Name               string `json:"name"`
+	Title              string `json:"title"`
+	Mimetype           string `json:"mimetype"`
+	Size               int64  `json:"size"`
+	Mode               string `json:"mode"`
+	URLPrivate         string `json:"url_private"`
+	URLPrivateDownload string `json:"url_private_download"`
+}
+
+// Options configures an import run
+type Options struct {
+	ArchivePath string
+	AdminEmail  string
+	SlackToken  string
+	Channels    []string
+}
+
+// User mapping statuses
+const (
+	UserStatusMapped    = "mapped"
+	UserStatusUnmatched = "unmatched"
+	UserStatusNoEmail   = "no_email"
+	UserStatusBot       = "bot"
+)
+
+// Report is the mapping report produced by an import run
+type Report struct {
+	Archive    string          `json:"archive"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+	Users      []UserReport    `json:"users"`
+	Channels   []ChannelReport `json:"channels"`
+	Totals     ReportTotals    `json:"totals"`
+}
+
+// UserReport shows how a Slack user was mapped
+type UserReport struct {
+	SlackID string `json:"slack_id"`
+	Name    string `json:"name"`
+	Email   string `json:"email,omitempty"`
+	UserID  *uint  `json:"user_id"`
+	Status  string `json:"status"`
+}
+
+// ChannelReport shows what was imported for a Slack channel
+type ChannelReport struct {
+	SlackID            string        `json:"slack_id"`
+	Name               string        `json:"name"`
+	ConversationID     uint          `json:"conversation_id"`
+	Created            bool          `json:"created"`
+	ParticipantsAdded  int           `json:"participants_added"`
+	MessagesImported   int           `json:"messages_imported"`
+	MessagesExisting   int           `json:"messages_existing"`
+	MessagesIgnored    int           `json:"messages_ignored"`
+	RepliesUnthreaded  int           `json:"replies_unthreaded"`
+	ReactionsAdded     int           `json:"reactions_added"`
+	FilesCopied        int           `json:"files_copied"`
+	FilesFailed        int           `json:"files_failed"`
+	Errors             []ImportError `json:"errors,omitempty"`
+}
+
+// ImportError records a message or file that could not be imported
+type ImportError struct {
+	Ts     string `json:"ts,omitempty"`
+	FileID string `json:"file_id,omitempty"`
+	Error  string `json:"error"`
+}
+
+// ReportTotals sums the channel reports
+type ReportTotals struct {
+	UsersMapped          int `json:"users_mapped"`
+	UsersUnmapped        int `json:"users_unmapped"`
+	ConversationsCreated int `json:"conversations_created"`
+	MessagesImported     int `json:"messages_imported"`
+	MessagesExisting     int `json:"messages_existing"`
+	ReactionsAdded       int `json:"reactions_added"`
+	FilesCopied          int `json:"files_copied"`
+	FilesFailed          int `json:"files_failed"`
+	Errors               int `json:"errors"`
+}
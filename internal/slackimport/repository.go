@@ -0,0 +1,197 @@
+package slackimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"huddle/internal/conversation"
+	"huddle/internal/database"
+	"huddle/internal/file"
+	"huddle/internal/message"
+	"huddle/internal/user"
+	"huddle/pkg/logger"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type repository struct {
+	db *gorm.DB
+}
+
+// NewRepository creates a new Slack import repository
+func NewRepository() Repository {
+	return &repository{
+		db: database.GetDB(),
+	}
+}
+
+// Users
+
+func (r *repository) GetUsersByEmails(ctx context.Context, emails []string) ([]user.User, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	lowered := make([]string, 0, len(emails))
+	for _, email := range emails {
+		lowered = append(lowered, strings.ToLower(email))
+	}
+
+	var users []user.User
+	if err := r.db.WithContext(ctx).
+		Where("LOWER(email) IN ?", lowered).
+		Find(&users).Error; err != nil {
+		logger.Error("Failed to get users by email", zap.Error(err))
+		return nil, err
+	}
+	return users, nil
+}
+
+// Mappings
+
+func (r *repository) GetMapping(ctx context.Context, sourceType, slackID string) (uint, bool, error) {
+	var mapping Mapping
+	if err := r.db.WithContext(ctx).
+		Where("source_type = ? AND slack_id = ?", sourceType, slackID).
+		First(&mapping).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return 0, false, nil
+		}
+		logger.Error("Failed to get Slack import mapping", zap.Error(err))
+		return 0, false, err
+	}
+	return mapping.TargetID, true, nil
+}
+
+func (r *repository) GetMappings(ctx context.Context, sourceType string, slackIDs []string) (map[string]uint, error) {
+	targets := make(map[string]uint)
+	if len(slackIDs) == 0 {
+		return targets, nil
+	}
+
+	var mappings []Mapping
+	if err := r.db.WithContext(ctx).
+		Where("source_type = ? AND slack_id IN ?", sourceType, slackIDs).
+		Find(&mappings).Error; err != nil {
+		logger.Error("Failed to get Slack import mappings", zap.Error(err))
+		return nil, err
+	}
+
+	for _, mapping := range mappings {
+		targets[mapping.SlackID] = mapping.TargetID
+	}
+	return targets, nil
+}
+
+// Conversations
+
+func (r *repository) CreateConversation(ctx context.Context, conv *conversation.Conversation, slackID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(conv).Error; err != nil {
+			return err
+		}
+		return tx.Create(&Mapping{
+			SourceType: SourceTypeChannel,
+			SlackID:    slackID,
+			TargetID:   conv.ID,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create imported conversation", zap.String("slack_id", slackID), zap.Error(err))
+		return err
+	}
+
+	logger.Info("Imported conversation created", zap.Uint("conversation_id", conv.ID), zap.String("slack_id", slackID))
+	return nil
+}
+
+func (r *repository) AddParticipants(ctx context.Context, participants []conversation.ConversationParticipant) (int, error) {
+	if len(participants) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&participants)
+	if result.Error != nil {
+		logger.Error("Failed to add imported participants", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
+
+func (r *repository) MarkConversationRead(ctx context.Context, conversationID uint) error {
+	// Imported history should not show up as unread
+	if err := r.db.WithContext(ctx).Exec(`
+		UPDATE conversation_participants cp SET
+			last_read_message_id = GREATEST(COALESCE(cp.last_read_message_id, 0), latest.id),
+			last_delivered_message_id = GREATEST(COALESCE(cp.last_delivered_message_id, 0), latest.id),
+			last_read_at = GREATEST(cp.last_read_at, latest.created_at)
+		FROM (
+			SELECT id, created_at FROM messages
+			WHERE conversation_id = ?
+			ORDER BY id DESC LIMIT 1
+		) latest
+		WHERE cp.conversation_id = ?`, conversationID, conversationID).Error; err != nil {
+		logger.Error("Failed to mark imported conversation as read", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// Messages
+
+func (r *repository) CreateMessage(ctx context.Context, msg *message.Message, files []file.File, slackID string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit(clause.Associations).Create(msg).Error; err != nil {
+			return err
+		}
+
+		for i := range files {
+			files[i].MessageID = &msg.ID
+			if err := tx.Omit(clause.Associations).Create(&files[i]).Error; err != nil {
+				return err
+			}
+		}
+
+		// The message links to its first file, whose id is only known now
+		if len(files) > 0 {
+			msg.FileURL = fmt.Sprintf("/api/files/%d/download", files[0].ID)
+			if err := tx.Model(&message.Message{}).Where("id = ?", msg.ID).Update("file_url", msg.FileURL).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Create(&Mapping{
+			SourceType: SourceTypeMessage,
+			SlackID:    slackID,
+			TargetID:   msg.ID,
+		}).Error
+	})
+	if err != nil {
+		logger.Error("Failed to create imported message", zap.String("slack_id", slackID), zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+func (r *repository) AddReactions(ctx context.Context, reactions []message.MessageReaction) (int, error) {
+	if len(reactions) == 0 {
+		return 0, nil
+	}
+
+	result := r.db.WithContext(ctx).
+		Omit(clause.Associations).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&reactions)
+	if result.Error != nil {
+		logger.Error("Failed to add imported reactions", zap.Error(result.Error))
+		return 0, result.Error
+	}
+	return int(result.RowsAffected), nil
+}
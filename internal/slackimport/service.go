@@ -0,0 +1,548 @@
+package slackimport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"huddle/internal/conversation"
+	"huddle/internal/file"
+	"huddle/internal/message"
+	"huddle/pkg/logger"
+	"huddle/pkg/minio"
+
+	"go.uber.org/zap"
+)
+
+// ignoredSubtypes are Slack message subtypes with no Huddle equivalent
+var ignoredSubtypes = map[string]bool{
+	"channel_join":      true,
+	"channel_leave":     true,
+	"channel_topic":     true,
+	"channel_purpose":   true,
+	"channel_name":      true,
+	"channel_archive":   true,
+	"channel_unarchive": true,
+	"group_join":        true,
+	"group_leave":       true,
+	"pinned_item":       true,
+	"unpinned_item":     true,
+	"tombstone":         true,
+}
+
+type service struct {
+	repo       Repository
+	httpClient *http.Client
+}
+
+// NewService creates a new Slack import service
+func NewService(repo Repository) Service {
+	return &service{
+		repo: repo,
+		httpClient: &http.Client{
+			Timeout: FileDownloadTimeout,
+		},
+	}
+}
+
+// importRun holds the state shared by all channels of one import
+type importRun struct {
+	opts      *Options
+	adminID   uint
+	userIDs   map[string]uint
+	userNames map[string]string
+	converter *textConverter
+}
+
+// Import ingests a Slack export archive; objects imported by a previous run are skipped
+func (s *service) Import(ctx context.Context, opts *Options) (*Report, error) {
+	report := &Report{
+		Archive:   opts.ArchivePath,
+		StartedAt: time.Now().UTC(),
+	}
+
+	a, err := openArchive(opts.ArchivePath)
+	if err != nil {
+		return nil, err
+	}
+	defer a.Close()
+
+	users, err := a.Users()
+	if err != nil {
+		return nil, err
+	}
+	channels, err := a.Channels()
+	if err != nil {
+		return nil, err
+	}
+
+	// The importing admin owns conversations and messages of unmapped users
+	admins, err := s.repo.GetUsersByEmails(ctx, []string{opts.AdminEmail})
+	if err != nil {
+		return nil, err
+	}
+	if len(admins) == 0 {
+		return nil, errors.New("admin user not found: " + opts.AdminEmail)
+	}
+
+	run := &importRun{
+		opts:      opts,
+		adminID:   admins[0].ID,
+		userIDs:   make(map[string]uint),
+		userNames: make(map[string]string),
+	}
+	if err := s.mapUsers(ctx, run, users, report); err != nil {
+		return nil, err
+	}
+
+	channelNames := make(map[string]string)
+	for _, ch := range channels {
+		channelNames[ch.ID] = ch.Name
+	}
+	converter := &textConverter{
+		userNames:    make(map[string]string),
+		channelNames: channelNames,
+	}
+	for _, u := range users {
+		converter.userNames[u.ID] = run.userNames[u.ID]
+	}
+	run.converter = converter
+
+	selected := make(map[string]bool)
+	for _, name := range opts.Channels {
+		selected[strings.TrimPrefix(name, "#")] = true
+	}
+
+	for _, ch := range channels {
+		if len(selected) > 0 && !selected[ch.Name] {
+			continue
+		}
+
+		channelReport, err := s.importChannel(ctx, run, a, ch)
+		if channelReport != nil {
+			report.Channels = append(report.Channels, *channelReport)
+		}
+		if err != nil {
+			report.FinishedAt = time.Now().UTC()
+			report.Totals = buildTotals(report)
+			return report, fmt.Errorf("failed to import channel #%s: %w", ch.Name, err)
+		}
+	}
+
+	report.FinishedAt = time.Now().UTC()
+	report.Totals = buildTotals(report)
+	return report, nil
+}
+
+// mapUsers matches Slack users to existing Huddle users by email
+func (s *service) mapUsers(ctx context.Context, run *importRun, users []slackUser, report *Report) error {
+	emails := make([]string, 0, len(users))
+	for _, u := range users {
+		if u.Profile.Email != "" {
+			emails = append(emails, u.Profile.Email)
+		}
+	}
+
+	existing, err := s.repo.GetUsersByEmails(ctx, emails)
+	if err != nil {
+		return err
+	}
+	byEmail := make(map[string]uint)
+	usernames := make(map[uint]string)
+	for _, u := range existing {
+		byEmail[strings.ToLower(u.Email)] = u.ID
+		usernames[u.ID] = u.Username
+	}
+
+	for _, u := range users {
+		entry := UserReport{
+			SlackID: u.ID,
+			Name:    slackDisplayName(&u),
+			Email:   u.Profile.Email,
+		}
+
+		switch userID, ok := byEmail[strings.ToLower(u.Profile.Email)]; {
+		case u.Profile.Email != "" && ok:
+			entry.UserID = &userID
+			entry.Status = UserStatusMapped
+			run.userIDs[u.ID] = userID
+			run.userNames[u.ID] = usernames[userID]
+		case u.IsBot:
+			entry.Status = UserStatusBot
+			run.userNames[u.ID] = entry.Name
+		case u.Profile.Email == "":
+			entry.Status = UserStatusNoEmail
+			run.userNames[u.ID] = entry.Name
+		default:
+			entry.Status = UserStatusUnmatched
+			run.userNames[u.ID] = entry.Name
+		}
+
+		report.Users = append(report.Users, entry)
+	}
+	return nil
+}
+
+// importChannel imports one channel as a group conversation with its full history
+func (s *service) importChannel(ctx context.Context, run *importRun, a *archive, ch slackChannel) (*ChannelReport, error) {
+	report := &ChannelReport{
+		SlackID: ch.ID,
+		Name:    ch.Name,
+	}
+
+	createdAt := time.Unix(ch.Created, 0).UTC()
+	conversationID, found, err := s.repo.GetMapping(ctx, SourceTypeChannel, ch.ID)
+	if err != nil {
+		return report, err
+	}
+	if !found {
+		creatorID := run.adminID
+		if id, ok := run.userIDs[ch.Creator]; ok {
+			creatorID = id
+		}
+		conv := &conversation.Conversation{
+			Name:                ch.Name,
+			Type:                conversation.ConversationTypeGroup,
+			CreatedBy:           creatorID,
+			OnlyAdminsCanPin:    true,
+			LinkPreviewsEnabled: true,
+			CreatedAt:           createdAt,
+		}
+		if err := s.repo.CreateConversation(ctx, conv, ch.ID); err != nil {
+			return report, err
+		}
+		conversationID = conv.ID
+		report.Created = true
+	}
+	report.ConversationID = conversationID
+
+	// The importing admin and the channel creator manage the conversation
+	roles := map[uint]string{run.adminID: conversation.ParticipantRoleAdmin}
+	if id, ok := run.userIDs[ch.Creator]; ok {
+		roles[id] = conversation.ParticipantRoleAdmin
+	}
+	for _, member := range ch.Members {
+		if id, ok := run.userIDs[member]; ok && roles[id] == "" {
+			roles[id] = conversation.ParticipantRoleMember
+		}
+	}
+	participants := make([]conversation.ConversationParticipant, 0, len(roles))
+	for userID, role := range roles {
+		participants = append(participants, conversation.ConversationParticipant{
+			ConversationID: conversationID,
+			UserID:         userID,
+			Role:           role,
+			JoinedAt:       createdAt,
+		})
+	}
+	added, err := s.repo.AddParticipants(ctx, participants)
+	if err != nil {
+		return report, err
+	}
+	report.ParticipantsAdded = added
+
+	// Thread replies point at the message their thread started from
+	messageIDs := make(map[string]uint)
+	for _, dayFile := range a.DayFiles(ch.Name) {
+		messages, err := a.Messages(dayFile)
+		if err != nil {
+			report.Errors = append(report.Errors, ImportError{Error: err.Error()})
+			continue
+		}
+
+		keys := make([]string, 0, len(messages))
+		for _, msg := range messages {
+			keys = append(keys, messageKey(ch.ID, msg.Ts))
+		}
+		existing, err := s.repo.GetMappings(ctx, SourceTypeMessage, keys)
+		if err != nil {
+			return report, err
+		}
+
+		for i := range messages {
+			if err := s.importMessage(ctx, run, conversationID, ch.ID, &messages[i], existing, messageIDs, report); err != nil {
+				return report, err
+			}
+		}
+	}
+
+	if err := s.repo.MarkConversationRead(ctx, conversationID); err != nil {
+		return report, err
+	}
+
+	logger.Info("Slack channel imported",
+		zap.String("channel", ch.Name),
+		zap.Uint("conversation_id", conversationID),
+		zap.Int("messages_imported", report.MessagesImported),
+		zap.Int("messages_existing", report.MessagesExisting))
+	return report, nil
+}
+
+// importMessage imports one Slack message with its files and reactions; only database failures are returned
+func (s *service) importMessage(ctx context.Context, run *importRun, conversationID uint, channelID string, msg *slackMessage, existing, messageIDs map[string]uint, report *ChannelReport) error {
+	if msg.Type != "message" || ignoredSubtypes[msg.Subtype] {
+		report.MessagesIgnored++
+		return nil
+	}
+
+	key := messageKey(channelID, msg.Ts)
+	createdAt, err := parseTimestamp(msg.Ts)
+	if err != nil {
+		report.Errors = append(report.Errors, ImportError{Ts: msg.Ts, Error: err.Error()})
+		return nil
+	}
+
+	messageID, found := existing[key]
+	if found {
+		report.MessagesExisting++
+	} else {
+		senderID, ok := run.userIDs[msg.User]
+		content := run.converter.Convert(msg.Text)
+		if !ok {
+			// Keep the original author visible when they have no Huddle account
+			senderID = run.adminID
+			author := run.userNames[msg.User]
+			if author == "" {
+				author = msg.Username
+			}
+			if author == "" {
+				author = "unknown"
+			}
+			content = fmt.Sprintf("[%s] %s", author, content)
+		}
+
+		newMessage := &message.Message{
+			ConversationID: conversationID,
+			SenderID:       senderID,
+			Content:        content,
+			MessageType:    message.MessageTypeText,
+			CreatedAt:      createdAt,
+			UpdatedAt:      createdAt,
+		}
+		if msg.ThreadTs != "" && msg.ThreadTs != msg.Ts {
+			if parentID, ok := messageIDs[messageKey(channelID, msg.ThreadTs)]; ok {
+				newMessage.ReplyToID = &parentID
+			} else {
+				report.RepliesUnthreaded++
+			}
+		}
+		if msg.Edited != nil {
+			if editedAt, err := parseTimestamp(msg.Edited.Ts); err == nil {
+				newMessage.IsEdited = true
+				newMessage.EditedAt = &editedAt
+				newMessage.UpdatedAt = editedAt
+			}
+		}
+
+		files, unavailable := s.copyFiles(ctx, run.opts.SlackToken, senderID, conversationID, createdAt, msg, report)
+		for _, name := range unavailable {
+			newMessage.Content = strings.TrimSpace(newMessage.Content + "\n[attachment unavailable: " + name + "]")
+		}
+		if len(files) > 0 {
+			newMessage.MessageType = message.MessageTypeFile
+			if strings.HasPrefix(files[0].MimeType, "image/") {
+				newMessage.MessageType = message.MessageTypeImage
+			}
+			newMessage.FileName = files[0].OriginalName
+			newMessage.FileSize = files[0].FileSize
+		}
+
+		if err := s.repo.CreateMessage(ctx, newMessage, files, key); err != nil {
+			s.deleteObjects(ctx, files)
+			return err
+		}
+
+		messageID = newMessage.ID
+		report.MessagesImported++
+		report.FilesCopied += len(files)
+	}
+	messageIDs[key] = messageID
+
+	// Reactions are re-applied on every run so users mapped later are picked up
+	var reactions []message.MessageReaction
+	for _, reaction := range msg.Reactions {
+		emoji, ok := convertEmoji(reaction.Name)
+		if !ok {
+			continue
+		}
+		for _, slackUserID := range reaction.Users {
+			if userID, ok := run.userIDs[slackUserID]; ok {
+				reactions = append(reactions, message.MessageReaction{
+					MessageID: messageID,
+					UserID:    userID,
+					Emoji:     emoji,
+					CreatedAt: createdAt,
+				})
+			}
+		}
+	}
+	added, err := s.repo.AddReactions(ctx, reactions)
+	if err != nil {
+		return err
+	}
+	report.ReactionsAdded += added
+
+	return nil
+}
+
+// copyFiles copies a message's files from Slack into MinIO, returning the names of files that could not be copied
+func (s *service) copyFiles(ctx context.Context, token string, ownerID, conversationID uint, createdAt time.Time, msg *slackMessage, report *ChannelReport) ([]file.File, []string) {
+	var files []file.File
+	var unavailable []string
+
+	for _, f := range msg.Files {
+		record, err := s.copyFile(ctx, token, ownerID, conversationID, createdAt, &f)
+		if err != nil {
+			name := f.Name
+			if name == "" {
+				name = f.ID
+			}
+			unavailable = append(unavailable, name)
+			report.FilesFailed++
+			report.Errors = append(report.Errors, ImportError{Ts: msg.Ts, FileID: f.ID, Error: err.Error()})
+			continue
+		}
+		files = append(files, *record)
+	}
+
+	return files, unavailable
+}
+
+// copyFile downloads one Slack file and uploads it to MinIO
+func (s *service) copyFile(ctx context.Context, token string, ownerID, conversationID uint, createdAt time.Time, f *slackFile) (*file.File, error) {
+	storage := minio.GetClient()
+	if storage == nil {
+		return nil, errors.New("file storage is not available")
+	}
+
+	downloadURL := f.URLPrivateDownload
+	if downloadURL == "" {
+		downloadURL = f.URLPrivate
+	}
+	if downloadURL == "" || f.Mode == "tombstone" || f.Mode == "hidden_by_limit" {
+		return nil, errors.New("file is not available in the export")
+	}
+	if err := storage.ValidateFileSize(f.Size); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download file: status %d", resp.StatusCode)
+	}
+
+	size := resp.ContentLength
+	if size < 0 {
+		size = f.Size
+	}
+	if err := storage.ValidateFileSize(size); err != nil {
+		return nil, err
+	}
+
+	mimeType := f.Mimetype
+	if mimeType == "" {
+		mimeType = resp.Header.Get("Content-Type")
+	}
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+
+	name := f.Name
+	if name == "" {
+		name = f.Title
+	}
+	objectKey := storage.GenerateObjectKey(ownerID, name)
+	if err := storage.UploadObject(ctx, objectKey, resp.Body, size, mimeType); err != nil {
+		return nil, err
+	}
+
+	return &file.File{
+		UserID:         ownerID,
+		ConversationID: &conversationID,
+		FileName:       filepath.Base(objectKey),
+		OriginalName:   name,
+		FileSize:       size,
+		MimeType:       mimeType,
+		FileExtension:  filepath.Ext(name),
+		BucketName:     "huddle-files",
+		ObjectKey:      objectKey,
+		StoragePath:    objectKey,
+		IsProcessed:    true,
+		CreatedAt:      createdAt,
+		UpdatedAt:      createdAt,
+	}, nil
+}
+
+// Helper methods
+
+// deleteObjects removes uploaded files whose message could not be saved
+func (s *service) deleteObjects(ctx context.Context, files []file.File) {
+	storage := minio.GetClient()
+	if storage == nil {
+		return
+	}
+	for _, f := range files {
+		if err := storage.DeleteFile(ctx, f.ObjectKey); err != nil {
+			logger.Error("Failed to delete orphaned import file", zap.String("object_key", f.ObjectKey), zap.Error(err))
+		}
+	}
+}
+
+// messageKey identifies a Slack message across runs
+func messageKey(channelID, ts string) string {
+	return channelID + ":" + ts
+}
+
+// slackDisplayName picks the most readable name of a Slack user
+func slackDisplayName(u *slackUser) string {
+	switch {
+	case u.Profile.DisplayName != "":
+		return u.Profile.DisplayName
+	case u.Profile.RealName != "":
+		return u.Profile.RealName
+	case u.RealName != "":
+		return u.RealName
+	default:
+		return u.Name
+	}
+}
+
+// buildTotals sums the per-channel counts of a report
+func buildTotals(report *Report) ReportTotals {
+	var totals ReportTotals
+	for _, u := range report.Users {
+		if u.UserID != nil {
+			totals.UsersMapped++
+		} else {
+			totals.UsersUnmapped++
+		}
+	}
+	for _, ch := range report.Channels {
+		if ch.Created {
+			totals.ConversationsCreated++
+		}
+		totals.MessagesImported += ch.MessagesImported
+		totals.MessagesExisting += ch.MessagesExisting
+		totals.ReactionsAdded += ch.ReactionsAdded
+		totals.FilesCopied += ch.FilesCopied
+		totals.FilesFailed += ch.FilesFailed
+		totals.Errors += len(ch.Errors)
+	}
+	return totals
+}
@@ -0,0 +1,57 @@
+package slackimport
+
+import "testing"
+
+func TestSlackDisplayName(t *testing.T) {
+	user := func(name, realName, profileRealName, displayName string) *slackUser {
+		u := &slackUser{Name: name, RealName: realName}
+		u.Profile.RealName = profileRealName
+		u.Profile.DisplayName = displayName
+		return u
+	}
+
+	tests := []struct {
+		name string
+		user *slackUser
+		want string
+	}{
+		{"display name first", user("alice", "Alice Smith", "Alice P. Smith", "Ali"), "Ali"},
+		{"then profile real name", user("alice", "Alice Smith", "Alice P. Smith", ""), "Alice P. Smith"},
+		{"then real name", user("alice", "Alice Smith", "", ""), "Alice Smith"},
+		{"then user name", user("alice", "", "", ""), "alice"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slackDisplayName(tt.user); got != tt.want {
+				t.Errorf("slackDisplayName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildTotals(t *testing.T) {
+	userID := uint(1)
+	report := &Report{
+		Users: []UserReport{{UserID: &userID}, {}, {UserID: &userID}},
+		Channels: []ChannelReport{
+			{Created: true, MessagesImported: 3, MessagesExisting: 1, ReactionsAdded: 2, FilesCopied: 1, Errors: []ImportError{{Ts: "1.0", Error: "x"}}},
+			{MessagesImported: 2, FilesFailed: 1},
+		},
+	}
+
+	want := ReportTotals{
+		UsersMapped:          2,
+		UsersUnmapped:        1,
+		ConversationsCreated: 1,
+		MessagesImported:     5,
+		MessagesExisting:     1,
+		ReactionsAdded:       2,
+		FilesCopied:          1,
+		FilesFailed:          1,
+		Errors:               1,
+	}
+	if got := buildTotals(report); got != want {
+		t.Errorf("buildTotals() = %+v, want %+v", got, want)
+	}
+}
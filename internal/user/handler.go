@@ -140,7 +140,7 @@ func (h *Handler) SearchUsers(c *gin.Context) {
 		req.PageSize = 20
 	}
 	
-	users, err := h.service.SearchUsers(c.Request.Context(), &req)
+	users, err := h.service.SearchUsers(c.Request.Context(), c.GetUint("user_id"), &req)
 	if err != nil {
 		logger.Error("Failed to search users", zap.Error(err))
 		utils.InternalServerErrorResponse(c, "Failed to search users")
@@ -12,7 +12,7 @@ type Repository interface {
 	GetByEmail(ctx context.Context, email string) (*User, error)
 	Update(ctx context.Context, user *User) error
 	Delete(ctx context.Context, id uint) error
-	Search(ctx context.Context, query string, page, pageSize int) ([]User, int64, error)
+	Search(ctx context.Context, viewerID uint, query string, page, pageSize int) ([]User, int64, error)
 	List(ctx context.Context, page, pageSize int) ([]User, int64, error)
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
@@ -25,7 +25,7 @@ type Service interface {
 	GetUserByUsername(ctx context.Context, username string) (*UserResponse, error)
 	UpdateUser(ctx context.Context, id uint, req *UpdateUserRequest) (*UserResponse, error)
 	DeleteUser(ctx context.Context, id uint) error
-	SearchUsers(ctx context.Context, req *UserSearchRequest) (*UserListResponse, error)
+	SearchUsers(ctx context.Context, viewerID uint, req *UserSearchRequest) (*UserListResponse, error)
 	ListUsers(ctx context.Context, page, pageSize int) (*UserListResponse, error)
 	ChangePassword(ctx context.Context, id uint, req *ChangePasswordRequest) error
 	UpdateAvatar(ctx context.Context, id uint, avatarURL string) (*UserResponse, error)
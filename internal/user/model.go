@@ -16,9 +16,11 @@ type User struct {
 	Bio         string         `json:"bio" gorm:"size:500"`
 	Avatar      string         `json:"avatar" gorm:"size:255"`
 	IsPublic    bool           `json:"is_public" gorm:"default:true"`
+	IsModerator bool           `json:"-" gorm:"default:false"` // Manages workspace moderation rules and the review queue
 	LastLogin   *time.Time     `json:"last_login"`
 	LoginAttempts int          `json:"-" gorm:"default:0"`
 	LockedUntil *time.Time     `json:"-"`
+	SuspendedUntil *time.Time  `json:"-"` // Set by moderators resolving a report
 	CreatedAt   time.Time      `json:"created_at"`
 	UpdatedAt   time.Time      `json:"updated_at"`
 	DeletedAt   gorm.DeletedAt `json:"-" gorm:"index"`
@@ -102,6 +104,14 @@ func (u *User) IsLocked() bool {
 	return time.Now().Before(*u.LockedUntil)
 }
 
+// IsSuspended checks if user account is suspended by a moderator
+func (u *User) IsSuspended() bool {
+	if u.SuspendedUntil == nil {
+		return false
+	}
+	return time.Now().Before(*u.SuspendedUntil)
+}
+
 // IncrementLoginAttempts increments failed login attempts
 func (u *User) IncrementLoginAttempts() {
 	u.LoginAttempts++
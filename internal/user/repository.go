@@ -80,8 +80,8 @@ func (r *repository) Delete(ctx context.Context, id uint) error {
 	return r.db.WithContext(ctx).Delete(&User{}, id).Error
 }
 
-// Search searches users by query
-func (r *repository) Search(ctx context.Context, query string, page, pageSize int) ([]User, int64, error) {
+// Search searches users by query, leaving out users blocked by or blocking the viewer
+func (r *repository) Search(ctx context.Context, viewerID uint, query string, page, pageSize int) ([]User, int64, error) {
 	var users []User
 	var total int64
 	
@@ -94,6 +94,10 @@ func (r *repository) Search(ctx context.Context, query string, page, pageSize in
 		db = db.Where("username ILIKE ? OR display_name ILIKE ? OR email ILIKE ?", 
 			searchQuery, searchQuery, searchQuery)
 	}
+	db = db.Where(`NOT EXISTS (
+		SELECT 1 FROM blocked_users b
+		WHERE (b.blocker_id = ? AND b.blocked_id = users.id) OR (b.blocker_id = users.id AND b.blocked_id = ?)
+	)`, viewerID, viewerID)
 	
 	// Count total
 	err := db.Count(&total).Error
@@ -13,7 +13,7 @@ func SetupRoutes(router *gin.RouterGroup, handler *Handler) {
 	{
 		// Public routes (no auth required)
 		users.POST("/", handler.CreateUser)                    // Create user
-		users.GET("/search", handler.SearchUsers)              // Search users
+		users.GET("/search", middleware.OptionalAuthMiddleware(), handler.SearchUsers) // Search users (hides blocked users when signed in)
 		users.GET("/", handler.ListUsers)                      // List users
 		users.GET("/:id", handler.GetUserByID)                 // Get user by ID
 		users.GET("/username/:username", handler.GetUserByUsername) // Get user by username
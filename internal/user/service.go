@@ -162,8 +162,8 @@ func (s *service) DeleteUser(ctx context.Context, id uint) error {
 }
 
 // SearchUsers searches users by query
-func (s *service) SearchUsers(ctx context.Context, req *UserSearchRequest) (*UserListResponse, error) {
-	users, total, err := s.repo.Search(ctx, req.Query, req.Page, req.PageSize)
+func (s *service) SearchUsers(ctx context.Context, viewerID uint, req *UserSearchRequest) (*UserListResponse, error) {
+	users, total, err := s.repo.Search(ctx, viewerID, req.Query, req.Page, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -25,6 +25,9 @@ const (
 
 	// Maximum message size allowed from peer
 	maxMessageSize = 512
+
+	// How long a client's blocked users are cached before being reloaded
+	blockCacheTTL = 30 * time.Second
 )
 
 var upgrader = websocket.Upgrader{
@@ -163,6 +166,15 @@ func (c *Client) handleMessage(message []byte) {
 	case MessageTypeMarkRead:
 		c.handleMarkRead(wsMessage)
 		
+	case MessageTypeMarkDelivered:
+		c.handleMarkDelivered(wsMessage)
+		
+	case MessageTypeLocationUpdate:
+		c.handleLocationUpdate(wsMessage)
+		
+	case MessageTypeStopLocation:
+		c.handleStopLocation(wsMessage)
+		
 	default:
 		logger.Warn("Unknown message type", zap.String("type", string(wsMessage.Type)))
 		c.sendError("UNKNOWN_MESSAGE_TYPE", "Unknown message type")
@@ -238,6 +250,7 @@ func (c *Client) handleTyping(wsMessage WebSocketMessage) {
 		Username:  c.Username,
 		Timestamp: time.Now(),
 		Data:      wsMessage.Data,
+		SkipUserIDs: c.blockedUsers(),
 	}
 
 	c.Hub.Broadcast <- typingMessage
@@ -264,11 +277,21 @@ func (c *Client) handleStopTyping(wsMessage WebSocketMessage) {
 		Username:  c.Username,
 		Timestamp: time.Now(),
 		Data:      wsMessage.Data,
+		SkipUserIDs: c.blockedUsers(),
 	}
 
 	c.Hub.Broadcast <- stopTypingMessage
 }
 
+// blockedUsers returns the client's blocked users, reloading them when the cache is stale
+func (c *Client) blockedUsers() map[uint]bool {
+	if c.BlockedIDs == nil || time.Since(c.BlockedAt) > blockCacheTTL {
+		c.BlockedIDs = c.Hub.getBlockedUserIDs(context.Background(), c.UserID)
+		c.BlockedAt = time.Now()
+	}
+	return c.BlockedIDs
+}
+
 // handleMarkRead handles mark as read requests
 func (c *Client) handleMarkRead(wsMessage WebSocketMessage) {
 	var data MarkReadData
@@ -283,12 +306,96 @@ func (c *Client) handleMarkRead(wsMessage WebSocketMessage) {
 		return
 	}
 
-	// TODO: Update last_read_at in database
-	// For now, just log the action
+	// Advance read pointer
+	ctx := context.Background()
+	pointers, advanced, err := c.Hub.markRead(ctx, c.UserID, data.ConversationID, data.MessageID)
+	if err != nil {
+		logger.Error("Failed to mark conversation read", zap.Error(err))
+		c.sendError("MARK_READ_FAILED", "Failed to mark conversation as read")
+		return
+	}
+
 	logger.Info("User marked conversation as read",
 		zap.Uint("user_id", c.UserID),
 		zap.Uint("conversation_id", data.ConversationID),
 		zap.Uint("message_id", data.MessageID))
+
+	if advanced {
+		c.Hub.wsService.HandleReceiptUpdated(ctx, data.ConversationID, c.UserID, pointers.LastReadMessageID, pointers.LastDeliveredMessageID)
+		c.Hub.wsService.HandleUnreadChanged(ctx, c.UserID, map[string]interface{}{
+			"conversation_id":      data.ConversationID,
+			"last_read_message_id": pointers.LastReadMessageID,
+			"unread_count":         pointers.UnreadCount,
+			"unread_mention_count": pointers.UnreadMentionCount,
+		})
+	}
+}
+
+// handleMarkDelivered handles delivery acknowledgements for messages received over the socket
+func (c *Client) handleMarkDelivered(wsMessage WebSocketMessage) {
+	var data MarkDeliveredData
+	if err := json.Unmarshal(wsMessage.Data, &data); err != nil || data.MessageID == 0 {
+		c.sendError("INVALID_DATA", "Invalid mark delivered data")
+		return
+	}
+
+	// Check if user is in conversation
+	if !c.Rooms[data.ConversationID] {
+		c.sendError("ACCESS_DENIED", "User is not in this conversation")
+		return
+	}
+
+	// Advance delivery pointer
+	ctx := context.Background()
+	pointers, advanced, err := c.Hub.markDelivered(ctx, c.UserID, data.ConversationID, data.MessageID)
+	if err != nil {
+		logger.Error("Failed to mark message delivered", zap.Error(err))
+		c.sendError("MARK_DELIVERED_FAILED", "Failed to acknowledge delivery")
+		return
+	}
+
+	if advanced {
+		c.Hub.wsService.HandleReceiptUpdated(ctx, data.ConversationID, c.UserID, pointers.LastReadMessageID, pointers.LastDeliveredMessageID)
+	}
+}
+
+// handleLocationUpdate handles position updates for the client's live location
+func (c *Client) handleLocationUpdate(wsMessage WebSocketMessage) {
+	var data LocationUpdateData
+	if err := json.Unmarshal(wsMessage.Data, &data); err != nil || data.MessageID == 0 || data.Latitude == nil || data.Longitude == nil {
+		c.sendError("INVALID_DATA", "Invalid location update data")
+		return
+	}
+
+	handler := c.Hub.wsService.locationHandler
+	if handler == nil {
+		c.sendError("LOCATION_UNAVAILABLE", "Live location is not available")
+		return
+	}
+
+	// The handler checks ownership, liveness and throttling, then broadcasts location_updated
+	if err := handler.UpdateLiveLocation(context.Background(), c.UserID, data.MessageID, *data.Latitude, *data.Longitude, data.Accuracy); err != nil {
+		c.sendError("LOCATION_UPDATE_FAILED", err.Error())
+	}
+}
+
+// handleStopLocation handles the client ending its live location early
+func (c *Client) handleStopLocation(wsMessage WebSocketMessage) {
+	var data StopLocationData
+	if err := json.Unmarshal(wsMessage.Data, &data); err != nil || data.MessageID == 0 {
+		c.sendError("INVALID_DATA", "Invalid stop location data")
+		return
+	}
+
+	handler := c.Hub.wsService.locationHandler
+	if handler == nil {
+		c.sendError("LOCATION_UNAVAILABLE", "Live location is not available")
+		return
+	}
+
+	if err := handler.StopLiveLocation(context.Background(), c.UserID, data.MessageID); err != nil {
+		c.sendError("STOP_LOCATION_FAILED", err.Error())
+	}
 }
 
 // sendError sends an error message to the client
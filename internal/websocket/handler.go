@@ -68,7 +68,7 @@ func (h *Handler) HandleWebSocket(w http.ResponseWriter, r *http.Request) {
 
 // GetOnlineUsers returns all online users
 func (h *Handler) GetOnlineUsers(c *gin.Context) {
-	users := h.service.GetOnlineUsers()
+	users := h.service.GetOnlineUsers(c.Request.Context(), c.GetUint("user_id"))
 	
 	utils.SuccessResponse(c, OnlineUsersResponse{
 		Users: users,
@@ -84,8 +84,8 @@ func (h *Handler) GetUserStatus(c *gin.Context) {
 		return
 	}
 
-	// Check if user is online
-	users := h.service.GetOnlineUsers()
+	// Check if user is online; blocked users always appear offline
+	users := h.service.GetOnlineUsers(c.Request.Context(), c.GetUint("user_id"))
 	var userStatus *UserStatus
 	
 	for _, user := range users {
@@ -206,6 +206,11 @@ func (h *Handler) getUserInfoFromGinContext(c *gin.Context) (uint, string, error
 		return 0, "", ErrUnauthorized
 	}
 
+	// Suspended users cannot connect
+	if auth.IsUserSuspended(c.Request.Context(), claims.UserID) {
+		return 0, "", ErrUnauthorized
+	}
+
 	return claims.UserID, claims.Username, nil
 }
 
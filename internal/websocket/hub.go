@@ -13,14 +13,15 @@ import (
 )
 
 // NewHub creates a new WebSocket hub
-func NewHub(wsService *service) *Hub {
+func NewHub(wsService *service, conversationRepo conversation.Repository) *Hub {
 	return &Hub{
-		Clients:    make(map[string]*Client),
-		Rooms:      make(map[uint]map[string]*Client),
-		Broadcast:  make(chan *WebSocketMessage, 100),
-		Register:   make(chan *Client, 10),
-		Unregister: make(chan *Client, 10),
-		wsService:  wsService,
+		Clients:          make(map[string]*Client),
+		Rooms:            make(map[uint]map[string]*Client),
+		Broadcast:        make(chan *WebSocketMessage, 100),
+		Register:         make(chan *Client, 10),
+		Unregister:       make(chan *Client, 10),
+		wsService:        wsService,
+		conversationRepo: conversationRepo,
 	}
 }
 
@@ -147,7 +148,7 @@ func (h *Hub) broadcastMessage(message *WebSocketMessage) {
 	
 	// Broadcast based on message type
 	switch message.Type {
-	case MessageTypeNewMessage, MessageTypeMessageUpdated, MessageTypeMessageDeleted,
+	case MessageTypeNewMessage, MessageTypeMessageUpdated, MessageTypeMessageDeleted, MessageTypeMessagePinned, MessageTypePollUpdated, MessageTypeReceiptUpdated,
 		 MessageTypeUserJoined, MessageTypeUserLeft, MessageTypeUserTyping, MessageTypeUserStopTyping:
 		// Extract conversation ID from data
 		var conversationID uint
@@ -163,23 +164,26 @@ func (h *Hub) broadcastMessage(message *WebSocketMessage) {
 		}
 		
 		if conversationID > 0 {
-			h.broadcastToRoom(conversationID, messageBytes)
+			h.broadcastToRoom(conversationID, messageBytes, message.SkipUserIDs)
 		}
 		
 	case MessageTypeUserOnline, MessageTypeUserOffline:
 		// Broadcast to all clients
-		h.broadcastToAll(messageBytes)
+		h.broadcastToAll(messageBytes, message.SkipUserIDs)
 		
 	default:
 		// Default: broadcast to all
-		h.broadcastToAll(messageBytes)
+		h.broadcastToAll(messageBytes, message.SkipUserIDs)
 	}
 }
 
-// broadcastToRoom broadcasts message to all clients in a room
-func (h *Hub) broadcastToRoom(conversationID uint, messageBytes []byte) {
+// broadcastToRoom broadcasts message to all clients in a room except skipped users
+func (h *Hub) broadcastToRoom(conversationID uint, messageBytes []byte, skipUserIDs map[uint]bool) {
 	if room, exists := h.Rooms[conversationID]; exists {
 		for _, client := range room {
+			if skipUserIDs[client.UserID] {
+				continue
+			}
 			select {
 			case client.Send <- messageBytes:
 				// Message sent successfully
@@ -195,9 +199,12 @@ func (h *Hub) broadcastToRoom(conversationID uint, messageBytes []byte) {
 	}
 }
 
-// broadcastToAll broadcasts message to all connected clients
-func (h *Hub) broadcastToAll(messageBytes []byte) {
+// broadcastToAll broadcasts message to all connected clients except skipped users
+func (h *Hub) broadcastToAll(messageBytes []byte, skipUserIDs map[uint]bool) {
 	for _, client := range h.Clients {
+		if skipUserIDs[client.UserID] {
+			continue
+		}
 		select {
 		case client.Send <- messageBytes:
 			// Message sent successfully
@@ -268,6 +275,20 @@ func (h *Hub) getRoomClients(conversationID uint) []*Client {
 	return []*Client{}
 }
 
+// getUserClients returns all connections of a user
+func (h *Hub) getUserClients(userID uint) []*Client {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	
+	clients := make([]*Client, 0)
+	for _, client := range h.Clients {
+		if client.UserID == userID {
+			clients = append(clients, client)
+		}
+	}
+	return clients
+}
+
 // getClient returns a client by ID
 func (h *Hub) getClient(clientID string) (*Client, error) {
 	h.mu.RLock()
@@ -279,13 +300,16 @@ func (h *Hub) getClient(clientID string) (*Client, error) {
 	return nil, fmt.Errorf("client not found: %s", clientID)
 }
 
-// getOnlineUsers returns all online users
-func (h *Hub) getOnlineUsers() []UserStatus {
+// getOnlineUsers returns all online users except skipped users
+func (h *Hub) getOnlineUsers(skipUserIDs map[uint]bool) []UserStatus {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	
 	users := make([]UserStatus, 0, len(h.Clients))
 	for _, client := range h.Clients {
+		if skipUserIDs[client.UserID] {
+			continue
+		}
 		users = append(users, UserStatus{
 			UserID:   client.UserID,
 			Username: client.Username,
@@ -299,8 +323,55 @@ func (h *Hub) getOnlineUsers() []UserStatus {
 // validateUserInConversation checks if user is in conversation
 func (h *Hub) validateUserInConversation(ctx context.Context, userID, conversationID uint) (bool, error) {
 	// Use conversation repository to validate
-	conversationRepo := conversation.NewRepository()
-	return conversationRepo.CheckUserInConversation(ctx, conversationID, userID)
+	return h.conversationRepo.CheckUserInConversation(ctx, conversationID, userID)
+}
+
+// getBlockedUserIDs returns the users who blocked, or were blocked by, the given user
+func (h *Hub) getBlockedUserIDs(ctx context.Context, userID uint) map[uint]bool {
+	userIDs, err := h.conversationRepo.GetBlockedUserIDs(ctx, userID)
+	if err != nil {
+		logger.Error("Failed to load blocked users", zap.Uint("user_id", userID), zap.Error(err))
+		return nil
+	}
+	
+	blocked := make(map[uint]bool, len(userIDs))
+	for _, id := range userIDs {
+		blocked[id] = true
+	}
+	return blocked
+}
+
+// markRead advances a user's read pointer to messageID, or to the latest message when messageID is 0
+func (h *Hub) markRead(ctx context.Context, userID, conversationID, messageID uint) (*conversation.ReceiptPointers, bool, error) {
+	messageID, err := h.resolveMessageID(ctx, conversationID, messageID)
+	if err != nil || messageID == 0 {
+		return nil, false, err
+	}
+	return h.conversationRepo.AdvanceReadPointer(ctx, conversationID, userID, messageID)
+}
+
+// markDelivered advances a user's delivery pointer to messageID
+func (h *Hub) markDelivered(ctx context.Context, userID, conversationID, messageID uint) (*conversation.ReceiptPointers, bool, error) {
+	messageID, err := h.resolveMessageID(ctx, conversationID, messageID)
+	if err != nil || messageID == 0 {
+		return nil, false, err
+	}
+	return h.conversationRepo.AdvanceDeliveredPointer(ctx, conversationID, userID, messageID)
+}
+
+// resolveMessageID checks a message belongs to the conversation; 0 resolves to the latest message
+func (h *Hub) resolveMessageID(ctx context.Context, conversationID, messageID uint) (uint, error) {
+	if messageID == 0 {
+		return h.conversationRepo.GetLatestMessageID(ctx, conversationID)
+	}
+	exists, err := h.conversationRepo.CheckMessageInConversation(ctx, conversationID, messageID)
+	if err != nil {
+		return 0, err
+	}
+	if !exists {
+		return 0, fmt.Errorf("message %d not found in conversation %d", messageID, conversationID)
+	}
+	return messageID, nil
 }
 
 // broadcastUserStatusChange broadcasts user online/offline status to all clients
@@ -327,8 +398,8 @@ func (h *Hub) broadcastUserStatusChange(userID uint, username string, isOnline b
 		Username:  username,
 	}
 	
-	// Broadcast to all clients
-	h.broadcastToAll(mustMarshalJSON(message))
+	// Broadcast to all clients, hiding presence from blocked users
+	h.broadcastToAll(mustMarshalJSON(message), h.getBlockedUserIDs(context.Background(), userID))
 	
 	logger.Info("Broadcasted user status change",
 		zap.Uint("user_id", userID),
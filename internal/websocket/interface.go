@@ -15,7 +15,7 @@ type Service interface {
 	RegisterClient(client *Client)
 	UnregisterClient(client *Client)
 	GetClient(clientID string) (*Client, error)
-	GetOnlineUsers() []UserStatus
+	GetOnlineUsers(ctx context.Context, viewerID uint) []UserStatus
 
 	// Room management
 	JoinRoom(client *Client, conversationID uint) error
@@ -29,13 +29,35 @@ type Service interface {
 
 	// Event handling
 	HandleNewMessage(ctx context.Context, conversationID uint, messageData map[string]interface{})
+	HandleNewMessageForUser(ctx context.Context, userID, conversationID uint, messageData map[string]interface{})
 	HandleMessageUpdated(ctx context.Context, conversationID uint, messageData map[string]interface{})
 	HandleMessageDeleted(ctx context.Context, conversationID uint, messageID uint)
+	HandleMessagePinned(ctx context.Context, conversationID uint, messageID uint, pinnedBy uint, pinned bool)
+	HandlePollUpdated(ctx context.Context, conversationID uint, pollData map[string]interface{})
+	HandleLocationUpdated(ctx context.Context, conversationID uint, locationData map[string]interface{})
+	HandleBookmarkReminder(ctx context.Context, userID uint, bookmarkData map[string]interface{})
+	HandleDraftUpdated(ctx context.Context, userID uint, draftData map[string]interface{})
+	HandleReceiptUpdated(ctx context.Context, conversationID, userID uint, lastReadMessageID, lastDeliveredMessageID *uint)
+	HandleUnreadChanged(ctx context.Context, userID uint, unreadData map[string]interface{})
+	HandleExportFinished(ctx context.Context, userID uint, exportData map[string]interface{})
+	HandleEphemeralMessage(ctx context.Context, userID uint, ephemeralData map[string]interface{})
+	HandleReportUpdated(ctx context.Context, userID uint, reportData map[string]interface{})
+	HandleModerationNotice(ctx context.Context, userID uint, noticeData map[string]interface{})
 	HandleUserJoined(ctx context.Context, conversationID uint, userData map[string]interface{})
 	HandleUserLeft(ctx context.Context, conversationID uint, userID uint, username string)
 
 	// Validation
 	ValidateUserInConversation(ctx context.Context, userID, conversationID uint) (bool, error)
+
+	// Client event handlers
+	SetLocationHandler(handler LocationHandler)
+}
+
+// LocationHandler applies the live location updates clients stream over the socket.
+// It is implemented by message.Service.
+type LocationHandler interface {
+	UpdateLiveLocation(ctx context.Context, userID, messageID uint, latitude, longitude float64, accuracy *float64) error
+	StopLiveLocation(ctx context.Context, userID, messageID uint) error
 }
 
 
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"sync"
 	"time"
+
+	"huddle/internal/conversation"
 )
 
 // MessageType represents the type of WebSocket message
@@ -16,11 +18,25 @@ const (
 	MessageTypeTyping           MessageType = "typing"
 	MessageTypeStopTyping       MessageType = "stop_typing"
 	MessageTypeMarkRead         MessageType = "mark_read"
+	MessageTypeMarkDelivered    MessageType = "mark_delivered"
+	MessageTypeLocationUpdate   MessageType = "location_update"
+	MessageTypeStopLocation     MessageType = "stop_location"
 
 	// Server events
 	MessageTypeNewMessage       MessageType = "new_message"
 	MessageTypeMessageUpdated   MessageType = "message_updated"
 	MessageTypeMessageDeleted   MessageType = "message_deleted"
+	MessageTypeMessagePinned    MessageType = "message_pinned"
+	MessageTypePollUpdated      MessageType = "poll_updated"
+	MessageTypeLocationUpdated  MessageType = "location_updated"
+	MessageTypeBookmarkReminder MessageType = "bookmark_reminder"
+	MessageTypeDraftUpdated     MessageType = "draft_updated"
+	MessageTypeReceiptUpdated   MessageType = "receipt_updated"
+	MessageTypeUnreadChanged    MessageType = "unread_changed"
+	MessageTypeExportFinished   MessageType = "export_finished"
+	MessageTypeEphemeral        MessageType = "ephemeral_message"
+	MessageTypeReportUpdated    MessageType = "report_updated"
+	MessageTypeModerationNotice MessageType = "moderation_notice"
 	MessageTypeUserJoined       MessageType = "user_joined"
 	MessageTypeUserLeft         MessageType = "user_left"
 	MessageTypeUserTyping       MessageType = "user_typing"
@@ -38,6 +54,7 @@ type WebSocketMessage struct {
 	Timestamp time.Time            `json:"timestamp"`
 	UserID    uint                 `json:"user_id,omitempty"`
 	Username  string               `json:"username,omitempty"`
+	SkipUserIDs map[uint]bool      `json:"-"` // Recipients who must not receive the event, such as users blocked by the sender
 }
 
 // Client represents a WebSocket client
@@ -51,6 +68,8 @@ type Client struct {
 	Send       chan []byte       `json:"-"`
 	LastPing   time.Time         `json:"last_ping"`
 	IsOnline   bool              `json:"is_online"`
+	BlockedIDs map[uint]bool     `json:"-"` // Users blocked by or blocking this user, refreshed every blockCacheTTL
+	BlockedAt  time.Time         `json:"-"`
 }
 
 // Connection wraps the WebSocket connection
@@ -60,13 +79,14 @@ type Connection struct {
 
 // Hub manages all WebSocket clients
 type Hub struct {
-	Clients    map[string]*Client           `json:"-"` // client_id -> client
-	Rooms      map[uint]map[string]*Client  `json:"-"` // conversation_id -> clients
-	Broadcast  chan *WebSocketMessage       `json:"-"`
-	Register   chan *Client                 `json:"-"`
-	Unregister chan *Client                 `json:"-"`
-	mu         sync.RWMutex                 `json:"-"` // mutex for thread safety
-	wsService  *service                     `json:"-"` // reference to service
+	Clients          map[string]*Client          `json:"-"` // client_id -> client
+	Rooms            map[uint]map[string]*Client `json:"-"` // conversation_id -> clients
+	Broadcast        chan *WebSocketMessage      `json:"-"`
+	Register         chan *Client                `json:"-"`
+	Unregister       chan *Client                `json:"-"`
+	mu               sync.RWMutex                `json:"-"` // mutex for thread safety
+	wsService        *service                    `json:"-"` // reference to service
+	conversationRepo conversation.Repository     `json:"-"` // participants, blocks and receipts
 }
 
 // Event data structures
@@ -88,6 +108,29 @@ type MarkReadData struct {
 	MessageID      uint `json:"message_id,omitempty"`
 }
 
+type MarkDeliveredData struct {
+	ConversationID uint `json:"conversation_id"`
+	MessageID      uint `json:"message_id"`
+}
+
+type LocationUpdateData struct {
+	MessageID uint     `json:"message_id"`
+	Latitude  *float64 `json:"latitude"`
+	Longitude *float64 `json:"longitude"`
+	Accuracy  *float64 `json:"accuracy,omitempty"`
+}
+
+type StopLocationData struct {
+	MessageID uint `json:"message_id"`
+}
+
+type ReceiptUpdatedData struct {
+	ConversationID         uint  `json:"conversation_id"`
+	UserID                 uint  `json:"user_id"`
+	LastReadMessageID      *uint `json:"last_read_message_id"`
+	LastDeliveredMessageID *uint `json:"last_delivered_message_id"`
+}
+
 type NewMessageData struct {
 	ConversationID uint                   `json:"conversation_id"`
 	Message        map[string]interface{} `json:"message"`
@@ -103,6 +146,13 @@ type MessageDeletedData struct {
 	MessageID      uint `json:"message_id"`
 }
 
+type MessagePinnedData struct {
+	ConversationID uint `json:"conversation_id"`
+	MessageID      uint `json:"message_id"`
+	Pinned         bool `json:"pinned"`
+	PinnedBy       uint `json:"pinned_by"`
+}
+
 type UserJoinedData struct {
 	ConversationID uint                   `json:"conversation_id"`
 	User           map[string]interface{} `json:"user"`
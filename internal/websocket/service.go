@@ -13,13 +13,14 @@ import (
 
 // service implements the Service interface
 type service struct {
-	hub *Hub
+	hub             *Hub
+	locationHandler LocationHandler // Set at startup, before clients connect
 }
 
 // NewService creates a new WebSocket service
 func NewService() Service {
 	s := &service{}
-	s.hub = NewHub(s)
+	s.hub = NewHub(s, conversation.NewRepository())
 	return s
 }
 
@@ -54,9 +55,9 @@ func (s *service) GetClient(clientID string) (*Client, error) {
 	return s.hub.getClient(clientID)
 }
 
-// GetOnlineUsers returns all online users
-func (s *service) GetOnlineUsers() []UserStatus {
-	return s.hub.getOnlineUsers()
+// GetOnlineUsers returns the online users visible to the viewer
+func (s *service) GetOnlineUsers(ctx context.Context, viewerID uint) []UserStatus {
+	return s.hub.getOnlineUsers(s.hub.getBlockedUserIDs(ctx, viewerID))
 }
 
 // JoinRoom adds a client to a room
@@ -96,21 +97,19 @@ func (s *service) BroadcastToRoom(conversationID uint, message *WebSocketMessage
 // BroadcastToUser broadcasts message to a specific user
 func (s *service) BroadcastToUser(userID uint, message *WebSocketMessage) {
 	// Find all clients for this user
-	for _, client := range s.hub.Clients {
-		if client.UserID == userID {
-			messageBytes, err := json.Marshal(message)
-			if err != nil {
-				logger.Error("Failed to marshal message", zap.Error(err))
-				continue
-			}
-			
-			select {
-			case client.Send <- messageBytes:
-				// Message sent successfully
-			default:
-				// Client buffer is full
-				logger.Warn("Client buffer full", zap.String("client_id", client.ID))
-			}
+	for _, client := range s.hub.getUserClients(userID) {
+		messageBytes, err := json.Marshal(message)
+		if err != nil {
+			logger.Error("Failed to marshal message", zap.Error(err))
+			continue
+		}
+		
+		select {
+		case client.Send <- messageBytes:
+			// Message sent successfully
+		default:
+			// Client buffer is full
+			logger.Warn("Client buffer full", zap.String("client_id", client.ID))
 		}
 	}
 }
@@ -139,6 +138,20 @@ func (s *service) HandleNewMessage(ctx context.Context, conversationID uint, mes
 	s.BroadcastToRoom(conversationID, message)
 }
 
+// HandleNewMessageForUser delivers a new message event to one user's devices only
+func (s *service) HandleNewMessageForUser(ctx context.Context, userID, conversationID uint, messageData map[string]interface{}) {
+	messageData["conversation_id"] = conversationID
+	
+	message := &WebSocketMessage{
+		Type:      MessageTypeNewMessage,
+		Data:      mustMarshalJSON(messageData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
 // HandleMessageUpdated handles message update events
 func (s *service) HandleMessageUpdated(ctx context.Context, conversationID uint, messageData map[string]interface{}) {
 	message := &WebSocketMessage{
@@ -166,6 +179,154 @@ func (s *service) HandleMessageDeleted(ctx context.Context, conversationID uint,
 	s.BroadcastToRoom(conversationID, message)
 }
 
+// HandleMessagePinned handles message pin/unpin events
+func (s *service) HandleMessagePinned(ctx context.Context, conversationID uint, messageID uint, pinnedBy uint, pinned bool) {
+	data := MessagePinnedData{
+		ConversationID: conversationID,
+		MessageID:      messageID,
+		Pinned:         pinned,
+		PinnedBy:       pinnedBy,
+	}
+	
+	message := &WebSocketMessage{
+		Type:      MessageTypeMessagePinned,
+		Data:      mustMarshalJSON(data),
+		Timestamp: time.Now(),
+	}
+	
+	s.BroadcastToRoom(conversationID, message)
+}
+
+// HandlePollUpdated handles poll tally and state change events
+func (s *service) HandlePollUpdated(ctx context.Context, conversationID uint, pollData map[string]interface{}) {
+	// Add conversation_id to poll data
+	pollData["conversation_id"] = conversationID
+	
+	message := &WebSocketMessage{
+		Type:      MessageTypePollUpdated,
+		Data:      mustMarshalJSON(pollData),
+		Timestamp: time.Now(),
+	}
+	
+	s.BroadcastToRoom(conversationID, message)
+}
+
+// HandleLocationUpdated handles live location position and state change events
+func (s *service) HandleLocationUpdated(ctx context.Context, conversationID uint, locationData map[string]interface{}) {
+	// Add conversation_id to location data
+	locationData["conversation_id"] = conversationID
+
+	message := &WebSocketMessage{
+		Type:      MessageTypeLocationUpdated,
+		Data:      mustMarshalJSON(locationData),
+		Timestamp: time.Now(),
+	}
+
+	s.BroadcastToRoom(conversationID, message)
+}
+
+// HandleBookmarkReminder sends a due bookmark reminder to all of the user's connections
+func (s *service) HandleBookmarkReminder(ctx context.Context, userID uint, bookmarkData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeBookmarkReminder,
+		Data:      mustMarshalJSON(bookmarkData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleDraftUpdated syncs a changed draft to all of the user's connections
+func (s *service) HandleDraftUpdated(ctx context.Context, userID uint, draftData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeDraftUpdated,
+		Data:      mustMarshalJSON(draftData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleReceiptUpdated handles read/delivery pointer change events
+func (s *service) HandleReceiptUpdated(ctx context.Context, conversationID, userID uint, lastReadMessageID, lastDeliveredMessageID *uint) {
+	data := ReceiptUpdatedData{
+		ConversationID:         conversationID,
+		UserID:                 userID,
+		LastReadMessageID:      lastReadMessageID,
+		LastDeliveredMessageID: lastDeliveredMessageID,
+	}
+	
+	message := &WebSocketMessage{
+		Type:      MessageTypeReceiptUpdated,
+		Data:      mustMarshalJSON(data),
+		Timestamp: time.Now(),
+	}
+	
+	s.BroadcastToRoom(conversationID, message)
+}
+
+// HandleUnreadChanged sends a conversation's new unread counters to all of the user's devices
+func (s *service) HandleUnreadChanged(ctx context.Context, userID uint, unreadData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeUnreadChanged,
+		Data:      mustMarshalJSON(unreadData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleExportFinished tells the requester that a conversation export is ready or failed
+func (s *service) HandleExportFinished(ctx context.Context, userID uint, exportData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeExportFinished,
+		Data:      mustMarshalJSON(exportData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleEphemeralMessage sends a message only the given user sees, such as a slash command reply or a reminder
+func (s *service) HandleEphemeralMessage(ctx context.Context, userID uint, ephemeralData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeEphemeral,
+		Data:      mustMarshalJSON(ephemeralData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleReportUpdated tells a reporter that their report was resolved
+func (s *service) HandleReportUpdated(ctx context.Context, userID uint, reportData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeReportUpdated,
+		Data:      mustMarshalJSON(reportData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
+// HandleModerationNotice sends a moderator's warning or suspension notice to a user
+func (s *service) HandleModerationNotice(ctx context.Context, userID uint, noticeData map[string]interface{}) {
+	message := &WebSocketMessage{
+		Type:      MessageTypeModerationNotice,
+		Data:      mustMarshalJSON(noticeData),
+		Timestamp: time.Now(),
+		UserID:    userID,
+	}
+	
+	s.BroadcastToUser(userID, message)
+}
+
 // HandleUserJoined handles user joined conversation events
 func (s *service) HandleUserJoined(ctx context.Context, conversationID uint, userData map[string]interface{}) {
 	message := &WebSocketMessage{
@@ -196,8 +357,12 @@ func (s *service) HandleUserLeft(ctx context.Context, conversationID uint, userI
 
 // ValidateUserInConversation checks if user is in conversation
 func (s *service) ValidateUserInConversation(ctx context.Context, userID, conversationID uint) (bool, error) {
-	conversationRepo := conversation.NewRepository()
-	return conversationRepo.CheckUserInConversation(ctx, conversationID, userID)
+	return s.hub.conversationRepo.CheckUserInConversation(ctx, conversationID, userID)
+}
+
+// SetLocationHandler sets the handler for live location updates sent by clients
+func (s *service) SetLocationHandler(handler LocationHandler) {
+	s.locationHandler = handler
 }
 
 // mustMarshalJSON marshals data to JSON, panics on error
@@ -0,0 +1,20 @@
+-- Migration: 008_pinned_messages.sql
+-- Description: Add pinned messages per conversation
+
+-- Who may pin messages in a conversation (admins only by default)
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS only_admins_can_pin BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Create pinned_messages table
+CREATE TABLE IF NOT EXISTS pinned_messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    pinned_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(conversation_id, message_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_conversation_id ON pinned_messages(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_message_id ON pinned_messages(message_id);
+CREATE INDEX IF NOT EXISTS idx_pinned_messages_conv_created ON pinned_messages(conversation_id, created_at);
@@ -0,0 +1,34 @@
+-- Migration: 009_scheduled_messages.sql
+-- Description: Add scheduled (send-later) messages
+
+-- Create scheduled_messages table
+CREATE TABLE IF NOT EXISTS scheduled_messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL,
+    message_type VARCHAR(20) NOT NULL DEFAULT 'text' CHECK (message_type IN ('text', 'image', 'file', 'system')),
+    file_url VARCHAR(500),
+    file_name VARCHAR(255),
+    file_size BIGINT,
+    reply_to_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+    scheduled_at TIMESTAMP NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'sending', 'sent', 'cancelled', 'failed')),
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    claimed_at TIMESTAMP,
+    message_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+    sent_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_sender_id ON scheduled_messages(sender_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_conversation_id ON scheduled_messages(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_scheduled_messages_status_scheduled ON scheduled_messages(status, scheduled_at);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_scheduled_messages_updated_at 
+    BEFORE UPDATE ON scheduled_messages 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
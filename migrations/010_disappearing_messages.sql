@@ -0,0 +1,11 @@
+-- Migration: 010_disappearing_messages.sql
+-- Description: Add per-conversation message retention timers (disappearing messages)
+
+-- Retention timer in seconds (0 = messages never expire)
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS message_ttl_seconds INTEGER NOT NULL DEFAULT 0 CHECK (message_ttl_seconds >= 0);
+
+-- Expiry time computed when the message is sent
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+-- Add index for the expiry sweeper
+CREATE INDEX IF NOT EXISTS idx_messages_expires_at ON messages(expires_at) WHERE expires_at IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Migration: 011_message_forwarding.sql
+-- Description: Track the origin of messages forwarded between conversations
+
+-- Origin of a forwarded message (NULL for regular messages)
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS forwarded_from_message_id INTEGER REFERENCES messages(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS forwarded_from_sender_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS forwarded_from_conversation_id INTEGER REFERENCES conversations(id) ON DELETE SET NULL;
+
+-- Add index for looking up forwarded copies
+CREATE INDEX IF NOT EXISTS idx_messages_forwarded_from_message_id ON messages(forwarded_from_message_id) WHERE forwarded_from_message_id IS NOT NULL;
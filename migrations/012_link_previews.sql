@@ -0,0 +1,21 @@
+-- Migration: 012_link_previews.sql
+-- Description: Add OpenGraph link previews for URLs in messages
+
+-- Whether links posted in a conversation are unfurled (on by default)
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS link_previews_enabled BOOLEAN NOT NULL DEFAULT TRUE;
+
+-- Create message_link_previews table
+CREATE TABLE IF NOT EXISTS message_link_previews (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    url VARCHAR(2048) NOT NULL,
+    title VARCHAR(300),
+    description TEXT,
+    image_url VARCHAR(2048),
+    site_name VARCHAR(300),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(message_id, url)
+);
+
+-- Add indexes for better performance
+CREATE INDEX IF NOT EXISTS idx_message_link_previews_message_id ON message_link_previews(message_id);
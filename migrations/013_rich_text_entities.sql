@@ -0,0 +1,8 @@
+-- Migration: 013_rich_text_entities.sql
+-- Description: Store parsed Markdown formatting as entity spans next to the plain text
+
+-- Formatting entities (bold, links, code, ...) with UTF-16 offsets into content
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS entities JSONB;
+
+-- Scheduled messages are parsed when posted, so remember how
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS parse_mode VARCHAR(20) NOT NULL DEFAULT 'markdown';
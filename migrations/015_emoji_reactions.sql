@@ -0,0 +1,22 @@
+-- Migration: 015_emoji_reactions.sql
+-- Description: Allow any emoji (and custom :shortcode: emoji) as a message reaction
+
+-- Drop the fixed reaction type list
+ALTER TABLE message_reactions DROP CONSTRAINT IF EXISTS message_reactions_reaction_type_check;
+ALTER TABLE message_reactions ALTER COLUMN reaction_type DROP DEFAULT;
+ALTER TABLE message_reactions ALTER COLUMN reaction_type TYPE VARCHAR(64);
+ALTER TABLE message_reactions RENAME COLUMN reaction_type TO emoji;
+
+-- Convert legacy reaction types to their emoji
+UPDATE message_reactions SET emoji = CASE emoji
+    WHEN 'like' THEN '👍'
+    WHEN 'love' THEN '❤️'
+    WHEN 'haha' THEN '😂'
+    WHEN 'wow' THEN '😮'
+    WHEN 'sad' THEN '😢'
+    WHEN 'angry' THEN '😠'
+    ELSE emoji
+END;
+
+-- Add index for aggregating reactions per message
+CREATE INDEX IF NOT EXISTS idx_message_reactions_message_emoji ON message_reactions(message_id, emoji);
@@ -0,0 +1,26 @@
+-- Migration: 016_message_bookmarks.sql
+-- Description: Add saved messages (bookmarks) with personal labels and reminders
+
+-- Create message_bookmarks table
+CREATE TABLE IF NOT EXISTS message_bookmarks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    label VARCHAR(50) NOT NULL DEFAULT '',
+    remind_at TIMESTAMP,
+    reminded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(user_id, message_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_message_bookmarks_user_id ON message_bookmarks(user_id, id DESC);
+CREATE INDEX IF NOT EXISTS idx_message_bookmarks_user_label ON message_bookmarks(user_id, label);
+CREATE INDEX IF NOT EXISTS idx_message_bookmarks_message_id ON message_bookmarks(message_id);
+CREATE INDEX IF NOT EXISTS idx_message_bookmarks_remind_at ON message_bookmarks(remind_at) WHERE reminded_at IS NULL;
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_message_bookmarks_updated_at 
+    BEFORE UPDATE ON message_bookmarks 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
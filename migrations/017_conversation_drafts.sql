@@ -0,0 +1,23 @@
+-- Migration: 017_conversation_drafts.sql
+-- Description: Add server-synced message drafts (one per user per conversation)
+
+-- Create conversation_drafts table
+CREATE TABLE IF NOT EXISTS conversation_drafts (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    content TEXT NOT NULL DEFAULT '',
+    reply_to_id INTEGER REFERENCES messages(id) ON DELETE SET NULL,
+    attachment_ids JSONB NOT NULL DEFAULT '[]',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(conversation_id, user_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_conversation_drafts_user_id ON conversation_drafts(user_id);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_conversation_drafts_updated_at 
+    BEFORE UPDATE ON conversation_drafts 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
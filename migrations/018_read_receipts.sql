@@ -0,0 +1,16 @@
+-- Migration: 018_read_receipts.sql
+-- Description: Track per-participant read and delivery pointers (message ids) for read receipts
+
+-- Add read/delivery pointers to conversation participants
+ALTER TABLE conversation_participants ADD COLUMN IF NOT EXISTS last_read_message_id INTEGER;
+ALTER TABLE conversation_participants ADD COLUMN IF NOT EXISTS last_delivered_message_id INTEGER;
+
+-- Backfill pointers from the existing last_read_at timestamps
+UPDATE conversation_participants cp SET last_read_message_id = (
+    SELECT MAX(m.id) FROM messages m
+    WHERE m.conversation_id = cp.conversation_id AND m.created_at <= cp.last_read_at
+)
+WHERE cp.last_read_message_id IS NULL;
+
+UPDATE conversation_participants SET last_delivered_message_id = last_read_message_id
+WHERE last_delivered_message_id IS NULL;
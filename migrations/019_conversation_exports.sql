@@ -0,0 +1,30 @@
+-- Migration: 019_conversation_exports.sql
+-- Description: Add asynchronous conversation history export jobs
+
+-- Create conversation_exports table
+CREATE TABLE IF NOT EXISTS conversation_exports (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    requested_by INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    format VARCHAR(10) NOT NULL CHECK (format IN ('json', 'html', 'txt')),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    object_key VARCHAR(500),
+    file_size BIGINT NOT NULL DEFAULT 0,
+    message_count INTEGER NOT NULL DEFAULT 0,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT,
+    started_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_conversation_exports_conversation_id ON conversation_exports(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_conversation_exports_requested_by ON conversation_exports(requested_by);
+CREATE INDEX IF NOT EXISTS idx_conversation_exports_status_created ON conversation_exports(status, created_at);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_conversation_exports_updated_at 
+    BEFORE UPDATE ON conversation_exports 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,15 @@
+-- Migration: 020_slack_import_mappings.sql
+-- Description: Track Slack objects already imported so a Slack export import can be re-run safely
+
+-- Create slack_import_mappings table
+CREATE TABLE IF NOT EXISTS slack_import_mappings (
+    id SERIAL PRIMARY KEY,
+    source_type VARCHAR(20) NOT NULL CHECK (source_type IN ('channel', 'message')),
+    slack_id VARCHAR(100) NOT NULL,
+    target_id INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(source_type, slack_id)
+);
+
+-- Add index for finding the Slack origin of imported rows
+CREATE INDEX IF NOT EXISTS idx_slack_import_mappings_target ON slack_import_mappings(source_type, target_id);
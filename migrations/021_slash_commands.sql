@@ -0,0 +1,29 @@
+-- Migration: 021_slash_commands.sql
+-- Description: Support slash commands: conversation topics, personal reminders and bot webhook commands
+
+-- Add topic to conversations (set with /topic)
+ALTER TABLE conversations ADD COLUMN IF NOT EXISTS topic VARCHAR(250) NOT NULL DEFAULT '';
+
+-- Scheduled messages can be personal reminders (set with /remind me) that are never posted
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS kind VARCHAR(20) NOT NULL DEFAULT 'message' CHECK (kind IN ('message', 'reminder'));
+
+-- Create bot_commands table (slash commands answered by an external webhook)
+CREATE TABLE IF NOT EXISTS bot_commands (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    name VARCHAR(32) NOT NULL,
+    description VARCHAR(200) NOT NULL DEFAULT '',
+    usage VARCHAR(200) NOT NULL DEFAULT '',
+    webhook_url TEXT NOT NULL,
+    secret VARCHAR(64) NOT NULL,
+    admin_only BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(conversation_id, name)
+);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_bot_commands_updated_at 
+    BEFORE UPDATE ON bot_commands 
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+-- Migration: 022_client_message_ids.sql
+-- Description: Client-generated message ids so retried sends do not create duplicates
+
+-- Id chosen by the sending client (NULL for messages sent without one)
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS client_message_id VARCHAR(64);
+
+-- A client id is unique per sender and conversation
+CREATE UNIQUE INDEX IF NOT EXISTS idx_messages_client_message_id ON messages(conversation_id, sender_id, client_message_id) WHERE client_message_id IS NOT NULL;
@@ -0,0 +1,8 @@
+-- Migration: 023_message_attachments.sql
+-- Description: Let a message reference several uploaded files
+
+-- Order of a file among the attachments of its message
+ALTER TABLE files ADD COLUMN IF NOT EXISTS attachment_position INTEGER NOT NULL DEFAULT 0;
+
+-- Add index for loading the attachments of a message in order
+CREATE INDEX IF NOT EXISTS idx_files_message_attachments ON files(message_id, attachment_position) WHERE message_id IS NOT NULL;
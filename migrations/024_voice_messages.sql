@@ -0,0 +1,9 @@
+-- Migration: 024_voice_messages.sql
+-- Description: Voice messages with duration and waveform
+
+-- Allow voice messages
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_message_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_message_type_check CHECK (message_type IN ('text', 'image', 'file', 'system', 'poll', 'voice'));
+
+-- Downsampled amplitudes (0-100) of audio files, shown as the voice message waveform
+ALTER TABLE files ADD COLUMN IF NOT EXISTS waveform JSONB;
@@ -0,0 +1,54 @@
+-- Migration: 025_moderation.sql
+-- Description: Content moderation rules, review queue and shadow-hidden messages
+
+-- Moderators manage workspace rules and review flagged messages
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_moderator BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Shadow-hidden messages stay visible to their sender only
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS hidden_at TIMESTAMP;
+
+-- Create moderation_rules table (conversation_id NULL = workspace-wide rule)
+CREATE TABLE IF NOT EXISTS moderation_rules (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER REFERENCES conversations(id) ON DELETE CASCADE,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('word', 'regex', 'domain')),
+    pattern VARCHAR(500) NOT NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('reject', 'mask', 'flag', 'shadow_hide')),
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Create moderation_flags table (review queue, one entry per message)
+-- message_id has no foreign key so decisions outlive the messages they removed
+CREATE TABLE IF NOT EXISTS moderation_flags (
+    id SERIAL PRIMARY KEY,
+    message_id INTEGER NOT NULL,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL CHECK (action IN ('flag', 'shadow_hide')),
+    reasons JSONB NOT NULL DEFAULT '[]',
+    content TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'removed')),
+    reviewed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    resolution_note VARCHAR(500) NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE(message_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_moderation_rules_conversation_id ON moderation_rules(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_moderation_flags_status_created ON moderation_flags(status, created_at);
+CREATE INDEX IF NOT EXISTS idx_messages_hidden_at ON messages(hidden_at) WHERE hidden_at IS NOT NULL;
+
+-- Add triggers for updated_at
+CREATE TRIGGER update_moderation_rules_updated_at
+    BEFORE UPDATE ON moderation_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_moderation_flags_updated_at
+    BEFORE UPDATE ON moderation_flags
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
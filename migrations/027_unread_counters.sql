@@ -0,0 +1,111 @@
+-- Migration: 027_unread_counters.sql
+-- Description: Per-participant unread and mention counters maintained on message insert, delete and hide
+
+-- Add counters to conversation participants
+ALTER TABLE conversation_participants ADD COLUMN IF NOT EXISTS unread_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE conversation_participants ADD COLUMN IF NOT EXISTS unread_mention_count INTEGER NOT NULL DEFAULT 0;
+
+-- Create message_mentions table (users @mentioned in a message)
+CREATE TABLE IF NOT EXISTS message_mentions (
+    message_id INTEGER NOT NULL REFERENCES messages(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP DEFAULT NOW(),
+    PRIMARY KEY (message_id, user_id)
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_message_mentions_user_id ON message_mentions(user_id);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_id_id ON messages(conversation_id, id);
+CREATE INDEX IF NOT EXISTS idx_conversation_participants_user_unread ON conversation_participants(user_id)
+    WHERE unread_count > 0 OR unread_mention_count > 0;
+
+-- Backfill counters from the read pointers
+UPDATE conversation_participants cp SET
+    unread_count = (
+        SELECT COUNT(*) FROM messages m
+        WHERE m.conversation_id = cp.conversation_id
+            AND m.id > COALESCE(cp.last_read_message_id, 0)
+            AND m.sender_id IS DISTINCT FROM cp.user_id
+            AND m.hidden_at IS NULL
+    ),
+    unread_mention_count = 0;
+
+-- Keep unread counters in step with visible messages after the reader's pointer
+CREATE OR REPLACE FUNCTION update_unread_counters()
+RETURNS TRIGGER AS $$
+DECLARE
+    msg messages%ROWTYPE;
+    delta INTEGER;
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.hidden_at IS NOT NULL THEN
+            RETURN NEW;
+        END IF;
+        msg := NEW;
+        delta := 1;
+    ELSIF TG_OP = 'DELETE' THEN
+        IF OLD.hidden_at IS NOT NULL THEN
+            RETURN OLD;
+        END IF;
+        msg := OLD;
+        delta := -1;
+    ELSE
+        -- Shadow-hiding removes a message from everyone else's counts; approving it adds it back
+        IF (OLD.hidden_at IS NULL) = (NEW.hidden_at IS NULL) THEN
+            RETURN NEW;
+        END IF;
+        msg := NEW;
+        delta := CASE WHEN NEW.hidden_at IS NULL THEN 1 ELSE -1 END;
+    END IF;
+
+    UPDATE conversation_participants
+    SET unread_count = GREATEST(unread_count + delta, 0)
+    WHERE conversation_id = msg.conversation_id
+        AND user_id IS DISTINCT FROM msg.sender_id
+        AND COALESCE(last_read_message_id, 0) < msg.id;
+
+    -- Mentions are inserted after the message, so only deletes and hide changes touch them here
+    IF TG_OP <> 'INSERT' THEN
+        UPDATE conversation_participants cp
+        SET unread_mention_count = GREATEST(cp.unread_mention_count + delta, 0)
+        FROM message_mentions mm
+        WHERE mm.message_id = msg.id
+            AND cp.conversation_id = msg.conversation_id
+            AND cp.user_id = mm.user_id
+            AND COALESCE(cp.last_read_message_id, 0) < msg.id;
+    END IF;
+
+    IF TG_OP = 'DELETE' THEN
+        RETURN OLD;
+    END IF;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+CREATE OR REPLACE FUNCTION update_unread_mention_counters()
+RETURNS TRIGGER AS $$
+BEGIN
+    UPDATE conversation_participants cp
+    SET unread_mention_count = cp.unread_mention_count + 1
+    FROM messages m
+    WHERE m.id = NEW.message_id
+        AND m.hidden_at IS NULL
+        AND cp.conversation_id = m.conversation_id
+        AND cp.user_id = NEW.user_id
+        AND COALESCE(cp.last_read_message_id, 0) < m.id;
+    RETURN NEW;
+END;
+$$ language 'plpgsql';
+
+-- Deletes run before the row goes so cascaded mentions are still visible
+CREATE TRIGGER update_unread_counters_on_write
+    AFTER INSERT OR UPDATE OF hidden_at ON messages
+    FOR EACH ROW EXECUTE FUNCTION update_unread_counters();
+
+CREATE TRIGGER update_unread_counters_on_delete
+    BEFORE DELETE ON messages
+    FOR EACH ROW EXECUTE FUNCTION update_unread_counters();
+
+CREATE TRIGGER update_unread_mention_counters
+    AFTER INSERT ON message_mentions
+    FOR EACH ROW EXECUTE FUNCTION update_unread_mention_counters();
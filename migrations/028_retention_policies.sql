@@ -0,0 +1,68 @@
+-- Migration: 028_retention_policies.sql
+-- Description: Message retention policies, legal holds and purge run reports
+
+-- Create retention_policies table (conversation_id NULL = workspace-wide policy)
+-- retention_days NULL keeps messages forever; a conversation policy overrides the workspace policy
+CREATE TABLE IF NOT EXISTS retention_policies (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER REFERENCES conversations(id) ON DELETE CASCADE,
+    retention_days INTEGER CHECK (retention_days > 0),
+    updated_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- One policy per scope, including a single workspace policy
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_policies_scope ON retention_policies((COALESCE(conversation_id, 0)));
+
+-- Create legal_holds table (held conversations are never purged)
+CREATE TABLE IF NOT EXISTS legal_holds (
+    conversation_id INTEGER PRIMARY KEY REFERENCES conversations(id) ON DELETE CASCADE,
+    reason VARCHAR(500) NOT NULL,
+    placed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Create retention_purge_runs table (one audit record per purge pass)
+CREATE TABLE IF NOT EXISTS retention_purge_runs (
+    id SERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('scheduled', 'manual')),
+    triggered_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'running' CHECK (status IN ('running', 'completed', 'failed')),
+    conversations_purged INTEGER NOT NULL DEFAULT 0,
+    conversations_held INTEGER NOT NULL DEFAULT 0,
+    messages_deleted INTEGER NOT NULL DEFAULT 0,
+    reactions_deleted INTEGER NOT NULL DEFAULT 0,
+    files_deleted INTEGER NOT NULL DEFAULT 0,
+    objects_deleted INTEGER NOT NULL DEFAULT 0,
+    objects_failed INTEGER NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    finished_at TIMESTAMP
+);
+
+-- Only one purge runs at a time
+CREATE UNIQUE INDEX IF NOT EXISTS idx_retention_purge_runs_running ON retention_purge_runs(status) WHERE status = 'running';
+
+-- Create retention_purge_entries table (what a run did in each conversation)
+-- conversation_id has no foreign key so reports outlive the conversations they cover
+CREATE TABLE IF NOT EXISTS retention_purge_entries (
+    id SERIAL PRIMARY KEY,
+    run_id INTEGER NOT NULL REFERENCES retention_purge_runs(id) ON DELETE CASCADE,
+    conversation_id INTEGER NOT NULL,
+    retention_days INTEGER NOT NULL,
+    cutoff TIMESTAMP NOT NULL,
+    legal_hold BOOLEAN NOT NULL DEFAULT FALSE,
+    messages_deleted INTEGER NOT NULL DEFAULT 0,
+    reactions_deleted INTEGER NOT NULL DEFAULT 0,
+    files_deleted INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Add indexes for performance
+CREATE INDEX IF NOT EXISTS idx_retention_purge_entries_run_id ON retention_purge_entries(run_id);
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_retention_policies_updated_at
+    BEFORE UPDATE ON retention_policies
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
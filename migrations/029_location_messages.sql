@@ -0,0 +1,26 @@
+-- Migration: 029_location_messages.sql
+-- Description: Location messages and live location sharing
+
+-- Allow location messages
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_message_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_message_type_check CHECK (message_type IN ('text', 'image', 'file', 'system', 'poll', 'voice', 'location'));
+
+-- Create message_locations table (the position shared by a location message)
+-- Live locations are updated in place until ended_at is set
+CREATE TABLE IF NOT EXISTS message_locations (
+    message_id INTEGER PRIMARY KEY REFERENCES messages(id) ON DELETE CASCADE,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    latitude DOUBLE PRECISION NOT NULL CHECK (latitude BETWEEN -90 AND 90),
+    longitude DOUBLE PRECISION NOT NULL CHECK (longitude BETWEEN -180 AND 180),
+    accuracy DOUBLE PRECISION CHECK (accuracy >= 0),
+    label VARCHAR(200) NOT NULL DEFAULT '',
+    live_until TIMESTAMP,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Add index for finalising expired live locations
+CREATE INDEX IF NOT EXISTS idx_message_locations_live_until ON message_locations(live_until)
+    WHERE live_until IS NOT NULL AND ended_at IS NULL;
@@ -0,0 +1,61 @@
+-- Migration: 030_custom_emoji_stickers.sql
+-- Description: Custom emoji, sticker packs and sticker messages
+
+-- Create custom_emoji table (user_id NULL means a workspace emoji usable by everyone)
+CREATE TABLE IF NOT EXISTS custom_emoji (
+    id SERIAL PRIMARY KEY,
+    shortcode VARCHAR(32) NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    object_key VARCHAR(500) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    file_size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Shortcodes share one namespace so :shortcode: renders the same for every reader
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_emoji_shortcode ON custom_emoji(shortcode);
+CREATE INDEX IF NOT EXISTS idx_custom_emoji_user_id ON custom_emoji(user_id);
+
+-- Create sticker_packs table (user_id NULL means a workspace pack)
+CREATE TABLE IF NOT EXISTS sticker_packs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    description VARCHAR(500) NOT NULL DEFAULT '',
+    user_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_sticker_packs_user_id ON sticker_packs(user_id);
+
+-- Create stickers table (ordered by position within a pack)
+CREATE TABLE IF NOT EXISTS stickers (
+    id SERIAL PRIMARY KEY,
+    pack_id INTEGER NOT NULL REFERENCES sticker_packs(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    emoji VARCHAR(64) NOT NULL DEFAULT '',
+    object_key VARCHAR(500) NOT NULL,
+    mime_type VARCHAR(100) NOT NULL,
+    file_size BIGINT NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_stickers_pack_position ON stickers(pack_id, position);
+
+-- Sticker messages reference a pack item; the message keeps its fallback text if the sticker is removed
+ALTER TABLE messages ADD COLUMN IF NOT EXISTS sticker_id INTEGER REFERENCES stickers(id) ON DELETE SET NULL;
+
+-- Allow sticker messages
+ALTER TABLE messages DROP CONSTRAINT IF EXISTS messages_message_type_check;
+ALTER TABLE messages ADD CONSTRAINT messages_message_type_check CHECK (message_type IN ('text', 'image', 'file', 'system', 'poll', 'voice', 'location', 'sticker'));
+
+-- Add trigger for updated_at
+CREATE TRIGGER update_sticker_packs_updated_at
+    BEFORE UPDATE ON sticker_packs
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
@@ -0,0 +1,8 @@
+-- Migration: 031_scheduled_client_message_ids.sql
+-- Description: Client-generated ids on scheduled messages and reminders so retried requests do not schedule twice
+
+-- Id chosen by the requesting client (NULL for requests sent without one)
+ALTER TABLE scheduled_messages ADD COLUMN IF NOT EXISTS client_message_id VARCHAR(64);
+
+-- A client id is unique per sender and conversation
+CREATE UNIQUE INDEX IF NOT EXISTS idx_scheduled_messages_client_message_id ON scheduled_messages(conversation_id, sender_id, client_message_id) WHERE client_message_id IS NOT NULL;
@@ -0,0 +1,10 @@
+-- Migration: 032_custom_emoji_owner_shortcodes.sql
+-- Description: Scope custom emoji shortcodes to their owner so personal emoji cannot claim workspace names
+
+DROP INDEX IF EXISTS idx_custom_emoji_shortcode;
+
+-- Workspace shortcodes are unique; each user has their own namespace on top.
+-- When names collide, :shortcode: resolves to the workspace emoji.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_emoji_workspace_shortcode ON custom_emoji(shortcode) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_custom_emoji_user_shortcode ON custom_emoji(user_id, shortcode) WHERE user_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_custom_emoji_shortcode ON custom_emoji(shortcode);
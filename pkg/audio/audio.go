@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"errors"
+	"io"
+	"time"
+)
+
+// Waveform limits
+const (
+	WaveformSamples  = 64  // Number of amplitude values in a waveform
+	MaxWaveformValue = 100 // Amplitude of the loudest part of the recording
+)
+
+// Format Constants
+const (
+	FormatWAV  = "wav"
+	FormatOpus = "opus"
+)
+
+// levelSlot is the resolution levels are collected at before being downsampled into a waveform
+const levelSlot = 10 * time.Millisecond
+
+// ErrUnsupportedFormat is returned for audio that is neither WAV nor Ogg/Opus
+var ErrUnsupportedFormat = errors.New("unsupported audio format: only WAV and Ogg/Opus are supported")
+
+// Info describes a recording as a whole, independent of any playback position
+type Info struct {
+	Format     string        `json:"format"`
+	Duration   time.Duration `json:"duration"`
+	SampleRate int           `json:"sample_rate"`
+	Channels   int           `json:"channels"`
+	Waveform   []int         `json:"waveform"` // WaveformSamples values from 0 to MaxWaveformValue
+}
+
+// Analyze detects the container from its magic bytes and extracts the duration and waveform
+func Analyze(r io.ReadSeeker) (*Info, error) {
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, ErrUnsupportedFormat
+	}
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	switch string(magic[:]) {
+	case "RIFF":
+		return analyzeWAV(r)
+	case "OggS":
+		return analyzeOgg(r)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+}
+
+// buildWaveform averages levels into WaveformSamples buckets and scales them so the loudest is MaxWaveformValue
+func buildWaveform(levels []float64) []int {
+	waveform := make([]int, WaveformSamples)
+	if len(levels) == 0 {
+		return waveform
+	}
+
+	buckets := make([]float64, WaveformSamples)
+	peak := 0.0
+	for i := range buckets {
+		start := i * len(levels) / WaveformSamples
+		end := (i + 1) * len(levels) / WaveformSamples
+		if end <= start {
+			end = start + 1 // Short recordings repeat levels rather than leaving gaps
+		}
+
+		sum := 0.0
+		for _, level := range levels[start:end] {
+			sum += level
+		}
+		buckets[i] = sum / float64(end-start)
+		if buckets[i] > peak {
+			peak = buckets[i]
+		}
+	}
+
+	if peak <= 0 {
+		return waveform
+	}
+	for i, bucket := range buckets {
+		waveform[i] = int(bucket/peak*MaxWaveformValue + 0.5)
+	}
+	return waveform
+}
@@ -0,0 +1,74 @@
+package audio
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestAnalyzeRejectsUnknownFormats(t *testing.T) {
+	tests := []struct {
+		name  string
+		input []byte
+	}{
+		{"empty", nil},
+		{"too short", []byte("RI")},
+		{"mp3", []byte("ID3\x04\x00\x00\x00\x00")},
+		{"text", []byte("hello world")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Analyze(bytes.NewReader(tt.input)); !errors.Is(err, ErrUnsupportedFormat) {
+				t.Errorf("Analyze() error = %v, want ErrUnsupportedFormat", err)
+			}
+		})
+	}
+}
+
+func TestBuildWaveform(t *testing.T) {
+	tests := []struct {
+		name   string
+		levels []float64
+		want   []int
+	}{
+		{"no levels", nil, repeat(0, WaveformSamples)},
+		{"silence", make([]float64, 200), repeat(0, WaveformSamples)},
+		{"constant level", []float64{0.3, 0.3, 0.3, 0.3}, repeat(MaxWaveformValue, WaveformSamples)},
+		{
+			name:   "short recording repeats levels",
+			levels: []float64{0.5, 1},
+			want:   append(repeat(MaxWaveformValue/2, WaveformSamples/2), repeat(MaxWaveformValue, WaveformSamples/2)...),
+		},
+		{
+			name:   "half level",
+			levels: append(repeatFloat(0.25, WaveformSamples), repeatFloat(0.5, WaveformSamples)...),
+			want:   append(repeat(MaxWaveformValue/2, WaveformSamples/2), repeat(MaxWaveformValue, WaveformSamples/2)...),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := buildWaveform(tt.levels); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("buildWaveform() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func repeat(value, n int) []int {
+	values := make([]int, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
+
+func repeatFloat(value float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = value
+	}
+	return values
+}
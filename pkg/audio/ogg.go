@@ -0,0 +1,227 @@
+package audio
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"time"
+)
+
+// Opus always counts granule positions at 48 kHz, whatever the input rate was
+const opusGranuleRate = 48000
+
+// Ogg page header flags
+const (
+	oggFlagContinued = 0x01
+	oggFlagFirst     = 0x02
+)
+
+// maxOggPacketSize bounds a reassembled packet so a corrupt file cannot grow it without limit
+const maxOggPacketSize = 1 << 20
+
+// oggPage is one page of an Ogg bitstream
+type oggPage struct {
+	flags    byte
+	granule  int64
+	serial   uint32
+	segments []byte
+	body     []byte
+}
+
+// analyzeOgg reads an Ogg/Opus file. The duration comes from the last granule position.
+// Opus is not decoded: the waveform uses each packet's bitrate, which follows loudness closely in VBR speech.
+func analyzeOgg(r io.Reader) (*Info, error) {
+	br := bufio.NewReader(r)
+
+	var (
+		serial      uint32
+		found       bool
+		headers     int // OpusHead and OpusTags come before any audio
+		channels    int
+		sampleRate  int
+		preSkip     int64
+		lastGranule int64 = -1
+		packet      []byte
+		levels      []float64
+		slotFill    int64 // 48 kHz samples already placed in the last level slot
+		decoded     int64 // 48 kHz samples covered by audio packets
+	)
+	slotSamples := int64(opusGranuleRate) * int64(levelSlot) / int64(time.Second)
+
+	for {
+		page, err := readOggPage(br)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break // A truncated final page still leaves a usable recording
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// The Opus stream is the logical stream whose first packet is OpusHead
+		if !found {
+			if page.flags&oggFlagFirst == 0 || !bytes.HasPrefix(page.body, []byte("OpusHead")) {
+				continue
+			}
+			found, serial = true, page.serial
+		}
+		if page.serial != serial {
+			continue
+		}
+
+		if page.flags&oggFlagContinued == 0 {
+			packet = packet[:0]
+		}
+
+		offset := 0
+		for _, lacing := range page.segments {
+			packet = append(packet, page.body[offset:offset+int(lacing)]...)
+			offset += int(lacing)
+			if len(packet) > maxOggPacketSize {
+				return nil, errors.New("ogg: packet too large")
+			}
+			if lacing == 255 {
+				continue // The packet continues in the next segment
+			}
+
+			switch headers {
+			case 0:
+				if len(packet) < 19 || !bytes.HasPrefix(packet, []byte("OpusHead")) {
+					return nil, errors.New("ogg: invalid OpusHead header")
+				}
+				channels = int(packet[9])
+				preSkip = int64(binary.LittleEndian.Uint16(packet[10:12]))
+				sampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+				headers++
+			case 1:
+				headers++ // OpusTags carries no audio
+			default:
+				samples := opusPacketSamples(packet)
+				if samples > 0 {
+					// Bytes per millisecond, spread over the 10 ms slots the packet covers
+					rate := float64(len(packet)) * opusGranuleRate / 1000 / float64(samples)
+					for remaining := samples; remaining > 0; {
+						if slotFill == 0 {
+							levels = append(levels, 0)
+						}
+						take := slotSamples - slotFill
+						if take > remaining {
+							take = remaining
+						}
+						levels[len(levels)-1] += rate * float64(take) / float64(slotSamples)
+						slotFill = (slotFill + take) % slotSamples
+						remaining -= take
+					}
+					decoded += samples
+				}
+			}
+			packet = packet[:0]
+		}
+
+		// Pages where no packet ends carry granule -1
+		if page.granule >= 0 {
+			lastGranule = page.granule
+		}
+	}
+
+	if !found {
+		return nil, errors.New("ogg: no Opus stream found")
+	}
+	if headers < 2 || decoded == 0 {
+		return nil, errors.New("ogg: no audio packets")
+	}
+
+	// Prefer the container's end position, which excludes encoder padding
+	samples := decoded - preSkip
+	if lastGranule > preSkip {
+		samples = lastGranule - preSkip
+	}
+	if samples < 0 {
+		samples = 0
+	}
+
+	// Silence is encoded in a handful of bytes per packet; treat the quietest slot as zero
+	floor := -1.0
+	for _, level := range levels {
+		if floor < 0 || level < floor {
+			floor = level
+		}
+	}
+	for i := range levels {
+		levels[i] -= floor
+	}
+
+	return &Info{
+		Format:     FormatOpus,
+		Duration:   time.Duration(samples * int64(time.Second) / opusGranuleRate),
+		SampleRate: sampleRate,
+		Channels:   channels,
+		Waveform:   buildWaveform(levels),
+	}, nil
+}
+
+// readOggPage reads one page; the CRC is not checked since the audio is not decoded
+func readOggPage(r *bufio.Reader) (*oggPage, error) {
+	var header [27]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		return nil, err
+	}
+	if string(header[0:4]) != "OggS" || header[4] != 0 {
+		return nil, errors.New("ogg: invalid page header")
+	}
+
+	page := &oggPage{
+		flags:    header[5],
+		granule:  int64(binary.LittleEndian.Uint64(header[6:14])),
+		serial:   binary.LittleEndian.Uint32(header[14:18]),
+		segments: make([]byte, header[26]),
+	}
+	if _, err := io.ReadFull(r, page.segments); err != nil {
+		return nil, err
+	}
+
+	size := 0
+	for _, lacing := range page.segments {
+		size += int(lacing)
+	}
+	page.body = make([]byte, size)
+	if _, err := io.ReadFull(r, page.body); err != nil {
+		return nil, err
+	}
+	return page, nil
+}
+
+// opusPacketSamples returns the duration of an Opus packet in 48 kHz samples, read from its TOC byte (RFC 6716 section 3.1)
+func opusPacketSamples(packet []byte) int64 {
+	if len(packet) == 0 {
+		return 0
+	}
+	toc := packet[0]
+	config := int(toc >> 3)
+
+	// Frame size in 48 kHz samples for each configuration
+	var frameSamples int64
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60 ms
+		frameSamples = []int64{480, 960, 1920, 2880}[config%4]
+	case config < 16: // Hybrid: 10, 20 ms
+		frameSamples = []int64{480, 960}[config%2]
+	default: // CELT: 2.5, 5, 10, 20 ms
+		frameSamples = []int64{120, 240, 480, 960}[config%4]
+	}
+
+	var frames int64
+	switch toc & 0x03 {
+	case 0:
+		frames = 1
+	case 1, 2:
+		frames = 2
+	default:
+		if len(packet) < 2 {
+			return 0
+		}
+		frames = int64(packet[1] & 0x3F)
+	}
+	return frameSamples * frames
+}
@@ -0,0 +1,233 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+	"time"
+)
+
+// oggWriter builds a synthetic Ogg bitstream
+type oggWriter struct {
+	buf      bytes.Buffer
+	sequence uint32
+}
+
+// page writes one page holding the given lacing values and body
+func (w *oggWriter) page(serial uint32, flags byte, granule int64, segments []byte, body []byte) {
+	w.buf.WriteString("OggS")
+	w.buf.WriteByte(0)
+	w.buf.WriteByte(flags)
+	binary.Write(&w.buf, binary.LittleEndian, uint64(granule))
+	binary.Write(&w.buf, binary.LittleEndian, serial)
+	binary.Write(&w.buf, binary.LittleEndian, w.sequence)
+	binary.Write(&w.buf, binary.LittleEndian, uint32(0)) // CRC is not checked
+	w.buf.WriteByte(byte(len(segments)))
+	w.buf.Write(segments)
+	w.buf.Write(body)
+	w.sequence++
+}
+
+// packets writes whole packets on a single page
+func (w *oggWriter) packets(serial uint32, flags byte, granule int64, packets ...[]byte) {
+	var segments, body []byte
+	for _, packet := range packets {
+		segments = append(segments, lacing(len(packet))...)
+		body = append(body, packet...)
+	}
+	w.page(serial, flags, granule, segments, body)
+}
+
+// lacing returns the segment table entries for a packet of n bytes
+func lacing(n int) []byte {
+	segments := bytes.Repeat([]byte{255}, n/255)
+	return append(segments, byte(n%255))
+}
+
+// opusHead returns an OpusHead packet
+func opusHead(channels int, preSkip uint16, sampleRate uint32) []byte {
+	head := []byte("OpusHead")
+	head = append(head, 1, byte(channels))
+	head = binary.LittleEndian.AppendUint16(head, preSkip)
+	head = binary.LittleEndian.AppendUint32(head, sampleRate)
+	return append(head, 0, 0, 0)
+}
+
+// opusPacket returns a 20 ms SILK packet of size bytes
+func opusPacket(size int) []byte {
+	packet := make([]byte, size)
+	packet[0] = 1 << 3 // config 1: SILK 20 ms, one frame
+	return packet
+}
+
+// opusStream writes the headers and one 20 ms packet per size, ten packets per page
+func opusStream(w *oggWriter, serial uint32, preSkip uint16, sizes []int) {
+	w.packets(serial, oggFlagFirst, 0, opusHead(1, preSkip, 16000))
+	w.packets(serial, 0, 0, []byte("OpusTags\x00\x00\x00\x00\x00\x00\x00\x00"))
+
+	granule := int64(0)
+	for start := 0; start < len(sizes); start += 10 {
+		end := min(start+10, len(sizes))
+		var packets [][]byte
+		for _, size := range sizes[start:end] {
+			packets = append(packets, opusPacket(size))
+			granule += 960
+		}
+		w.packets(serial, 0, granule+int64(preSkip), packets...)
+	}
+}
+
+func repeatSize(size, n int) []int {
+	sizes := make([]int, n)
+	for i := range sizes {
+		sizes[i] = size
+	}
+	return sizes
+}
+
+func TestAnalyzeOgg(t *testing.T) {
+	tests := []struct {
+		name         string
+		build        func(w *oggWriter)
+		wantDuration time.Duration
+		wantErr      string
+	}{
+		{
+			name:         "one second",
+			build:        func(w *oggWriter) { opusStream(w, 1, 312, repeatSize(40, 50)) },
+			wantDuration: time.Second,
+		},
+		{
+			name: "other logical streams are ignored",
+			build: func(w *oggWriter) {
+				w.packets(9, oggFlagFirst, 0, []byte("\x80theora-header"))
+				opusStream(w, 1, 0, repeatSize(40, 25))
+				w.packets(9, 0, 100000, opusPacket(40), opusPacket(40))
+			},
+			wantDuration: 500 * time.Millisecond,
+		},
+		{
+			name: "packet continued on the next page",
+			build: func(w *oggWriter) {
+				opusStream(w, 1, 0, repeatSize(40, 10))
+				packet := opusPacket(300)
+				w.page(1, 0, -1, []byte{255}, packet[:255])
+				w.page(1, oggFlagContinued, 220*48, []byte{45}, packet[255:])
+			},
+			wantDuration: 220 * time.Millisecond,
+		},
+		{
+			name: "truncated final page",
+			build: func(w *oggWriter) {
+				opusStream(w, 1, 0, repeatSize(40, 50))
+				w.buf.WriteString("OggS\x00")
+			},
+			wantDuration: time.Second,
+		},
+		{
+			name:    "no Opus stream",
+			build:   func(w *oggWriter) { w.packets(1, oggFlagFirst, 0, []byte("\x01vorbis")) },
+			wantErr: "no Opus stream found",
+		},
+		{
+			name:    "headers only",
+			build:   func(w *oggWriter) { opusStream(w, 1, 0, nil) },
+			wantErr: "no audio packets",
+		},
+		{
+			name:    "short OpusHead",
+			build:   func(w *oggWriter) { w.packets(1, oggFlagFirst, 0, []byte("OpusHead\x01")) },
+			wantErr: "invalid OpusHead header",
+		},
+		{
+			name: "corrupt page header",
+			build: func(w *oggWriter) {
+				opusStream(w, 1, 0, repeatSize(40, 10))
+				w.buf.WriteString("OggX" + strings.Repeat("\x00", 23))
+			},
+			wantErr: "invalid page header",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := &oggWriter{}
+			tt.build(w)
+
+			info, err := Analyze(bytes.NewReader(w.buf.Bytes()))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Analyze() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if info.Format != FormatOpus {
+				t.Errorf("Format = %q, want %q", info.Format, FormatOpus)
+			}
+			if info.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", info.Duration, tt.wantDuration)
+			}
+			if info.SampleRate != 16000 || info.Channels != 1 {
+				t.Errorf("SampleRate, Channels = %d, %d; want 16000, 1", info.SampleRate, info.Channels)
+			}
+			if len(info.Waveform) != WaveformSamples {
+				t.Errorf("len(Waveform) = %d, want %d", len(info.Waveform), WaveformSamples)
+			}
+		})
+	}
+}
+
+func TestAnalyzeOggWaveform(t *testing.T) {
+	// Silence-sized packets, then speech-sized packets
+	sizes := append(repeatSize(3, 50), repeatSize(80, 50)...)
+	w := &oggWriter{}
+	opusStream(w, 1, 0, sizes)
+
+	info, err := Analyze(bytes.NewReader(w.buf.Bytes()))
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	half := WaveformSamples / 2
+	for i, value := range info.Waveform {
+		want := 0
+		if i >= half {
+			want = MaxWaveformValue
+		}
+		if value != want {
+			t.Errorf("Waveform[%d] = %d, want %d", i, value, want)
+		}
+	}
+}
+
+func TestOpusPacketSamples(t *testing.T) {
+	tests := []struct {
+		name   string
+		packet []byte
+		want   int64
+	}{
+		{"empty", nil, 0},
+		{"SILK 10 ms", []byte{0 << 3}, 480},
+		{"SILK 60 ms", []byte{3 << 3}, 2880},
+		{"hybrid 20 ms", []byte{13 << 3}, 960},
+		{"CELT 2.5 ms", []byte{16 << 3}, 120},
+		{"CELT 20 ms", []byte{31 << 3}, 960},
+		{"two equal frames", []byte{1<<3 | 1}, 1920},
+		{"two different frames", []byte{1<<3 | 2}, 1920},
+		{"arbitrary frame count", []byte{1<<3 | 3, 3}, 2880},
+		{"frame count masks flags", []byte{1<<3 | 3, 0xC0 | 2}, 1920},
+		{"missing frame count", []byte{1<<3 | 3}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := opusPacketSamples(tt.packet); got != tt.want {
+				t.Errorf("opusPacketSamples(%v) = %d, want %d", tt.packet, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"time"
+)
+
+// WAV format codes
+const (
+	wavFormatPCM        = 1
+	wavFormatFloat      = 3
+	wavFormatExtensible = 0xFFFE
+)
+
+// wavFormat holds the fields of a WAV fmt chunk
+type wavFormat struct {
+	code          uint16
+	channels      int
+	sampleRate    int
+	blockAlign    int
+	bitsPerSample int
+}
+
+// analyzeWAV reads a RIFF/WAVE file: its fmt chunk for the layout, then every sample for the waveform
+func analyzeWAV(r io.Reader) (*Info, error) {
+	br := bufio.NewReader(r)
+
+	var header [12]byte
+	if _, err := io.ReadFull(br, header[:]); err != nil || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WAVE" {
+		return nil, errors.New("wav: not a RIFF/WAVE file")
+	}
+
+	var format *wavFormat
+	for {
+		var chunk [8]byte
+		if _, err := io.ReadFull(br, chunk[:]); err != nil {
+			return nil, errors.New("wav: data chunk not found")
+		}
+		id := string(chunk[0:4])
+		size := binary.LittleEndian.Uint32(chunk[4:8])
+
+		switch id {
+		case "fmt ":
+			parsed, err := readWAVFormat(br, size)
+			if err != nil {
+				return nil, err
+			}
+			format = parsed
+		case "data":
+			if format == nil {
+				return nil, errors.New("wav: data chunk before fmt chunk")
+			}
+			return readWAVData(br, format, size)
+		default:
+			// Chunks are padded to an even size
+			if _, err := io.CopyN(io.Discard, br, int64(size)+int64(size&1)); err != nil {
+				return nil, errors.New("wav: data chunk not found")
+			}
+		}
+	}
+}
+
+// readWAVFormat parses and validates a fmt chunk
+func readWAVFormat(r io.Reader, size uint32) (*wavFormat, error) {
+	if size < 16 || size > 1024 {
+		return nil, errors.New("wav: invalid fmt chunk")
+	}
+	buf := make([]byte, size+size&1)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, errors.New("wav: truncated fmt chunk")
+	}
+
+	format := &wavFormat{
+		code:          binary.LittleEndian.Uint16(buf[0:2]),
+		channels:      int(binary.LittleEndian.Uint16(buf[2:4])),
+		sampleRate:    int(binary.LittleEndian.Uint32(buf[4:8])),
+		blockAlign:    int(binary.LittleEndian.Uint16(buf[12:14])),
+		bitsPerSample: int(binary.LittleEndian.Uint16(buf[14:16])),
+	}
+	// WAVE_FORMAT_EXTENSIBLE keeps the real format code at the start of the sub-format GUID
+	if format.code == wavFormatExtensible && size >= 26 {
+		format.code = binary.LittleEndian.Uint16(buf[24:26])
+	}
+
+	switch {
+	case format.channels <= 0 || format.sampleRate <= 0:
+		return nil, errors.New("wav: invalid channel count or sample rate")
+	case format.code == wavFormatPCM && format.bitsPerSample != 8 && format.bitsPerSample != 16 && format.bitsPerSample != 24 && format.bitsPerSample != 32:
+		return nil, fmt.Errorf("wav: unsupported PCM sample size %d", format.bitsPerSample)
+	case format.code == wavFormatFloat && format.bitsPerSample != 32:
+		return nil, fmt.Errorf("wav: unsupported float sample size %d", format.bitsPerSample)
+	case format.code != wavFormatPCM && format.code != wavFormatFloat:
+		return nil, fmt.Errorf("wav: unsupported format code %d", format.code)
+	case format.blockAlign != format.channels*format.bitsPerSample/8:
+		return nil, errors.New("wav: block alignment does not match the sample size")
+	}
+	return format, nil
+}
+
+// readWAVData reads the samples of a data chunk, keeping the peak of every levelSlot
+func readWAVData(r io.Reader, format *wavFormat, size uint32) (*Info, error) {
+	// Streaming writers leave the size unset; read to the end of the file instead
+	if size != 0 && size != math.MaxUint32 {
+		r = io.LimitReader(r, int64(size))
+	}
+
+	framesPerSlot := int(int64(format.sampleRate) * int64(levelSlot) / int64(time.Second))
+	if framesPerSlot < 1 {
+		framesPerSlot = 1
+	}
+	bytesPerSample := format.bitsPerSample / 8
+	buf := make([]byte, framesPerSlot*format.blockAlign)
+
+	var levels []float64
+	var frames int64
+	for {
+		n, err := io.ReadFull(r, buf)
+		n -= n % format.blockAlign
+		if n > 0 {
+			peak := 0.0
+			for offset := 0; offset < n; offset += bytesPerSample {
+				if sample := wavSampleAmplitude(buf[offset:offset+bytesPerSample], format); sample > peak {
+					peak = sample
+				}
+			}
+			levels = append(levels, peak)
+			frames += int64(n / format.blockAlign)
+		}
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("wav: failed to read samples: %w", err)
+		}
+	}
+
+	if frames == 0 {
+		return nil, errors.New("wav: no audio samples")
+	}
+
+	return &Info{
+		Format:     FormatWAV,
+		Duration:   time.Duration(frames * int64(time.Second) / int64(format.sampleRate)),
+		SampleRate: format.sampleRate,
+		Channels:   format.channels,
+		Waveform:   buildWaveform(levels),
+	}, nil
+}
+
+// wavSampleAmplitude returns the absolute amplitude of one sample, from 0 to 1
+func wavSampleAmplitude(b []byte, format *wavFormat) float64 {
+	var v float64
+	switch {
+	case format.code == wavFormatFloat:
+		v = float64(math.Float32frombits(binary.LittleEndian.Uint32(b)))
+		if math.IsNaN(v) {
+			return 0
+		}
+	case format.bitsPerSample == 8:
+		v = (float64(b[0]) - 128) / 128 // 8-bit PCM is unsigned
+	case format.bitsPerSample == 16:
+		v = float64(int16(binary.LittleEndian.Uint16(b))) / 32768
+	case format.bitsPerSample == 24:
+		v = float64(int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24)>>8) / 8388608
+	default:
+		v = float64(int32(binary.LittleEndian.Uint32(b))) / 2147483648
+	}
+	return math.Min(math.Abs(v), 1)
+}
@@ -0,0 +1,250 @@
+package audio
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// wavFile describes a synthetic WAV file
+type wavFile struct {
+	code          uint16
+	channels      int
+	sampleRate    int
+	bitsPerSample int
+	blockAlign    int // defaults to channels * bitsPerSample / 8
+	dataSize      int // overrides the data chunk size when non-zero; -1 writes 0 as streaming writers do
+	extraChunk    []byte
+	dataFirst     bool
+	samples       []float64 // amplitudes from -1 to 1, one per sample
+}
+
+// build encodes the file
+func (w wavFile) build() []byte {
+	blockAlign := w.blockAlign
+	if blockAlign == 0 {
+		blockAlign = w.channels * w.bitsPerSample / 8
+	}
+
+	var data bytes.Buffer
+	for _, sample := range w.samples {
+		switch {
+		case w.code == wavFormatFloat:
+			binary.Write(&data, binary.LittleEndian, float32(sample))
+		case w.bitsPerSample == 8:
+			data.WriteByte(byte(128 + int(sample*127)))
+		case w.bitsPerSample == 16:
+			binary.Write(&data, binary.LittleEndian, int16(sample*32767))
+		case w.bitsPerSample == 24:
+			v := int32(sample * 8388607)
+			data.Write([]byte{byte(v), byte(v >> 8), byte(v >> 16)})
+		default:
+			binary.Write(&data, binary.LittleEndian, int32(sample*2147483647))
+		}
+	}
+
+	var fmtChunk bytes.Buffer
+	fmtChunk.WriteString("fmt ")
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(16))
+	binary.Write(&fmtChunk, binary.LittleEndian, w.code)
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(w.channels))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(w.sampleRate))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint32(w.sampleRate*blockAlign))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&fmtChunk, binary.LittleEndian, uint16(w.bitsPerSample))
+
+	dataSize := uint32(data.Len())
+	switch {
+	case w.dataSize < 0:
+		dataSize = 0
+	case w.dataSize > 0:
+		dataSize = uint32(w.dataSize)
+	}
+	var dataChunk bytes.Buffer
+	dataChunk.WriteString("data")
+	binary.Write(&dataChunk, binary.LittleEndian, dataSize)
+	dataChunk.Write(data.Bytes())
+
+	var body bytes.Buffer
+	body.WriteString("WAVE")
+	if w.dataFirst {
+		body.Write(dataChunk.Bytes())
+		body.Write(fmtChunk.Bytes())
+	} else {
+		body.Write(fmtChunk.Bytes())
+		body.Write(w.extraChunk)
+		body.Write(dataChunk.Bytes())
+	}
+
+	var file bytes.Buffer
+	file.WriteString("RIFF")
+	binary.Write(&file, binary.LittleEndian, uint32(body.Len()))
+	file.Write(body.Bytes())
+	return file.Bytes()
+}
+
+// tone returns n samples of a square wave with the given amplitude
+func tone(n int, amplitude float64) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = amplitude
+		if i%2 == 1 {
+			samples[i] = -amplitude
+		}
+	}
+	return samples
+}
+
+func TestAnalyzeWAV(t *testing.T) {
+	second := tone(8000, 0.5)
+	oddChunk := append([]byte("LIST\x03\x00\x00\x00abc"), 0) // 3 bytes plus padding
+
+	tests := []struct {
+		name         string
+		file         wavFile
+		wantDuration time.Duration
+		wantChannels int
+		wantErr      string
+	}{
+		{
+			name:         "16-bit PCM mono",
+			file:         wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "8-bit PCM",
+			file:         wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 8, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "24-bit PCM",
+			file:         wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 24, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "32-bit float",
+			file:         wavFile{code: wavFormatFloat, channels: 1, sampleRate: 8000, bitsPerSample: 32, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "stereo counts frames, not samples",
+			file:         wavFile{code: wavFormatPCM, channels: 2, sampleRate: 8000, bitsPerSample: 16, samples: second},
+			wantDuration: 500 * time.Millisecond,
+			wantChannels: 2,
+		},
+		{
+			name:         "skips unknown chunks with padding",
+			file:         wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, extraChunk: oddChunk, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "streaming data size reads to the end",
+			file:         wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, dataSize: -1, samples: second},
+			wantDuration: time.Second,
+			wantChannels: 1,
+		},
+		{
+			name:         "partial trailing frame is ignored",
+			file:         wavFile{code: wavFormatPCM, channels: 2, sampleRate: 8000, bitsPerSample: 16, samples: tone(8001, 0.5)},
+			wantDuration: 500 * time.Millisecond,
+			wantChannels: 2,
+		},
+		{
+			name:    "data before fmt",
+			file:    wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, dataFirst: true, samples: second},
+			wantErr: "data chunk before fmt chunk",
+		},
+		{
+			name:    "unsupported format code",
+			file:    wavFile{code: 2, channels: 1, sampleRate: 8000, bitsPerSample: 4, samples: nil},
+			wantErr: "unsupported format code 2",
+		},
+		{
+			name:    "unsupported PCM sample size",
+			file:    wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 12, blockAlign: 2},
+			wantErr: "unsupported PCM sample size 12",
+		},
+		{
+			name:    "block alignment mismatch",
+			file:    wavFile{code: wavFormatPCM, channels: 2, sampleRate: 8000, bitsPerSample: 16, blockAlign: 2},
+			wantErr: "block alignment",
+		},
+		{
+			name:    "no channels",
+			file:    wavFile{code: wavFormatPCM, channels: 0, sampleRate: 8000, bitsPerSample: 16},
+			wantErr: "invalid channel count",
+		},
+		{
+			name:    "no samples",
+			file:    wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16},
+			wantErr: "no audio samples",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			info, err := Analyze(bytes.NewReader(tt.file.build()))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Analyze() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if info.Format != FormatWAV {
+				t.Errorf("Format = %q, want %q", info.Format, FormatWAV)
+			}
+			if info.Duration != tt.wantDuration {
+				t.Errorf("Duration = %v, want %v", info.Duration, tt.wantDuration)
+			}
+			if info.SampleRate != tt.file.sampleRate || info.Channels != tt.wantChannels {
+				t.Errorf("SampleRate, Channels = %d, %d; want %d, %d", info.SampleRate, info.Channels, tt.file.sampleRate, tt.wantChannels)
+			}
+			if len(info.Waveform) != WaveformSamples {
+				t.Errorf("len(Waveform) = %d, want %d", len(info.Waveform), WaveformSamples)
+			}
+		})
+	}
+}
+
+func TestAnalyzeWAVWaveform(t *testing.T) {
+	// Half a second of quiet followed by half a second of loud audio
+	samples := append(tone(4000, 0.1), tone(4000, 0.8)...)
+	file := wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, samples: samples}
+
+	info, err := Analyze(bytes.NewReader(file.build()))
+	if err != nil {
+		t.Fatalf("Analyze() error = %v", err)
+	}
+
+	half := WaveformSamples / 2
+	for i, value := range info.Waveform {
+		want := MaxWaveformValue
+		if i < half {
+			want = int(math.Round(0.1 / 0.8 * MaxWaveformValue))
+		}
+		if value < want-1 || value > want+1 {
+			t.Errorf("Waveform[%d] = %d, want about %d", i, value, want)
+		}
+	}
+}
+
+func TestAnalyzeWAVTruncatedHeader(t *testing.T) {
+	file := wavFile{code: wavFormatPCM, channels: 1, sampleRate: 8000, bitsPerSample: 16, samples: tone(10, 0.5)}.build()
+
+	for _, size := range []int{4, 12, 20, 40} {
+		if _, err := Analyze(bytes.NewReader(file[:size])); err == nil {
+			t.Errorf("Analyze() of the first %d bytes succeeded, want an error", size)
+		}
+	}
+}
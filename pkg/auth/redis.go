@@ -122,6 +122,40 @@ func DeleteUserSession(ctx context.Context, userID uint) error {
 	return nil
 }
 
+// MarkUserSuspended records a suspension so access tokens issued before it are rejected
+func MarkUserSuspended(ctx context.Context, userID uint, until time.Time) error {
+	if redisClient == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+	
+	expiresIn := time.Until(until)
+	if expiresIn <= 0 {
+		return nil
+	}
+	
+	key := fmt.Sprintf("suspended:%d", userID)
+	if err := redisClient.Set(ctx, key, until.Unix(), expiresIn).Err(); err != nil {
+		logger.Error("Failed to mark user suspended", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// IsUserSuspended checks if user is currently suspended
+func IsUserSuspended(ctx context.Context, userID uint) bool {
+	if redisClient == nil {
+		return false
+	}
+	
+	key := fmt.Sprintf("suspended:%d", userID)
+	exists, err := redisClient.Exists(ctx, key).Result()
+	if err != nil {
+		logger.Error("Failed to check user suspension", zap.Error(err))
+		return false
+	}
+	return exists > 0
+}
+
 // StoreLoginAttempt stores login attempt for rate limiting
 func StoreLoginAttempt(ctx context.Context, username string, success bool) error {
 	key := "login_attempts:" + username
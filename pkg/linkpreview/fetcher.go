@@ -0,0 +1,274 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Default limits used when Options leaves a field empty
+const (
+	DefaultTimeout      = 5 * time.Second
+	DefaultMaxBytes     = 512 * 1024
+	DefaultMaxRedirects = 3
+)
+
+// ErrBlockedAddress is returned when a URL resolves to an address that may not be fetched
+var ErrBlockedAddress = errors.New("link preview: address not allowed")
+
+// ErrCachedFailure is returned when an earlier fetch of the same URL failed and the failure is still cached
+var ErrCachedFailure = errors.New("link preview: previous fetch failed")
+
+// Cache stores previews between fetches. A nil preview records a failed fetch.
+type Cache interface {
+	Get(ctx context.Context, url string) (preview *Preview, found bool)
+	Set(ctx context.Context, url string, preview *Preview)
+}
+
+// Options configures a Fetcher
+type Options struct {
+	Timeout      time.Duration
+	MaxBytes     int64
+	MaxRedirects int
+	UserAgent    string
+	// AllowedHosts lists hostnames or CIDR ranges that may be fetched even if they resolve to private addresses
+	AllowedHosts []string
+	// Cache, when set, is consulted before fetching and filled afterwards
+	Cache Cache
+}
+
+// Fetcher downloads pages and extracts preview metadata with SSRF protection
+type Fetcher struct {
+	client    *http.Client
+	maxBytes  int64
+	userAgent string
+	cache     Cache
+}
+
+// hostGuard decides which addresses outgoing requests may connect to
+type hostGuard struct {
+	allowedNames map[string]bool
+	allowedNets  []*net.IPNet
+}
+
+// NewFetcher creates a new link preview fetcher
+func NewFetcher(opts Options) *Fetcher {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = DefaultMaxBytes
+	}
+	if opts.UserAgent == "" {
+		opts.UserAgent = "HuddleBot/1.0 (+link preview)"
+	}
+
+	return &Fetcher{
+		client:    NewSafeClient(opts),
+		maxBytes:  opts.MaxBytes,
+		userAgent: opts.UserAgent,
+		cache:     opts.Cache,
+	}
+}
+
+// NewSafeClient creates an HTTP client for user-supplied URLs that refuses to connect to internal addresses
+func NewSafeClient(opts Options) *http.Client {
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultTimeout
+	}
+	if opts.MaxRedirects <= 0 {
+		opts.MaxRedirects = DefaultMaxRedirects
+	}
+
+	guard := &hostGuard{
+		allowedNames: make(map[string]bool),
+	}
+	for _, entry := range opts.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if entry == "" {
+			continue
+		}
+		if _, ipNet, err := net.ParseCIDR(entry); err == nil {
+			guard.allowedNets = append(guard.allowedNets, ipNet)
+		} else if ip := net.ParseIP(entry); ip != nil {
+			guard.allowedNets = append(guard.allowedNets, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+		} else {
+			guard.allowedNames[entry] = true
+		}
+	}
+
+	transport := &http.Transport{
+		Proxy:                 nil, // never route user-supplied URLs through an environment proxy
+		DialContext:           guard.dialContext,
+		TLSHandshakeTimeout:   opts.Timeout,
+		ResponseHeaderTimeout: opts.Timeout,
+		MaxIdleConns:          10,
+		IdleConnTimeout:       30 * time.Second,
+	}
+
+	maxRedirects := opts.MaxRedirects
+	return &http.Client{
+		Transport: transport,
+		Timeout:   opts.Timeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > maxRedirects {
+				return fmt.Errorf("link preview: stopped after %d redirects", maxRedirects)
+			}
+			return checkScheme(req.URL)
+		},
+	}
+}
+
+// Fetch returns the preview metadata for rawURL, from the cache when possible
+func (f *Fetcher) Fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	if f.cache == nil {
+		return f.fetch(ctx, rawURL)
+	}
+
+	if cached, found := f.cache.Get(ctx, rawURL); found {
+		if cached == nil {
+			return nil, ErrCachedFailure
+		}
+		preview := *cached
+		preview.URL = rawURL
+		return &preview, nil
+	}
+
+	preview, err := f.fetch(ctx, rawURL)
+	f.cache.Set(ctx, rawURL, preview)
+	return preview, err
+}
+
+// fetch downloads rawURL and extracts its preview metadata
+func (f *Fetcher) fetch(ctx context.Context, rawURL string) (*Preview, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("link preview: invalid url: %w", err)
+	}
+	if err := checkScheme(u); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", f.userAgent)
+	req.Header.Set("Accept", "text/html,application/xhtml+xml")
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("link preview: unexpected status %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return nil, fmt.Errorf("link preview: unsupported content type %q", mediaType)
+	}
+
+	preview, err := Parse(io.LimitReader(resp.Body, f.maxBytes), resp.Request.URL)
+	if err != nil {
+		return nil, err
+	}
+	preview.URL = rawURL
+	if preview.IsEmpty() {
+		return nil, errors.New("link preview: no metadata found")
+	}
+
+	return preview, nil
+}
+
+// dialContext resolves the host itself so every address is checked before connecting (defeats DNS rebinding)
+func (g *hostGuard) dialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	ips, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	hostAllowed := g.allowedNames[strings.ToLower(host)]
+	dialer := &net.Dialer{Timeout: DefaultTimeout}
+
+	var lastErr error = ErrBlockedAddress
+	for _, ip := range ips {
+		if !hostAllowed && !g.ipAllowed(ip.IP) {
+			continue
+		}
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(ip.IP.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+
+	return nil, lastErr
+}
+
+// ipAllowed reports whether ip is public or explicitly allowlisted
+func (g *hostGuard) ipAllowed(ip net.IP) bool {
+	for _, ipNet := range g.allowedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return !isPrivateIP(ip)
+}
+
+// isPrivateIP reports whether ip belongs to a loopback, private, link-local or otherwise internal range
+func isPrivateIP(ip net.IP) bool {
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+	for _, ipNet := range reservedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// reservedNets covers internal ranges the net package does not classify as private
+var reservedNets = func() []*net.IPNet {
+	var nets []*net.IPNet
+	for _, cidr := range []string{
+		"0.0.0.0/8",     // "this" network
+		"100.64.0.0/10", // carrier-grade NAT
+		"192.0.0.0/24",  // IETF protocol assignments
+		"198.18.0.0/15", // benchmarking
+		"240.0.0.0/4",   // reserved
+		"64:ff9b::/96",  // NAT64
+		"2002::/16",     // 6to4
+	} {
+		_, ipNet, _ := net.ParseCIDR(cidr)
+		nets = append(nets, ipNet)
+	}
+	return nets
+}()
+
+// checkScheme only allows plain web URLs
+func checkScheme(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("link preview: unsupported scheme %q", u.Scheme)
+	}
+	if u.Hostname() == "" {
+		return errors.New("link preview: missing host")
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package linkpreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+const testPage = `<html><head>
+<title>Plain title</title>
+<meta property="og:title" content="Huddle">
+<meta property="og:description" content="Team chat">
+<meta property="og:image" content="/logo.png">
+</head><body>ignored</body></html>`
+
+// newTestServer serves testPage at / and counts how often it was requested.
+// /redirect/{n} reaches the page after n redirects.
+func newTestServer(t *testing.T) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	hits := &atomic.Int32{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		hits.Add(1)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, testPage)
+	})
+	mux.HandleFunc("/redirect/", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/redirect/"))
+		if n <= 1 {
+			http.Redirect(w, r, "/", http.StatusFound)
+			return
+		}
+		http.Redirect(w, r, fmt.Sprintf("/redirect/%d", n-1), http.StatusFound)
+	})
+	mux.HandleFunc("/to-loopback", func(w http.ResponseWriter, r *http.Request) {
+		_, port, _ := net.SplitHostPort(r.Host)
+		http.Redirect(w, r, "http://127.0.0.1:"+port+"/", http.StatusFound)
+	})
+	mux.HandleFunc("/json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"title":"Huddle"}`)
+	})
+	mux.HandleFunc("/large", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		fmt.Fprintf(w, "<html><head><!-- %s --><title>Late title</title></head></html>", strings.Repeat("x", 4096))
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, hits
+}
+
+// memoryCache is an in-memory Cache
+type memoryCache struct {
+	mu      sync.Mutex
+	entries map[string]*Preview
+}
+
+func (c *memoryCache) Get(ctx context.Context, url string) (*Preview, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	preview, found := c.entries[url]
+	return preview, found
+}
+
+func (c *memoryCache) Set(ctx context.Context, url string, preview *Preview) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = preview
+}
+
+func TestFetch(t *testing.T) {
+	server, _ := newTestServer(t)
+
+	tests := []struct {
+		name         string
+		path         string
+		maxBytes     int64
+		maxRedirects int
+		wantTitle    string
+		wantErr      string
+	}{
+		{name: "html page", path: "/", wantTitle: "Huddle"},
+		{name: "redirects within the cap", path: "/redirect/2", maxRedirects: 3, wantTitle: "Huddle"},
+		{name: "redirects at the cap", path: "/redirect/3", maxRedirects: 3, wantTitle: "Huddle"},
+		{name: "redirects over the cap", path: "/redirect/4", maxRedirects: 3, wantErr: "stopped after 3 redirects"},
+		{name: "non-HTML content", path: "/json", wantErr: "unsupported content type"},
+		{name: "error status", path: "/missing", wantErr: "unexpected status 404"},
+		{name: "metadata within the size limit", path: "/large", maxBytes: 8192, wantTitle: "Late title"},
+		{name: "metadata past the size limit", path: "/large", maxBytes: 1024, wantErr: "no metadata found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewFetcher(Options{
+				MaxBytes:     tt.maxBytes,
+				MaxRedirects: tt.maxRedirects,
+				AllowedHosts: []string{"127.0.0.1"},
+			})
+
+			preview, err := fetcher.Fetch(context.Background(), server.URL+tt.path)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Fetch() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			if preview.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", preview.Title, tt.wantTitle)
+			}
+			if preview.URL != server.URL+tt.path {
+				t.Errorf("URL = %q, want the requested URL", preview.URL)
+			}
+		})
+	}
+}
+
+func TestFetchResolvesRelativeImage(t *testing.T) {
+	server, _ := newTestServer(t)
+	fetcher := NewFetcher(Options{AllowedHosts: []string{"127.0.0.1"}})
+
+	preview, err := fetcher.Fetch(context.Background(), server.URL+"/redirect/1")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if want := server.URL + "/logo.png"; preview.ImageURL != want {
+		t.Errorf("ImageURL = %q, want %q", preview.ImageURL, want)
+	}
+}
+
+func TestFetchBlocksPrivateAddresses(t *testing.T) {
+	server, hits := newTestServer(t)
+	port := server.Listener.Addr().(*net.TCPAddr).Port
+
+	tests := []struct {
+		name         string
+		url          string
+		allowedHosts []string
+	}{
+		{name: "loopback", url: server.URL},
+		{name: "localhost name", url: fmt.Sprintf("http://localhost:%d/", port)},
+		{name: "other allowlisted range", url: server.URL, allowedHosts: []string{"10.0.0.0/8"}},
+		{name: "redirect from an allowlisted name", url: fmt.Sprintf("http://localhost:%d/to-loopback", port), allowedHosts: []string{"localhost"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetcher := NewFetcher(Options{AllowedHosts: tt.allowedHosts})
+
+			before := hits.Load()
+			_, err := fetcher.Fetch(context.Background(), tt.url)
+			if !errors.Is(err, ErrBlockedAddress) {
+				t.Fatalf("Fetch() error = %v, want ErrBlockedAddress", err)
+			}
+			if hits.Load() != before {
+				t.Errorf("blocked fetch reached the page handler")
+			}
+		})
+	}
+}
+
+func TestFetchRejectsUnsupportedSchemes(t *testing.T) {
+	fetcher := NewFetcher(Options{})
+	for _, rawURL := range []string{"ftp://example.com/", "file:///etc/passwd", "javascript:alert(1)", "http:///path"} {
+		if _, err := fetcher.Fetch(context.Background(), rawURL); err == nil {
+			t.Errorf("Fetch(%q) succeeded, want an error", rawURL)
+		}
+	}
+}
+
+func TestFetchUsesCache(t *testing.T) {
+	server, hits := newTestServer(t)
+	cache := &memoryCache{entries: make(map[string]*Preview)}
+	fetcher := NewFetcher(Options{AllowedHosts: []string{"127.0.0.1"}, Cache: cache})
+
+	for i := 0; i < 3; i++ {
+		preview, err := fetcher.Fetch(context.Background(), server.URL)
+		if err != nil {
+			t.Fatalf("Fetch() error = %v", err)
+		}
+		if preview.Title != "Huddle" || preview.URL != server.URL {
+			t.Fatalf("Fetch() = %+v, want the cached page preview", preview)
+		}
+	}
+	if got := hits.Load(); got != 1 {
+		t.Errorf("page fetched %d times, want 1", got)
+	}
+
+	// Failures are cached too
+	failing := server.URL + "/json"
+	if _, err := fetcher.Fetch(context.Background(), failing); err == nil {
+		t.Fatal("Fetch() of a JSON document succeeded")
+	}
+	if preview, found := cache.Get(context.Background(), failing); !found || preview != nil {
+		t.Fatalf("cache entry = %v, %v; want a cached failure", preview, found)
+	}
+	if _, err := fetcher.Fetch(context.Background(), failing); !errors.Is(err, ErrCachedFailure) {
+		t.Errorf("second Fetch() error = %v, want ErrCachedFailure", err)
+	}
+}
@@ -0,0 +1,178 @@
+package linkpreview
+
+import (
+	"io"
+	"net/url"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// Field length limits applied to parsed metadata
+const (
+	maxTitleLength       = 300
+	maxDescriptionLength = 1000
+	maxURLLength         = 2048
+)
+
+// Preview holds the metadata shown for a link
+type Preview struct {
+	URL         string `json:"url"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	ImageURL    string `json:"image_url,omitempty"`
+	SiteName    string `json:"site_name,omitempty"`
+}
+
+// IsEmpty reports whether the preview has nothing worth showing
+func (p *Preview) IsEmpty() bool {
+	return p.Title == "" && p.Description == "" && p.ImageURL == ""
+}
+
+// urlRegex matches http(s) links in message text
+var urlRegex = regexp.MustCompile(`https?://[^\s<>"'\x60]+`)
+
+// ExtractURLs returns up to limit distinct http(s) URLs found in text
+func ExtractURLs(text string, limit int) []string {
+	var urls []string
+	seen := make(map[string]bool)
+	for _, match := range urlRegex.FindAllString(text, -1) {
+		// Trailing punctuation usually belongs to the sentence, not the link
+		match = strings.TrimRight(match, ".,;:!?)]}")
+		if seen[match] || len(match) > maxURLLength {
+			continue
+		}
+		if u, err := url.Parse(match); err != nil || u.Hostname() == "" {
+			continue
+		}
+		seen[match] = true
+		urls = append(urls, match)
+		if len(urls) == limit {
+			break
+		}
+	}
+	return urls
+}
+
+// Parse reads OpenGraph, Twitter card and <title> metadata from an HTML document.
+// Relative image URLs are resolved against base.
+func Parse(r io.Reader, base *url.URL) (*Preview, error) {
+	meta := make(map[string]string)
+	var title strings.Builder
+	inTitle := false
+
+	tokenizer := html.NewTokenizer(r)
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			// End of input, or a document cut off by the size limit: use whatever was read
+			return buildPreview(meta, title.String(), base), nil
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = tokenType == html.StartTagToken
+			case "meta":
+				key, content := metaKeyContent(token)
+				if key != "" && content != "" {
+					if _, exists := meta[key]; !exists {
+						meta[key] = content
+					}
+				}
+			case "body":
+				// Metadata lives in <head>; stop before reading the page body
+				return buildPreview(meta, title.String(), base), nil
+			}
+
+		case html.EndTagToken:
+			token := tokenizer.Token()
+			switch token.Data {
+			case "title":
+				inTitle = false
+			case "head":
+				return buildPreview(meta, title.String(), base), nil
+			}
+
+		case html.TextToken:
+			if inTitle {
+				title.Write(tokenizer.Text())
+			}
+		}
+	}
+}
+
+// metaKeyContent returns the lower-cased property/name and content of a meta tag
+func metaKeyContent(token html.Token) (string, string) {
+	var key, content string
+	for _, attr := range token.Attr {
+		switch strings.ToLower(attr.Key) {
+		case "property", "name":
+			if key == "" {
+				key = strings.ToLower(strings.TrimSpace(attr.Val))
+			}
+		case "content":
+			content = attr.Val
+		}
+	}
+	return key, content
+}
+
+// buildPreview picks the best value for each field, preferring OpenGraph over Twitter cards over plain HTML
+func buildPreview(meta map[string]string, title string, base *url.URL) *Preview {
+	first := func(keys ...string) string {
+		for _, key := range keys {
+			if value := cleanText(meta[key]); value != "" {
+				return value
+			}
+		}
+		return ""
+	}
+
+	preview := &Preview{
+		Title:       truncate(first("og:title", "twitter:title"), maxTitleLength),
+		Description: truncate(first("og:description", "twitter:description", "description"), maxDescriptionLength),
+		SiteName:    truncate(first("og:site_name", "application-name"), maxTitleLength),
+	}
+	if preview.Title == "" {
+		preview.Title = truncate(cleanText(title), maxTitleLength)
+	}
+
+	if image := first("og:image:secure_url", "og:image", "og:image:url", "twitter:image", "twitter:image:src"); image != "" {
+		preview.ImageURL = resolveImageURL(image, base)
+	}
+
+	return preview
+}
+
+// resolveImageURL makes image absolute and drops anything that is not a web URL
+func resolveImageURL(image string, base *url.URL) string {
+	u, err := url.Parse(image)
+	if err != nil {
+		return ""
+	}
+	if base != nil {
+		u = base.ResolveReference(u)
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || len(u.String()) > maxURLLength {
+		return ""
+	}
+	return u.String()
+}
+
+// cleanText collapses whitespace in metadata values
+func cleanText(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// truncate shortens s to at most max runes
+func truncate(s string, max int) string {
+	if utf8.RuneCountInString(s) <= max {
+		return s
+	}
+	runes := []rune(s)
+	return string(runes[:max-1]) + "…"
+}
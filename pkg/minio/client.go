@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net/url"
 	"path/filepath"
 	"strings"
 	"time"
@@ -114,6 +115,25 @@ func (c *Client) UploadFile(ctx context.Context, objectKey string, file multipar
 	return nil
 }
 
+// UploadObject uploads generated content (not a user upload) to MinIO
+func (c *Client) UploadObject(ctx context.Context, objectKey string, reader io.Reader, size int64, contentType string) error {
+	_, err := c.client.PutObject(ctx, c.bucketName, objectKey, reader, size, minio.PutObjectOptions{
+		ContentType: contentType,
+		UserMetadata: map[string]string{
+			"upload-time": time.Now().Format(time.RFC3339),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upload object to MinIO: %w", err)
+	}
+
+	logger.Info("Object uploaded to MinIO successfully",
+		zap.String("object_key", objectKey),
+		zap.Int64("size", size))
+
+	return nil
+}
+
 // DownloadFile downloads a file from MinIO
 func (c *Client) DownloadFile(ctx context.Context, objectKey string) (io.ReadCloser, error) {
 	obj, err := c.client.GetObject(ctx, c.bucketName, objectKey, minio.GetObjectOptions{})
@@ -145,6 +165,19 @@ func (c *Client) GetPresignedURL(ctx context.Context, objectKey string, expires
 	return url.String(), nil
 }
 
+// GetPresignedDownloadURL generates a presigned URL that downloads the object as fileName
+func (c *Client) GetPresignedDownloadURL(ctx context.Context, objectKey, fileName string, expires time.Duration) (string, error) {
+	reqParams := make(url.Values)
+	reqParams.Set("response-content-disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+
+	presignedURL, err := c.client.PresignedGetObject(ctx, c.bucketName, objectKey, expires, reqParams)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate presigned URL: %w", err)
+	}
+
+	return presignedURL.String(), nil
+}
+
 // GetFileInfo gets file information from MinIO
 func (c *Client) GetFileInfo(ctx context.Context, objectKey string) (*minio.ObjectInfo, error) {
 	info, err := c.client.StatObject(ctx, c.bucketName, objectKey, minio.StatObjectOptions{})
@@ -175,7 +208,12 @@ func (c *Client) GenerateObjectKey(userID uint, originalName string) string {
 
 // GetFileType determines file type from MIME type
 func (c *Client) GetFileType(mimeType string) string {
-	mimeType = strings.ToLower(mimeType)
+	return GetFileType(mimeType)
+}
+
+// GetFileType determines file type from MIME type without needing a client
+func GetFileType(mimeType string) string {
+	mimeType = baseMimeType(mimeType)
 	
 	// Check image types
 	imageTypes := []string{
@@ -192,7 +230,7 @@ func (c *Client) GetFileType(mimeType string) string {
 	// Check audio types
 	audioTypes := []string{
 		"audio/mpeg", "audio/mp3", "audio/wav", "audio/aac",
-		"audio/ogg", "audio/flac", "audio/m4a",
+		"audio/ogg", "audio/flac", "audio/m4a", "audio/opus", "audio/x-wav", "audio/wave",
 	}
 	
 	// Check document types
@@ -267,7 +305,7 @@ func (c *Client) ValidateFileType(mimeType string) error {
 	allowedTypes := []string{
 		"image/jpeg", "image/jpg", "image/png", "image/gif", "image/webp", "image/bmp", "image/svg+xml",
 		"video/mp4", "video/avi", "video/mov", "video/wmv", "video/flv", "video/webm", "video/mkv",
-		"audio/mpeg", "audio/mp3", "audio/wav", "audio/aac", "audio/ogg", "audio/flac", "audio/m4a",
+		"audio/mpeg", "audio/mp3", "audio/wav", "audio/aac", "audio/ogg", "audio/flac", "audio/m4a", "audio/opus", "audio/x-wav", "audio/wave",
 		"application/pdf", "application/msword", "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
 		"application/vnd.ms-excel", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
 		"application/vnd.ms-powerpoint", "application/vnd.openxmlformats-officedocument.presentationml.presentation",
@@ -275,7 +313,7 @@ func (c *Client) ValidateFileType(mimeType string) error {
 		"application/zip", "application/x-rar-compressed", "application/x-7z-compressed", "application/gzip", "application/x-tar",
 	}
 	
-	mimeType = strings.ToLower(mimeType)
+	mimeType = baseMimeType(mimeType)
 	for _, allowedType := range allowedTypes {
 		if mimeType == allowedType {
 			return nil
@@ -285,3 +323,11 @@ func (c *Client) ValidateFileType(mimeType string) error {
 	return fmt.Errorf("file type %s is not allowed", mimeType)
 }
 
+
+// baseMimeType lowercases a MIME type and drops parameters such as "; codecs=opus"
+func baseMimeType(mimeType string) string {
+	if i := strings.Index(mimeType, ";"); i >= 0 {
+		mimeType = mimeType[:i]
+	}
+	return strings.ToLower(strings.TrimSpace(mimeType))
+}
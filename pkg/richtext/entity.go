@@ -0,0 +1,119 @@
+package richtext
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Entity Type Constants
+const (
+	EntityBold          = "bold"
+	EntityItalic        = "italic"
+	EntityStrikethrough = "strikethrough"
+	EntityCode          = "code"
+	EntityPre           = "pre"
+	EntityTextLink      = "text_link"
+	EntityBlockquote    = "blockquote"
+	EntityListItem      = "list_item"
+	EntityCustomEmoji   = "custom_emoji"
+)
+
+// List Type Constants
+const (
+	ListTypeBullet  = "bullet"
+	ListTypeOrdered = "ordered"
+)
+
+// Limits applied to parsed messages
+const (
+	MaxEntities  = 200
+	maxURLLength = 2048
+)
+
+// Entity marks a span of the plain text with formatting.
+// Offset and Length are measured in UTF-16 code units so they index the text the same way JavaScript strings do.
+type Entity struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	URL      string `json:"url,omitempty"`       // text_link
+	Language string `json:"language,omitempty"`  // pre
+	ListType string `json:"list_type,omitempty"` // list_item
+	Number   int    `json:"number,omitempty"`    // ordered list_item
+
+	CustomEmojiID uint `json:"custom_emoji_id,omitempty"` // custom_emoji
+}
+
+// allowedSchemes lists the link schemes that survive sanitising
+var allowedSchemes = map[string]bool{
+	"http":   true,
+	"https":  true,
+	"mailto": true,
+}
+
+// SanitizeURL returns a safe absolute link or "" if the URL must not be rendered as a link
+func SanitizeURL(raw string) string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || len(raw) > maxURLLength {
+		return ""
+	}
+	for _, r := range raw {
+		if r < 0x20 || r == 0x7f || r == ' ' {
+			return ""
+		}
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if u.Scheme == "" {
+		// Bare domains such as example.com/page are treated as https links
+		if strings.HasPrefix(raw, "/") || !strings.Contains(strings.SplitN(raw, "/", 2)[0], ".") {
+			return ""
+		}
+		u, err = url.Parse("https://" + raw)
+		if err != nil {
+			return ""
+		}
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if !allowedSchemes[scheme] {
+		return ""
+	}
+	if scheme != "mailto" && u.Host == "" {
+		return ""
+	}
+	u.Scheme = scheme
+	return u.String()
+}
+
+// Merge adds entities found after parsing, keeping the result ordered and capped
+func Merge(entities []Entity, extra ...Entity) []Entity {
+	return normalize(append(append([]Entity(nil), entities...), extra...))
+}
+
+// normalize drops empty entities, caps their number and orders them by position (outer spans first)
+func normalize(entities []Entity) []Entity {
+	result := make([]Entity, 0, len(entities))
+	for _, entity := range entities {
+		if entity.Length > 0 {
+			result = append(result, entity)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Offset != result[j].Offset {
+			return result[i].Offset < result[j].Offset
+		}
+		return result[i].Length > result[j].Length
+	})
+	if len(result) > MaxEntities {
+		result = result[:MaxEntities]
+	}
+	if len(result) == 0 {
+		return nil
+	}
+	return result
+}
@@ -0,0 +1,92 @@
+package richtext
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSanitizeURL(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"https://example.com/path?q=1", "https://example.com/path?q=1"},
+		{"HTTP://example.com", "http://example.com"},
+		{"example.com/page", "https://example.com/page"},
+		{"mailto:team@example.com", "mailto:team@example.com"},
+		{"  https://example.com  ", "https://example.com"},
+		{"javascript:alert(1)", ""},
+		{"data:text/html,hi", ""},
+		{"ftp://example.com", ""},
+		{"/relative/path", ""},
+		{"localhost", ""},
+		{"https://exa mple.com", ""},
+		{"https://example.com/\n", "https://example.com/"},
+		{"https://example.com/\x00", ""},
+		{"https://", ""},
+		{"", ""},
+		{"https://example.com/" + strings.Repeat("a", maxURLLength), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := SanitizeURL(tt.input); got != tt.want {
+				t.Errorf("SanitizeURL(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		name     string
+		entities []Entity
+		extra    []Entity
+		want     []Entity
+	}{
+		{
+			name: "nothing",
+			want: nil,
+		},
+		{
+			name:     "orders by offset then outer span first",
+			entities: []Entity{{Type: EntityItalic, Offset: 4, Length: 2}, {Type: EntityCode, Offset: 0, Length: 2}},
+			extra:    []Entity{{Type: EntityBold, Offset: 4, Length: 6}},
+			want: []Entity{
+				{Type: EntityCode, Offset: 0, Length: 2},
+				{Type: EntityBold, Offset: 4, Length: 6},
+				{Type: EntityItalic, Offset: 4, Length: 2},
+			},
+		},
+		{
+			name:     "drops empty entities",
+			entities: []Entity{{Type: EntityBold, Offset: 1, Length: 0}},
+			extra:    []Entity{{Type: EntityCustomEmoji, Offset: 0, Length: 6, CustomEmojiID: 7}},
+			want:     []Entity{{Type: EntityCustomEmoji, Offset: 0, Length: 6, CustomEmojiID: 7}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Merge(tt.entities, tt.extra...); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Merge() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMergeCapsEntities(t *testing.T) {
+	entities := make([]Entity, MaxEntities+10)
+	for i := range entities {
+		entities[i] = Entity{Type: EntityBold, Offset: i, Length: 1}
+	}
+
+	got := Merge(entities[:MaxEntities], entities[MaxEntities:]...)
+	if len(got) != MaxEntities {
+		t.Fatalf("Merge() returned %d entities, want %d", len(got), MaxEntities)
+	}
+	if got[len(got)-1].Offset != MaxEntities-1 {
+		t.Errorf("Merge() kept entity at offset %d, want the first %d", got[len(got)-1].Offset, MaxEntities)
+	}
+}
@@ -0,0 +1,258 @@
+package richtext
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+)
+
+// Block level patterns
+var (
+	fenceRegex = regexp.MustCompile("^\\s*```\\s*([A-Za-z0-9_+#.-]*)\\s*$")
+	quoteRegex = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	listRegex  = regexp.MustCompile(`^\s*(?:([-*+])|(\d{1,9})[.)])\s+(.*)$`)
+)
+
+// builder accumulates plain text and tracks the UTF-16 offset of its end
+type builder struct {
+	text     strings.Builder
+	offset   int
+	entities []Entity
+}
+
+func (b *builder) writeRune(r rune) {
+	b.text.WriteRune(r)
+	b.offset += utf16.RuneLen(r)
+}
+
+func (b *builder) writeString(s string) {
+	for _, r := range s {
+		b.writeRune(r)
+	}
+}
+
+func (b *builder) add(entity Entity, start int) {
+	entity.Offset = start
+	entity.Length = b.offset - start
+	b.entities = append(b.entities, entity)
+}
+
+// Parse converts a Markdown subset into plain text plus entity spans.
+// Supported: **bold**, __bold__, *italic*, _italic_, ~~strike~~, `code`, fenced ``` blocks,
+// [text](url) links, > quotes, and bullet or numbered lists. Backslash escapes a marker.
+func Parse(input string) (string, []Entity) {
+	input = strings.ReplaceAll(input, "\r\n", "\n")
+	lines := strings.Split(input, "\n")
+	b := &builder{}
+
+	for i := 0; i < len(lines); i++ {
+		if i > 0 {
+			b.writeRune('\n')
+		}
+		line := lines[i]
+
+		// Fenced code block: everything up to the closing fence is literal
+		if match := fenceRegex.FindStringSubmatch(line); match != nil {
+			end := i + 1
+			for end < len(lines) && !fenceRegex.MatchString(lines[end]) {
+				end++
+			}
+			start := b.offset
+			b.writeString(strings.Join(lines[i+1:min(end, len(lines))], "\n"))
+			b.add(Entity{Type: EntityPre, Language: strings.ToLower(match[1])}, start)
+			i = end
+			continue
+		}
+
+		// Block quote: consecutive quoted lines form one entity
+		if quoteRegex.MatchString(line) {
+			start := b.offset
+			for j := i; j < len(lines) && quoteRegex.MatchString(lines[j]); j++ {
+				if j > i {
+					b.writeRune('\n')
+				}
+				parseInline([]rune(quoteRegex.FindStringSubmatch(lines[j])[1]), b)
+				i = j
+			}
+			b.add(Entity{Type: EntityBlockquote}, start)
+			continue
+		}
+
+		// List item
+		if match := listRegex.FindStringSubmatch(line); match != nil {
+			entity := Entity{Type: EntityListItem, ListType: ListTypeBullet}
+			if match[2] != "" {
+				entity.ListType = ListTypeOrdered
+				entity.Number, _ = strconv.Atoi(match[2])
+			}
+			start := b.offset
+			parseInline([]rune(match[3]), b)
+			b.add(entity, start)
+			continue
+		}
+
+		parseInline([]rune(line), b)
+	}
+
+	return b.text.String(), normalize(b.entities)
+}
+
+// parseInline writes one line of inline Markdown to b
+func parseInline(runes []rune, b *builder) {
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case r == '\\' && i+1 < len(runes) && isMarker(runes[i+1]):
+			b.writeRune(runes[i+1])
+			i++
+			continue
+
+		case r == '`':
+			if end := indexRune(runes, i+1, '`'); end > i+1 {
+				start := b.offset
+				b.writeString(string(runes[i+1 : end]))
+				b.add(Entity{Type: EntityCode}, start)
+				i = end
+				continue
+			}
+
+		case r == '[':
+			if textEnd, urlEnd := findLink(runes, i); urlEnd > 0 {
+				link := SanitizeURL(string(runes[textEnd+2 : urlEnd]))
+				start := b.offset
+				parseInline(runes[i+1:textEnd], b)
+				if link != "" {
+					b.add(Entity{Type: EntityTextLink, URL: link}, start)
+				}
+				i = urlEnd
+				continue
+			}
+
+		case (r == '*' || r == '_' || r == '~') && i+1 < len(runes) && runes[i+1] == r:
+			if r == '~' || canOpen(runes, i, 2) {
+				if end := findCloser(runes, i+2, r, 2); end > i+2 {
+					entityType := EntityBold
+					if r == '~' {
+						entityType = EntityStrikethrough
+					}
+					start := b.offset
+					parseInline(runes[i+2:end], b)
+					b.add(Entity{Type: entityType}, start)
+					i = end + 1
+					continue
+				}
+			}
+
+		case r == '*' || r == '_':
+			if canOpen(runes, i, 1) {
+				if end := findCloser(runes, i+1, r, 1); end > i+1 {
+					start := b.offset
+					parseInline(runes[i+1:end], b)
+					b.add(Entity{Type: EntityItalic}, start)
+					i = end
+					continue
+				}
+			}
+		}
+
+		b.writeRune(r)
+	}
+}
+
+// canOpen reports whether a delimiter run at i may start emphasis: it must be followed by
+// non-space, and underscores inside words (snake_case) never count
+func canOpen(runes []rune, i, size int) bool {
+	if i+size >= len(runes) || unicode.IsSpace(runes[i+size]) {
+		return false
+	}
+	if runes[i] == '_' && i > 0 && isWordRune(runes[i-1]) {
+		return false
+	}
+	return true
+}
+
+// findCloser finds the closing delimiter run for emphasis opened before from, skipping code spans and escapes
+func findCloser(runes []rune, from int, delim rune, size int) int {
+	for i := from; i+size <= len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			i++
+			continue
+		case '`':
+			if end := indexRune(runes, i+1, '`'); end > 0 {
+				i = end
+				continue
+			}
+		}
+
+		if !hasRun(runes, i, delim, size) || unicode.IsSpace(runes[i-1]) {
+			continue
+		}
+		// A single delimiter must not be half of a double one
+		if size == 1 && (hasRun(runes, i+1, delim, 1) || (i > from && runes[i-1] == delim)) {
+			continue
+		}
+		if delim == '_' && i+size < len(runes) && isWordRune(runes[i+size]) {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+// findLink returns the index of "]" and of the closing ")" for a [text](url) link starting at i
+func findLink(runes []rune, i int) (int, int) {
+	textEnd := indexRune(runes, i+1, ']')
+	if textEnd <= i+1 || textEnd+1 >= len(runes) || runes[textEnd+1] != '(' {
+		return -1, -1
+	}
+	// URLs may contain balanced parentheses, e.g. wiki links
+	depth := 0
+	for j := textEnd + 2; j < len(runes); j++ {
+		switch runes[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				if j == textEnd+2 {
+					return -1, -1
+				}
+				return textEnd, j
+			}
+			depth--
+		}
+	}
+	return -1, -1
+}
+
+func hasRun(runes []rune, i int, delim rune, size int) bool {
+	if i < 0 || i+size > len(runes) {
+		return false
+	}
+	for j := i; j < i+size; j++ {
+		if runes[j] != delim {
+			return false
+		}
+	}
+	return true
+}
+
+func indexRune(runes []rune, from int, target rune) int {
+	for i := from; i < len(runes); i++ {
+		if runes[i] == target {
+			return i
+		}
+	}
+	return -1
+}
+
+func isMarker(r rune) bool {
+	return strings.ContainsRune("\\`*_~[]()>#+-.!|", r)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,161 @@
+package richtext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		wantText     string
+		wantEntities []Entity
+	}{
+		{
+			name:     "plain text",
+			input:    "hello world",
+			wantText: "hello world",
+		},
+		{
+			name:     "bold and italic",
+			input:    "**bold** and *it*",
+			wantText: "bold and it",
+			wantEntities: []Entity{
+				{Type: EntityBold, Offset: 0, Length: 4},
+				{Type: EntityItalic, Offset: 9, Length: 2},
+			},
+		},
+		{
+			name:     "underscore markers",
+			input:    "__b__ _i_",
+			wantText: "b i",
+			wantEntities: []Entity{
+				{Type: EntityBold, Offset: 0, Length: 1},
+				{Type: EntityItalic, Offset: 2, Length: 1},
+			},
+		},
+		{
+			name:     "snake_case is not italic",
+			input:    "snake_case_name",
+			wantText: "snake_case_name",
+		},
+		{
+			name:     "strikethrough",
+			input:    "~~gone~~",
+			wantText: "gone",
+			wantEntities: []Entity{
+				{Type: EntityStrikethrough, Offset: 0, Length: 4},
+			},
+		},
+		{
+			name:     "nested emphasis",
+			input:    "**a *b* c**",
+			wantText: "a b c",
+			wantEntities: []Entity{
+				{Type: EntityBold, Offset: 0, Length: 5},
+				{Type: EntityItalic, Offset: 2, Length: 1},
+			},
+		},
+		{
+			name:     "unclosed marker stays literal",
+			input:    "**unclosed",
+			wantText: "**unclosed",
+		},
+		{
+			name:     "escaped markers",
+			input:    `\*not italic\*`,
+			wantText: "*not italic*",
+		},
+		{
+			name:     "code span is literal",
+			input:    "a `*x*` b",
+			wantText: "a *x* b",
+			wantEntities: []Entity{
+				{Type: EntityCode, Offset: 2, Length: 3},
+			},
+		},
+		{
+			name:     "link",
+			input:    "see [site](https://example.com/a_(b))",
+			wantText: "see site",
+			wantEntities: []Entity{
+				{Type: EntityTextLink, Offset: 4, Length: 4, URL: "https://example.com/a_(b)"},
+			},
+		},
+		{
+			name:     "bare domain link",
+			input:    "[site](example.com)",
+			wantText: "site",
+			wantEntities: []Entity{
+				{Type: EntityTextLink, Offset: 0, Length: 4, URL: "https://example.com"},
+			},
+		},
+		{
+			name:     "unsafe link keeps only the text",
+			input:    "[click](javascript:alert(1))",
+			wantText: "click",
+		},
+		{
+			name:     "block quote",
+			input:    "> one\n> two\nafter",
+			wantText: "one\ntwo\nafter",
+			wantEntities: []Entity{
+				{Type: EntityBlockquote, Offset: 0, Length: 7},
+			},
+		},
+		{
+			name:     "lists",
+			input:    "- first\n2. second",
+			wantText: "first\nsecond",
+			wantEntities: []Entity{
+				{Type: EntityListItem, Offset: 0, Length: 5, ListType: ListTypeBullet},
+				{Type: EntityListItem, Offset: 6, Length: 6, ListType: ListTypeOrdered, Number: 2},
+			},
+		},
+		{
+			name:     "fenced code block",
+			input:    "```Go\nx := *y*\n```\ndone",
+			wantText: "x := *y*\ndone",
+			wantEntities: []Entity{
+				{Type: EntityPre, Offset: 0, Length: 8, Language: "go"},
+			},
+		},
+		{
+			name:     "unterminated fence runs to the end",
+			input:    "```\ncode",
+			wantText: "code",
+			wantEntities: []Entity{
+				{Type: EntityPre, Offset: 0, Length: 4},
+			},
+		},
+		{
+			name:     "offsets count UTF-16 code units",
+			input:    "😀 **b**",
+			wantText: "😀 b",
+			wantEntities: []Entity{
+				{Type: EntityBold, Offset: 3, Length: 1},
+			},
+		},
+		{
+			name:     "CRLF line endings",
+			input:    "a\r\n**b**",
+			wantText: "a\nb",
+			wantEntities: []Entity{
+				{Type: EntityBold, Offset: 2, Length: 1},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, entities := Parse(tt.input)
+			if text != tt.wantText {
+				t.Errorf("Parse(%q) text = %q, want %q", tt.input, text, tt.wantText)
+			}
+			if !reflect.DeepEqual(entities, tt.wantEntities) {
+				t.Errorf("Parse(%q) entities = %+v, want %+v", tt.input, entities, tt.wantEntities)
+			}
+		})
+	}
+}
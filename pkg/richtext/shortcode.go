@@ -0,0 +1,74 @@
+package richtext
+
+import (
+	"regexp"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// shortcodeRegex matches :shortcode: tokens; names follow the custom emoji rules
+var shortcodeRegex = regexp.MustCompile(`:([a-z0-9_+-]{2,32}):`)
+
+// Shortcode is a :shortcode: token found in plain text
+type Shortcode struct {
+	Name   string // Without the colons
+	Offset int    // UTF-16 code units, like Entity.Offset
+	Length int
+}
+
+// FindShortcodes returns the :shortcode: tokens of text that stand on their own and are not inside code or pre spans
+func FindShortcodes(text string, entities []Entity) []Shortcode {
+	var shortcodes []Shortcode
+	offset, consumed := 0, 0
+	for _, match := range shortcodeRegex.FindAllStringSubmatchIndex(text, -1) {
+		start, end := match[0], match[1]
+
+		// Tokens glued to words, such as times like 10:30:45, are not shortcodes
+		if before, _ := utf8.DecodeLastRuneInString(text[:start]); start > 0 && isShortcodeNeighbour(before) {
+			continue
+		}
+		if after, _ := utf8.DecodeRuneInString(text[end:]); end < len(text) && isShortcodeNeighbour(after) {
+			continue
+		}
+
+		offset += utf16Len(text[consumed:start])
+		consumed = start
+		shortcode := Shortcode{
+			Name:   text[match[2]:match[3]],
+			Offset: offset,
+			Length: utf16Len(text[start:end]),
+		}
+		if !insideCode(shortcode, entities) {
+			shortcodes = append(shortcodes, shortcode)
+		}
+	}
+	return shortcodes
+}
+
+// isShortcodeNeighbour reports whether r would join a shortcode to the surrounding word
+func isShortcodeNeighbour(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// insideCode reports whether a shortcode overlaps a code or pre entity
+func insideCode(shortcode Shortcode, entities []Entity) bool {
+	for _, entity := range entities {
+		if entity.Type != EntityCode && entity.Type != EntityPre {
+			continue
+		}
+		if shortcode.Offset < entity.Offset+entity.Length && entity.Offset < shortcode.Offset+shortcode.Length {
+			return true
+		}
+	}
+	return false
+}
+
+// utf16Len returns the length of s in UTF-16 code units
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
@@ -0,0 +1,79 @@
+package richtext
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFindShortcodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		entities []Entity
+		want     []Shortcode
+	}{
+		{
+			name: "single shortcode",
+			text: "hi :wave:",
+			want: []Shortcode{{Name: "wave", Offset: 3, Length: 6}},
+		},
+		{
+			name: "several shortcodes",
+			text: ":party_parrot: and :+1:",
+			want: []Shortcode{
+				{Name: "party_parrot", Offset: 0, Length: 14},
+				{Name: "+1", Offset: 19, Length: 4},
+			},
+		},
+		{
+			name: "adjacent shortcodes",
+			text: ":ab::cd:",
+			want: []Shortcode{
+				{Name: "ab", Offset: 0, Length: 4},
+				{Name: "cd", Offset: 4, Length: 4},
+			},
+		},
+		{
+			name: "times are not shortcodes",
+			text: "at 10:30:45 today",
+		},
+		{
+			name: "glued to a word",
+			text: "word:wave: and :wave:word",
+		},
+		{
+			name: "too short or invalid names",
+			text: ":a: :Wave: :two words:",
+		},
+		{
+			name:     "inside code",
+			text:     "use :wave: or `:wave:`",
+			entities: []Entity{{Type: EntityCode, Offset: 14, Length: 6}},
+			want:     []Shortcode{{Name: "wave", Offset: 4, Length: 6}},
+		},
+		{
+			name:     "inside pre",
+			text:     ":wave:",
+			entities: []Entity{{Type: EntityPre, Offset: 0, Length: 6}},
+		},
+		{
+			name:     "other entities do not hide shortcodes",
+			text:     ":wave:",
+			entities: []Entity{{Type: EntityBold, Offset: 0, Length: 6}},
+			want:     []Shortcode{{Name: "wave", Offset: 0, Length: 6}},
+		},
+		{
+			name: "offsets count UTF-16 code units",
+			text: "😀 é :wave:",
+			want: []Shortcode{{Name: "wave", Offset: 5, Length: 6}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := FindShortcodes(tt.text, tt.entities); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindShortcodes(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
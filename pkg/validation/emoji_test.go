@@ -0,0 +1,107 @@
+package validation
+
+import "testing"
+
+func TestIsEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  bool
+	}{
+		{"emoji presentation", "😀", true},
+		{"text default with VS16", "❤️", true},
+		{"skin tone", "👍🏽", true},
+		{"text default with skin tone", "✌🏻", true},
+		{"flag", "🇻🇳", true},
+		{"keycap", "1️⃣", true},
+		{"ZWJ flag", "🏳️‍🌈", true},
+		{"ZWJ with text default base", "❤️‍🔥", true},
+		{"ZWJ of two text defaults", "👁️‍🗨️", true},
+		{"ZWJ with skin tone", "🧑🏽‍💻", true},
+		{"ZWJ with gender sign", "⛹️‍♀️", true},
+		{"subdivision flag", "🏴\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", true},
+
+		{"empty", "", false},
+		{"letter", "a", false},
+		{"arrow", "→", false},
+		{"geometric shape", "■", false},
+		{"text default without VS16", "❤", false},
+		{"text default with VS15", "❤︎", false},
+		{"keycap without VS16", "1⃣", false},
+		{"digit", "1", false},
+		{"single regional indicator", "🇻", false},
+		{"trailing ZWJ", "😀‍", false},
+		{"leading modifier", "🏽", false},
+		{"skin tone on an emoji without tones", "😀🏽", false},
+		{"skin tone on a text default without tones", "✔🏻", false},
+		{"skin tone after VS16", "👍️🏽", false},
+		{"two emoji", "😀😀", false},
+		{"emoji and text", "😀a", false},
+		{"invalid UTF-8", "\xff", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsEmoji(tt.input); got != tt.want {
+				t.Errorf("IsEmoji(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeEmoji(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"emoji presentation unchanged", "😀", "😀"},
+		{"emoji presentation drops VS16", "😀️", "😀"},
+		{"adds VS16 to text default", "❤", "❤️"},
+		{"keeps fully qualified", "❤️", "❤️"},
+		{"replaces VS15", "❤︎", "❤️"},
+		{"no VS16 before skin tone", "✌️🏻", "✌🏻"},
+		{"keycap", "1⃣", "1️⃣"},
+		{"plain digit unchanged", "1", "1"},
+		{"ZWJ sequence", "🏳‍🌈", "🏳️‍🌈"},
+		{"ZWJ with text defaults", "👁‍🗨", "👁️‍🗨️"},
+		{"flag unchanged", "🇻🇳", "🇻🇳"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeEmoji(tt.input); got != tt.want {
+				t.Errorf("NormalizeEmoji(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got := NormalizeEmoji(tt.want); got != tt.want {
+				t.Errorf("NormalizeEmoji(%q) = %q, want it unchanged", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIsCustomEmoji(t *testing.T) {
+	tests := []struct {
+		input string
+		want  bool
+	}{
+		{":party_parrot:", true},
+		{":+1:", true},
+		{":a-b:", true},
+		{":ab:", true},
+		{":a:", false},
+		{":Party:", false},
+		{"party_parrot", false},
+		{":party parrot:", false},
+		{":" + "abcdefghijklmnopqrstuvwxyzabcdefg" + ":", false},
+		{"😀", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsCustomEmoji(tt.input); got != tt.want {
+				t.Errorf("IsCustomEmoji(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}